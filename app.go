@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"path/filepath"
 
 	"llm-desk/internal/logger"
 	"llm-desk/internal/models"
@@ -9,6 +10,8 @@ import (
 	"llm-desk/internal/storage"
 	"llm-desk/internal/updater"
 	"llm-desk/internal/version"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct - main application with all services
@@ -17,43 +20,72 @@ type App struct {
 	storage         *storage.Storage
 	providerService *services.ProviderService
 	settingsService *services.SettingsService
+	keyBackend      *services.KeyBackendService
+	reconciler      *services.ReconcileService
+	watchService    *services.WatchService
 	exportService   *services.ExportService
+	backupService   *services.BackupService
 	fetcher         *services.ModelFetcher
+	location        storage.Location
 	initError       error // Stores initialization error for graceful handling
 }
 
 // NewApp creates a new App application struct
 // Returns App even on error - check initError for initialization failures
-func NewApp() *App {
+func NewApp(opts LaunchOptions) *App {
 	defer logger.Recovery()
 	app := &App{}
 
+	// Resolve the data directory first so logs follow it (portable mode, --data-dir)
+	loc, locErr := storage.ResolveLocation(opts.locationOptions())
+
 	// Initialize logger first
-	if err := logger.Init(logger.Config{
+	logCfg := logger.Config{
 		Level:   "info",
 		Console: true,
-	}); err != nil {
+	}
+	if locErr == nil {
+		logCfg.LogDir = filepath.Join(loc.DataDir, "logs")
+	}
+	if err := logger.Init(logCfg); err != nil {
 		// Log to stderr if logger fails, but continue
 		println("Warning: Failed to initialize logger:", err.Error())
 	}
 
 	logger.Info("Starting LLM Desk application")
 
+	if locErr != nil {
+		logger.Error("Failed to resolve data directory", "error", locErr)
+		app.initError = locErr
+		return app
+	}
+	app.location = loc
+
 	// Initialize storage
-	store, err := storage.New()
+	keyring := storage.NewKeyringStore()
+	store, err := storage.NewWithDir(loc.DataDir, keyring)
 	if err != nil {
 		logger.Error("Failed to initialize storage", "error", err)
 		app.initError = err
 		return app
 	}
 
-	logger.Info("Storage initialized", "dataDir", store.GetDataDir())
+	logger.Info("Storage initialized", "dataDir", store.GetDataDir(), "source", loc.Source, "portable", loc.Portable)
 
 	app.storage = store
 	app.providerService = services.NewProviderService(store)
 	app.settingsService = services.NewSettingsService(store)
+
+	// Pick the OS keyring or the encrypted vault for API keys
+	vault := storage.NewFileVault(filepath.Join(loc.DataDir, storage.VaultFilename))
+	app.keyBackend = services.NewKeyBackendService(store, app.settingsService, keyring, vault, storage.ProbeKeyring, loc.Portable)
+	app.keyBackend.Init()
+	app.reconciler = services.NewReconcileService(store)
 	app.exportService = services.NewExportService(store)
-	app.fetcher = services.NewModelFetcher()
+	app.backupService = services.NewBackupService(store, app.settingsService, app.exportService)
+	app.fetcher = services.NewModelFetcher(app.settingsService)
+	app.watchService = services.NewWatchService(store, app.settingsService,
+		storage.NewInstanceLock(loc.DataDir), storage.DefaultWatchInterval, app.emitEvent)
 
 	// Clean old logs on startup (keep 7 days)
 	go func() {
@@ -86,9 +118,22 @@ func (a *App) startup(ctx context.Context) {
 	if a.exportService != nil {
 		a.exportService.SetContext(ctx)
 	}
+	if a.watchService != nil {
+		a.watchService.Start()
+	}
+	if a.backupService != nil {
+		a.backupService.Start()
+	}
 	logger.Info("Application startup complete", "version", version.GetVersion())
 }
 
+// emitEvent sends an event to the frontend once the runtime is available
+func (a *App) emitEvent(event string, data interface{}) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, event, data)
+	}
+}
+
 // GetVersion returns the application version
 func (a *App) GetVersion() string {
 	return version.GetVersion()
@@ -102,6 +147,12 @@ func (a *App) CheckForUpdates() (*updater.UpdateInfo, error) {
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
 	logger.Info("Application shutting down")
+	if a.watchService != nil {
+		a.watchService.Stop()
+	}
+	if a.backupService != nil {
+		a.backupService.Stop()
+	}
 	if err := logger.Get().Close(); err != nil {
 		println("Warning: Failed to close logger:", err.Error())
 	}
@@ -161,6 +212,158 @@ func (a *App) SetCrashReporting(enabled bool) error {
 	return a.settingsService.SetCrashReporting(enabled)
 }
 
+// ============================================
+// API Key Backend
+// ============================================
+
+// GetKeyBackendStatus returns which backend stores API keys and its state
+func (a *App) GetKeyBackendStatus() services.KeyBackendStatus {
+	if a.keyBackend == nil {
+		return services.KeyBackendStatus{}
+	}
+	return a.keyBackend.Status()
+}
+
+// UnlockVault unlocks the encrypted key vault, creating it on first use
+func (a *App) UnlockVault(passphrase string) error {
+	if a.keyBackend == nil {
+		return a.initError
+	}
+	err := a.keyBackend.UnlockVault(passphrase)
+	if err != nil {
+		logger.Warn("Failed to unlock key vault", "error", err)
+	}
+	return err
+}
+
+// LockVault locks the encrypted key vault
+func (a *App) LockVault() {
+	if a.keyBackend != nil {
+		a.keyBackend.LockVault()
+	}
+}
+
+// SetKeyBackend switches the API key backend ("auto", "keyring" or "vault"),
+// migrating existing keys. passphrase is needed while the vault is locked.
+func (a *App) SetKeyBackend(backend, passphrase string) error {
+	if a.keyBackend == nil {
+		return a.initError
+	}
+	logger.Info("Switching key backend", "backend", backend)
+	err := a.keyBackend.SetBackend(backend, passphrase)
+	if err != nil {
+		logger.Error("Failed to switch key backend", "backend", backend, "error", err)
+	}
+	return err
+}
+
+// GetKeyringHealth returns the key storage status seen by the last load
+func (a *App) GetKeyringHealth() services.KeyringHealth {
+	if a.reconciler == nil {
+		return services.KeyringHealth{Status: services.KeyringHealthUnavailable, Message: a.GetInitError()}
+	}
+	return a.reconciler.Health()
+}
+
+// ScanKeyring checks every provider against key storage and lists
+// orphaned, unreadable and plaintext keys
+func (a *App) ScanKeyring() (services.KeyringHealth, error) {
+	if a.reconciler == nil {
+		return services.KeyringHealth{}, a.initError
+	}
+	health, err := a.reconciler.Scan()
+	if err != nil {
+		logger.Error("Keyring scan failed", "error", err)
+	} else if health.Status != services.KeyringHealthOK {
+		logger.Warn("Keyring scan found issues", "status", health.Status, "issues", len(health.Issues))
+	}
+	return health, err
+}
+
+// RepairKeyIssue applies the repair action for an issue found by ScanKeyring
+func (a *App) RepairKeyIssue(kind, providerID string) error {
+	if a.reconciler == nil {
+		return a.initError
+	}
+	logger.Info("Repairing key issue", "kind", kind, "providerId", providerID)
+	err := a.reconciler.Repair(kind, providerID)
+	if err != nil {
+		logger.Error("Failed to repair key issue", "kind", kind, "providerId", providerID, "error", err)
+	}
+	return err
+}
+
+// ============================================
+// Encryption at Rest
+// ============================================
+
+// GetEncryptionStatus reports whether providers.json and settings.json are encrypted
+func (a *App) GetEncryptionStatus() storage.EncryptionStatus {
+	if a.storage == nil {
+		return storage.EncryptionStatus{}
+	}
+	return a.storage.EncryptionStatus()
+}
+
+// EnableEncryption encrypts the data files with a key kept in the key backend
+// ("keyring") or derived from passphrase ("passphrase")
+func (a *App) EnableEncryption(source, passphrase string) error {
+	if a.storage == nil {
+		return a.initError
+	}
+	logger.Info("Enabling encryption at rest", "keySource", source)
+	err := a.storage.EnableEncryption(source, passphrase)
+	if err != nil {
+		logger.Error("Failed to enable encryption at rest", "error", err)
+	}
+	return err
+}
+
+// DisableEncryption rewrites the data files in plaintext
+func (a *App) DisableEncryption() error {
+	if a.storage == nil {
+		return a.initError
+	}
+	logger.Info("Disabling encryption at rest")
+	err := a.storage.DisableEncryption()
+	if err != nil {
+		logger.Error("Failed to disable encryption at rest", "error", err)
+	}
+	return err
+}
+
+// UnlockData unlocks passphrase-encrypted data files and reloads settings
+func (a *App) UnlockData(passphrase string) error {
+	if a.storage == nil {
+		return a.initError
+	}
+	if err := a.storage.UnlockData(passphrase); err != nil {
+		logger.Warn("Failed to unlock data files", "error", err)
+		return err
+	}
+	if _, err := a.settingsService.Reload(); err != nil {
+		logger.Warn("Failed to reload settings after unlock", "error", err)
+	}
+	// The saved key backend preference was unreadable until now
+	a.keyBackend.Init()
+	return nil
+}
+
+// ReencryptBackup rewrites an encrypted backup chosen by the user in the
+// current encryption format
+func (a *App) ReencryptBackup(passphrase string) (bool, error) {
+	if a.exportService == nil {
+		return false, a.initError
+	}
+	upgraded, err := a.exportService.ReencryptBackup(passphrase)
+	if err != nil {
+		logger.Error("Failed to re-encrypt backup", "error", err)
+	} else if upgraded {
+		logger.Info("Re-encrypted backup in the current format")
+	}
+	return upgraded, err
+}
+
 // ============================================
 // Provider Operations
 // ============================================
@@ -224,16 +427,54 @@ func (a *App) DeleteProvider(id string) error {
 	return err
 }
 
-// UpdateCredentials updates provider API keys
-func (a *App) UpdateCredentials(providerID string, keys []string) error {
+// UpdateCredentials updates provider API keys. revision is the provider
+// revision the caller loaded; 0 skips the conflict check.
+func (a *App) UpdateCredentials(providerID string, keys []string, revision int64) error {
 	if a.providerService == nil {
 		return a.initError
 	}
 	logger.Info("Updating credentials", "providerId", providerID, "keyCount", len(keys))
-	return a.providerService.UpdateCredentials(providerID, keys)
+	return a.providerService.UpdateCredentials(providerID, keys, revision)
+}
+
+// AddAPIKey adds a labelled key record to a provider
+func (a *App) AddAPIKey(providerID string, key models.APIKey, revision int64) (*models.APIKey, error) {
+	if a.providerService == nil {
+		return nil, a.initError
+	}
+	logger.Info("Adding API key", "providerId", providerID, "label", key.Label)
+	return a.providerService.AddAPIKey(providerID, key, revision)
+}
+
+// UpdateAPIKey updates the metadata of a key record, rotating it if a new secret is given
+func (a *App) UpdateAPIKey(providerID, keyID string, updates models.APIKey, revision int64) error {
+	if a.providerService == nil {
+		return a.initError
+	}
+	logger.Info("Updating API key", "providerId", providerID, "keyId", keyID)
+	return a.providerService.UpdateAPIKey(providerID, keyID, updates, revision)
+}
+
+// SetAPIKeyEnabled enables or disables a key record
+func (a *App) SetAPIKeyEnabled(providerID, keyID string, enabled bool, revision int64) error {
+	if a.providerService == nil {
+		return a.initError
+	}
+	logger.Info("Setting API key enabled", "providerId", providerID, "keyId", keyID, "enabled", enabled)
+	return a.providerService.SetAPIKeyEnabled(providerID, keyID, enabled, revision)
 }
 
-// SaveProviders saves all providers (bulk operation)
+// RemoveAPIKey removes a key record and its secret
+func (a *App) RemoveAPIKey(providerID, keyID string, revision int64) error {
+	if a.providerService == nil {
+		return a.initError
+	}
+	logger.Info("Removing API key", "providerId", providerID, "keyId", keyID)
+	return a.providerService.RemoveAPIKey(providerID, keyID, revision)
+}
+
+// SaveProviders saves all providers (bulk operation). Every stored provider
+// must be in the list; use DeleteProvider to remove one.
 func (a *App) SaveProviders(providers []models.Provider) error {
 	if a.providerService == nil {
 		return a.initError
@@ -246,13 +487,14 @@ func (a *App) SaveProviders(providers []models.Provider) error {
 // Model Operations
 // ============================================
 
-// AddModel adds a model to a provider
-func (a *App) AddModel(providerID string, m models.Model) error {
+// AddModel adds a model to a provider. revision is the provider revision
+// the caller loaded; 0 skips the conflict check.
+func (a *App) AddModel(providerID string, m models.Model, revision int64) error {
 	if a.providerService == nil {
 		return a.initError
 	}
 	logger.Info("Adding model", "providerId", providerID, "modelId", m.ID)
-	err := a.providerService.AddModel(providerID, m)
+	err := a.providerService.AddModel(providerID, m, revision)
 	if err != nil {
 		logger.Error("Failed to add model", "providerId", providerID, "modelId", m.ID, "error", err)
 	}
@@ -260,12 +502,12 @@ func (a *App) AddModel(providerID string, m models.Model) error {
 }
 
 // UpdateModel updates a model in a provider
-func (a *App) UpdateModel(providerID, modelID string, updates models.Model) error {
+func (a *App) UpdateModel(providerID, modelID string, updates models.Model, revision int64) error {
 	if a.providerService == nil {
 		return a.initError
 	}
 	logger.Info("Updating model", "providerId", providerID, "modelId", modelID)
-	err := a.providerService.UpdateModel(providerID, modelID, updates)
+	err := a.providerService.UpdateModel(providerID, modelID, updates, revision)
 	if err != nil {
 		logger.Error("Failed to update model", "providerId", providerID, "modelId", modelID, "error", err)
 	}
@@ -273,12 +515,12 @@ func (a *App) UpdateModel(providerID, modelID string, updates models.Model) erro
 }
 
 // DeleteModel removes a model from a provider
-func (a *App) DeleteModel(providerID, modelID string) error {
+func (a *App) DeleteModel(providerID, modelID string, revision int64) error {
 	if a.providerService == nil {
 		return a.initError
 	}
 	logger.Info("Deleting model", "providerId", providerID, "modelId", modelID)
-	err := a.providerService.DeleteModel(providerID, modelID)
+	err := a.providerService.DeleteModel(providerID, modelID, revision)
 	if err != nil {
 		logger.Error("Failed to delete model", "providerId", providerID, "modelId", modelID, "error", err)
 	}
@@ -291,11 +533,47 @@ func (a *App) DeleteModel(providerID, modelID string) error {
 
 // FetchModels fetches available models from a provider's API
 func (a *App) FetchModels(baseURL, apiKey string, anthropicURL *string) models.FetchModelsResult {
+	return a.FetchProtocolModels("", baseURL, apiKey, anthropicURL)
+}
+
+// FetchProtocolModels fetches available models with the model-list API of
+// protocol, such as "gemini" or "ollama"; an empty protocol is detected
+func (a *App) FetchProtocolModels(protocol models.ProviderProtocol, baseURL, apiKey string, anthropicURL *string) models.FetchModelsResult {
 	if a.fetcher == nil {
 		return models.FetchModelsResult{Error: "Application not initialized"}
 	}
-	logger.Debug("Fetching models", "baseURL", baseURL)
-	return a.fetcher.FetchModels(baseURL, apiKey, anthropicURL)
+	logger.Debug("Fetching models", "protocol", protocol, "baseURL", baseURL)
+	result := a.fetcher.FetchProtocolModels(protocol, baseURL, apiKey, anthropicURL)
+	if result.ErrorKind != "" {
+		logger.Warn("Failed to fetch models", "baseURL", baseURL, "kind", result.ErrorKind, "attempts", len(result.Attempts))
+	}
+	if result.Truncated {
+		logger.Warn("Model list truncated at fetch cap", "baseURL", baseURL, "models", len(result.Models), "pages", result.Pages)
+	}
+	return result
+}
+
+// GetModelFetchSettings returns the page and model caps for fetching
+// paginated model lists
+func (a *App) GetModelFetchSettings() storage.ModelFetchSettings {
+	if a.settingsService == nil {
+		return storage.DefaultModelFetchSettings()
+	}
+	return a.settingsService.GetModelFetchSettings()
+}
+
+// SetModelFetchSettings saves the page and model caps for fetching
+// paginated model lists
+func (a *App) SetModelFetchSettings(settings storage.ModelFetchSettings) error {
+	if a.settingsService == nil {
+		return a.initError
+	}
+	logger.Info("Updating model fetch settings", "maxPages", settings.MaxPages, "maxModels", settings.MaxModels)
+	err := a.settingsService.SetModelFetchSettings(settings)
+	if err != nil {
+		logger.Error("Failed to update model fetch settings", "error", err)
+	}
+	return err
 }
 
 // TransformFetchedModel converts a fetched model to our Model type
@@ -307,22 +585,176 @@ func (a *App) TransformFetchedModel(fetched models.FetchedModel) models.Model {
 // Import/Export Operations
 // ============================================
 
-// ExportData exports all provider data to a user-selected file
-func (a *App) ExportData() (bool, error) {
+// ExportData exports all provider data to a user-selected file. opts decides
+// whether API keys are excluded, included in plaintext or encrypted.
+func (a *App) ExportData(opts models.ExportOptions) (models.ExportResult, error) {
 	if a.exportService == nil {
-		return false, a.initError
+		return models.ExportResult{}, a.initError
+	}
+	logger.Info("Exporting data", "secrets", opts.Secrets)
+	result, err := a.exportService.ExportData(opts)
+	if result.ContainsPlaintextSecrets {
+		logger.Warn("Exported backup contains plaintext API keys")
 	}
-	logger.Info("Exporting data")
-	return a.exportService.ExportData()
+	return result, err
 }
 
-// ImportData imports provider data from a user-selected file
-func (a *App) ImportData(mode string) (models.ImportResult, error) {
+// GetDataSchema returns the JSON Schema of the export and backup format
+func (a *App) GetDataSchema() string {
+	return string(services.DataSchema())
+}
+
+// ValidateData checks an export in JSON form against the data schema and
+// returns where it does not match; an empty list means it is valid
+func (a *App) ValidateData(content string) ([]models.SchemaError, error) {
+	return services.ValidateDataJSON([]byte(content))
+}
+
+// ExportToolConfig generates a LiteLLM, Continue, Aider, Open WebUI or
+// Cline config from the catalog and saves it to a user-selected file
+func (a *App) ExportToolConfig(opts models.ToolConfigOptions) (models.ToolConfigResult, error) {
+	if a.exportService == nil {
+		return models.ToolConfigResult{}, a.initError
+	}
+	logger.Info("Exporting tool config", "format", opts.Format, "keys", opts.Keys)
+	result, err := a.exportService.ExportToolConfig(opts)
+	if err != nil {
+		logger.Error("Failed to export tool config", "format", opts.Format, "error", err)
+	} else if result.ContainsPlaintextSecrets {
+		logger.Warn("Exported tool config contains plaintext API keys")
+	}
+	return result, err
+}
+
+// WriteToolConfig generates a tool config from the catalog and writes it to
+// path without a dialog
+func (a *App) WriteToolConfig(path string, opts models.ToolConfigOptions) (models.ToolConfigResult, error) {
+	if a.exportService == nil {
+		return models.ToolConfigResult{}, a.initError
+	}
+	logger.Info("Writing tool config", "format", opts.Format, "keys", opts.Keys)
+	result, err := a.exportService.WriteToolConfig(path, opts)
+	if err != nil {
+		logger.Error("Failed to write tool config", "format", opts.Format, "error", err)
+	} else if result.ContainsPlaintextSecrets {
+		logger.Warn("Written tool config contains plaintext API keys")
+	}
+	return result, err
+}
+
+// ShareProviderTemplate returns the given providers, without credentials,
+// as a share string to copy and paste
+func (a *App) ShareProviderTemplate(providerIDs []string) (models.TemplateExportResult, error) {
+	if a.exportService == nil {
+		return models.TemplateExportResult{}, a.initError
+	}
+	logger.Info("Sharing provider template", "providers", providerIDs)
+	result, err := a.exportService.ShareTemplate(providerIDs)
+	if err != nil {
+		logger.Error("Failed to share provider template", "error", err)
+	}
+	return result, err
+}
+
+// ExportProviderTemplate saves the given providers, without credentials, as
+// a template file
+func (a *App) ExportProviderTemplate(providerIDs []string) (models.TemplateExportResult, error) {
+	if a.exportService == nil {
+		return models.TemplateExportResult{}, a.initError
+	}
+	logger.Info("Exporting provider template", "providers", providerIDs)
+	result, err := a.exportService.ExportTemplate(providerIDs)
+	if err != nil {
+		logger.Error("Failed to export provider template", "error", err)
+	}
+	return result, err
+}
+
+// ImportProviderTemplate creates providers from a user-selected template file
+func (a *App) ImportProviderTemplate() (models.TemplateResult, error) {
+	if a.exportService == nil {
+		return models.TemplateResult{}, a.initError
+	}
+	result, err := a.exportService.ImportTemplate()
+	if err != nil {
+		logger.Error("Failed to import provider template", "error", err)
+	} else if result.Success {
+		logger.Info("Created providers from template", "providers", result.NeedsKeys)
+	}
+	return result, err
+}
+
+// InstantiateProviderTemplate creates providers from a pasted share string
+func (a *App) InstantiateProviderTemplate(share string) (models.TemplateResult, error) {
+	if a.exportService == nil {
+		return models.TemplateResult{}, a.initError
+	}
+	result, err := a.exportService.InstantiateTemplate(share)
+	if err != nil {
+		logger.Error("Failed to create providers from template", "error", err)
+	} else {
+		logger.Info("Created providers from template", "providers", result.NeedsKeys)
+	}
+	return result, err
+}
+
+// ImportData imports provider data from a user-selected file. opts sets the
+// merge policy and which duplicate providers to merge.
+func (a *App) ImportData(mode string, opts models.MergeOptions) (models.ImportResult, error) {
+	if a.exportService == nil {
+		return models.ImportResult{}, a.initError
+	}
+	logger.Info("Importing data", "mode", mode, "policy", opts.Policy)
+	return a.exportService.ImportData(mode, opts)
+}
+
+// PreviewImport lists what importing a user-selected backup would change.
+// opts sets the merge policy and which duplicate providers to merge.
+func (a *App) PreviewImport(mode string, opts models.MergeOptions) (models.ImportPreview, error) {
+	if a.exportService == nil {
+		return models.ImportPreview{}, a.initError
+	}
+	logger.Info("Previewing import", "mode", mode, "policy", opts.Policy)
+	return a.exportService.PreviewImport(mode, opts)
+}
+
+// PreviewImportWithPassphrase previews the encrypted backup selected by
+// PreviewImport
+func (a *App) PreviewImportWithPassphrase(mode, passphrase string, opts models.MergeOptions) (models.ImportPreview, error) {
+	if a.exportService == nil {
+		return models.ImportPreview{}, a.initError
+	}
+	return a.exportService.PreviewImportWithPassphrase(mode, passphrase, opts)
+}
+
+// CommitImport applies the selected changes of an import preview
+func (a *App) CommitImport(token string, selection models.ImportSelection) (models.ImportResult, error) {
+	if a.exportService == nil {
+		return models.ImportResult{}, a.initError
+	}
+	logger.Info("Committing import", "providers", len(selection.Providers))
+	result, err := a.exportService.CommitImport(token, selection)
+	if err != nil {
+		logger.Error("Failed to commit import", "error", err)
+	}
+	return result, err
+}
+
+// DiscardImportPreview forgets an import preview
+func (a *App) DiscardImportPreview(token string) {
+	if a.exportService != nil {
+		a.exportService.DiscardImportPreview(token)
+	}
+}
+
+// ImportWithPassphrase completes the import of an encrypted backup selected
+// by ImportData
+func (a *App) ImportWithPassphrase(mode, passphrase string, opts models.MergeOptions) (models.ImportResult, error) {
 	if a.exportService == nil {
 		return models.ImportResult{}, a.initError
 	}
-	logger.Info("Importing data", "mode", mode)
-	return a.exportService.ImportData(mode)
+	logger.Info("Importing encrypted data", "mode", mode, "policy", opts.Policy)
+	return a.exportService.ImportWithPassphrase(mode, passphrase, opts)
 }
 
 // ============================================
@@ -338,6 +770,146 @@ func (a *App) ClearAllData() error {
 	return a.providerService.ClearAllData()
 }
 
+// ListSnapshots returns the rolling backups of the provider catalog
+func (a *App) ListSnapshots() ([]storage.Snapshot, error) {
+	if a.storage == nil {
+		return nil, a.initError
+	}
+	return a.storage.ListSnapshots()
+}
+
+// RestoreSnapshot restores the provider catalog from a backup generation
+func (a *App) RestoreSnapshot(generation int) error {
+	if a.storage == nil {
+		return a.initError
+	}
+	logger.Warn("Restoring snapshot", "generation", generation)
+	err := a.storage.RestoreSnapshot(generation)
+	if err != nil {
+		logger.Error("Failed to restore snapshot", "generation", generation, "error", err)
+	}
+	return err
+}
+
+// GetBackupSettings returns the automatic backup settings
+func (a *App) GetBackupSettings() storage.BackupSettings {
+	if a.settingsService == nil {
+		return storage.DefaultBackupSettings()
+	}
+	return a.settingsService.GetBackupSettings()
+}
+
+// SetBackupSettings saves the automatic backup settings. A non-empty
+// passphrase replaces the one that encrypts backups with API keys.
+func (a *App) SetBackupSettings(settings storage.BackupSettings, passphrase string) error {
+	if a.backupService == nil {
+		return a.initError
+	}
+	logger.Info("Updating backup settings", "enabled", settings.Enabled, "onChange", settings.OnChange,
+		"intervalMinutes", settings.IntervalMinutes, "includeSecrets", settings.IncludeSecrets)
+	err := a.backupService.Configure(settings, passphrase)
+	if err != nil {
+		logger.Error("Failed to update backup settings", "error", err)
+	}
+	return err
+}
+
+// GetBackupStatus returns where backups go and how the last one went
+func (a *App) GetBackupStatus() services.BackupStatus {
+	if a.backupService == nil {
+		return services.BackupStatus{}
+	}
+	return a.backupService.Status()
+}
+
+// BackupNow writes an automatic backup immediately
+func (a *App) BackupNow() (storage.BackupFile, error) {
+	if a.backupService == nil {
+		return storage.BackupFile{}, a.initError
+	}
+	backup, err := a.backupService.BackupNow()
+	if err != nil {
+		logger.Error("Failed to write backup", "error", err)
+	} else {
+		logger.Info("Backup written", "file", backup.Name)
+	}
+	return backup, err
+}
+
+// ListBackups returns the automatic backups, newest first
+func (a *App) ListBackups() ([]storage.BackupFile, error) {
+	if a.backupService == nil {
+		return nil, a.initError
+	}
+	return a.backupService.ListBackups()
+}
+
+// VerifyBackup checks that an automatic backup can be read and restored.
+// An empty passphrase uses the stored backup passphrase.
+func (a *App) VerifyBackup(name, passphrase string) (services.BackupVerification, error) {
+	if a.backupService == nil {
+		return services.BackupVerification{}, a.initError
+	}
+	return a.backupService.VerifyBackup(name, passphrase)
+}
+
+// RestoreBackup replaces the catalog with an automatic backup. An empty
+// passphrase uses the stored backup passphrase.
+func (a *App) RestoreBackup(name, passphrase string) (models.ImportResult, error) {
+	if a.backupService == nil {
+		return models.ImportResult{}, a.initError
+	}
+	logger.Warn("Restoring backup", "file", name)
+	result, err := a.backupService.RestoreBackup(name, passphrase)
+	if err != nil {
+		logger.Error("Failed to restore backup", "file", name, "error", err)
+	}
+	return result, err
+}
+
+// GetRecoveryReport returns details of the last automatic recovery from a
+// corrupt data file, or nil if none happened
+func (a *App) GetRecoveryReport() *storage.RecoveryReport {
+	if a.storage == nil {
+		return nil
+	}
+	return a.storage.LastRecovery()
+}
+
+// GetHistory returns the catalog change journal, newest first. limit <= 0 returns everything.
+func (a *App) GetHistory(limit int) ([]storage.JournalEntry, error) {
+	if a.storage == nil {
+		return nil, a.initError
+	}
+	return a.storage.History(limit)
+}
+
+// RestoreCatalog returns the whole catalog to its state after journal entry seq
+func (a *App) RestoreCatalog(seq int64) error {
+	if a.storage == nil {
+		return a.initError
+	}
+	logger.Warn("Restoring catalog from journal", "seq", seq)
+	err := a.storage.RestoreCatalog(seq)
+	if err != nil {
+		logger.Error("Failed to restore catalog", "seq", seq, "error", err)
+	}
+	return err
+}
+
+// RestoreProviderAt returns one provider to its state after journal entry seq
+func (a *App) RestoreProviderAt(providerID string, seq int64) error {
+	if a.storage == nil {
+		return a.initError
+	}
+	logger.Warn("Restoring provider from journal", "providerId", providerID, "seq", seq)
+	err := a.storage.RestoreProvider(providerID, seq)
+	if err != nil {
+		logger.Error("Failed to restore provider", "providerId", providerID, "seq", seq, "error", err)
+	}
+	return err
+}
+
 // GetDataDir returns the data directory path (for debugging/info)
 func (a *App) GetDataDir() string {
 	if a.storage == nil {
@@ -346,6 +918,27 @@ func (a *App) GetDataDir() string {
 	return a.storage.GetDataDir()
 }
 
+// GetDataLocation returns where data is stored and how that was decided
+func (a *App) GetDataLocation() storage.Location {
+	return a.location
+}
+
+// GetInstanceStatus reports whether another running instance shares the data directory
+func (a *App) GetInstanceStatus() services.InstanceStatus {
+	if a.watchService == nil {
+		return services.InstanceStatus{}
+	}
+	return a.watchService.InstanceStatus()
+}
+
+// ReloadSettings re-reads settings.json after it was changed outside the app
+func (a *App) ReloadSettings() (storage.AppSettings, error) {
+	if a.settingsService == nil {
+		return storage.AppSettings{}, a.initError
+	}
+	return a.settingsService.Reload()
+}
+
 // GetLogDir returns the log directory path
 func (a *App) GetLogDir() string {
 	return logger.Get().GetLogDir()
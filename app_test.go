@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -45,3 +49,58 @@ func TestApp_StartupContext(t *testing.T) {
 		t.Error("Context was not saved on startup")
 	}
 }
+
+func TestParseLaunchOptions(t *testing.T) {
+	opts := parseLaunchOptions([]string{"--data-dir", "/tmp/llmdesk", "--portable"})
+	if opts.DataDir != "/tmp/llmdesk" {
+		t.Errorf("Expected data dir '/tmp/llmdesk', got '%s'", opts.DataDir)
+	}
+	if !opts.Portable {
+		t.Error("Expected portable to be set")
+	}
+
+	// Unknown launcher arguments must not cause a failure
+	opts = parseLaunchOptions([]string{"-psn_0_12345"})
+	if opts.DataDir != "" || opts.Portable {
+		t.Errorf("Expected zero options, got %+v", opts)
+	}
+
+	// Nor hide the options after them
+	opts = parseLaunchOptions([]string{"-psn_0_123", "--data-dir", "X", "stray", "--unknown=1", "--portable"})
+	if opts.DataDir != "X" || !opts.Portable {
+		t.Errorf("Expected the options after unknown arguments, got %+v", opts)
+	}
+	if opts = parseLaunchOptions([]string{"--data-dir=Y"}); opts.DataDir != "Y" {
+		t.Errorf("Expected --data-dir=Y, got %+v", opts)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	// Launch flags are not commands
+	if _, ok := runCommand([]string{"--portable"}, &stdout, &stderr); ok {
+		t.Error("Expected launch flags to start the app")
+	}
+	if _, ok := runCommand(nil, &stdout, &stderr); ok {
+		t.Error("Expected no arguments to start the app")
+	}
+
+	code, ok := runCommand([]string{"export-config", "--format", "litellm"}, &stdout, &stderr)
+	if !ok || code != 2 {
+		t.Errorf("Expected usage error 2, got %d (%v)", code, ok)
+	}
+	if !strings.Contains(stderr.String(), "--out") {
+		t.Errorf("Expected usage on stderr, got %q", stderr.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, []byte(`{"providers": [{"id": "p", "name": 7}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	code, _ = runCommand([]string{"validate", path}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stdout.String(), path+": /providers/0/name: expected string, got integer") {
+		t.Errorf("Expected the schema error reported, got %d %q", code, stdout.String())
+	}
+}
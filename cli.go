@@ -0,0 +1,192 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/models"
+	"llm-desk/internal/services"
+	"llm-desk/internal/storage"
+)
+
+// passphraseEnv holds the passphrase for headless commands when data files
+// or the key vault are passphrase-protected
+const passphraseEnv = "LLMDESK_PASSPHRASE"
+
+// runCommand runs a headless command named by the first argument. It
+// reports false when args name no command, in which case the desktop app
+// starts as usual.
+func runCommand(args []string, stdout, stderr io.Writer) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	switch args[0] {
+	case "export-config":
+		return runExportConfig(args[1:], stdout, stderr), true
+	case "schema":
+		return runSchema(args[1:], stdout, stderr), true
+	case "validate":
+		return runValidate(args[1:], stdout, stderr), true
+	}
+	return 0, false
+}
+
+// runExportConfig writes a config file for another tool from the catalog
+func runExportConfig(args []string, stdout, stderr io.Writer) int {
+	var launch LaunchOptions
+	var format, out, providers, keys string
+
+	fs := flag.NewFlagSet("export-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&launch.DataDir, "data-dir", "", "directory for providers, settings and logs")
+	fs.BoolVar(&launch.Portable, "portable", false, "store data next to the executable")
+	fs.StringVar(&format, "format", "", "litellm, continue, aider, openwebui or cline")
+	fs.StringVar(&out, "out", "", "file to write")
+	fs.StringVar(&providers, "providers", "", "comma-separated provider IDs (default: all enabled providers)")
+	fs.StringVar(&keys, "keys", string(models.ToolConfigKeysEnv), "write keys as env references (env) or in plaintext (inline)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if format == "" || out == "" {
+		fmt.Fprintln(stderr, "export-config: --format and --out are required")
+		fs.Usage()
+		return 2
+	}
+
+	opts := models.ToolConfigOptions{Format: models.ToolConfigFormat(format), Keys: models.ToolConfigKeys(keys)}
+	for _, id := range strings.Split(providers, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			opts.Providers = append(opts.Providers, id)
+		}
+	}
+
+	app, err := newHeadlessApp(launch)
+	if err != nil {
+		fmt.Fprintln(stderr, "export-config:", err)
+		return 1
+	}
+	result, err := app.WriteToolConfig(out, opts)
+	for _, w := range result.Warnings {
+		fmt.Fprintln(stderr, "warning:", w)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "export-config:", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: %s\n", result.Message, result.Path)
+	if len(result.EnvVars) > 0 {
+		fmt.Fprintf(stdout, "Keys are read from: %s\n", strings.Join(result.EnvVars, ", "))
+	}
+	return 0
+}
+
+// runSchema prints or writes the JSON Schema of the export format
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	var out string
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&out, "out", "", "file to write (default: standard output)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	schema := services.DataSchema()
+	if out == "" {
+		stdout.Write(schema)
+		return 0
+	}
+	if err := os.WriteFile(out, schema, 0644); err != nil {
+		fmt.Fprintln(stderr, "schema:", err)
+		return 1
+	}
+	return 0
+}
+
+// runValidate checks export files against the schema and prints each
+// mismatch as "file: pointer: message". Encrypted files are decrypted with
+// LLMDESK_PASSPHRASE.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: llm-desk validate FILE...")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	status := 0
+	for _, path := range fs.Args() {
+		raw, err := os.ReadFile(path)
+		if err == nil && storage.IsEncrypted(raw) {
+			passphrase := os.Getenv(passphraseEnv)
+			if passphrase == "" {
+				err = fmt.Errorf("file is encrypted; set %s to decrypt it", passphraseEnv)
+			} else {
+				raw, err = storage.Decrypt(raw, passphrase)
+			}
+		}
+		var errs []models.SchemaError
+		if err == nil {
+			errs, err = services.ValidateDataJSON(raw)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		for _, e := range errs {
+			pointer := e.Pointer
+			if pointer == "" {
+				pointer = "/"
+			}
+			fmt.Fprintf(stdout, "%s: %s: %s\n", path, pointer, e.Message)
+		}
+		if len(errs) > 0 {
+			status = 1
+		}
+	}
+	return status
+}
+
+// newHeadlessApp initializes the app for a command-line run. Logs go to the
+// log file only, and LLMDESK_PASSPHRASE unlocks passphrase-encrypted data
+// files and the key vault.
+func newHeadlessApp(opts LaunchOptions) (*App, error) {
+	if loc, err := storage.ResolveLocation(opts.locationOptions()); err == nil {
+		// Initialized before NewApp so its console logging stays off
+		if err := logger.Init(logger.Config{Level: "info", LogDir: filepath.Join(loc.DataDir, "logs")}); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: Failed to initialize logger:", err)
+		}
+	}
+
+	app := NewApp(opts)
+	if app.HasInitError() {
+		return nil, app.initError
+	}
+
+	passphrase := os.Getenv(passphraseEnv)
+	if app.GetEncryptionStatus().Locked {
+		if passphrase == "" {
+			return nil, fmt.Errorf("data files are locked; set %s to unlock them", passphraseEnv)
+		}
+		if err := app.UnlockData(passphrase); err != nil {
+			return nil, err
+		}
+	}
+	if app.GetKeyBackendStatus().VaultLocked && passphrase != "" {
+		if err := app.UnlockVault(passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return app, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"llm-desk/internal/storage"
+)
+
+// LaunchOptions holds the command-line options of the desktop app
+type LaunchOptions struct {
+	DataDir  string // --data-dir: store all data in this directory
+	Portable bool   // --portable: store data next to the executable
+}
+
+// parseLaunchOptions parses command-line arguments (without the program name).
+// Unknown arguments are skipped so platform launchers that add their own,
+// such as macOS's -psn_…, do not hide the options after them.
+func parseLaunchOptions(args []string) LaunchOptions {
+	var opts LaunchOptions
+
+	fs := flag.NewFlagSet("llm-desk", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.DataDir, "data-dir", "", "directory for providers, settings and logs")
+	fs.BoolVar(&opts.Portable, "portable", false, "store data next to the executable")
+	_ = fs.Parse(knownArgs(fs, args))
+
+	return opts
+}
+
+// knownArgs returns the arguments that set a flag of fs, each followed by
+// its value when that is a separate argument
+func knownArgs(fs *flag.FlagSet, args []string) []string {
+	var known []string
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		if name == args[i] || name == "" {
+			continue
+		}
+		name, _, hasValue := strings.Cut(name, "=")
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		known = append(known, args[i])
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && boolFlag.IsBoolFlag()) && i+1 < len(args) {
+			i++
+			known = append(known, args[i])
+		}
+	}
+	return known
+}
+
+// locationOptions converts launch options to storage location options
+func (o LaunchOptions) locationOptions() storage.LocationOptions {
+	return storage.LocationOptions{
+		DataDir:  o.DataDir,
+		Portable: o.Portable,
+	}
+}
@@ -5,6 +5,7 @@ import { Sidebar, MobileNav } from '@/components/layout';
 import { Dashboard, ModelsList, ProvidersList, ProviderDetail, Settings, ProviderForm, ModelForm } from '@/pages';
 import { useSettings, useProviders } from '@/hooks';
 import { ViewState, Provider, Model } from '@/types';
+import type { ExportOptions } from '@/utils/dataExport';
 import '@/styles/index.css';
 
 import { ConfirmationDialog } from '@/components/ui';
@@ -33,7 +34,9 @@ const App: React.FC = () => {
         updateModel,
         deleteModel,
         exportData,
-        importDataFromFile
+        previewImport,
+        commitImport,
+        discardImportPreview
     } = useProviders();
 
     // Form state for editing
@@ -59,16 +62,24 @@ const App: React.FC = () => {
         setIsClearDataDialogOpen(false);
     };
 
-    const handleExportData = async () => {
+    const handleExportData = async (options: ExportOptions) => {
         try {
-            const success = await exportData();
-            if (success) {
-                Snackbar.add('Data exported successfully');
+            const result = await exportData(options);
+            if (result.containsPlaintextSecrets) {
+                setAlertDialog({
+                    isOpen: true,
+                    title: 'Backup contains plaintext API keys',
+                    message: result.warnings.join('\n')
+                });
+            } else if (result.success) {
+                Snackbar.add(result.encrypted ? 'Encrypted backup exported successfully' : 'Data exported successfully');
+            } else if (!result.cancelled) {
+                Snackbar.add(result.message);
             }
         } catch (e) {
             Snackbar.add('Failed to export data', {
                 text: 'RETRY',
-                handler: handleExportData
+                handler: () => handleExportData(options)
             });
         }
     };
@@ -241,7 +252,9 @@ const App: React.FC = () => {
                                         toggleFollowSystem={toggleFollowSystem}
                                         onClearData={handleClearDataClick}
                                         onExportData={handleExportData}
-                                        onImportData={importDataFromFile}
+                                        onPreviewImport={previewImport}
+                                        onCommitImport={commitImport}
+                                        onDiscardImportPreview={discardImportPreview}
                                         crashReporting={crashReporting}
                                         toggleCrashReporting={toggleCrashReporting}
                                         onCheckForUpdates={checkForUpdates}
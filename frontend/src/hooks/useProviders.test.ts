@@ -1,6 +1,6 @@
 import { renderHook, act } from '@testing-library/react';
 import { describe, it, expect, vi, beforeEach } from 'vitest';
-import { useProviders } from './useProviders';
+import { useProviders, isUsableKey } from './useProviders';
 import * as WailsApp from '../../wailsjs/go/main/App';
 import { Provider } from '@/types';
 
@@ -18,6 +18,9 @@ vi.mock('../../wailsjs/go/main/App', () => ({
     ClearAllData: vi.fn(),
     ExportData: vi.fn(),
     ImportData: vi.fn(),
+    PreviewImport: vi.fn(),
+    CommitImport: vi.fn(),
+    DiscardImportPreview: vi.fn(),
 }));
 
 const mockProvider: Provider = {
@@ -53,6 +56,28 @@ describe('useProviders', () => {
         expect(WailsApp.GetAllProviders).toHaveBeenCalled();
     });
 
+    it('should only offer enabled, unexpired keys for requests', async () => {
+        (WailsApp.GetAllProviders as any).mockResolvedValue([{
+            ...mockProvider,
+            credentials: {
+                apiKeys: [
+                    { id: 'a', key: 'sk-off', enabled: false },
+                    { id: 'b', key: 'sk-old', enabled: true, expiresAt: '2020-01-01T00:00:00Z' },
+                    { id: 'c', key: 'sk-on', enabled: true }
+                ]
+            }
+        }]);
+        const { result } = renderHook(() => useProviders());
+
+        await act(async () => {
+            await Promise.resolve();
+        });
+
+        expect(result.current.providers[0].credentials.apiKeys).toEqual(['sk-off', 'sk-old', 'sk-on']);
+        expect(result.current.providers[0].credentials.usableKeys).toEqual(['sk-on']);
+        expect(isUsableKey({ key: 'sk', enabled: true, expiresAt: '2999-01-01T00:00:00Z' } as any)).toBe(true);
+    });
+
     it('should add a new provider', async () => {
         const { result } = renderHook(() => useProviders());
 
@@ -144,15 +169,40 @@ describe('useProviders', () => {
     });
 
     it('should handle export data', async () => {
-        (WailsApp.ExportData as any).mockResolvedValue(true);
+        (WailsApp.ExportData as any).mockResolvedValue({ success: true, warnings: [] });
         const { result } = renderHook(() => useProviders());
 
         let success: boolean = false;
         await act(async () => {
-            success = await result.current.exportData();
+            success = (await result.current.exportData()).success;
         });
 
         expect(success).toBe(true);
-        expect(WailsApp.ExportData).toHaveBeenCalled();
+        expect(WailsApp.ExportData).toHaveBeenCalledWith({ secrets: 'exclude' });
+    });
+
+    it('should preview an import before committing it', async () => {
+        (WailsApp.GetAllProviders as any).mockResolvedValue([]);
+        (WailsApp.PreviewImport as any).mockResolvedValue({
+            token: 'tok',
+            message: 'Importing would add 1 provider',
+            warnings: [],
+            providers: [{ providerId: 'openai', name: 'OpenAI', action: 'added', keysBefore: 0, keysAfter: 1 }]
+        });
+        (WailsApp.CommitImport as any).mockResolvedValue({ success: true, message: 'Imported', warnings: [] });
+        const { result } = renderHook(() => useProviders());
+
+        let token = '';
+        await act(async () => {
+            const preview = await result.current.previewImport('merge');
+            token = preview.token;
+            expect(preview.providers[0].providerId).toBe('openai');
+        });
+        expect(WailsApp.CommitImport).not.toHaveBeenCalled();
+
+        await act(async () => {
+            expect((await result.current.commitImport(token, ['openai'])).success).toBe(true);
+        });
+        expect(WailsApp.CommitImport).toHaveBeenCalledWith('tok', { providers: ['openai'] });
     });
 });
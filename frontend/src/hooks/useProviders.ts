@@ -12,8 +12,15 @@ import {
     SaveProviders,
     ClearAllData,
     ExportData,
-    ImportData
+    ImportData,
+    ImportWithPassphrase,
+    PreviewImport,
+    PreviewImportWithPassphrase,
+    CommitImport,
+    DiscardImportPreview
 } from '../../wailsjs/go/main/App';
+import type { ExportOptions } from '@/utils/dataExport';
+import type { ImportFormat, ImportIssue, ImportPreview, MergePolicy } from '@/utils/dataImport';
 import type { models } from '../../wailsjs/go/models';
 
 export type ImportMode = 'replace' | 'merge';
@@ -23,6 +30,28 @@ export interface ImportResult {
     message: string;
     warnings: string[];
     imported: { providers: number; models: number };
+    passphraseRequired?: boolean;
+    format?: ImportFormat;
+    containsPlaintextSecrets?: boolean;
+    issues?: ImportIssue[];
+    quarantinePath?: string;
+}
+
+/**
+ * Converts an import result from the backend
+ */
+function toImportResult(result: models.ImportResult): ImportResult {
+    return {
+        success: result.success,
+        message: result.message,
+        warnings: result.warnings || [],
+        imported: result.imported || { providers: 0, models: 0 },
+        passphraseRequired: result.passphraseRequired,
+        format: result.format as ImportFormat | undefined,
+        containsPlaintextSecrets: result.containsPlaintextSecrets,
+        issues: result.issues as ImportIssue[] | undefined,
+        quarantinePath: result.quarantinePath
+    };
 }
 
 /**
@@ -68,13 +97,40 @@ function createDefaultModel(id: string, name: string): Model {
     };
 }
 
+// Extract the secrets from key records (older backends sent plain strings)
+function keySecrets(keys: Array<models.APIKey | string> | undefined): string[] {
+    return (keys || [])
+        .map(k => (typeof k === 'string' ? k : k.key || ''))
+        .filter(Boolean);
+}
+
+// Whether a key record may be used for requests: enabled and not expired
+export function isUsableKey(k: models.APIKey | string, now: Date = new Date()): boolean {
+    if (typeof k === 'string') {
+        return k !== '';
+    }
+    if (!k.enabled || !k.key) {
+        return false;
+    }
+    return !k.expiresAt || new Date(k.expiresAt) > now;
+}
+
+// The revision of a provider as last loaded, sent with edits so the backend
+// rejects changes made on top of a stale copy (0 skips the check)
+function revisionOf(providers: Provider[], providerId: string): number {
+    return providers.find(p => p.id === providerId)?.revision ?? 0;
+}
+
 // Convert Wails model to our Provider type
 function convertProvider(p: models.Provider): Provider {
     return {
         id: p.id,
         name: p.name,
         enabled: p.enabled,
-        credentials: { apiKeys: p.credentials?.apiKeys || [] },
+        credentials: {
+            apiKeys: keySecrets(p.credentials?.apiKeys),
+            usableKeys: keySecrets((p.credentials?.apiKeys || []).filter(k => isUsableKey(k)))
+        },
         endpoints: {
             openai: p.endpoints?.openai || '',
             anthropic: p.endpoints?.anthropic || null
@@ -92,7 +148,8 @@ function convertProvider(p: models.Provider): Provider {
             features: m.features || {},
             limits: m.limits || []
         })),
-        isCustom: p.isCustom
+        isCustom: p.isCustom,
+        revision: p.revision
     };
 }
 
@@ -121,7 +178,7 @@ export function useProviders() {
     // Update API keys for a provider
     const updateProviderKeys = useCallback(async (providerId: string, newKeys: string[]) => {
         try {
-            await UpdateCredentials(providerId, newKeys);
+            await UpdateCredentials(providerId, newKeys, revisionOf(providers, providerId));
             await loadProviders();
 
             // Update selected provider if it matches
@@ -134,7 +191,7 @@ export function useProviders() {
         } catch (e) {
             console.error('Failed to update credentials:', e);
         }
-    }, [selectedProvider]);
+    }, [providers, selectedProvider]);
 
     // Add a new provider
     const addProvider = useCallback(async (providerData: Partial<Provider> & { name: string }) => {
@@ -190,7 +247,7 @@ export function useProviders() {
     // Add a model to a provider
     const addModel = useCallback(async (providerId: string, model: Model) => {
         try {
-            await AddModelAPI(providerId, model as any);
+            await AddModelAPI(providerId, model as any, revisionOf(providers, providerId));
             await loadProviders();
 
             if (selectedProvider?.id === providerId) {
@@ -202,7 +259,7 @@ export function useProviders() {
         } catch (e) {
             console.error('Failed to add model:', e);
         }
-    }, [selectedProvider]);
+    }, [providers, selectedProvider]);
 
     // Update a model
     const updateModel = useCallback(async (providerId: string, modelId: string, updates: Partial<Model>) => {
@@ -212,7 +269,7 @@ export function useProviders() {
                 const model = provider.models.find(m => m.id === modelId);
                 if (model) {
                     const updated = { ...model, ...updates };
-                    await UpdateModelAPI(providerId, modelId, updated as any);
+                    await UpdateModelAPI(providerId, modelId, updated as any, provider.revision ?? 0);
                     await loadProviders();
 
                     if (selectedProvider?.id === providerId) {
@@ -233,7 +290,7 @@ export function useProviders() {
     // Delete a model
     const deleteModel = useCallback(async (providerId: string, modelId: string) => {
         try {
-            await DeleteModelAPI(providerId, modelId);
+            await DeleteModelAPI(providerId, modelId, revisionOf(providers, providerId));
             await loadProviders();
 
             if (selectedProvider?.id === providerId) {
@@ -245,7 +302,7 @@ export function useProviders() {
         } catch (e) {
             console.error('Failed to delete model:', e);
         }
-    }, [selectedProvider]);
+    }, [providers, selectedProvider]);
 
     // Clear all data
     const clearAllData = useCallback(async () => {
@@ -259,10 +316,11 @@ export function useProviders() {
     }, []);
 
     // Export data (uses native file dialog)
-    const exportData = useCallback(async (): Promise<boolean> => {
+    const exportData = useCallback(async (
+        options: ExportOptions = { secrets: 'exclude' }
+    ): Promise<models.ExportResult> => {
         try {
-            const success = await ExportData();
-            return success;
+            return await ExportData(options);
         } catch (e) {
             console.error('Failed to export data:', e);
             throw e;
@@ -270,20 +328,77 @@ export function useProviders() {
     }, []);
 
     // Import data from file (uses native file dialog)
+    // Encrypted files report passphraseRequired; pass the passphrase to retry
     const importDataFromFile = useCallback(async (
-        mode: ImportMode
+        mode: ImportMode,
+        passphrase?: string,
+        policy: MergePolicy = 'takeIncoming'
     ): Promise<ImportResult> => {
+        const options = { policy } as models.MergeOptions;
         try {
-            const result = await ImportData(mode);
+            const result = passphrase === undefined
+                ? await ImportData(mode, options)
+                : await ImportWithPassphrase(mode, passphrase, options);
             if (result.success) {
                 await loadProviders();
             }
+            return toImportResult(result);
+        } catch (e) {
+            return {
+                success: false,
+                message: `Failed to import: ${e instanceof Error ? e.message : 'Unknown error'}`,
+                warnings: [],
+                imported: { providers: 0, models: 0 }
+            };
+        }
+    }, []);
+
+    // Read an import without applying it (uses native file dialog)
+    // Encrypted files report passphraseRequired; pass the passphrase to retry
+    const previewImport = useCallback(async (
+        mode: ImportMode,
+        passphrase?: string,
+        policy: MergePolicy = 'takeIncoming'
+    ): Promise<ImportPreview> => {
+        const options = { policy } as models.MergeOptions;
+        try {
+            const preview = passphrase === undefined
+                ? await PreviewImport(mode, options)
+                : await PreviewImportWithPassphrase(mode, passphrase, options);
             return {
-                success: result.success,
-                message: result.message,
-                warnings: result.warnings || [],
-                imported: result.imported || { providers: 0, models: 0 }
+                token: preview.token,
+                mode: (preview.mode || mode) as ImportMode,
+                message: preview.message,
+                warnings: preview.warnings || [],
+                passphraseRequired: preview.passphraseRequired,
+                format: preview.format as ImportFormat | undefined,
+                containsPlaintextSecrets: preview.containsPlaintextSecrets,
+                providers: (preview.providers || []) as ImportPreview['providers'],
+                issues: (preview.issues || []) as ImportIssue[]
             };
+        } catch (e) {
+            return {
+                token: '',
+                mode,
+                message: `Failed to read import: ${e instanceof Error ? e.message : 'Unknown error'}`,
+                warnings: [],
+                providers: [],
+                issues: []
+            };
+        }
+    }, []);
+
+    // Apply the changes of a preview to the selected providers
+    const commitImport = useCallback(async (
+        token: string,
+        providerIds: string[]
+    ): Promise<ImportResult> => {
+        try {
+            const result = await CommitImport(token, { providers: providerIds } as models.ImportSelection);
+            if (result.success) {
+                await loadProviders();
+            }
+            return toImportResult(result);
         } catch (e) {
             return {
                 success: false,
@@ -294,6 +409,15 @@ export function useProviders() {
         }
     }, []);
 
+    // Forget a preview that will not be committed
+    const discardImportPreview = useCallback(async (token: string) => {
+        try {
+            await DiscardImportPreview(token);
+        } catch (e) {
+            console.error('Failed to discard import preview:', e);
+        }
+    }, []);
+
     return useMemo(() => ({
         providers,
         selectedProvider,
@@ -312,6 +436,9 @@ export function useProviders() {
         // Import/Export
         exportData,
         importDataFromFile,
+        previewImport,
+        commitImport,
+        discardImportPreview,
         // Utilities
         createDefaultProvider,
         createDefaultModel
@@ -329,7 +456,10 @@ export function useProviders() {
         updateModel,
         deleteModel,
         exportData,
-        importDataFromFile
+        importDataFromFile,
+        previewImport,
+        commitImport,
+        discardImportPreview
         // createDefaultProvider/Model are static functions, could be moved out of hook or memoized if constructed here (they are function declarations outside currently?)
         // Actually, createDefaultProvider/Model serve as utilities. If they are defined outside, they are stable.
         // Looking at file content, they are defined outside.
@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { ChevronRight, Plus, Trash2, RefreshCw, AlertCircle } from 'lucide-react';
 import { FormInput, Toggle } from '@/components/ui';
-import { Provider, Model, ProviderFeatures, Limit, FetchedModel } from '@/types';
+import { Provider, Model, ProviderFeatures, Limit, FetchedModel, FetchAttempt } from '@/types';
 import { fetchModels, transformFetchedModel } from '@/utils/modelFetcher';
 
 interface ProviderFormProps {
@@ -41,6 +41,8 @@ export const ProviderForm: React.FC<ProviderFormProps> = ({
     // Fetch state
     const [isFetching, setIsFetching] = useState(false);
     const [fetchError, setFetchError] = useState<string | null>(null);
+    const [fetchWarning, setFetchWarning] = useState<string | null>(null);
+    const [fetchAttempts, setFetchAttempts] = useState<FetchAttempt[]>([]);
     const [fetchedModels, setFetchedModels] = useState<FetchedModel[]>([]);
 
     // Validation
@@ -52,7 +54,7 @@ export const ProviderForm: React.FC<ProviderFormProps> = ({
             setName(provider.name);
             setOpenaiUrl(provider.endpoints.openai);
             setAnthropicUrl(provider.endpoints.anthropic || '');
-            setApiKey(provider.credentials.apiKeys?.[0] || '');
+            setApiKey(provider.credentials.usableKeys?.[0] || '');
             setFeatures(provider.features);
             setLimits(provider.limits || []);
             setModels(provider.models || []);
@@ -67,6 +69,8 @@ export const ProviderForm: React.FC<ProviderFormProps> = ({
         }
         setFetchedModels([]);
         setFetchError(null);
+        setFetchWarning(null);
+        setFetchAttempts([]);
         setErrors({});
     }, [provider]);
 
@@ -107,20 +111,25 @@ export const ProviderForm: React.FC<ProviderFormProps> = ({
 
         setIsFetching(true);
         setFetchError(null);
+        setFetchWarning(null);
+        setFetchAttempts([]);
 
         try {
             const result = await fetchModels({
                 baseUrl: openaiUrl.replace(/\/$/, ''),
                 apiKey,
-                anthropicUrl: anthropicUrl ? anthropicUrl.replace(/\/$/, '') : undefined
+                anthropicUrl: anthropicUrl ? anthropicUrl.replace(/\/$/, '') : undefined,
+                protocol: provider?.protocol
             });
 
             if (result.error) {
                 setFetchError(result.error);
+                setFetchAttempts(result.attempts || []);
             } else if (result.models.length === 0) {
                 setFetchError('No models found at the specified endpoint');
             } else {
                 setFetchedModels(result.models);
+                setFetchWarning(result.warning || null);
             }
         } catch (error) {
             setFetchError('Failed to fetch models. Please check your credentials and try again.');
@@ -174,7 +183,14 @@ export const ProviderForm: React.FC<ProviderFormProps> = ({
                 anthropic: anthropicUrl.trim() ? anthropicUrl.trim().replace(/\/$/, '') : null
             },
             credentials: {
-                apiKeys: apiKey ? [apiKey] : []
+                // The form edits one key; the provider's other keys, including
+                // disabled and expired ones, are kept
+                apiKeys: [
+                    ...(apiKey ? [apiKey] : []),
+                    ...(provider?.credentials.apiKeys || []).filter(
+                        k => k !== apiKey && k !== provider?.credentials.usableKeys?.[0]
+                    )
+                ]
             },
             features,
             limits,
@@ -335,6 +351,27 @@ export const ProviderForm: React.FC<ProviderFormProps> = ({
                             </div>
                         )}
 
+                        {fetchError && fetchAttempts.length > 0 && (
+                            <details className="fetch-attempts">
+                                <summary>Endpoints tried ({fetchAttempts.length})</summary>
+                                <ul>
+                                    {fetchAttempts.map((attempt, i) => (
+                                        <li key={i}>
+                                            <code>{attempt.protocol}</code> {attempt.endpoint}: {attempt.message || `${attempt.models} models`}
+                                            {attempt.bodyExcerpt && <pre>{attempt.bodyExcerpt}</pre>}
+                                        </li>
+                                    ))}
+                                </ul>
+                            </details>
+                        )}
+
+                        {fetchWarning && fetchedModels.length > 0 && (
+                            <div className="fetch-error fetch-error--warning">
+                                <AlertCircle size={14} />
+                                <span>{fetchWarning}</span>
+                            </div>
+                        )}
+
                         {fetchedModels.length > 0 && (
                             <div className="fetch-section">
                                 <div className="fetch-section__header">
@@ -83,8 +83,8 @@ export const ProvidersList: React.FC<ProvidersListProps> = React.memo(({
                                 <div className="provider-card__avatar">
                                     {provider.name.charAt(0)}
                                 </div>
-                                <div className={`provider-card__status ${provider.credentials.apiKeys.length > 0 ? 'provider-card__status--active' : 'provider-card__status--inactive'}`}>
-                                    {provider.credentials.apiKeys.length > 0 ? 'ACTIVE' : 'NO KEY'}
+                                <div className={`provider-card__status ${(provider.credentials.usableKeys ?? provider.credentials.apiKeys).length > 0 ? 'provider-card__status--active' : 'provider-card__status--inactive'}`}>
+                                    {(provider.credentials.usableKeys ?? provider.credentials.apiKeys).length > 0 ? 'ACTIVE' : 'NO KEY'}
                                 </div>
                             </div>
 
@@ -2,7 +2,8 @@ import React, { useState, useEffect } from 'react';
 import { Snackbar } from 'minisnackbar';
 import { Sun, Moon, Download, Upload, AlertCircle, CheckCircle, RefreshCw } from 'lucide-react';
 import { Card } from '@/components/ui';
-import { ImportMode, ImportResult } from '@/utils/dataImport';
+import { ImportMode, ImportPreview, ImportResult, MergePolicy } from '@/utils/dataImport';
+import { ExportOptions, ExportSecrets } from '@/utils/dataExport';
 import { GetVersion } from '../../wailsjs/go/main/App';
 
 interface SettingsProps {
@@ -11,8 +12,10 @@ interface SettingsProps {
     followSystem: boolean;
     toggleFollowSystem: () => void;
     onClearData: () => void;
-    onExportData: () => void;
-    onImportData: (mode: ImportMode) => Promise<ImportResult>;
+    onExportData: (options: ExportOptions) => void;
+    onPreviewImport: (mode: ImportMode, passphrase?: string, policy?: MergePolicy) => Promise<ImportPreview>;
+    onCommitImport: (token: string, providerIds: string[]) => Promise<ImportResult>;
+    onDiscardImportPreview: (token: string) => void;
     crashReporting: boolean;
     toggleCrashReporting: () => void;
     onCheckForUpdates: () => Promise<any>;
@@ -26,13 +29,22 @@ export const Settings: React.FC<SettingsProps> = React.memo(({
     toggleFollowSystem,
     onClearData,
     onExportData,
-    onImportData,
+    onPreviewImport,
+    onCommitImport,
+    onDiscardImportPreview,
     crashReporting,
     toggleCrashReporting,
     onCheckForUpdates
 }) => {
     const [importMode, setImportMode] = useState<ImportMode>('merge');
+    const [mergePolicy, setMergePolicy] = useState<MergePolicy>('takeIncoming');
     const [importWarnings, setImportWarnings] = useState<string[]>([]);
+    const [exportSecrets, setExportSecrets] = useState<ExportSecrets>('exclude');
+    const [exportPassphrase, setExportPassphrase] = useState('');
+    const [importPassphrase, setImportPassphrase] = useState('');
+    const [awaitingPassphrase, setAwaitingPassphrase] = useState(false);
+    const [importPreview, setImportPreview] = useState<ImportPreview | null>(null);
+    const [selectedImports, setSelectedImports] = useState<string[]>([]);
     const [isCheckingUpdates, setIsCheckingUpdates] = useState(false);
     const [updateResult, setUpdateResult] = useState<any>(null);
     const [appVersion, setAppVersion] = useState<string>('Loading...');
@@ -44,27 +56,64 @@ export const Settings: React.FC<SettingsProps> = React.memo(({
             .catch(() => setAppVersion('Unknown'));
     }, []);
 
-    const handleImportClick = async () => {
-        Snackbar.add('Importing...');
+    const handleExportClick = () => {
+        if (exportSecrets === 'encrypt' && !exportPassphrase) {
+            Snackbar.add('Enter a passphrase to encrypt the backup');
+            return;
+        }
+        onExportData({
+            secrets: exportSecrets,
+            passphrase: exportSecrets === 'encrypt' ? exportPassphrase : undefined
+        });
+    };
+
+    const handleImportClick = async (passphrase?: string) => {
+        if (importPreview) {
+            onDiscardImportPreview(importPreview.token);
+            setImportPreview(null);
+        }
         setImportWarnings([]);
 
-        try {
-            const result = await onImportData(importMode);
-
-            if (result.success) {
-                Snackbar.add(result.message, {
-                    text: 'VIEW',
-                    handler: () => { /* Logic to navigate to providers if needed, or just let user explore */ }
-                });
-            } else if (result.message !== 'Import cancelled') {
-                Snackbar.add(result.message);
-            }
+        const preview = await onPreviewImport(importMode, passphrase, mergePolicy);
+        setAwaitingPassphrase(!!preview.passphraseRequired);
+        if (!preview.passphraseRequired) {
+            setImportPassphrase('');
+        }
+
+        if (preview.token) {
+            setImportPreview(preview);
+            setSelectedImports(preview.providers.map(p => p.providerId));
+        } else if (preview.message && preview.message !== 'Import cancelled' && !preview.passphraseRequired) {
+            Snackbar.add(preview.message);
+        }
+        setImportWarnings(preview.warnings);
+    };
+
+    const toggleImportSelection = (providerId: string) => {
+        setSelectedImports(selected => selected.includes(providerId)
+            ? selected.filter(id => id !== providerId)
+            : [...selected, providerId]);
+    };
+
+    const handleCommitImport = async () => {
+        if (!importPreview) return;
+        Snackbar.add('Importing...');
+
+        const result = await onCommitImport(importPreview.token, selectedImports);
+        Snackbar.add(result.message);
+        if (result.success) {
+            setImportPreview(null);
             setImportWarnings(result.warnings);
-        } catch (e) {
-            Snackbar.add(e instanceof Error ? e.message : 'Import failed');
         }
     };
 
+    const handleDiscardImport = () => {
+        if (!importPreview) return;
+        onDiscardImportPreview(importPreview.token);
+        setImportPreview(null);
+        setImportWarnings([]);
+    };
+
     const handleCheckUpdates = async () => {
         setIsCheckingUpdates(true);
         setUpdateResult(null);
@@ -150,12 +199,42 @@ export const Settings: React.FC<SettingsProps> = React.memo(({
                             Download all your data as a JSON backup file.
                         </p>
                     </div>
-                    <button onClick={onExportData} className="btn btn--primary">
+                    <button onClick={handleExportClick} className="btn btn--primary">
                         <Download size={16} />
                         Export
                     </button>
                 </div>
 
+                <div className="setting-row setting-row--column">
+                    <div className="import-mode-toggle">
+                        {(['exclude', 'encrypt', 'include'] as ExportSecrets[]).map(option => (
+                            <button
+                                key={option}
+                                type="button"
+                                onClick={() => setExportSecrets(option)}
+                                className={`import-mode-toggle__btn ${exportSecrets === option ? 'import-mode-toggle__btn--active' : ''}`}
+                            >
+                                {option === 'exclude' ? 'Without API keys' : option === 'encrypt' ? 'Encrypted' : 'Plaintext keys'}
+                            </button>
+                        ))}
+                    </div>
+                    {exportSecrets === 'encrypt' && (
+                        <input
+                            type="password"
+                            className="input"
+                            placeholder="Backup passphrase"
+                            value={exportPassphrase}
+                            onChange={e => setExportPassphrase(e.target.value)}
+                        />
+                    )}
+                    {exportSecrets === 'include' && (
+                        <div className="import-warning">
+                            <AlertCircle size={14} />
+                            <span>API keys will be readable by anyone who can open the file.</span>
+                        </div>
+                    )}
+                </div>
+
                 {/* Import Section */}
                 <div className="setting-row setting-row--divider setting-row--column">
                     <div className="setting-row__header">
@@ -187,15 +266,31 @@ export const Settings: React.FC<SettingsProps> = React.memo(({
 
                     <p className="import-mode-hint">
                         {importMode === 'merge'
-                            ? 'Merge: Add new items without overwriting existing data.'
+                            ? 'Merge: Add new items; conflicting fields follow the policy below.'
                             : 'Replace: Clear existing data and import everything fresh.'}
                     </p>
 
+                    {/* Merge Policy Toggle */}
+                    {importMode === 'merge' && (
+                        <div className="import-mode-toggle">
+                            {(['takeIncoming', 'keepLocal', 'newestWins'] as MergePolicy[]).map(policy => (
+                                <button
+                                    key={policy}
+                                    type="button"
+                                    onClick={() => setMergePolicy(policy)}
+                                    className={`import-mode-toggle__btn ${mergePolicy === policy ? 'import-mode-toggle__btn--active' : ''}`}
+                                >
+                                    {policy === 'takeIncoming' ? 'Prefer imported' : policy === 'keepLocal' ? 'Keep mine' : 'Newest wins'}
+                                </button>
+                            ))}
+                        </div>
+                    )}
+
                     {/* File Drop Zone */}
                     {/* Import Trigger Zone */}
                     <div
                         className="file-drop-zone"
-                        onClick={handleImportClick}
+                        onClick={() => handleImportClick()}
                     >
                         <Upload size={24} className="file-drop-zone__icon" />
                         <span className="file-drop-zone__text">
@@ -205,6 +300,71 @@ export const Settings: React.FC<SettingsProps> = React.memo(({
 
 
 
+                    {/* Passphrase for encrypted backups */}
+                    {awaitingPassphrase && (
+                        <form
+                            className="setting-row"
+                            onSubmit={e => {
+                                e.preventDefault();
+                                handleImportClick(importPassphrase);
+                            }}
+                        >
+                            <input
+                                type="password"
+                                className="input"
+                                placeholder="Backup passphrase"
+                                value={importPassphrase}
+                                onChange={e => setImportPassphrase(e.target.value)}
+                                autoFocus
+                            />
+                            <button type="submit" className="btn btn--primary">
+                                Decrypt & Preview
+                            </button>
+                        </form>
+                    )}
+
+                    {/* Preview of the changes; nothing is applied until confirmed */}
+                    {importPreview && (
+                        <div className="import-preview">
+                            <p className="import-mode-hint">{importPreview.message}</p>
+                            {importPreview.providers.length === 0 ? (
+                                <p className="import-mode-hint">Nothing would change.</p>
+                            ) : (
+                                <ul className="import-preview__list">
+                                    {importPreview.providers.map(diff => (
+                                        <li key={diff.providerId} className="import-preview__item">
+                                            <label className="import-preview__label">
+                                                <input
+                                                    type="checkbox"
+                                                    checked={selectedImports.includes(diff.providerId)}
+                                                    onChange={() => toggleImportSelection(diff.providerId)}
+                                                />
+                                                <span>{diff.name}</span>
+                                            </label>
+                                            <span className={`import-preview__action import-preview__action--${diff.action}`}>
+                                                {diff.action}
+                                                {diff.models && diff.models.length > 0 && `, ${diff.models.length} model${diff.models.length === 1 ? '' : 's'}`}
+                                            </span>
+                                        </li>
+                                    ))}
+                                </ul>
+                            )}
+                            <div className="import-preview__actions">
+                                <button type="button" className="btn btn--secondary" onClick={handleDiscardImport}>
+                                    Cancel
+                                </button>
+                                <button
+                                    type="button"
+                                    className="btn btn--primary"
+                                    onClick={handleCommitImport}
+                                    disabled={selectedImports.length === 0}
+                                >
+                                    Import Selected
+                                </button>
+                            </div>
+                        </div>
+                    )}
+
                     {/* Warnings */}
                     {importWarnings.length > 0 && (
                         <div className="import-warnings">
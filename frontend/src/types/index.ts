@@ -48,6 +48,7 @@ export interface Endpoints {
 // API Credentials
 export interface Credentials {
     apiKeys: string[];
+    usableKeys?: string[]; // Enabled, unexpired keys; the ones requests should use
 }
 
 // Model definition
@@ -73,9 +74,14 @@ export interface Provider {
     limits: Limit[];
     features: ProviderFeatures;
     models: Model[];
+    protocol?: ProviderProtocol; // Model-list API; detected when unset
     isCustom?: boolean;
+    revision?: number; // Sent back on save to detect conflicting edits
 }
 
+// APIs a provider can speak for listing its models
+export type ProviderProtocol = 'openai' | 'anthropic' | 'gemini' | 'ollama' | 'azure' | 'cohere';
+
 // Export/Import metadata
 export interface Metadata {
     createdAt: string;
@@ -97,6 +103,42 @@ export interface FetchedModel {
     object?: string;
     created?: number;
     owned_by?: string;
+    // Provider-specific metadata, when the API reports it
+    displayName?: string;
+    description?: string;
+    contextLength?: number;
+    maxOutputTokens?: number;
+    pricing?: Pricing;
+    modalities?: string[];
+    features?: ModelFeatures;
+}
+
+// Why fetching models failed
+export type FetchErrorKind =
+    | 'auth'
+    | 'forbidden'
+    | 'not_found'
+    | 'rate_limited'
+    | 'http'
+    | 'tls'
+    | 'dns'
+    | 'timeout'
+    | 'network'
+    | 'not_json'
+    | 'no_models'
+    | 'invalid_url'
+    | 'protocol';
+
+// One endpoint tried while fetching models, with secrets redacted
+export interface FetchAttempt {
+    protocol: ProviderProtocol;
+    endpoint: string;
+    status?: number;
+    errorKind?: FetchErrorKind;
+    message?: string;
+    retryAfter?: number;
+    bodyExcerpt?: string;
+    models?: number;
 }
 
 // Application view states
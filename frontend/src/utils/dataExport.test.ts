@@ -12,7 +12,7 @@ describe('dataExport', () => {
     });
 
     it('should return true when ExportData succeeds', async () => {
-        (WailsApp.ExportData as any).mockResolvedValue(true);
+        (WailsApp.ExportData as any).mockResolvedValue({ success: true, warnings: [] });
         const result = await downloadExportFile();
         expect(result).toBe(true);
         expect(WailsApp.ExportData).toHaveBeenCalled();
@@ -5,13 +5,22 @@ import { ExportData } from '../../wailsjs/go/main/App';
 
 export const SCHEMA_VERSION = '1.0.0';
 
+/** How API keys are written: left out, in plaintext, or with the file encrypted */
+export type ExportSecrets = 'exclude' | 'include' | 'encrypt';
+
+export interface ExportOptions {
+    secrets: ExportSecrets;
+    passphrase?: string;
+}
+
 /**
  * Triggers export via native file dialog
  * This replaces the browser download approach
  */
-export async function downloadExportFile(): Promise<boolean> {
+export async function downloadExportFile(options: ExportOptions = { secrets: 'exclude' }): Promise<boolean> {
     try {
-        return await ExportData();
+        const result = await ExportData(options);
+        return result.success;
     } catch (e) {
         console.error('Export failed:', e);
         return false;
@@ -32,7 +32,7 @@ describe('dataImport', () => {
 
             expect(result.success).toBe(true);
             expect(result.imported.providers).toBe(2);
-            expect(WailsApp.ImportData).toHaveBeenCalledWith('merge');
+            expect(WailsApp.ImportData).toHaveBeenCalledWith('merge', { policy: 'takeIncoming' });
         });
 
         it('should handle errors from ImportData', async () => {
@@ -5,11 +5,61 @@ import { ImportData } from '../../wailsjs/go/main/App';
 
 export type ImportMode = 'replace' | 'merge';
 
+// Which side wins a conflicting field in merge mode
+export type MergePolicy = 'takeIncoming' | 'keepLocal' | 'newestWins';
+
+// Kind of file an import was read from
+export type ImportFormat = 'llmdesk' | 'litellm' | 'continue' | 'aider' | 'openrouter';
+
+export interface ImportIssue {
+    providerId: string;
+    modelId?: string;
+    field?: string;
+    message: string;
+    action: 'fixed' | 'quarantined';
+}
+
 export interface ImportResult {
     success: boolean;
     message: string;
     warnings: string[];
     imported: { providers: number; models: number };
+    passphraseRequired?: boolean;
+    format?: ImportFormat;
+    containsPlaintextSecrets?: boolean;
+    issues?: ImportIssue[];
+    quarantinePath?: string;
+}
+
+// What an import does to a provider or model
+export type DiffAction = 'added' | 'changed' | 'removed';
+
+export interface ModelDiff {
+    modelId: string;
+    name: string;
+    action: DiffAction;
+}
+
+export interface ProviderDiff {
+    providerId: string;
+    name: string;
+    action: DiffAction;
+    models?: ModelDiff[];
+    keysBefore: number;
+    keysAfter: number;
+}
+
+// An import read but not yet applied; commit it by token
+export interface ImportPreview {
+    token: string;
+    mode: ImportMode;
+    message: string;
+    warnings: string[];
+    passphraseRequired?: boolean;
+    format?: ImportFormat;
+    containsPlaintextSecrets?: boolean;
+    providers: ProviderDiff[];
+    issues: ImportIssue[];
 }
 
 export interface ValidationResult {
@@ -36,10 +86,11 @@ export function validateImportData(_content: string): ValidationResult {
 /**
  * Performs import via native file dialog
  * @param mode - 'replace' to replace all data, 'merge' to merge with existing
+ * @param policy - which side wins conflicting fields when merging
  */
-export async function importData(mode: ImportMode): Promise<ImportResult> {
+export async function importData(mode: ImportMode, policy: MergePolicy = 'takeIncoming'): Promise<ImportResult> {
     try {
-        const result = await ImportData(mode);
+        const result = await ImportData(mode, { policy });
         return {
             success: result.success,
             message: result.message,
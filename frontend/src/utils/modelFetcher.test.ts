@@ -3,7 +3,7 @@ import { fetchModels, transformFetchedModel, generateProviderId } from './modelF
 import * as WailsApp from '../../wailsjs/go/main/App';
 
 vi.mock('../../wailsjs/go/main/App', () => ({
-    FetchModels: vi.fn(),
+    FetchProtocolModels: vi.fn(),
     TransformFetchedModel: vi.fn(),
 }));
 
@@ -13,12 +13,12 @@ describe('modelFetcher', () => {
     });
 
     describe('fetchModels', () => {
-        it('should return models when FetchModels succeeds', async () => {
+        it('should return models when FetchProtocolModels succeeds', async () => {
             const mockResult = {
                 models: [{ id: 'gpt-4', object: 'model' }],
                 error: ''
             };
-            (WailsApp.FetchModels as any).mockResolvedValue(mockResult);
+            (WailsApp.FetchProtocolModels as any).mockResolvedValue(mockResult);
 
             const result = await fetchModels({ baseUrl: 'url', apiKey: 'key' });
 
@@ -27,8 +27,23 @@ describe('modelFetcher', () => {
             expect(result.error).toBe('');
         });
 
-        it('should handle errors from FetchModels', async () => {
-            (WailsApp.FetchModels as any).mockRejectedValue(new Error('Network error'));
+        it('should pass through classified errors and attempts', async () => {
+            (WailsApp.FetchProtocolModels as any).mockResolvedValue({
+                models: [],
+                error: 'The provider rejected the API key (HTTP 401).',
+                errorKind: 'auth',
+                attempts: [{ protocol: 'openai', endpoint: 'url/models', status: 401, errorKind: 'auth', message: 'HTTP 401' }]
+            });
+
+            const result = await fetchModels({ baseUrl: 'url', apiKey: 'key' });
+
+            expect(result.errorKind).toBe('auth');
+            expect(result.attempts).toHaveLength(1);
+            expect(result.attempts?.[0].status).toBe(401);
+        });
+
+        it('should handle errors from FetchProtocolModels', async () => {
+            (WailsApp.FetchProtocolModels as any).mockRejectedValue(new Error('Network error'));
 
             const result = await fetchModels({ baseUrl: 'url', apiKey: 'key' });
 
@@ -52,6 +67,22 @@ describe('modelFetcher', () => {
 
             expect(transformed.name).toBe('Anthropic Claude 3 Opus');
         });
+
+        it('should use reported metadata', () => {
+            const fetched = {
+                id: 'gemini-1.5-pro',
+                displayName: 'Gemini 1.5 Pro',
+                contextLength: 2000000,
+                maxOutputTokens: 8192,
+                modalities: ['text', 'vision'],
+            };
+            const transformed = transformFetchedModel(fetched as any);
+
+            expect(transformed.name).toBe('Gemini 1.5 Pro');
+            expect(transformed.context).toEqual({ maxInput: 2000000, maxOutput: 8192 });
+            expect(transformed.modalities).toEqual(['text', 'vision']);
+            expect(transformed.pricing.input).toBe(0);
+        });
     });
 
     describe('generateProviderId', () => {
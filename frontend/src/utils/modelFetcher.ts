@@ -1,25 +1,36 @@
-import { FetchModels, TransformFetchedModel } from '../../wailsjs/go/main/App';
-import { FetchedModel, Model } from '@/types';
+import { FetchProtocolModels } from '../../wailsjs/go/main/App';
+import { FetchAttempt, FetchedModel, FetchErrorKind, Model, ProviderProtocol } from '@/types';
 
 interface FetchModelsOptions {
     baseUrl: string;
     apiKey: string;
     anthropicUrl?: string;
+    protocol?: ProviderProtocol;
 }
 
 interface FetchModelsResult {
     models: FetchedModel[];
     error?: string;
+    errorKind?: FetchErrorKind;
+    retryAfter?: number;
+    attempts?: FetchAttempt[];
+    warning?: string;
+    truncated?: boolean;
 }
 
 export async function fetchModels(options: FetchModelsOptions): Promise<FetchModelsResult> {
-    const { baseUrl, apiKey, anthropicUrl } = options;
+    const { baseUrl, apiKey, anthropicUrl, protocol } = options;
 
     try {
-        const result = await FetchModels(baseUrl, apiKey, anthropicUrl || null);
+        const result = await FetchProtocolModels(protocol || '', baseUrl, apiKey, anthropicUrl || null);
         return {
             models: result.models || [],
-            error: result.error
+            error: result.error,
+            errorKind: result.errorKind as FetchErrorKind | undefined,
+            retryAfter: result.retryAfter,
+            attempts: result.attempts as FetchAttempt[] | undefined,
+            warning: result.warning,
+            truncated: result.truncated
         };
     } catch (e) {
         return {
@@ -38,17 +49,18 @@ function formatModelName(id: string): string {
         .join(' ');
 }
 
-// Transform fetched models to our Model type with sensible defaults
+// Transform fetched models to our Model type, using the metadata the API
+// reported and sensible defaults for the rest
 export function transformFetchedModel(fetched: FetchedModel): Model {
     return {
         id: fetched.id,
-        name: formatModelName(fetched.id),
+        name: fetched.displayName || formatModelName(fetched.id),
         enabled: true,
         parameters: null,
-        pricing: { input: 0, output: 0, cached: null, currency: 'USD' },
-        context: { maxInput: 128000, maxOutput: null },
-        modalities: ['text'],
-        features: {},
+        pricing: fetched.pricing || { input: 0, output: 0, cached: null, currency: 'USD' },
+        context: { maxInput: fetched.contextLength || 128000, maxOutput: fetched.maxOutputTokens ?? null },
+        modalities: fetched.modalities?.length ? fetched.modalities : ['text'],
+        features: fetched.features || {},
         limits: []
     };
 }
@@ -1,58 +1,156 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {models} from '../models';
+import {storage} from '../models';
 import {updater} from '../models';
+import {services} from '../models';
 
-export function AddModel(arg1:string,arg2:models.Model):Promise<void>;
+export function AddAPIKey(arg1:string,arg2:models.APIKey,arg3:number):Promise<models.APIKey>;
+
+export function AddModel(arg1:string,arg2:models.Model,arg3:number):Promise<void>;
+
+export function BackupNow():Promise<storage.BackupFile>;
 
 export function CheckForUpdates():Promise<updater.UpdateInfo>;
 
 export function ClearAllData():Promise<void>;
 
+export function CommitImport(arg1:string,arg2:models.ImportSelection):Promise<models.ImportResult>;
+
 export function CreateProvider(arg1:models.Provider):Promise<models.Provider>;
 
-export function DeleteModel(arg1:string,arg2:string):Promise<void>;
+export function DeleteModel(arg1:string,arg2:string,arg3:number):Promise<void>;
 
 export function DeleteProvider(arg1:string):Promise<void>;
 
-export function ExportData():Promise<boolean>;
+export function DisableEncryption():Promise<void>;
+
+export function DiscardImportPreview(arg1:string):Promise<void>;
+
+export function EnableEncryption(arg1:string,arg2:string):Promise<void>;
+
+export function ExportData(arg1:models.ExportOptions):Promise<models.ExportResult>;
+
+export function ExportProviderTemplate(arg1:Array<string>):Promise<models.TemplateExportResult>;
+
+export function ExportToolConfig(arg1:models.ToolConfigOptions):Promise<models.ToolConfigResult>;
 
 export function FetchModels(arg1:string,arg2:string,arg3:any):Promise<models.FetchModelsResult>;
 
+export function FetchProtocolModels(arg1:models.ProviderProtocol,arg2:string,arg3:string,arg4:any):Promise<models.FetchModelsResult>;
+
 export function GetAllProviders():Promise<Array<models.Provider>>;
 
+export function GetBackupSettings():Promise<storage.BackupSettings>;
+
+export function GetBackupStatus():Promise<services.BackupStatus>;
+
 export function GetCrashReporting():Promise<boolean>;
 
 export function GetDataDir():Promise<string>;
 
+export function GetDataLocation():Promise<storage.Location>;
+
+export function GetDataSchema():Promise<string>;
+
+export function GetEncryptionStatus():Promise<storage.EncryptionStatus>;
+
 export function GetFollowSystemTheme():Promise<boolean>;
 
+export function GetHistory(arg1:number):Promise<Array<storage.JournalEntry>>;
+
 export function GetInitError():Promise<string>;
 
+export function GetInstanceStatus():Promise<services.InstanceStatus>;
+
+export function GetKeyBackendStatus():Promise<services.KeyBackendStatus>;
+
+export function GetKeyringHealth():Promise<services.KeyringHealth>;
+
 export function GetLogDir():Promise<string>;
 
+export function GetModelFetchSettings():Promise<storage.ModelFetchSettings>;
+
 export function GetProvider(arg1:string):Promise<models.Provider>;
 
+export function GetRecoveryReport():Promise<storage.RecoveryReport>;
+
 export function GetTheme():Promise<string>;
 
 export function GetVersion():Promise<string>;
 
 export function HasInitError():Promise<boolean>;
 
-export function ImportData(arg1:string):Promise<models.ImportResult>;
+export function ImportData(arg1:string,arg2:models.MergeOptions):Promise<models.ImportResult>;
+
+export function ImportProviderTemplate():Promise<models.TemplateResult>;
+
+export function ImportWithPassphrase(arg1:string,arg2:string,arg3:models.MergeOptions):Promise<models.ImportResult>;
+
+export function InstantiateProviderTemplate(arg1:string):Promise<models.TemplateResult>;
+
+export function ListBackups():Promise<Array<storage.BackupFile>>;
+
+export function ListSnapshots():Promise<Array<storage.Snapshot>>;
+
+export function LockVault():Promise<void>;
+
+export function PreviewImport(arg1:string,arg2:models.MergeOptions):Promise<models.ImportPreview>;
+
+export function PreviewImportWithPassphrase(arg1:string,arg2:string,arg3:models.MergeOptions):Promise<models.ImportPreview>;
+
+export function ReencryptBackup(arg1:string):Promise<boolean>;
+
+export function ReloadSettings():Promise<storage.AppSettings>;
+
+export function RemoveAPIKey(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function RepairKeyIssue(arg1:string,arg2:string):Promise<void>;
+
+export function RestoreBackup(arg1:string,arg2:string):Promise<models.ImportResult>;
+
+export function RestoreCatalog(arg1:number):Promise<void>;
+
+export function RestoreProviderAt(arg1:string,arg2:number):Promise<void>;
+
+export function RestoreSnapshot(arg1:number):Promise<void>;
 
 export function SaveProviders(arg1:Array<models.Provider>):Promise<void>;
 
+export function ScanKeyring():Promise<services.KeyringHealth>;
+
+export function SetAPIKeyEnabled(arg1:string,arg2:string,arg3:boolean,arg4:number):Promise<void>;
+
+export function SetBackupSettings(arg1:storage.BackupSettings,arg2:string):Promise<void>;
+
 export function SetCrashReporting(arg1:boolean):Promise<void>;
 
 export function SetFollowSystemTheme(arg1:boolean):Promise<void>;
 
+export function SetKeyBackend(arg1:string,arg2:string):Promise<void>;
+
+export function SetModelFetchSettings(arg1:storage.ModelFetchSettings):Promise<void>;
+
 export function SetTheme(arg1:string):Promise<void>;
 
+export function ShareProviderTemplate(arg1:Array<string>):Promise<models.TemplateExportResult>;
+
 export function TransformFetchedModel(arg1:models.FetchedModel):Promise<models.Model>;
 
-export function UpdateCredentials(arg1:string,arg2:Array<string>):Promise<void>;
+export function UnlockData(arg1:string):Promise<void>;
 
-export function UpdateModel(arg1:string,arg2:string,arg3:models.Model):Promise<void>;
+export function UnlockVault(arg1:string):Promise<void>;
+
+export function UpdateAPIKey(arg1:string,arg2:string,arg3:models.APIKey,arg4:number):Promise<void>;
+
+export function UpdateCredentials(arg1:string,arg2:Array<string>,arg3:number):Promise<void>;
+
+export function UpdateModel(arg1:string,arg2:string,arg3:models.Model,arg4:number):Promise<void>;
 
 export function UpdateProvider(arg1:string,arg2:models.Provider):Promise<void>;
+
+export function ValidateData(arg1:string):Promise<Array<models.SchemaError>>;
+
+export function VerifyBackup(arg1:string,arg2:string):Promise<services.BackupVerification>;
+
+export function WriteToolConfig(arg1:string,arg2:models.ToolConfigOptions):Promise<models.ToolConfigResult>;
@@ -2,8 +2,16 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function AddModel(arg1, arg2) {
-  return window['go']['main']['App']['AddModel'](arg1, arg2);
+export function AddAPIKey(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddAPIKey'](arg1, arg2, arg3);
+}
+
+export function AddModel(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddModel'](arg1, arg2, arg3);
+}
+
+export function BackupNow() {
+  return window['go']['main']['App']['BackupNow']();
 }
 
 export function CheckForUpdates() {
@@ -14,30 +22,66 @@ export function ClearAllData() {
   return window['go']['main']['App']['ClearAllData']();
 }
 
+export function CommitImport(arg1, arg2) {
+  return window['go']['main']['App']['CommitImport'](arg1, arg2);
+}
+
 export function CreateProvider(arg1) {
   return window['go']['main']['App']['CreateProvider'](arg1);
 }
 
-export function DeleteModel(arg1, arg2) {
-  return window['go']['main']['App']['DeleteModel'](arg1, arg2);
+export function DeleteModel(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteModel'](arg1, arg2, arg3);
 }
 
 export function DeleteProvider(arg1) {
   return window['go']['main']['App']['DeleteProvider'](arg1);
 }
 
-export function ExportData() {
-  return window['go']['main']['App']['ExportData']();
+export function DisableEncryption() {
+  return window['go']['main']['App']['DisableEncryption']();
+}
+
+export function DiscardImportPreview(arg1) {
+  return window['go']['main']['App']['DiscardImportPreview'](arg1);
+}
+
+export function EnableEncryption(arg1, arg2) {
+  return window['go']['main']['App']['EnableEncryption'](arg1, arg2);
+}
+
+export function ExportData(arg1) {
+  return window['go']['main']['App']['ExportData'](arg1);
+}
+
+export function ExportProviderTemplate(arg1) {
+  return window['go']['main']['App']['ExportProviderTemplate'](arg1);
+}
+
+export function ExportToolConfig(arg1) {
+  return window['go']['main']['App']['ExportToolConfig'](arg1);
 }
 
 export function FetchModels(arg1, arg2, arg3) {
   return window['go']['main']['App']['FetchModels'](arg1, arg2, arg3);
 }
 
+export function FetchProtocolModels(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['FetchProtocolModels'](arg1, arg2, arg3, arg4);
+}
+
 export function GetAllProviders() {
   return window['go']['main']['App']['GetAllProviders']();
 }
 
+export function GetBackupSettings() {
+  return window['go']['main']['App']['GetBackupSettings']();
+}
+
+export function GetBackupStatus() {
+  return window['go']['main']['App']['GetBackupStatus']();
+}
+
 export function GetCrashReporting() {
   return window['go']['main']['App']['GetCrashReporting']();
 }
@@ -46,22 +90,58 @@ export function GetDataDir() {
   return window['go']['main']['App']['GetDataDir']();
 }
 
+export function GetDataLocation() {
+  return window['go']['main']['App']['GetDataLocation']();
+}
+
+export function GetDataSchema() {
+  return window['go']['main']['App']['GetDataSchema']();
+}
+
+export function GetEncryptionStatus() {
+  return window['go']['main']['App']['GetEncryptionStatus']();
+}
+
 export function GetFollowSystemTheme() {
   return window['go']['main']['App']['GetFollowSystemTheme']();
 }
 
+export function GetHistory(arg1) {
+  return window['go']['main']['App']['GetHistory'](arg1);
+}
+
 export function GetInitError() {
   return window['go']['main']['App']['GetInitError']();
 }
 
+export function GetInstanceStatus() {
+  return window['go']['main']['App']['GetInstanceStatus']();
+}
+
+export function GetKeyBackendStatus() {
+  return window['go']['main']['App']['GetKeyBackendStatus']();
+}
+
+export function GetKeyringHealth() {
+  return window['go']['main']['App']['GetKeyringHealth']();
+}
+
 export function GetLogDir() {
   return window['go']['main']['App']['GetLogDir']();
 }
 
+export function GetModelFetchSettings() {
+  return window['go']['main']['App']['GetModelFetchSettings']();
+}
+
 export function GetProvider(arg1) {
   return window['go']['main']['App']['GetProvider'](arg1);
 }
 
+export function GetRecoveryReport() {
+  return window['go']['main']['App']['GetRecoveryReport']();
+}
+
 export function GetTheme() {
   return window['go']['main']['App']['GetTheme']();
 }
@@ -74,14 +154,90 @@ export function HasInitError() {
   return window['go']['main']['App']['HasInitError']();
 }
 
-export function ImportData(arg1) {
-  return window['go']['main']['App']['ImportData'](arg1);
+export function ImportData(arg1, arg2) {
+  return window['go']['main']['App']['ImportData'](arg1, arg2);
+}
+
+export function ImportProviderTemplate() {
+  return window['go']['main']['App']['ImportProviderTemplate']();
+}
+
+export function ImportWithPassphrase(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportWithPassphrase'](arg1, arg2, arg3);
+}
+
+export function InstantiateProviderTemplate(arg1) {
+  return window['go']['main']['App']['InstantiateProviderTemplate'](arg1);
+}
+
+export function ListBackups() {
+  return window['go']['main']['App']['ListBackups']();
+}
+
+export function ListSnapshots() {
+  return window['go']['main']['App']['ListSnapshots']();
+}
+
+export function LockVault() {
+  return window['go']['main']['App']['LockVault']();
+}
+
+export function PreviewImport(arg1, arg2) {
+  return window['go']['main']['App']['PreviewImport'](arg1, arg2);
+}
+
+export function PreviewImportWithPassphrase(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewImportWithPassphrase'](arg1, arg2, arg3);
+}
+
+export function ReencryptBackup(arg1) {
+  return window['go']['main']['App']['ReencryptBackup'](arg1);
+}
+
+export function ReloadSettings() {
+  return window['go']['main']['App']['ReloadSettings']();
+}
+
+export function RemoveAPIKey(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RemoveAPIKey'](arg1, arg2, arg3);
+}
+
+export function RepairKeyIssue(arg1, arg2) {
+  return window['go']['main']['App']['RepairKeyIssue'](arg1, arg2);
+}
+
+export function RestoreBackup(arg1, arg2) {
+  return window['go']['main']['App']['RestoreBackup'](arg1, arg2);
+}
+
+export function RestoreCatalog(arg1) {
+  return window['go']['main']['App']['RestoreCatalog'](arg1);
+}
+
+export function RestoreProviderAt(arg1, arg2) {
+  return window['go']['main']['App']['RestoreProviderAt'](arg1, arg2);
+}
+
+export function RestoreSnapshot(arg1) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1);
 }
 
 export function SaveProviders(arg1) {
   return window['go']['main']['App']['SaveProviders'](arg1);
 }
 
+export function ScanKeyring() {
+  return window['go']['main']['App']['ScanKeyring']();
+}
+
+export function SetAPIKeyEnabled(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetAPIKeyEnabled'](arg1, arg2, arg3, arg4);
+}
+
+export function SetBackupSettings(arg1, arg2) {
+  return window['go']['main']['App']['SetBackupSettings'](arg1, arg2);
+}
+
 export function SetCrashReporting(arg1) {
   return window['go']['main']['App']['SetCrashReporting'](arg1);
 }
@@ -90,22 +246,58 @@ export function SetFollowSystemTheme(arg1) {
   return window['go']['main']['App']['SetFollowSystemTheme'](arg1);
 }
 
+export function SetKeyBackend(arg1, arg2) {
+  return window['go']['main']['App']['SetKeyBackend'](arg1, arg2);
+}
+
+export function SetModelFetchSettings(arg1) {
+  return window['go']['main']['App']['SetModelFetchSettings'](arg1);
+}
+
 export function SetTheme(arg1) {
   return window['go']['main']['App']['SetTheme'](arg1);
 }
 
+export function ShareProviderTemplate(arg1) {
+  return window['go']['main']['App']['ShareProviderTemplate'](arg1);
+}
+
 export function TransformFetchedModel(arg1) {
   return window['go']['main']['App']['TransformFetchedModel'](arg1);
 }
 
-export function UpdateCredentials(arg1, arg2) {
-  return window['go']['main']['App']['UpdateCredentials'](arg1, arg2);
+export function UnlockData(arg1) {
+  return window['go']['main']['App']['UnlockData'](arg1);
 }
 
-export function UpdateModel(arg1, arg2, arg3) {
-  return window['go']['main']['App']['UpdateModel'](arg1, arg2, arg3);
+export function UnlockVault(arg1) {
+  return window['go']['main']['App']['UnlockVault'](arg1);
+}
+
+export function UpdateAPIKey(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateAPIKey'](arg1, arg2, arg3, arg4);
+}
+
+export function UpdateCredentials(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateCredentials'](arg1, arg2, arg3);
+}
+
+export function UpdateModel(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateModel'](arg1, arg2, arg3, arg4);
 }
 
 export function UpdateProvider(arg1, arg2) {
   return window['go']['main']['App']['UpdateProvider'](arg1, arg2);
 }
+
+export function ValidateData(arg1) {
+  return window['go']['main']['App']['ValidateData'](arg1);
+}
+
+export function VerifyBackup(arg1, arg2) {
+  return window['go']['main']['App']['VerifyBackup'](arg1, arg2);
+}
+
+export function WriteToolConfig(arg1, arg2) {
+  return window['go']['main']['App']['WriteToolConfig'](arg1, arg2);
+}
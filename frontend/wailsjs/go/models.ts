@@ -1,5 +1,37 @@
 export namespace models {
 	
+	export class APIKey {
+	    id: string;
+	    label: string;
+	    key?: string;
+	    fingerprint?: string;
+	    hint?: string;
+	    createdAt: string;
+	    expiresAt?: string;
+	    enabled: boolean;
+	    models?: string[];
+	    lastVerifiedAt?: string;
+	    status?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new APIKey(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.key = source["key"];
+	        this.fingerprint = source["fingerprint"];
+	        this.hint = source["hint"];
+	        this.createdAt = source["createdAt"];
+	        this.expiresAt = source["expiresAt"];
+	        this.enabled = source["enabled"];
+	        this.models = source["models"];
+	        this.lastVerifiedAt = source["lastVerifiedAt"];
+	        this.status = source["status"];
+	    }
+	}
 	export class Context {
 	    maxInput: number;
 	    maxOutput?: number;
@@ -15,7 +47,7 @@ export namespace models {
 	    }
 	}
 	export class Credentials {
-	    apiKeys: string[];
+	    apiKeys: APIKey[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Credentials(source);
@@ -23,125 +55,121 @@ export namespace models {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.apiKeys = source["apiKeys"];
+	        this.apiKeys = this.convertValues(source["apiKeys"], APIKey);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class Endpoints {
-	    openai: string;
-	    anthropic?: string;
+	export class DuplicateProvider {
+	    incomingId: string;
+	    incomingName: string;
+	    localId: string;
+	    localName: string;
+	    endpoint: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Endpoints(source);
+	        return new DuplicateProvider(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.openai = source["openai"];
-	        this.anthropic = source["anthropic"];
+	        this.incomingId = source["incomingId"];
+	        this.incomingName = source["incomingName"];
+	        this.localId = source["localId"];
+	        this.localName = source["localName"];
+	        this.endpoint = source["endpoint"];
 	    }
 	}
-	export class FetchedModel {
-	    id: string;
-	    object?: string;
-	    created?: number;
-	    owned_by?: string;
+	export class Endpoints {
+	    openai: string;
+	    anthropic?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FetchedModel(source);
+	        return new Endpoints(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.object = source["object"];
-	        this.created = source["created"];
-	        this.owned_by = source["owned_by"];
+	        this.openai = source["openai"];
+	        this.anthropic = source["anthropic"];
 	    }
 	}
-	export class FetchModelsResult {
-	    models: FetchedModel[];
-	    error?: string;
+	export class ExportOptions {
+	    secrets: string;
+	    passphrase?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FetchModelsResult(source);
+	        return new ExportOptions(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.models = this.convertValues(source["models"], FetchedModel);
-	        this.error = source["error"];
+	        this.secrets = source["secrets"];
+	        this.passphrase = source["passphrase"];
 	    }
-	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
-		    } else if ("object" === typeof a) {
-		        if (asMap) {
-		            for (const key of Object.keys(a)) {
-		                a[key] = new classs(a[key]);
-		            }
-		            return a;
-		        }
-		        return new classs(a);
-		    }
-		    return a;
-		}
 	}
-	
-	export class ImportResult {
+	export class ExportResult {
 	    success: boolean;
+	    cancelled: boolean;
 	    message: string;
 	    warnings: string[];
-	    // Go type: struct { Providers int "json:\"providers\""; Models int "json:\"models\"" }
-	    imported: any;
+	    encrypted: boolean;
+	    containsPlaintextSecrets: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ImportResult(source);
+	        return new ExportResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.success = source["success"];
+	        this.cancelled = source["cancelled"];
 	        this.message = source["message"];
 	        this.warnings = source["warnings"];
-	        this.imported = this.convertValues(source["imported"], Object);
+	        this.encrypted = source["encrypted"];
+	        this.containsPlaintextSecrets = source["containsPlaintextSecrets"];
 	    }
-	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
-		    } else if ("object" === typeof a) {
-		        if (asMap) {
-		            for (const key of Object.keys(a)) {
-		                a[key] = new classs(a[key]);
-		            }
-		            return a;
-		        }
-		        return new classs(a);
-		    }
-		    return a;
-		}
 	}
-	export class Limit {
-	    type: string;
-	    limit: number;
-	    window: number;
+	export class FetchAttempt {
+	    protocol: string;
+	    endpoint: string;
+	    status?: number;
+	    errorKind?: string;
+	    message?: string;
+	    retryAfter?: number;
+	    bodyExcerpt?: string;
+	    models?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Limit(source);
+	        return new FetchAttempt(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.type = source["type"];
-	        this.limit = source["limit"];
-	        this.window = source["window"];
+	        this.protocol = source["protocol"];
+	        this.endpoint = source["endpoint"];
+	        this.status = source["status"];
+	        this.errorKind = source["errorKind"];
+	        this.message = source["message"];
+	        this.retryAfter = source["retryAfter"];
+	        this.bodyExcerpt = source["bodyExcerpt"];
+	        this.models = source["models"];
 	    }
 	}
 	export class ModelFeatures {
@@ -182,32 +210,38 @@ export namespace models {
 	        this.currency = source["currency"];
 	    }
 	}
-	export class Model {
+	export class FetchedModel {
 	    id: string;
-	    name: string;
-	    enabled: boolean;
+	    object?: string;
+	    created?: number;
+	    owned_by?: string;
 	    parameters?: string;
-	    pricing: Pricing;
-	    context: Context;
-	    modalities: string[];
+	    displayName?: string;
+	    description?: string;
+	    contextLength?: number;
+	    maxOutputTokens?: number;
+	    pricing?: Pricing;
+	    modalities?: string[];
 	    features?: ModelFeatures;
-	    limits?: Limit[];
 	
 	    static createFrom(source: any = {}) {
-	        return new Model(source);
+	        return new FetchedModel(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.enabled = source["enabled"];
+	        this.object = source["object"];
+	        this.created = source["created"];
+	        this.owned_by = source["owned_by"];
 	        this.parameters = source["parameters"];
+	        this.displayName = source["displayName"];
+	        this.description = source["description"];
+	        this.contextLength = source["contextLength"];
+	        this.maxOutputTokens = source["maxOutputTokens"];
 	        this.pricing = this.convertValues(source["pricing"], Pricing);
-	        this.context = this.convertValues(source["context"], Context);
 	        this.modalities = source["modalities"];
 	        this.features = this.convertValues(source["features"], ModelFeatures);
-	        this.limits = this.convertValues(source["limits"], Limit);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -228,50 +262,181 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class FetchModelsResult {
+	    models: FetchedModel[];
+	    error?: string;
+	    errorKind?: string;
+	    retryAfter?: number;
+	    attempts?: FetchAttempt[];
+	    warning?: string;
+	    pages?: number;
+	    truncated?: boolean;
 	
+	    static createFrom(source: any = {}) {
+	        return new FetchModelsResult(source);
+	    }
 	
-	export class ProviderFeatures {
-	    streaming?: boolean;
-	    toolCalling?: boolean;
-	    jsonMode?: boolean;
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.models = this.convertValues(source["models"], FetchedModel);
+	        this.error = source["error"];
+	        this.errorKind = source["errorKind"];
+	        this.retryAfter = source["retryAfter"];
+	        this.attempts = this.convertValues(source["attempts"], FetchAttempt);
+	        this.warning = source["warning"];
+	        this.pages = source["pages"];
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FieldChange {
+	    field: string;
+	    before?: any;
+	    after?: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new ProviderFeatures(source);
+	        return new FieldChange(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.streaming = source["streaming"];
-	        this.toolCalling = source["toolCalling"];
-	        this.jsonMode = source["jsonMode"];
+	        this.field = source["field"];
+	        this.before = source["before"];
+	        this.after = source["after"];
 	    }
 	}
-	export class Provider {
-	    id: string;
+	export class ImportIssue {
+	    providerId: string;
+	    modelId?: string;
+	    field?: string;
+	    message: string;
+	    action: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.providerId = source["providerId"];
+	        this.modelId = source["modelId"];
+	        this.field = source["field"];
+	        this.message = source["message"];
+	        this.action = source["action"];
+	    }
+	}
+	export class ImportSummary {
+	    providersAdded: number;
+	    providersChanged: number;
+	    providersRemoved: number;
+	    modelsAdded: number;
+	    modelsChanged: number;
+	    modelsRemoved: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.providersAdded = source["providersAdded"];
+	        this.providersChanged = source["providersChanged"];
+	        this.providersRemoved = source["providersRemoved"];
+	        this.modelsAdded = source["modelsAdded"];
+	        this.modelsChanged = source["modelsChanged"];
+	        this.modelsRemoved = source["modelsRemoved"];
+	    }
+	}
+	export class SchemaError {
+	    pointer: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SchemaError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pointer = source["pointer"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ModelDiff {
+	    modelId: string;
 	    name: string;
-	    enabled: boolean;
-	    credentials: Credentials;
-	    endpoints: Endpoints;
-	    limits: Limit[];
-	    features: ProviderFeatures;
-	    models: Model[];
-	    isCustom?: boolean;
+	    action: string;
+	    fields?: FieldChange[];
 	
 	    static createFrom(source: any = {}) {
-	        return new Provider(source);
+	        return new ModelDiff(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
+	        this.modelId = source["modelId"];
 	        this.name = source["name"];
-	        this.enabled = source["enabled"];
-	        this.credentials = this.convertValues(source["credentials"], Credentials);
-	        this.endpoints = this.convertValues(source["endpoints"], Endpoints);
-	        this.limits = this.convertValues(source["limits"], Limit);
-	        this.features = this.convertValues(source["features"], ProviderFeatures);
-	        this.models = this.convertValues(source["models"], Model);
-	        this.isCustom = source["isCustom"];
+	        this.action = source["action"];
+	        this.fields = this.convertValues(source["fields"], FieldChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProviderDiff {
+	    providerId: string;
+	    name: string;
+	    action: string;
+	    fields?: FieldChange[];
+	    models?: ModelDiff[];
+	    keysBefore: number;
+	    keysAfter: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.providerId = source["providerId"];
+	        this.name = source["name"];
+	        this.action = source["action"];
+	        this.fields = this.convertValues(source["fields"], FieldChange);
+	        this.models = this.convertValues(source["models"], ModelDiff);
+	        this.keysBefore = source["keysBefore"];
+	        this.keysAfter = source["keysAfter"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -292,6 +457,871 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class ImportPreview {
+	    token: string;
+	    mode: string;
+	    format?: string;
+	    message: string;
+	    warnings: string[];
+	    passphraseRequired: boolean;
+	    encrypted: boolean;
+	    containsPlaintextSecrets: boolean;
+	    policy?: string;
+	    providers: ProviderDiff[];
+	    duplicates: DuplicateProvider[];
+	    issues: ImportIssue[];
+	    schemaErrors?: SchemaError[];
+	    summary: ImportSummary;
+	    expiresAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.token = source["token"];
+	        this.mode = source["mode"];
+	        this.format = source["format"];
+	        this.message = source["message"];
+	        this.warnings = source["warnings"];
+	        this.passphraseRequired = source["passphraseRequired"];
+	        this.encrypted = source["encrypted"];
+	        this.containsPlaintextSecrets = source["containsPlaintextSecrets"];
+	        this.policy = source["policy"];
+	        this.providers = this.convertValues(source["providers"], ProviderDiff);
+	        this.duplicates = this.convertValues(source["duplicates"], DuplicateProvider);
+	        this.issues = this.convertValues(source["issues"], ImportIssue);
+	        this.schemaErrors = this.convertValues(source["schemaErrors"], SchemaError);
+	        this.summary = this.convertValues(source["summary"], ImportSummary);
+	        this.expiresAt = source["expiresAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportResult {
+	    success: boolean;
+	    message: string;
+	    warnings: string[];
+	    passphraseRequired: boolean;
+	    encrypted: boolean;
+	    format?: string;
+	    containsPlaintextSecrets: boolean;
+	    issues?: ImportIssue[];
+	    quarantinePath?: string;
+	    schemaErrors?: SchemaError[];
+	    // Go type: struct { Providers int "json:\"providers\""; Models int "json:\"models\"" }
+	    imported: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.warnings = source["warnings"];
+	        this.passphraseRequired = source["passphraseRequired"];
+	        this.encrypted = source["encrypted"];
+	        this.format = source["format"];
+	        this.containsPlaintextSecrets = source["containsPlaintextSecrets"];
+	        this.issues = this.convertValues(source["issues"], ImportIssue);
+	        this.quarantinePath = source["quarantinePath"];
+	        this.schemaErrors = this.convertValues(source["schemaErrors"], SchemaError);
+	        this.imported = this.convertValues(source["imported"], Object);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportSelection {
+	    providers: string[];
+	    models?: Record<string, Array<string>>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportSelection(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.providers = source["providers"];
+	        this.models = source["models"];
+	    }
+	}
+	
+	export class Limit {
+	    type: string;
+	    limit: number;
+	    window: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Limit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.limit = source["limit"];
+	        this.window = source["window"];
+	    }
+	}
+	export class MergeOptions {
+	    policy: string;
+	    mergeDuplicates?: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new MergeOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.policy = source["policy"];
+	        this.mergeDuplicates = source["mergeDuplicates"];
+	    }
+	}
+	export class Model {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    parameters?: string;
+	    pricing: Pricing;
+	    context: Context;
+	    modalities: string[];
+	    features?: ModelFeatures;
+	    limits?: Limit[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Model(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.parameters = source["parameters"];
+	        this.pricing = this.convertValues(source["pricing"], Pricing);
+	        this.context = this.convertValues(source["context"], Context);
+	        this.modalities = source["modalities"];
+	        this.features = this.convertValues(source["features"], ModelFeatures);
+	        this.limits = this.convertValues(source["limits"], Limit);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class ProviderFeatures {
+	    streaming?: boolean;
+	    toolCalling?: boolean;
+	    jsonMode?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderFeatures(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.streaming = source["streaming"];
+	        this.toolCalling = source["toolCalling"];
+	        this.jsonMode = source["jsonMode"];
+	    }
+	}
+	export class Provider {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    credentials: Credentials;
+	    endpoints: Endpoints;
+	    limits: Limit[];
+	    features: ProviderFeatures;
+	    models: Model[];
+	    protocol?: string;
+	    isCustom?: boolean;
+	    revision?: number;
+	    updatedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Provider(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.credentials = this.convertValues(source["credentials"], Credentials);
+	        this.endpoints = this.convertValues(source["endpoints"], Endpoints);
+	        this.limits = this.convertValues(source["limits"], Limit);
+	        this.features = this.convertValues(source["features"], ProviderFeatures);
+	        this.models = this.convertValues(source["models"], Model);
+	        this.protocol = source["protocol"];
+	        this.isCustom = source["isCustom"];
+	        this.revision = source["revision"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class TemplateExportResult {
+	    success: boolean;
+	    cancelled: boolean;
+	    message: string;
+	    path?: string;
+	    shareString?: string;
+	    providers: number;
+	    models: number;
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.cancelled = source["cancelled"];
+	        this.message = source["message"];
+	        this.path = source["path"];
+	        this.shareString = source["shareString"];
+	        this.providers = source["providers"];
+	        this.models = source["models"];
+	        this.warnings = source["warnings"];
+	    }
+	}
+	export class TemplateResult {
+	    success: boolean;
+	    cancelled: boolean;
+	    message: string;
+	    warnings: string[];
+	    providers: Provider[];
+	    needsKeys: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.cancelled = source["cancelled"];
+	        this.message = source["message"];
+	        this.warnings = source["warnings"];
+	        this.providers = this.convertValues(source["providers"], Provider);
+	        this.needsKeys = source["needsKeys"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ToolConfigOptions {
+	    format: string;
+	    providers?: string[];
+	    keys: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ToolConfigOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.providers = source["providers"];
+	        this.keys = source["keys"];
+	    }
+	}
+	export class ToolConfigResult {
+	    success: boolean;
+	    cancelled: boolean;
+	    message: string;
+	    warnings: string[];
+	    path?: string;
+	    providers: number;
+	    models: number;
+	    envVars: string[];
+	    containsPlaintextSecrets: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ToolConfigResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.cancelled = source["cancelled"];
+	        this.message = source["message"];
+	        this.warnings = source["warnings"];
+	        this.path = source["path"];
+	        this.providers = source["providers"];
+	        this.models = source["models"];
+	        this.envVars = source["envVars"];
+	        this.containsPlaintextSecrets = source["containsPlaintextSecrets"];
+	    }
+	}
+
+}
+
+export namespace services {
+	
+	export class BackupStatus {
+	    enabled: boolean;
+	    directory: string;
+	    passphraseSet: boolean;
+	    lastBackup?: storage.BackupFile;
+	    lastError?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.directory = source["directory"];
+	        this.passphraseSet = source["passphraseSet"];
+	        this.lastBackup = this.convertValues(source["lastBackup"], storage.BackupFile);
+	        this.lastError = source["lastError"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupVerification {
+	    name: string;
+	    valid: boolean;
+	    encrypted: boolean;
+	    version?: string;
+	    providers: number;
+	    models: number;
+	    secrets: boolean;
+	    issues: models.ImportIssue[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupVerification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.valid = source["valid"];
+	        this.encrypted = source["encrypted"];
+	        this.version = source["version"];
+	        this.providers = source["providers"];
+	        this.models = source["models"];
+	        this.secrets = source["secrets"];
+	        this.issues = this.convertValues(source["issues"], models.ImportIssue);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InstanceStatus {
+	    owned: boolean;
+	    holder?: storage.LockInfo;
+	
+	    static createFrom(source: any = {}) {
+	        return new InstanceStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.owned = source["owned"];
+	        this.holder = this.convertValues(source["holder"], storage.LockInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class KeyBackendStatus {
+	    active: string;
+	    preference: string;
+	    keyringAvailable: boolean;
+	    keyringError?: string;
+	    vaultPath: string;
+	    vaultExists: boolean;
+	    vaultLocked: boolean;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyBackendStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.preference = source["preference"];
+	        this.keyringAvailable = source["keyringAvailable"];
+	        this.keyringError = source["keyringError"];
+	        this.vaultPath = source["vaultPath"];
+	        this.vaultExists = source["vaultExists"];
+	        this.vaultLocked = source["vaultLocked"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class KeyIssue {
+	    kind: string;
+	    providerId: string;
+	    providerName?: string;
+	    detail: string;
+	    repair: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.providerId = source["providerId"];
+	        this.providerName = source["providerName"];
+	        this.detail = source["detail"];
+	        this.repair = source["repair"];
+	    }
+	}
+	export class KeyringHealth {
+	    status: string;
+	    issues: KeyIssue[];
+	    listable: boolean;
+	    message?: string;
+	    checkedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeyringHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.issues = this.convertValues(source["issues"], KeyIssue);
+	        this.listable = source["listable"];
+	        this.message = source["message"];
+	        this.checkedAt = source["checkedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace storage {
+	
+	export class ModelFetchSettings {
+	    maxPages: number;
+	    maxModels: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelFetchSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxPages = source["maxPages"];
+	        this.maxModels = source["maxModels"];
+	    }
+	}
+	export class BackupSettings {
+	    enabled: boolean;
+	    directory?: string;
+	    onChange: boolean;
+	    intervalMinutes: number;
+	    includeSecrets: boolean;
+	    keepLast: number;
+	    keepDaily: number;
+	    keepWeekly: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.directory = source["directory"];
+	        this.onChange = source["onChange"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	        this.includeSecrets = source["includeSecrets"];
+	        this.keepLast = source["keepLast"];
+	        this.keepDaily = source["keepDaily"];
+	        this.keepWeekly = source["keepWeekly"];
+	    }
+	}
+	export class AppSettings {
+	    theme: string;
+	    followSystemTheme: boolean;
+	    enableCrashReporting: boolean;
+	    keyBackend?: string;
+	    backup: BackupSettings;
+	    modelFetch: ModelFetchSettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.theme = source["theme"];
+	        this.followSystemTheme = source["followSystemTheme"];
+	        this.enableCrashReporting = source["enableCrashReporting"];
+	        this.keyBackend = source["keyBackend"];
+	        this.backup = this.convertValues(source["backup"], BackupSettings);
+	        this.modelFetch = this.convertValues(source["modelFetch"], ModelFetchSettings);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupFile {
+	    name: string;
+	    path: string;
+	    createdAt: string;
+	    size: number;
+	    encrypted: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.createdAt = source["createdAt"];
+	        this.size = source["size"];
+	        this.encrypted = source["encrypted"];
+	    }
+	}
+	
+	export class EncryptionStatus {
+	    enabled: boolean;
+	    keySource?: string;
+	    locked: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.keySource = source["keySource"];
+	        this.locked = source["locked"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ProviderChange {
+	    providerId: string;
+	    action: string;
+	    modelIds?: string[];
+	    before?: models.Provider;
+	    after?: models.Provider;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.providerId = source["providerId"];
+	        this.action = source["action"];
+	        this.modelIds = source["modelIds"];
+	        this.before = this.convertValues(source["before"], models.Provider);
+	        this.after = this.convertValues(source["after"], models.Provider);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JournalEntry {
+	    seq: number;
+	    timestamp: string;
+	    operation: string;
+	    changes: ProviderChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
+	        this.timestamp = source["timestamp"];
+	        this.operation = source["operation"];
+	        this.changes = this.convertValues(source["changes"], ProviderChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Location {
+	    dataDir: string;
+	    source: string;
+	    portable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Location(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dataDir = source["dataDir"];
+	        this.source = source["source"];
+	        this.portable = source["portable"];
+	    }
+	}
+	export class LockInfo {
+	    pid: number;
+	    hostname: string;
+	    startedAt: string;
+	    heartbeat: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LockInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pid = source["pid"];
+	        this.hostname = source["hostname"];
+	        this.startedAt = source["startedAt"];
+	        this.heartbeat = source["heartbeat"];
+	    }
+	}
+	
+	
+	export class RecoveryReport {
+	    file: string;
+	    generation: number;
+	    reason: string;
+	    recoveredAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecoveryReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.file = source["file"];
+	        this.generation = source["generation"];
+	        this.reason = source["reason"];
+	        this.recoveredAt = source["recoveredAt"];
+	    }
+	}
+	export class Snapshot {
+	    generation: number;
+	    path: string;
+	    modifiedAt: string;
+	    size: number;
+	    valid: boolean;
+	    providers: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.generation = source["generation"];
+	        this.path = source["path"];
+	        this.modifiedAt = source["modifiedAt"];
+	        this.size = source["size"];
+	        this.valid = source["valid"];
+	        this.providers = source["providers"];
+	        this.error = source["error"];
+	    }
+	}
 
 }
 
@@ -6,6 +6,7 @@ require (
 	github.com/wailsapp/wails/v2 v2.11.0
 	github.com/zalando/go-keyring v0.2.6
 	golang.org/x/crypto v0.47.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// API key verification statuses
+const (
+	KeyStatusUnknown = "unknown"
+	KeyStatusValid   = "valid"
+	KeyStatusInvalid = "invalid"
+)
+
+// APIKey is a single credential of a provider. Key holds the secret; it is
+// never written to providers.json and lives in the OS keyring or vault.
+type APIKey struct {
+	ID             string   `json:"id"`
+	Label          string   `json:"label"`
+	Key            string   `json:"key,omitempty"`
+	Fingerprint    string   `json:"fingerprint,omitempty"` // Matches the record to its stored secret
+	Hint           string   `json:"hint,omitempty"`        // Last characters of the secret, for display
+	CreatedAt      string   `json:"createdAt"`
+	ExpiresAt      *string  `json:"expiresAt,omitempty"`
+	Enabled        bool     `json:"enabled"`
+	Models         []string `json:"models,omitempty"` // Model IDs the key is limited to; empty means all
+	LastVerifiedAt *string  `json:"lastVerifiedAt,omitempty"`
+	Status         string   `json:"status,omitempty" schema:"enum"`
+}
+
+// UnmarshalJSON accepts both a key record and the legacy bare secret string
+func (k *APIKey) UnmarshalJSON(data []byte) error {
+	var secret string
+	if err := json.Unmarshal(data, &secret); err == nil {
+		*k = NewAPIKey(secret)
+		return nil
+	}
+
+	type plain APIKey
+	var record plain
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	*k = APIKey(record)
+	return nil
+}
+
+// NewAPIKey creates an enabled key record for secret with a fresh ID
+func NewAPIKey(secret string) APIKey {
+	k := APIKey{
+		ID:        NewAPIKeyID(),
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Enabled:   true,
+		Status:    KeyStatusUnknown,
+	}
+	k.SetSecret(secret)
+	return k
+}
+
+// NewAPIKeys creates key records for a list of secrets
+func NewAPIKeys(secrets ...string) []APIKey {
+	keys := make([]APIKey, 0, len(secrets))
+	for _, secret := range secrets {
+		keys = append(keys, NewAPIKey(secret))
+	}
+	return keys
+}
+
+// NewAPIKeyID returns a random identifier for a key record
+func NewAPIKeyID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "key-" + time.Now().Format("20060102150405.000000000")
+	}
+	return "key-" + hex.EncodeToString(b)
+}
+
+// KeyFingerprint returns a short, non-reversible identifier of a secret
+func KeyFingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// keyHint returns the last four characters of a secret
+func keyHint(secret string) string {
+	if len(secret) <= 8 {
+		return ""
+	}
+	return secret[len(secret)-4:]
+}
+
+// SetSecret replaces the secret and refreshes the derived fingerprint and hint
+func (k *APIKey) SetSecret(secret string) {
+	k.Key = secret
+	if secret == "" {
+		return
+	}
+	k.Fingerprint = KeyFingerprint(secret)
+	k.Hint = keyHint(secret)
+}
+
+// IsExpired reports whether the key has an expiry at or before now
+func (k APIKey) IsExpired(now time.Time) bool {
+	if k.ExpiresAt == nil || *k.ExpiresAt == "" {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, *k.ExpiresAt)
+	if err != nil {
+		return false
+	}
+	return !expires.After(now)
+}
+
+// IsUsable reports whether the key is enabled, unexpired and has a secret
+func (k APIKey) IsUsable(now time.Time) bool {
+	return k.Enabled && k.Key != "" && !k.IsExpired(now)
+}
+
+// AllowsModel reports whether the key may be used for modelID
+func (k APIKey) AllowsModel(modelID string) bool {
+	if len(k.Models) == 0 {
+		return true
+	}
+	for _, m := range k.Models {
+		if m == modelID {
+			return true
+		}
+	}
+	return false
+}
+
+// UsableSecrets returns the secrets of every usable key, in order
+func (c Credentials) UsableSecrets() []string {
+	now := time.Now()
+	secrets := []string{}
+	for _, k := range c.APIKeys {
+		if k.IsUsable(now) {
+			secrets = append(secrets, k.Key)
+		}
+	}
+	return secrets
+}
+
+// UsableKey returns the first usable key that may be used for modelID, or
+// nil. An empty modelID accepts keys of any scope, as for listing models.
+func (c Credentials) UsableKey(modelID string, now time.Time) *APIKey {
+	for i, k := range c.APIKeys {
+		if k.IsUsable(now) && (modelID == "" || k.AllowsModel(modelID)) {
+			return &c.APIKeys[i]
+		}
+	}
+	return nil
+}
+
+// FindKey returns the key record with id, or nil
+func (c *Credentials) FindKey(id string) *APIKey {
+	for i := range c.APIKeys {
+		if c.APIKeys[i].ID == id {
+			return &c.APIKeys[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAPIKey_UnmarshalLegacyString(t *testing.T) {
+	var creds Credentials
+	if err := json.Unmarshal([]byte(`{"apiKeys": ["sk-legacy-123456"]}`), &creds); err != nil {
+		t.Fatalf("Failed to unmarshal legacy credentials: %v", err)
+	}
+
+	if len(creds.APIKeys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(creds.APIKeys))
+	}
+	k := creds.APIKeys[0]
+	if k.Key != "sk-legacy-123456" || k.ID == "" || !k.Enabled {
+		t.Errorf("Unexpected migrated key: %+v", k)
+	}
+	if k.Fingerprint != KeyFingerprint("sk-legacy-123456") || k.Hint != "3456" {
+		t.Errorf("Expected fingerprint and hint to be derived, got %+v", k)
+	}
+}
+
+func TestAPIKey_UnmarshalRecord(t *testing.T) {
+	var k APIKey
+	data := `{"id":"key-1","label":"CI","createdAt":"2026-01-01T00:00:00Z","enabled":false,"models":["gpt-4o"]}`
+	if err := json.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatalf("Failed to unmarshal key record: %v", err)
+	}
+	if k.ID != "key-1" || k.Label != "CI" || k.Enabled || len(k.Models) != 1 {
+		t.Errorf("Unexpected key record: %+v", k)
+	}
+}
+
+func TestAPIKey_Usability(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	past := "2026-05-01T00:00:00Z"
+	future := "2026-07-01T00:00:00Z"
+
+	k := NewAPIKey("sk-abc")
+	if !k.IsUsable(now) {
+		t.Error("New key should be usable")
+	}
+
+	k.ExpiresAt = &past
+	if !k.IsExpired(now) || k.IsUsable(now) {
+		t.Error("Expired key should not be usable")
+	}
+
+	k.ExpiresAt = &future
+	k.Enabled = false
+	if k.IsUsable(now) {
+		t.Error("Disabled key should not be usable")
+	}
+
+	k.Models = []string{"gpt-4o"}
+	if k.AllowsModel("gpt-3.5") || !k.AllowsModel("gpt-4o") {
+		t.Error("Model scope not applied")
+	}
+}
+
+func TestCredentials_UsableKey(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	past := "2026-05-01T00:00:00Z"
+
+	disabled, expired, scoped, open := NewAPIKey("sk-off"), NewAPIKey("sk-old"), NewAPIKey("sk-4o"), NewAPIKey("sk-any")
+	disabled.Enabled = false
+	expired.ExpiresAt = &past
+	scoped.Models = []string{"gpt-4o"}
+	c := Credentials{APIKeys: []APIKey{disabled, expired, scoped, open}}
+
+	if k := c.UsableKey("gpt-4o", now); k == nil || k.Key != "sk-4o" {
+		t.Errorf("Expected the scoped key for its model, got %+v", k)
+	}
+	if k := c.UsableKey("o1", now); k == nil || k.Key != "sk-any" {
+		t.Errorf("Expected the unscoped key for another model, got %+v", k)
+	}
+	if k := c.UsableKey("", now); k == nil || k.Key != "sk-4o" {
+		t.Errorf("Expected any usable key without a model, got %+v", k)
+	}
+	if k := (Credentials{APIKeys: []APIKey{disabled, expired}}).UsableKey("", now); k != nil {
+		t.Errorf("Expected no usable key, got %+v", k)
+	}
+}
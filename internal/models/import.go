@@ -0,0 +1,152 @@
+package models
+
+// DiffAction describes what an import does to a provider or model
+type DiffAction string
+
+const (
+	DiffAdded   DiffAction = "added"
+	DiffChanged DiffAction = "changed"
+	DiffRemoved DiffAction = "removed"
+)
+
+// ImportFormat identifies the kind of file an import was read from
+type ImportFormat string
+
+const (
+	ImportFormatLLMDesk    ImportFormat = "llmdesk"    // LLM Desk backup (LLMDeskData)
+	ImportFormatLiteLLM    ImportFormat = "litellm"    // LiteLLM proxy config.yaml model_list
+	ImportFormatContinue   ImportFormat = "continue"   // Continue config.json
+	ImportFormatAider      ImportFormat = "aider"      // Aider .aider.model.metadata.json
+	ImportFormatOpenRouter ImportFormat = "openrouter" // OpenRouter-style model list
+)
+
+// MergePolicy decides which side wins when a field or model differs between
+// the local catalog and a merged import
+type MergePolicy string
+
+const (
+	MergeKeepLocal    MergePolicy = "keepLocal"
+	MergeTakeIncoming MergePolicy = "takeIncoming"
+	MergeNewestWins   MergePolicy = "newestWins" // Compares when each local field or model last changed with the imported updatedAt, or the file's modifiedAt
+)
+
+// MergeOptions configures merge-mode imports. Policy defaults to
+// MergeTakeIncoming.
+type MergeOptions struct {
+	Policy MergePolicy `json:"policy"`
+	// MergeDuplicates maps an incoming provider ID to the local provider it
+	// should be merged into, for duplicates reported by a preview
+	MergeDuplicates map[string]string `json:"mergeDuplicates,omitempty"`
+}
+
+// ImportOptions configures an import through the dialog-free API. Name is
+// the source file name, which tells YAML from JSON; Passphrase decrypts an
+// encrypted backup; Merge configures merge mode.
+type ImportOptions struct {
+	Name       string       `json:"name,omitempty"`
+	Passphrase string       `json:"passphrase,omitempty"`
+	Merge      MergeOptions `json:"merge"`
+}
+
+// DuplicateProvider is an incoming provider with a new ID but the same
+// endpoint as a local one
+type DuplicateProvider struct {
+	IncomingID   string `json:"incomingId"`
+	IncomingName string `json:"incomingName"`
+	LocalID      string `json:"localId"`
+	LocalName    string `json:"localName"`
+	Endpoint     string `json:"endpoint"`
+}
+
+// ImportIssueAction is what an import did about an invalid item
+type ImportIssueAction string
+
+const (
+	ImportIssueFixed       ImportIssueAction = "fixed"       // The item was corrected and imported
+	ImportIssueQuarantined ImportIssueAction = "quarantined" // The item was left out and saved to the quarantine file
+)
+
+// ImportIssue is a problem found in one imported provider or model. ModelID
+// is empty for provider-level issues.
+type ImportIssue struct {
+	ProviderID string            `json:"providerId"`
+	ModelID    string            `json:"modelId,omitempty"`
+	Field      string            `json:"field,omitempty"`
+	Message    string            `json:"message"`
+	Action     ImportIssueAction `json:"action"`
+}
+
+// SchemaError is a place where a file does not match the JSON Schema of the
+// exchange format. Pointer is a JSON pointer (RFC 6901) to the value; it is
+// empty for the whole document.
+type SchemaError struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// FieldChange is one changed field. Nested fields use dotted paths such as
+// "endpoints.openai" or "pricing.input".
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ModelDiff describes the effect of an import on one model
+type ModelDiff struct {
+	ModelID string        `json:"modelId"`
+	Name    string        `json:"name"`
+	Action  DiffAction    `json:"action"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// ProviderDiff describes the effect of an import on one provider. Key counts
+// are reported instead of key contents.
+type ProviderDiff struct {
+	ProviderID string        `json:"providerId"`
+	Name       string        `json:"name"`
+	Action     DiffAction    `json:"action"`
+	Fields     []FieldChange `json:"fields,omitempty"`
+	Models     []ModelDiff   `json:"models,omitempty"`
+	KeysBefore int           `json:"keysBefore"`
+	KeysAfter  int           `json:"keysAfter"`
+}
+
+// ImportSummary counts the changes in an import preview
+type ImportSummary struct {
+	ProvidersAdded   int `json:"providersAdded"`
+	ProvidersChanged int `json:"providersChanged"`
+	ProvidersRemoved int `json:"providersRemoved"`
+	ModelsAdded      int `json:"modelsAdded"`
+	ModelsChanged    int `json:"modelsChanged"`
+	ModelsRemoved    int `json:"modelsRemoved"`
+}
+
+// ImportPreview lists what an import would change without applying it.
+// Token identifies the preview when committing.
+type ImportPreview struct {
+	Token                    string              `json:"token"`
+	Mode                     ImportMode          `json:"mode"`
+	Format                   ImportFormat        `json:"format,omitempty"`
+	Message                  string              `json:"message"`
+	Warnings                 []string            `json:"warnings"`
+	PassphraseRequired       bool                `json:"passphraseRequired"`
+	Encrypted                bool                `json:"encrypted"`
+	ContainsPlaintextSecrets bool                `json:"containsPlaintextSecrets"`
+	Policy                   MergePolicy         `json:"policy,omitempty"`
+	Providers                []ProviderDiff      `json:"providers"`
+	Duplicates               []DuplicateProvider `json:"duplicates"`
+	Issues                   []ImportIssue       `json:"issues"`
+	SchemaErrors             []SchemaError       `json:"schemaErrors,omitempty"` // Where the file does not match the format
+	Summary                  ImportSummary       `json:"summary"`
+	ExpiresAt                string              `json:"expiresAt,omitempty"`
+}
+
+// ImportSelection picks the changes of a preview to apply. Providers lists
+// the provider IDs whose changes are applied. Models optionally narrows a
+// provider to some model IDs; a provider without an entry gets all of its
+// model changes.
+type ImportSelection struct {
+	Providers []string            `json:"providers"`
+	Models    map[string][]string `json:"models,omitempty"`
+}
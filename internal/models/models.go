@@ -3,6 +3,9 @@ package models
 // ============================================
 // LLM Desk Enterprise Data Types
 // Mirrors TypeScript types from src/types/index.ts
+// schema tags feed the published JSON Schema of the
+// exchange format (required, enum=a|b, or enum for
+// values registered from the constants)
 // ============================================
 
 // Pricing represents cost per million tokens
@@ -15,9 +18,9 @@ type Pricing struct {
 
 // Limit represents rate limiting configuration
 type Limit struct {
-	Type   string `json:"type"` // "requests" or "tokens"
-	Limit  int    `json:"limit"`
-	Window int    `json:"window"`
+	Type   string `json:"type" schema:"required,enum=requests|tokens"`
+	Limit  int    `json:"limit" schema:"required"`
+	Window int    `json:"window" schema:"required"`
 }
 
 // Context represents the context window configuration
@@ -50,7 +53,7 @@ type Endpoints struct {
 
 // Credentials represents API credentials
 type Credentials struct {
-	APIKeys []string `json:"apiKeys"`
+	APIKeys []APIKey `json:"apiKeys"`
 }
 
 // Model represents an LLM model configuration
@@ -76,30 +79,58 @@ type Provider struct {
 	Limits      []Limit          `json:"limits"`
 	Features    ProviderFeatures `json:"features"`
 	Models      []Model          `json:"models"`
+	Protocol    ProviderProtocol `json:"protocol,omitempty" schema:"enum"` // Empty means detect
 	IsCustom    bool             `json:"isCustom,omitempty"`
+	Revision    int64            `json:"revision,omitempty"`  // Bumped by storage on every change
+	UpdatedAt   string           `json:"updatedAt,omitempty"` // Set by storage on every change
 }
 
+// ProviderProtocol is the API a provider speaks for listing its models
+type ProviderProtocol string
+
+const (
+	ProtocolOpenAI    ProviderProtocol = "openai"    // Bearer token, GET /models
+	ProtocolAnthropic ProviderProtocol = "anthropic" // x-api-key header, GET /models
+	ProtocolGemini    ProviderProtocol = "gemini"    // ?key= parameter, GET /models with page tokens
+	ProtocolOllama    ProviderProtocol = "ollama"    // No key, GET /api/tags
+	ProtocolAzure     ProviderProtocol = "azure"     // api-key header, GET /openai/deployments
+	ProtocolCohere    ProviderProtocol = "cohere"    // Bearer token, GET /models with page tokens
+)
+
 // Metadata represents export/import metadata
 type Metadata struct {
-	CreatedAt   string  `json:"createdAt"`
-	ModifiedAt  string  `json:"modifiedAt"`
-	Generator   string  `json:"generator"`
-	Description *string `json:"description,omitempty"`
+	CreatedAt   string        `json:"createdAt"`
+	ModifiedAt  string        `json:"modifiedAt"`
+	Generator   string        `json:"generator"`
+	Description *string       `json:"description,omitempty"`
+	Secrets     ExportSecrets `json:"secrets,omitempty" schema:"enum"` // How API keys were exported
 }
 
 // LLMDeskData represents the unified data structure for import/export
 type LLMDeskData struct {
 	Version   string     `json:"version"`
 	Metadata  Metadata   `json:"metadata"`
-	Providers []Provider `json:"providers"`
+	Providers []Provider `json:"providers" schema:"required"`
 }
 
-// FetchedModel represents a model from an API response
+// FetchedModel represents a model from an API response. ID through OwnedBy
+// keep the field names of the OpenAI model object, which the frontend has
+// always read, hence owned_by. The fields after it are gathered from
+// provider-specific metadata, use the camelCase names of the rest of the app
+// and stay empty when the API does not report them.
 type FetchedModel struct {
-	ID      string `json:"id"`
-	Object  string `json:"object,omitempty"`
-	Created int64  `json:"created,omitempty"`
-	OwnedBy string `json:"owned_by,omitempty"`
+	ID              string         `json:"id"`
+	Object          string         `json:"object,omitempty"`
+	Created         int64          `json:"created,omitempty"`
+	OwnedBy         string         `json:"owned_by,omitempty"`
+	Parameters      string         `json:"parameters,omitempty"` // Parameter count, such as "8B"
+	DisplayName     string         `json:"displayName,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	ContextLength   int            `json:"contextLength,omitempty"`
+	MaxOutputTokens *int           `json:"maxOutputTokens,omitempty"`
+	Pricing         *Pricing       `json:"pricing,omitempty"` // Per million tokens, like Model.Pricing
+	Modalities      []string       `json:"modalities,omitempty"`
+	Features        *ModelFeatures `json:"features,omitempty"`
 }
 
 // ImportMode defines how to handle data import
@@ -110,12 +141,44 @@ const (
 	ImportModeMerge   ImportMode = "merge"
 )
 
+// ExportSecrets controls how API keys are written to an export
+type ExportSecrets string
+
+const (
+	ExportSecretsInclude ExportSecrets = "include" // Plaintext keys in the file
+	ExportSecretsExclude ExportSecrets = "exclude" // Key records without secrets
+	ExportSecretsEncrypt ExportSecrets = "encrypt" // Keys included, whole file encrypted
+)
+
+// ExportOptions configures an export. Secrets defaults to ExportSecretsExclude.
+type ExportOptions struct {
+	Secrets    ExportSecrets `json:"secrets"`
+	Passphrase string        `json:"passphrase,omitempty"` // Required with ExportSecretsEncrypt
+}
+
+// ExportResult represents the result of an export operation
+type ExportResult struct {
+	Success                  bool     `json:"success"`
+	Cancelled                bool     `json:"cancelled"`
+	Message                  string   `json:"message"`
+	Warnings                 []string `json:"warnings"`
+	Encrypted                bool     `json:"encrypted"`
+	ContainsPlaintextSecrets bool     `json:"containsPlaintextSecrets"` // The file on disk holds readable API keys
+}
+
 // ImportResult represents the result of an import operation
 type ImportResult struct {
-	Success  bool     `json:"success"`
-	Message  string   `json:"message"`
-	Warnings []string `json:"warnings"`
-	Imported struct {
+	Success                  bool          `json:"success"`
+	Message                  string        `json:"message"`
+	Warnings                 []string      `json:"warnings"`
+	PassphraseRequired       bool          `json:"passphraseRequired"` // The file is encrypted; retry with a passphrase
+	Encrypted                bool          `json:"encrypted"`
+	Format                   ImportFormat  `json:"format,omitempty"`         // Kind of file that was imported
+	ContainsPlaintextSecrets bool          `json:"containsPlaintextSecrets"` // The imported file holds readable API keys
+	Issues                   []ImportIssue `json:"issues,omitempty"`         // Items that were fixed or left out
+	QuarantinePath           string        `json:"quarantinePath,omitempty"` // File holding the items left out
+	SchemaErrors             []SchemaError `json:"schemaErrors,omitempty"`   // Where the file does not match the format
+	Imported                 struct {
 		Providers int `json:"providers"`
 		Models    int `json:"models"`
 	} `json:"imported"`
@@ -123,6 +186,44 @@ type ImportResult struct {
 
 // FetchModelsResult represents the result of fetching models from an API
 type FetchModelsResult struct {
-	Models []FetchedModel `json:"models"`
-	Error  string         `json:"error,omitempty"`
+	Models     []FetchedModel `json:"models"`
+	Error      string         `json:"error,omitempty"`      // What went wrong and what to fix
+	ErrorKind  FetchErrorKind `json:"errorKind,omitempty"`  // Why the fetch failed
+	RetryAfter int            `json:"retryAfter,omitempty"` // Seconds to wait when rate limited, if the provider said
+	Attempts   []FetchAttempt `json:"attempts,omitempty"`   // Each endpoint tried, in order
+	Warning    string         `json:"warning,omitempty"`
+	Pages      int            `json:"pages,omitempty"`     // Pages read from the API
+	Truncated  bool           `json:"truncated,omitempty"` // Stopped at the page or model cap with more models left
+}
+
+// FetchErrorKind classifies why fetching models failed
+type FetchErrorKind string
+
+const (
+	FetchErrorAuth        FetchErrorKind = "auth"         // HTTP 401: missing or wrong API key
+	FetchErrorForbidden   FetchErrorKind = "forbidden"    // HTTP 403: the key may not list models
+	FetchErrorNotFound    FetchErrorKind = "not_found"    // HTTP 404: wrong base path
+	FetchErrorRateLimited FetchErrorKind = "rate_limited" // HTTP 429
+	FetchErrorHTTP        FetchErrorKind = "http"         // Any other unexpected status
+	FetchErrorTLS         FetchErrorKind = "tls"          // Certificate or handshake failure
+	FetchErrorDNS         FetchErrorKind = "dns"          // The host name does not resolve
+	FetchErrorTimeout     FetchErrorKind = "timeout"      // No response in time
+	FetchErrorNetwork     FetchErrorKind = "network"      // Connection refused, reset or similar
+	FetchErrorNotJSON     FetchErrorKind = "not_json"     // A 200 response that is not JSON
+	FetchErrorNoModels    FetchErrorKind = "no_models"    // JSON without any models in it
+	FetchErrorInvalidURL  FetchErrorKind = "invalid_url"  // The base URL cannot be requested
+	FetchErrorProtocol    FetchErrorKind = "protocol"     // Unknown provider protocol
+)
+
+// FetchAttempt records one endpoint tried while fetching models. Secrets
+// are redacted from the endpoint and the body excerpt.
+type FetchAttempt struct {
+	Protocol    ProviderProtocol `json:"protocol"`
+	Endpoint    string           `json:"endpoint"`
+	Status      int              `json:"status,omitempty"` // HTTP status, if a response arrived
+	ErrorKind   FetchErrorKind   `json:"errorKind,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	RetryAfter  int              `json:"retryAfter,omitempty"`
+	BodyExcerpt string           `json:"bodyExcerpt,omitempty"` // Start of the response body
+	Models      int              `json:"models,omitempty"`      // Models found, on success
 }
@@ -12,7 +12,7 @@ func TestJSONMarshaling(t *testing.T) {
 		Name:    "Test Provider",
 		Enabled: true,
 		Credentials: Credentials{
-			APIKeys: []string{"key1"},
+			APIKeys: NewAPIKeys("key1"),
 		},
 		Endpoints: Endpoints{
 			OpenAI: "https://api.openai.com",
@@ -35,7 +35,7 @@ func TestJSONMarshaling(t *testing.T) {
 	if p2.Name != p.Name {
 		t.Errorf("Expected Name %s, got %s", p.Name, p2.Name)
 	}
-	if len(p2.Credentials.APIKeys) != 1 || p2.Credentials.APIKeys[0] != "key1" {
+	if len(p2.Credentials.APIKeys) != 1 || p2.Credentials.APIKeys[0].Key != "key1" {
 		t.Errorf("Expected API key 'key1', got %v", p2.Credentials.APIKeys)
 	}
 }
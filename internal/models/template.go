@@ -0,0 +1,48 @@
+package models
+
+// TemplateKind marks a provider template document
+const TemplateKind = "llm-desk-template"
+
+// TemplateVersion is the current provider template format version
+const TemplateVersion = "1"
+
+// ProviderTemplate is a provider setup that can be shared: endpoints,
+// features, limits and models, without credentials or local identity
+type ProviderTemplate struct {
+	Name      string           `json:"name"`
+	Endpoints Endpoints        `json:"endpoints"`
+	Protocol  ProviderProtocol `json:"protocol,omitempty"`
+	Limits    []Limit          `json:"limits,omitempty"`
+	Features  ProviderFeatures `json:"features"`
+	Models    []Model          `json:"models"`
+}
+
+// TemplateBundle is the content of a template file or share string
+type TemplateBundle struct {
+	Kind      string             `json:"kind"`
+	Version   string             `json:"version"`
+	CreatedAt string             `json:"createdAt"`
+	Templates []ProviderTemplate `json:"templates"`
+}
+
+// TemplateExportResult represents the result of sharing provider templates
+type TemplateExportResult struct {
+	Success     bool     `json:"success"`
+	Cancelled   bool     `json:"cancelled"`
+	Message     string   `json:"message"`
+	Path        string   `json:"path,omitempty"`
+	ShareString string   `json:"shareString,omitempty"`
+	Providers   int      `json:"providers"`
+	Models      int      `json:"models"`
+	Warnings    []string `json:"warnings"`
+}
+
+// TemplateResult represents the result of creating providers from a template
+type TemplateResult struct {
+	Success   bool       `json:"success"`
+	Cancelled bool       `json:"cancelled"`
+	Message   string     `json:"message"`
+	Warnings  []string   `json:"warnings"`
+	Providers []Provider `json:"providers"` // Created providers
+	NeedsKeys []string   `json:"needsKeys"` // IDs of created providers to prompt for API keys
+}
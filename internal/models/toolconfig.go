@@ -0,0 +1,43 @@
+package models
+
+// ToolConfigFormat is a downstream tool whose configuration file LLM Desk
+// can generate from the catalog
+type ToolConfigFormat string
+
+const (
+	ToolConfigLiteLLM   ToolConfigFormat = "litellm"   // LiteLLM proxy config.yaml
+	ToolConfigContinue  ToolConfigFormat = "continue"  // Continue config.yaml
+	ToolConfigAider     ToolConfigFormat = "aider"     // Aider .aider.model.metadata.json
+	ToolConfigOpenWebUI ToolConfigFormat = "openwebui" // Open WebUI environment file
+	ToolConfigCline     ToolConfigFormat = "cline"     // Cline API configuration profiles
+)
+
+// ToolConfigKeys decides how API keys appear in a generated config
+type ToolConfigKeys string
+
+const (
+	ToolConfigKeysEnv    ToolConfigKeys = "env"    // References to environment variables
+	ToolConfigKeysInline ToolConfigKeys = "inline" // The first enabled key of each provider in plaintext
+)
+
+// ToolConfigOptions configures config generation. Providers lists provider
+// IDs; empty means every enabled provider. Keys defaults to
+// ToolConfigKeysEnv.
+type ToolConfigOptions struct {
+	Format    ToolConfigFormat `json:"format"`
+	Providers []string         `json:"providers,omitempty"`
+	Keys      ToolConfigKeys   `json:"keys"`
+}
+
+// ToolConfigResult represents the result of generating a tool config
+type ToolConfigResult struct {
+	Success                  bool     `json:"success"`
+	Cancelled                bool     `json:"cancelled"`
+	Message                  string   `json:"message"`
+	Warnings                 []string `json:"warnings"`
+	Path                     string   `json:"path,omitempty"`
+	Providers                int      `json:"providers"`
+	Models                   int      `json:"models"`
+	EnvVars                  []string `json:"envVars"`                  // Environment variables the config reads keys from
+	ContainsPlaintextSecrets bool     `json:"containsPlaintextSecrets"` // The file holds readable API keys
+}
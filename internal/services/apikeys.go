@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// UpdateCredentials replaces the provider's keys with the given secrets.
+// Secrets that were already configured keep their record and metadata.
+func (s *ProviderService) UpdateCredentials(id string, keys []string, revision int64) error {
+	return s.updateProvider("updateCredentials", id, revision, func(p *models.Provider) error {
+		records := make([]models.APIKey, 0, len(keys))
+		for _, secret := range keys {
+			secret = strings.TrimSpace(secret)
+			if secret == "" {
+				continue
+			}
+			if existing := findKeyBySecret(p.Credentials.APIKeys, secret); existing != nil {
+				kept := *existing
+				kept.Key = secret
+				records = append(records, kept)
+				continue
+			}
+			record := models.NewAPIKey(secret)
+			record.Label = fmt.Sprintf("Key %d", len(records)+1)
+			records = append(records, record)
+		}
+		p.Credentials.APIKeys = records
+		return nil
+	})
+}
+
+// AddAPIKey adds a key record to a provider. The secret is required; the ID
+// and creation time are assigned here and new keys start enabled.
+func (s *ProviderService) AddAPIKey(providerID string, key models.APIKey, revision int64) (*models.APIKey, error) {
+	secret := strings.TrimSpace(key.Key)
+	if secret == "" {
+		return nil, fmt.Errorf("API key cannot be empty")
+	}
+	if validation := ValidateAPIKey(&key); !validation.Valid {
+		return nil, validation.ToError()
+	}
+
+	record := models.NewAPIKey(secret)
+	record.Label = strings.TrimSpace(key.Label)
+	record.ExpiresAt = key.ExpiresAt
+	record.Models = key.Models
+
+	err := s.updateProvider("addAPIKey", providerID, revision, func(p *models.Provider) error {
+		if findKeyBySecret(p.Credentials.APIKeys, secret) != nil {
+			return fmt.Errorf("this API key is already configured for the provider")
+		}
+		if record.Label == "" {
+			record.Label = fmt.Sprintf("Key %d", len(p.Credentials.APIKeys)+1)
+		}
+		p.Credentials.APIKeys = append(p.Credentials.APIKeys, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// UpdateAPIKey updates the label, expiry, enabled flag and model scope of a
+// key. A non-empty Key in updates rotates the secret.
+func (s *ProviderService) UpdateAPIKey(providerID, keyID string, updates models.APIKey, revision int64) error {
+	if validation := ValidateAPIKey(&updates); !validation.Valid {
+		return validation.ToError()
+	}
+
+	return s.updateProvider("updateAPIKey", providerID, revision, func(p *models.Provider) error {
+		k := p.Credentials.FindKey(keyID)
+		if k == nil {
+			return fmt.Errorf("API key not found: %s", keyID)
+		}
+		k.Label = strings.TrimSpace(updates.Label)
+		k.ExpiresAt = updates.ExpiresAt
+		k.Enabled = updates.Enabled
+		k.Models = updates.Models
+		if secret := strings.TrimSpace(updates.Key); secret != "" && secret != k.Key {
+			k.SetSecret(secret)
+			k.Status = models.KeyStatusUnknown
+			k.LastVerifiedAt = nil
+		}
+		return nil
+	})
+}
+
+// SetAPIKeyEnabled enables or disables a key without removing it
+func (s *ProviderService) SetAPIKeyEnabled(providerID, keyID string, enabled bool, revision int64) error {
+	return s.updateProvider("setAPIKeyEnabled", providerID, revision, func(p *models.Provider) error {
+		k := p.Credentials.FindKey(keyID)
+		if k == nil {
+			return fmt.Errorf("API key not found: %s", keyID)
+		}
+		k.Enabled = enabled
+		return nil
+	})
+}
+
+// SetAPIKeyStatus records the outcome of verifying a key with its provider
+func (s *ProviderService) SetAPIKeyStatus(providerID, keyID, status string, revision int64) error {
+	switch status {
+	case models.KeyStatusUnknown, models.KeyStatusValid, models.KeyStatusInvalid:
+	default:
+		return fmt.Errorf("invalid key status: %s", status)
+	}
+
+	return s.updateProvider("setAPIKeyStatus", providerID, revision, func(p *models.Provider) error {
+		k := p.Credentials.FindKey(keyID)
+		if k == nil {
+			return fmt.Errorf("API key not found: %s", keyID)
+		}
+		now := time.Now().Format(time.RFC3339)
+		k.Status = status
+		k.LastVerifiedAt = &now
+		return nil
+	})
+}
+
+// RemoveAPIKey removes a key record and its stored secret
+func (s *ProviderService) RemoveAPIKey(providerID, keyID string, revision int64) error {
+	return s.updateProvider("removeAPIKey", providerID, revision, func(p *models.Provider) error {
+		kept := make([]models.APIKey, 0, len(p.Credentials.APIKeys))
+		for _, k := range p.Credentials.APIKeys {
+			if k.ID != keyID {
+				kept = append(kept, k)
+			}
+		}
+		if len(kept) == len(p.Credentials.APIKeys) {
+			return fmt.Errorf("API key not found: %s", keyID)
+		}
+		p.Credentials.APIKeys = kept
+		return nil
+	})
+}
+
+// keepKeyRecords carries the metadata of existing records over to incoming
+// records that were rebuilt from a bare secret, such as keys sent by an older
+// client as plain strings
+func keepKeyRecords(existing, incoming []models.APIKey) []models.APIKey {
+	byID := make(map[string]bool, len(existing))
+	for _, k := range existing {
+		byID[k.ID] = true
+	}
+
+	for i, k := range incoming {
+		if k.Key == "" || byID[k.ID] {
+			continue
+		}
+		if match := findKeyBySecret(existing, k.Key); match != nil {
+			kept := *match
+			kept.Key = k.Key
+			incoming[i] = kept
+		}
+	}
+	return incoming
+}
+
+// findKeyBySecret returns the record holding secret. Records whose secret is
+// not loaded are matched by fingerprint.
+func findKeyBySecret(keys []models.APIKey, secret string) *models.APIKey {
+	fingerprint := models.KeyFingerprint(secret)
+	for i := range keys {
+		if keys[i].Key == secret || (keys[i].Key == "" && keys[i].Fingerprint == fingerprint) {
+			return &keys[i]
+		}
+	}
+	return nil
+}
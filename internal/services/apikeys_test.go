@@ -0,0 +1,173 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+// createKeyTestProvider creates an empty provider for key record tests
+func createKeyTestProvider(t *testing.T, service *ProviderService) string {
+	t.Helper()
+
+	created, err := service.CreateProvider(models.Provider{
+		Name:      "Key Provider",
+		Enabled:   true,
+		Endpoints: models.Endpoints{OpenAI: "https://api.example.com/v1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+	return created.ID
+}
+
+func TestProviderService_AddAPIKey(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+	id := createKeyTestProvider(t, service)
+
+	added, err := service.AddAPIKey(id, models.APIKey{Key: "sk-project-a-0001", Label: "Project A"}, 0)
+	if err != nil {
+		t.Fatalf("AddAPIKey failed: %v", err)
+	}
+	if added.ID == "" || !added.Enabled {
+		t.Errorf("Expected an enabled record with an ID, got %+v", added)
+	}
+
+	if _, err := service.AddAPIKey(id, models.APIKey{Key: "sk-project-a-0001"}, 0); err == nil {
+		t.Error("Expected duplicate key to be rejected")
+	}
+	if _, err := service.AddAPIKey(id, models.APIKey{Label: "empty"}, 0); err == nil {
+		t.Error("Expected empty key to be rejected")
+	}
+
+	p, err := service.GetProvider(id)
+	if err != nil {
+		t.Fatalf("GetProvider failed: %v", err)
+	}
+	if len(p.Credentials.APIKeys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(p.Credentials.APIKeys))
+	}
+	k := p.Credentials.APIKeys[0]
+	if k.Label != "Project A" || k.Key != "sk-project-a-0001" {
+		t.Errorf("Expected label and secret to round-trip, got %+v", k)
+	}
+}
+
+func TestProviderService_UpdateAndDisableAPIKey(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+	id := createKeyTestProvider(t, service)
+
+	added, err := service.AddAPIKey(id, models.APIKey{Key: "sk-old-secret-1234"}, 0)
+	if err != nil {
+		t.Fatalf("AddAPIKey failed: %v", err)
+	}
+
+	bad := "next week"
+	if err := service.UpdateAPIKey(id, added.ID, models.APIKey{ExpiresAt: &bad}, 0); err == nil {
+		t.Error("Expected invalid expiry to be rejected")
+	}
+
+	expires := "2030-01-01T00:00:00Z"
+	err = service.UpdateAPIKey(id, added.ID, models.APIKey{
+		Label:     "Teammate",
+		Key:       "sk-new-secret-5678",
+		ExpiresAt: &expires,
+		Enabled:   true,
+		Models:    []string{"gpt-4o"},
+	}, 0)
+	if err != nil {
+		t.Fatalf("UpdateAPIKey failed: %v", err)
+	}
+
+	if err := service.SetAPIKeyEnabled(id, added.ID, false, 0); err != nil {
+		t.Fatalf("SetAPIKeyEnabled failed: %v", err)
+	}
+
+	p, _ := service.GetProvider(id)
+	k := p.Credentials.FindKey(added.ID)
+	if k == nil {
+		t.Fatal("Expected key record to keep its ID after rotation")
+	}
+	if k.Key != "sk-new-secret-5678" || k.Hint != "5678" {
+		t.Errorf("Expected rotated secret, got key=%q hint=%q", k.Key, k.Hint)
+	}
+	if k.Label != "Teammate" || k.Enabled || !k.AllowsModel("gpt-4o") || k.AllowsModel("o1") {
+		t.Errorf("Unexpected record after update: %+v", k)
+	}
+	if len(p.Credentials.UsableSecrets()) != 0 {
+		t.Error("Expected disabled key to be excluded from usable secrets")
+	}
+}
+
+func TestProviderService_RemoveAPIKey(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+	id := createKeyTestProvider(t, service)
+
+	first, _ := service.AddAPIKey(id, models.APIKey{Key: "sk-first-000000001"}, 0)
+	second, _ := service.AddAPIKey(id, models.APIKey{Key: "sk-second-00000002"}, 0)
+
+	if err := service.RemoveAPIKey(id, first.ID, 0); err != nil {
+		t.Fatalf("RemoveAPIKey failed: %v", err)
+	}
+	if err := service.RemoveAPIKey(id, first.ID, 0); err == nil {
+		t.Error("Expected error removing an unknown key")
+	}
+
+	p, _ := service.GetProvider(id)
+	if len(p.Credentials.APIKeys) != 1 || p.Credentials.APIKeys[0].ID != second.ID {
+		t.Fatalf("Expected only the second key to remain, got %+v", p.Credentials.APIKeys)
+	}
+	if p.Credentials.APIKeys[0].Key != "sk-second-00000002" {
+		t.Errorf("Expected remaining secret to be intact, got %q", p.Credentials.APIKeys[0].Key)
+	}
+}
+
+func TestProviderService_UpdateCredentials_KeepsRecords(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+	id := createKeyTestProvider(t, service)
+
+	added, _ := service.AddAPIKey(id, models.APIKey{Key: "sk-keep-000000001", Label: "Keep"}, 0)
+
+	if err := service.UpdateCredentials(id, []string{"sk-keep-000000001", "sk-added-00000002"}, 0); err != nil {
+		t.Fatalf("UpdateCredentials failed: %v", err)
+	}
+
+	p, _ := service.GetProvider(id)
+	if len(p.Credentials.APIKeys) != 2 {
+		t.Fatalf("Expected 2 keys, got %d", len(p.Credentials.APIKeys))
+	}
+	if kept := p.Credentials.FindKey(added.ID); kept == nil || kept.Label != "Keep" {
+		t.Errorf("Expected existing record to keep its metadata, got %+v", kept)
+	}
+}
+
+func TestProviderService_UpdateProvider_LegacyKeyStrings(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+	id := createKeyTestProvider(t, service)
+
+	added, _ := service.AddAPIKey(id, models.APIKey{Key: "sk-legacy-00000001", Label: "Labelled"}, 0)
+
+	// Older clients send keys as plain strings
+	var updates models.Provider
+	data := []byte(`{"name":"Key Provider","enabled":true,"endpoints":{"openai":"https://api.example.com/v1"},"credentials":{"apiKeys":["sk-legacy-00000001"]}}`)
+	if err := json.Unmarshal(data, &updates); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := service.UpdateProvider(id, updates); err != nil {
+		t.Fatalf("UpdateProvider failed: %v", err)
+	}
+
+	p, _ := service.GetProvider(id)
+	if len(p.Credentials.APIKeys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(p.Credentials.APIKeys))
+	}
+	if k := p.Credentials.APIKeys[0]; k.ID != added.ID || k.Label != "Labelled" {
+		t.Errorf("Expected record metadata to be kept, got %+v", k)
+	}
+}
@@ -0,0 +1,428 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// backupDebounce is how long the catalog must stay unchanged before an
+// on-change backup is written, so a burst of edits gives one backup
+const backupDebounce = 5 * time.Second
+
+// BackupStatus describes automatic backups for the UI
+type BackupStatus struct {
+	Enabled       bool                `json:"enabled"`
+	Directory     string              `json:"directory"`
+	PassphraseSet bool                `json:"passphraseSet"`
+	LastBackup    *storage.BackupFile `json:"lastBackup,omitempty"`
+	LastError     string              `json:"lastError,omitempty"`
+}
+
+// BackupVerification reports whether a backup can be restored
+type BackupVerification struct {
+	Name      string               `json:"name"`
+	Valid     bool                 `json:"valid"`
+	Encrypted bool                 `json:"encrypted"`
+	Version   string               `json:"version,omitempty"`
+	Providers int                  `json:"providers"`
+	Models    int                  `json:"models"`
+	Secrets   bool                 `json:"secrets"` // The backup holds API keys
+	Issues    []models.ImportIssue `json:"issues"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// BackupService writes automatic backups of the catalog after changes and on
+// a schedule, prunes them by the retention settings and restores them
+type BackupService struct {
+	storage  *storage.Storage
+	settings *SettingsService
+	export   *ExportService
+	debounce time.Duration
+	now      func() time.Time
+
+	writeMu sync.Mutex // Serializes writing and pruning backups
+
+	mu          sync.Mutex
+	lastError   string
+	lastAttempt time.Time // Also delays the schedule after a failed backup
+	changed     chan struct{}
+	reload      chan struct{}
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewBackupService creates a BackupService and subscribes it to catalog
+// changes. Nothing is written until Start is called or BackupNow is used.
+func NewBackupService(s *storage.Storage, settings *SettingsService, export *ExportService) *BackupService {
+	b := &BackupService{
+		storage:  s,
+		settings: settings,
+		export:   export,
+		debounce: backupDebounce,
+		now:      time.Now,
+		changed:  make(chan struct{}, 1),
+		reload:   make(chan struct{}, 1),
+	}
+	s.OnChange(b.notify)
+	return b
+}
+
+// Start begins writing backups in the background until Stop is called
+func (b *BackupService) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil {
+		return
+	}
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	go b.run(b.stop, b.done)
+}
+
+// Stop ends background backups and waits for a running one to finish. A
+// pending on-change backup is written first.
+func (b *BackupService) Stop() {
+	b.mu.Lock()
+	stop, done := b.stop, b.done
+	b.stop, b.done = nil, nil
+	b.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// notify is the storage change hook. It must not block.
+func (b *BackupService) notify(op string) {
+	select {
+	case b.changed <- struct{}{}:
+	default:
+	}
+}
+
+// signal wakes the background loop so it picks up new settings
+func (b *BackupService) signal() {
+	select {
+	case b.reload <- struct{}{}:
+	default:
+	}
+}
+
+// run is the background loop. On-change backups wait for the catalog to
+// settle; scheduled backups run once the newest backup is older than the
+// interval.
+func (b *BackupService) run(stop, done chan struct{}) {
+	defer close(done)
+	var pending *time.Timer
+	var pendingC <-chan time.Time
+
+	for {
+		var scheduled *time.Timer
+		var scheduledC <-chan time.Time
+		if wait, ok := b.untilScheduled(); ok {
+			scheduled = time.NewTimer(wait)
+			scheduledC = scheduled.C
+		}
+
+		select {
+		case <-stop:
+			if pending != nil && pending.Stop() {
+				b.backupLogged("change")
+			}
+			if scheduled != nil {
+				scheduled.Stop()
+			}
+			return
+		case <-b.changed:
+			if cfg := b.settings.GetBackupSettings(); cfg.Enabled && cfg.OnChange {
+				if pending == nil {
+					pending = time.NewTimer(b.debounce)
+				} else {
+					pending.Reset(b.debounce)
+				}
+				pendingC = pending.C
+			}
+		case <-pendingC:
+			pending, pendingC = nil, nil
+			b.backupLogged("change")
+		case <-scheduledC:
+			b.backupLogged("schedule")
+		case <-b.reload:
+		}
+		if scheduled != nil {
+			scheduled.Stop()
+		}
+	}
+}
+
+// untilScheduled returns how long until the next scheduled backup is due,
+// or false if no schedule is configured
+func (b *BackupService) untilScheduled() (time.Duration, bool) {
+	cfg := b.settings.GetBackupSettings()
+	if !cfg.Enabled || cfg.IntervalMinutes <= 0 {
+		return 0, false
+	}
+	b.mu.Lock()
+	last := b.lastAttempt
+	b.mu.Unlock()
+	if backups, err := b.storage.ListBackups(b.storage.BackupDir(cfg)); err == nil && len(backups) > 0 && backups[0].Created().After(last) {
+		last = backups[0].Created()
+	}
+	return nextScheduledBackup(last, time.Duration(cfg.IntervalMinutes)*time.Minute, b.now()), true
+}
+
+// nextScheduledBackup returns the wait until interval has passed since last.
+// Without a previous backup one is due immediately.
+func nextScheduledBackup(last time.Time, interval time.Duration, now time.Time) time.Duration {
+	if last.IsZero() {
+		return 0
+	}
+	if wait := last.Add(interval).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// backupLogged writes a backup from the background loop, logging failures
+func (b *BackupService) backupLogged(reason string) {
+	if !b.settings.GetBackupSettings().Enabled {
+		return
+	}
+	backup, err := b.BackupNow()
+	if err != nil {
+		logger.Error("Automatic backup failed", "reason", reason, "error", err)
+		return
+	}
+	logger.Info("Automatic backup written", "reason", reason, "file", backup.Name)
+}
+
+// Configure saves new backup settings. A non-empty passphrase replaces the
+// stored backup passphrase; one must be stored to include API keys.
+func (b *BackupService) Configure(cfg storage.BackupSettings, passphrase string) error {
+	if passphrase != "" {
+		if err := b.storage.SetBackupPassphrase(passphrase); err != nil {
+			return fmt.Errorf("failed to store backup passphrase: %w", err)
+		}
+	}
+	if cfg.IncludeSecrets {
+		stored, err := b.storage.BackupPassphrase()
+		if err != nil {
+			return fmt.Errorf("failed to read backup passphrase: %w", err)
+		}
+		if stored == "" {
+			return fmt.Errorf("a backup passphrase is required to include API keys")
+		}
+	}
+	if err := b.settings.SetBackupSettings(cfg); err != nil {
+		return err
+	}
+	b.signal()
+	return nil
+}
+
+// Status returns the backup settings in effect and the latest backup
+func (b *BackupService) Status() BackupStatus {
+	cfg := b.settings.GetBackupSettings()
+	status := BackupStatus{Enabled: cfg.Enabled, Directory: b.storage.BackupDir(cfg)}
+	if passphrase, err := b.storage.BackupPassphrase(); err == nil {
+		status.PassphraseSet = passphrase != ""
+	}
+	if backups, err := b.storage.ListBackups(status.Directory); err == nil && len(backups) > 0 {
+		status.LastBackup = &backups[0]
+	}
+	b.mu.Lock()
+	status.LastError = b.lastError
+	b.mu.Unlock()
+	return status
+}
+
+// BackupNow writes a backup of the catalog and prunes old backups. API keys
+// are included, encrypted with the backup passphrase, only if the settings
+// ask for it.
+func (b *BackupService) BackupNow() (storage.BackupFile, error) {
+	return b.backup(true)
+}
+
+// backup writes a backup, pruning old ones if prune is set, and records
+// the outcome for Status
+func (b *BackupService) backup(prune bool) (storage.BackupFile, error) {
+	backup, err := b.writeBackup(prune)
+	b.mu.Lock()
+	b.lastAttempt = b.now()
+	b.lastError = ""
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	b.mu.Unlock()
+	return backup, err
+}
+
+// writeBackup writes a backup and, if prune is set, applies the retention
+// settings
+func (b *BackupService) writeBackup(prune bool) (storage.BackupFile, error) {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+
+	cfg := b.settings.GetBackupSettings()
+	providers, err := b.storage.Load()
+	if err != nil {
+		return storage.BackupFile{}, err
+	}
+
+	secrets, passphrase := models.ExportSecretsExclude, ""
+	if cfg.IncludeSecrets {
+		if passphrase, err = b.storage.BackupPassphrase(); err != nil {
+			return storage.BackupFile{}, fmt.Errorf("failed to read backup passphrase: %w", err)
+		}
+		if passphrase == "" {
+			return storage.BackupFile{}, fmt.Errorf("a backup passphrase is required to include API keys")
+		}
+		secrets = models.ExportSecretsEncrypt
+	} else {
+		providers = withoutSecrets(providers)
+	}
+
+	dir := b.storage.BackupDir(cfg)
+	data := newExportData(providers, secrets, "LLM Desk automatic backup")
+	backup, err := b.storage.WriteBackup(dir, data, passphrase, b.now())
+	if err != nil {
+		return storage.BackupFile{}, err
+	}
+	if !prune {
+		return backup, nil
+	}
+
+	backups, err := b.storage.ListBackups(dir)
+	if err != nil {
+		logger.Warn("Failed to list backups for pruning", "error", err)
+		return backup, nil
+	}
+	for _, old := range backupsToPrune(backups, cfg, b.now()) {
+		if err := b.storage.RemoveBackup(old); err != nil {
+			logger.Warn("Failed to prune backup", "file", old.Name, "error", err)
+		}
+	}
+	return backup, nil
+}
+
+// backupsToPrune returns the backups, newest first, that no retention rule
+// keeps. The KeepLast newest are kept, plus the newest backup of each of the
+// last KeepDaily days and of each of the last KeepWeekly weeks, counted back
+// from today.
+func backupsToPrune(backups []storage.BackupFile, cfg storage.BackupSettings, now time.Time) []storage.BackupFile {
+	today := startOfDay(now)
+	days := make(map[int]bool)
+	weeks := make(map[int]bool)
+
+	var prune []storage.BackupFile
+	for i, backup := range backups {
+		age := int(math.Round(today.Sub(startOfDay(backup.Created().In(now.Location()))).Hours() / 24))
+		keep := i < cfg.KeepLast
+		if age < cfg.KeepDaily && !days[age] {
+			days[age] = true
+			keep = true
+		}
+		if week := age / 7; age >= 0 && week < cfg.KeepWeekly && !weeks[week] {
+			weeks[week] = true
+			keep = true
+		}
+		if !keep {
+			prune = append(prune, backup)
+		}
+	}
+	return prune
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// ListBackups returns the automatic backups, newest first
+func (b *BackupService) ListBackups() ([]storage.BackupFile, error) {
+	return b.storage.ListBackups(b.storage.BackupDir(b.settings.GetBackupSettings()))
+}
+
+// VerifyBackup reads and validates a backup without importing it. An empty
+// passphrase uses the stored backup passphrase.
+func (b *BackupService) VerifyBackup(name, passphrase string) (BackupVerification, error) {
+	result := BackupVerification{Name: name, Issues: []models.ImportIssue{}}
+	backup, err := b.storage.FindBackup(b.storage.BackupDir(b.settings.GetBackupSettings()), name)
+	if err != nil {
+		return result, err
+	}
+	result.Encrypted = backup.Encrypted
+
+	data, err := b.storage.ReadBackup(backup, b.passphraseFor(backup, passphrase))
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Version = data.Version
+	if _, err := checkImportVersion(data.Version); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	clean, _, issues := sanitizeImport(data.Providers)
+	result.Issues = append(result.Issues, issues...)
+	result.Providers = len(clean)
+	for _, p := range clean {
+		result.Models += len(p.Models)
+	}
+	result.Secrets = hasSecrets(data.Providers)
+	result.Valid = countQuarantined(issues) == 0
+	if !result.Valid {
+		result.Error = fmt.Sprintf("%d items failed validation", countQuarantined(issues))
+	}
+	return result, nil
+}
+
+// RestoreBackup replaces the catalog with a backup. The current catalog is
+// backed up first when automatic backups are enabled. An empty passphrase
+// uses the stored backup passphrase.
+func (b *BackupService) RestoreBackup(name, passphrase string) (models.ImportResult, error) {
+	cfg := b.settings.GetBackupSettings()
+	backup, err := b.storage.FindBackup(b.storage.BackupDir(cfg), name)
+	if err != nil {
+		return models.ImportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	passphrase = b.passphraseFor(backup, passphrase)
+	if backup.Encrypted && passphrase == "" {
+		return models.ImportResult{
+			Message:            "This backup is encrypted. Enter its passphrase to restore it.",
+			Warnings:           []string{},
+			PassphraseRequired: true,
+			Encrypted:          true,
+		}, nil
+	}
+
+	// Not pruned, so the backup being restored cannot be removed
+	if cfg.Enabled {
+		if _, err := b.backup(false); err != nil {
+			return models.ImportResult{Message: "Failed to back up the current catalog: " + err.Error(), Warnings: []string{}}, err
+		}
+	}
+	return b.export.importFile(backup.Path, string(models.ImportModeReplace), passphrase, models.MergeOptions{})
+}
+
+// passphraseFor returns passphrase, or the stored backup passphrase for an
+// encrypted backup when passphrase is empty
+func (b *BackupService) passphraseFor(backup storage.BackupFile, passphrase string) string {
+	if passphrase != "" || !backup.Encrypted {
+		return passphrase
+	}
+	stored, err := b.storage.BackupPassphrase()
+	if err != nil {
+		logger.Warn("Failed to read backup passphrase", "error", err)
+	}
+	return stored
+}
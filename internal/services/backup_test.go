@@ -0,0 +1,185 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// setupTestBackupService creates a backup service over the export test
+// catalog with backups enabled
+func setupTestBackupService(t *testing.T, cfg storage.BackupSettings, passphrase string) (*BackupService, *storage.Storage) {
+	t.Helper()
+	export, store, _ := setupTestExportService(t)
+	backups := NewBackupService(store, NewSettingsService(store), export)
+	cfg.Enabled = true
+	if err := backups.Configure(cfg, passphrase); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	return backups, store
+}
+
+func TestBackupsToPrune(t *testing.T) {
+	store, err := storage.NewWithDir(t.TempDir(), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+
+	// Two backups a day for 40 days
+	data := &models.LLMDeskData{Version: schemaVersion}
+	for day := 0; day < 40; day++ {
+		for _, hour := range []int{9, 15} {
+			at := time.Date(2026, 10, 16-day, hour, 0, 0, 0, time.UTC)
+			if _, err := store.WriteBackup(dir, data, "", at); err != nil {
+				t.Fatalf("WriteBackup failed: %v", err)
+			}
+		}
+	}
+	backups, _ := store.ListBackups(dir)
+
+	cfg := storage.BackupSettings{KeepLast: 3, KeepDaily: 7, KeepWeekly: 4}
+	pruned := make(map[string]bool)
+	for _, b := range backupsToPrune(backups, cfg, now) {
+		pruned[b.Name] = true
+	}
+
+	var kept []time.Time
+	for _, b := range backups {
+		if !pruned[b.Name] {
+			kept = append(kept, b.Created())
+		}
+	}
+	// Last 3, then the newest of each of 7 days, then of each of 4 weeks
+	expected := []time.Time{
+		time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 15, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 13, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 12, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 11, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 10, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 9, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 2, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 25, 15, 0, 0, 0, time.UTC),
+	}
+	if len(kept) != len(expected) {
+		t.Fatalf("Expected %d backups kept, got %d: %v", len(expected), len(kept), kept)
+	}
+	for i := range expected {
+		if !kept[i].Equal(expected[i]) {
+			t.Errorf("Kept backup %d: expected %v, got %v", i, expected[i], kept[i])
+		}
+	}
+}
+
+func TestNextScheduledBackup(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if wait := nextScheduledBackup(time.Time{}, time.Hour, now); wait != 0 {
+		t.Errorf("Expected a first backup to be due now, got %v", wait)
+	}
+	if wait := nextScheduledBackup(now.Add(-20*time.Minute), time.Hour, now); wait != 40*time.Minute {
+		t.Errorf("Expected 40m, got %v", wait)
+	}
+	if wait := nextScheduledBackup(now.Add(-2*time.Hour), time.Hour, now); wait != 0 {
+		t.Errorf("Expected an overdue backup to be due now, got %v", wait)
+	}
+}
+
+func TestBackupService_EncryptedBackupAndRestore(t *testing.T) {
+	cfg := storage.DefaultBackupSettings()
+	cfg.IncludeSecrets = true
+	backups, store := setupTestBackupService(t, cfg, "backup-pw")
+
+	backup, err := backups.BackupNow()
+	if err != nil {
+		t.Fatalf("BackupNow failed: %v", err)
+	}
+	if !backup.Encrypted {
+		t.Errorf("Expected a backup with keys to be encrypted, got %+v", backup)
+	}
+
+	verified, err := backups.VerifyBackup(backup.Name, "")
+	if err != nil || !verified.Valid || !verified.Secrets || verified.Providers != 1 || verified.Models != 1 {
+		t.Fatalf("Expected a valid backup with keys, got %+v (%v)", verified, err)
+	}
+	if verified, _ := backups.VerifyBackup(backup.Name, "wrong"); verified.Valid || verified.Error == "" {
+		t.Errorf("Expected a wrong passphrase to fail verification, got %+v", verified)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	result, err := backups.RestoreBackup(backup.Name, "")
+	if err != nil || !result.Success {
+		t.Fatalf("RestoreBackup failed: %+v (%v)", result, err)
+	}
+	providers, _ := store.Load()
+	if len(providers) != 1 || providers[0].Credentials.APIKeys[0].Key != "sk-export-secret" {
+		t.Errorf("Expected the catalog and its key restored, got %+v", providers)
+	}
+
+	// The restore backed up the cleared catalog first
+	if list, _ := backups.ListBackups(); len(list) != 2 {
+		t.Errorf("Expected a pre-restore backup, got %+v", list)
+	}
+}
+
+func TestBackupService_SecretsNeedPassphrase(t *testing.T) {
+	export, store, _ := setupTestExportService(t)
+	backups := NewBackupService(store, NewSettingsService(store), export)
+
+	cfg := storage.DefaultBackupSettings()
+	cfg.Enabled, cfg.IncludeSecrets = true, true
+	if err := backups.Configure(cfg, ""); err == nil {
+		t.Error("Expected keys without a backup passphrase to be rejected")
+	}
+	cfg.KeepLast = 0
+	if err := backups.Configure(cfg, "pw"); err == nil {
+		t.Error("Expected KeepLast 0 to be rejected")
+	}
+
+	// Without keys the backup is plain JSON and leaves keys out
+	cfg = storage.DefaultBackupSettings()
+	cfg.Enabled = true
+	if err := backups.Configure(cfg, ""); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	backup, err := backups.BackupNow()
+	if err != nil || backup.Encrypted {
+		t.Fatalf("Expected a plain backup, got %+v (%v)", backup, err)
+	}
+	if verified, _ := backups.VerifyBackup(backup.Name, ""); !verified.Valid || verified.Secrets {
+		t.Errorf("Expected a valid backup without keys, got %+v", verified)
+	}
+}
+
+func TestBackupService_BacksUpOnChange(t *testing.T) {
+	cfg := storage.DefaultBackupSettings()
+	cfg.IntervalMinutes = 0
+	backups, store := setupTestBackupService(t, cfg, "")
+	backups.debounce = 10 * time.Millisecond
+	backups.Start()
+	defer backups.Stop()
+
+	err := store.Update(func(providers *[]models.Provider) error {
+		(*providers)[0].Name = "OpenAI (renamed)"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if list, _ := backups.ListBackups(); len(list) == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected a backup after the catalog changed")
+}
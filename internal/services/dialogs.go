@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// FileDialogs shows native file dialogs. An empty path means the user
+// cancelled. The Wails runtime provides them in the app; tests and
+// automation can substitute their own with SetDialogs.
+type FileDialogs interface {
+	SaveFile(opts runtime.SaveDialogOptions) (string, error)
+	OpenFile(opts runtime.OpenDialogOptions) (string, error)
+}
+
+// runtimeDialogs shows dialogs through the Wails runtime
+type runtimeDialogs struct {
+	ctx context.Context
+}
+
+// SaveFile shows a save dialog
+func (d runtimeDialogs) SaveFile(opts runtime.SaveDialogOptions) (string, error) {
+	return runtime.SaveFileDialog(d.ctx, opts)
+}
+
+// OpenFile shows an open dialog
+func (d runtimeDialogs) OpenFile(opts runtime.OpenDialogOptions) (string, error) {
+	return runtime.OpenFileDialog(d.ctx, opts)
+}
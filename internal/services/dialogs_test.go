@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// fakeDialogs answers file dialogs with fixed paths and records their titles
+type fakeDialogs struct {
+	savePath string
+	openPath string
+	err      error
+	titles   []string
+}
+
+func (d *fakeDialogs) SaveFile(opts runtime.SaveDialogOptions) (string, error) {
+	d.titles = append(d.titles, opts.Title)
+	return d.savePath, d.err
+}
+
+func (d *fakeDialogs) OpenFile(opts runtime.OpenDialogOptions) (string, error) {
+	d.titles = append(d.titles, opts.Title)
+	return d.openPath, d.err
+}
+
+func TestExportService_Dialogs(t *testing.T) {
+	service, store, dir := setupTestExportService(t)
+	path := filepath.Join(dir, "backup.json")
+	dialogs := &fakeDialogs{savePath: path, openPath: path}
+	service.SetDialogs(dialogs)
+
+	exported, err := service.ExportData(models.ExportOptions{Secrets: models.ExportSecretsInclude})
+	if err != nil || !exported.Success {
+		t.Fatalf("ExportData failed: %+v (%v)", exported, err)
+	}
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	imported, err := service.ImportData("replace", models.MergeOptions{})
+	if err != nil || !imported.Success || imported.Imported.Providers != 1 {
+		t.Fatalf("ImportData failed: %+v (%v)", imported, err)
+	}
+	if len(dialogs.titles) != 2 || dialogs.titles[0] != "Export LLM Desk Data" {
+		t.Errorf("Expected a save and an open dialog, got %v", dialogs.titles)
+	}
+
+	// Cancelling either dialog writes and imports nothing
+	dialogs.savePath, dialogs.openPath = "", ""
+	if result, _ := service.ExportData(models.ExportOptions{}); !result.Cancelled {
+		t.Errorf("Expected a cancelled export, got %+v", result)
+	}
+	if result, _ := service.ImportData("merge", models.MergeOptions{}); result.Success || result.Message != "Import cancelled" {
+		t.Errorf("Expected a cancelled import, got %+v", result)
+	}
+
+	dialogs.err = errors.New("no display")
+	if _, err := service.ExportToolConfig(models.ToolConfigOptions{Format: models.ToolConfigLiteLLM}); err == nil {
+		t.Error("Expected the dialog error to be returned")
+	}
+}
+
+func TestExportService_StreamRoundTrip(t *testing.T) {
+	service, store, _ := setupTestExportService(t)
+
+	var buf bytes.Buffer
+	exported, err := service.Export(&buf, models.ExportOptions{Secrets: models.ExportSecretsEncrypt, Passphrase: "pw"})
+	if err != nil || !exported.Encrypted {
+		t.Fatalf("Export failed: %+v (%v)", exported, err)
+	}
+	content := buf.Bytes()
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	result, err := service.Import(bytes.NewReader(content), "replace", models.ImportOptions{})
+	if err == nil || !result.PassphraseRequired {
+		t.Errorf("Expected an encrypted stream to need a passphrase, got %+v (%v)", result, err)
+	}
+	result, err = service.Import(bytes.NewReader(content), "replace", models.ImportOptions{Passphrase: "pw"})
+	if err != nil || !result.Success || !result.Encrypted {
+		t.Fatalf("Import failed: %+v (%v)", result, err)
+	}
+	providers, _ := store.Load()
+	if len(providers) != 1 || providers[0].Credentials.APIKeys[0].Key != "sk-export-secret" {
+		t.Errorf("Expected the catalog and its key imported, got %+v", providers)
+	}
+}
+
+func TestExportService_ImportStream(t *testing.T) {
+	service, store, _ := setupTestExportService(t)
+
+	// A LiteLLM config is recognised without a file name
+	result, err := service.Import(strings.NewReader(liteLLMConfig), "merge", models.ImportOptions{Merge: models.MergeOptions{Policy: models.MergeKeepLocal}})
+	if err != nil || result.Format != models.ImportFormatLiteLLM {
+		t.Fatalf("Import failed: %+v (%v)", result, err)
+	}
+	providers, _ := store.Load()
+	if len(providers) != 2 {
+		t.Errorf("Expected the LiteLLM endpoint added, got %+v", providers)
+	}
+
+	if _, err := service.Import(strings.NewReader("{}"), "append", models.ImportOptions{}); err == nil {
+		t.Error("Expected an invalid mode to fail")
+	}
+	if _, err := service.Import(strings.NewReader("not a backup"), "merge", models.ImportOptions{}); err == nil {
+		t.Error("Expected unreadable content to fail")
+	}
+	if _, err := service.Import(strings.NewReader("{}"), "merge", models.ImportOptions{Merge: models.MergeOptions{Policy: "coinFlip"}}); err == nil {
+		t.Error("Expected an invalid merge policy to fail")
+	}
+	if _, err := service.ImportFile(filepath.Join(t.TempDir(), "missing.json"), "merge", models.ImportOptions{}); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
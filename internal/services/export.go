@@ -1,9 +1,17 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
 	"time"
 
+	"llm-desk/internal/logger"
 	"llm-desk/internal/models"
 	"llm-desk/internal/storage"
 
@@ -12,15 +20,23 @@ import (
 
 const schemaVersion = "1.0.0"
 
+// plaintextSecretsWarning is shown whenever a backup holds readable API keys
+const plaintextSecretsWarning = "This backup stores API keys in plaintext. Keep it somewhere safe, or export again with encryption or without secrets."
+
 // ExportService handles data import/export operations
 type ExportService struct {
 	ctx     context.Context
+	dialogs FileDialogs // Nil uses the Wails runtime
 	storage *storage.Storage
+
+	mu            sync.Mutex
+	pendingImport string                    // Encrypted file waiting for its passphrase
+	previews      map[string]*importPreview // Import previews by token
 }
 
 // NewExportService creates a new ExportService
 func NewExportService(s *storage.Storage) *ExportService {
-	return &ExportService{storage: s}
+	return &ExportService{storage: s, previews: make(map[string]*importPreview)}
 }
 
 // SetContext sets the Wails runtime context
@@ -28,65 +44,199 @@ func (s *ExportService) SetContext(ctx context.Context) {
 	s.ctx = ctx
 }
 
-// ExportData exports all provider data to a user-selected file
-func (s *ExportService) ExportData() (bool, error) {
+// SetDialogs replaces the Wails runtime file dialogs, e.g. with a fake in
+// tests
+func (s *ExportService) SetDialogs(d FileDialogs) {
+	s.dialogs = d
+}
+
+// fileDialogs returns the dialogs set with SetDialogs, or the Wails runtime's
+func (s *ExportService) fileDialogs() FileDialogs {
+	if s.dialogs != nil {
+		return s.dialogs
+	}
+	return runtimeDialogs{ctx: s.ctx}
+}
+
+// ExportData exports all provider data to a user-selected file. opts
+// decides whether API keys are left out, written in plaintext or written
+// with the whole file encrypted.
+func (s *ExportService) ExportData(opts models.ExportOptions) (models.ExportResult, error) {
+	opts, err := normalizeExportOptions(opts)
+	if err != nil {
+		return models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+
 	// Get current date for default filename
 	date := time.Now().Format("2006-01-02")
 	defaultFilename := "llm-desk-backup-" + date + ".json"
+	filters := []runtime.FileFilter{
+		{DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
+	}
+	if opts.Secrets == models.ExportSecretsEncrypt {
+		defaultFilename += ".enc"
+		filters = []runtime.FileFilter{
+			{DisplayName: "Encrypted Backups (*.enc)", Pattern: "*.enc"},
+		}
+	}
 
 	// Show save dialog
-	filepath, err := runtime.SaveFileDialog(s.ctx, runtime.SaveDialogOptions{
+	filepath, err := s.fileDialogs().SaveFile(runtime.SaveDialogOptions{
 		DefaultFilename: defaultFilename,
 		Title:           "Export LLM Desk Data",
-		Filters: []runtime.FileFilter{
-			{DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
-		},
+		Filters:         filters,
 	})
 
 	if err != nil {
-		return false, err
+		return models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
 	}
 
 	// User cancelled
 	if filepath == "" {
-		return false, nil
+		return models.ExportResult{Cancelled: true, Message: "Export cancelled", Warnings: []string{}}, nil
+	}
+
+	return s.ExportFile(filepath, opts)
+}
+
+// normalizeExportOptions applies defaults and rejects invalid options
+func normalizeExportOptions(opts models.ExportOptions) (models.ExportOptions, error) {
+	switch opts.Secrets {
+	case "":
+		opts.Secrets = models.ExportSecretsExclude
+	case models.ExportSecretsInclude, models.ExportSecretsExclude:
+	case models.ExportSecretsEncrypt:
+		if opts.Passphrase == "" {
+			return opts, fmt.Errorf("a passphrase is required to encrypt the export")
+		}
+	default:
+		return opts, fmt.Errorf("invalid export secrets option: %s", opts.Secrets)
+	}
+	return opts, nil
+}
+
+// ExportFile writes the catalog to filepath as an LLM Desk backup. It is
+// ExportData without the dialog.
+func (s *ExportService) ExportFile(filepath string, opts models.ExportOptions) (models.ExportResult, error) {
+	content, result, err := s.encodeExport(opts)
+	if err != nil {
+		return result, err
+	}
+	if err := storage.WriteFileAtomic(filepath, content, 0600); err != nil {
+		return models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	return result, nil
+}
+
+// Export writes the catalog to w as an LLM Desk backup. opts works as for
+// ExportData.
+func (s *ExportService) Export(w io.Writer, opts models.ExportOptions) (models.ExportResult, error) {
+	content, result, err := s.encodeExport(opts)
+	if err != nil {
+		return result, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	return result, nil
+}
+
+// encodeExport renders the catalog as backup content according to opts
+func (s *ExportService) encodeExport(opts models.ExportOptions) ([]byte, models.ExportResult, error) {
+	opts, err := normalizeExportOptions(opts)
+	if err != nil {
+		return nil, models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
 	}
 
 	// Load providers
 	providers, err := s.storage.Load()
 	if err != nil {
-		return false, err
+		return nil, models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	if opts.Secrets == models.ExportSecretsExclude {
+		providers = withoutSecrets(providers)
+	}
+
+	data := newExportData(providers, opts.Secrets, "LLM Desk configuration export")
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
 	}
 
-	// Create export data
+	result := models.ExportResult{Success: true, Message: "Successfully exported data", Warnings: []string{}}
+	if opts.Secrets == models.ExportSecretsEncrypt {
+		if content, err = storage.Encrypt(content, opts.Passphrase); err != nil {
+			return nil, models.ExportResult{Message: err.Error(), Warnings: []string{}}, err
+		}
+		result.Encrypted = true
+	} else if hasSecrets(providers) {
+		result.ContainsPlaintextSecrets = true
+		result.Warnings = append(result.Warnings, plaintextSecretsWarning)
+	}
+	return content, result, nil
+}
+
+// newExportData wraps providers in an LLMDeskData document
+func newExportData(providers []models.Provider, secrets models.ExportSecrets, description string) *models.LLMDeskData {
 	now := time.Now().Format(time.RFC3339)
-	description := "LLM Desk configuration export"
-	data := &models.LLMDeskData{
+	return &models.LLMDeskData{
 		Version: schemaVersion,
 		Metadata: models.Metadata{
 			CreatedAt:   now,
 			ModifiedAt:  now,
 			Generator:   "llm-desk",
 			Description: &description,
+			Secrets:     secrets,
 		},
 		Providers: providers,
 	}
+}
 
-	// Write to file
-	if err := s.storage.ExportToFile(filepath, data); err != nil {
-		return false, err
+// withoutSecrets returns copies of providers whose key records carry nothing
+// derived from the secrets, not even the fingerprint or hint. The records
+// keep their IDs, so importing the file on the same machine reattaches the
+// stored keys.
+func withoutSecrets(providers []models.Provider) []models.Provider {
+	stripped := make([]models.Provider, len(providers))
+	for i, p := range providers {
+		keys := make([]models.APIKey, len(p.Credentials.APIKeys))
+		for j, k := range p.Credentials.APIKeys {
+			k.Key = ""
+			k.Fingerprint = ""
+			k.Hint = ""
+			keys[j] = k
+		}
+		p.Credentials.APIKeys = keys
+		stripped[i] = p
 	}
+	return stripped
+}
 
-	return true, nil
+// hasSecrets reports whether any provider carries a readable API key
+func hasSecrets(providers []models.Provider) bool {
+	for _, p := range providers {
+		for _, k := range p.Credentials.APIKeys {
+			if k.Key != "" {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-// ImportData imports provider data from a user-selected file
-func (s *ExportService) ImportData(mode string) (models.ImportResult, error) {
+// ImportData imports provider data from a user-selected file. Besides LLM
+// Desk backups it reads LiteLLM, Continue, Aider and OpenRouter files.
+// Encrypted files are detected automatically; the result then has
+// PassphraseRequired set and ImportWithPassphrase completes the import.
+// opts sets the merge policy and which duplicate providers to merge.
+func (s *ExportService) ImportData(mode string, opts models.MergeOptions) (models.ImportResult, error) {
 	// Show open dialog
-	filepath, err := runtime.OpenFileDialog(s.ctx, runtime.OpenDialogOptions{
+	filepath, err := s.fileDialogs().OpenFile(runtime.OpenDialogOptions{
 		Title: "Import LLM Desk Data",
 		Filters: []runtime.FileFilter{
-			{DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
+			{DisplayName: "LLM Desk Backups (*.json, *.enc)", Pattern: "*.json;*.enc"},
+			{DisplayName: "LiteLLM, Continue, Aider or OpenRouter Files (*.yaml, *.yml, *.json)", Pattern: "*.yaml;*.yml;*.json"},
+			{DisplayName: "All Files", Pattern: "*"},
 		},
 	})
 
@@ -105,88 +255,327 @@ func (s *ExportService) ImportData(mode string) (models.ImportResult, error) {
 		}, nil
 	}
 
-	// Read and parse file
-	importedData, err := s.storage.ImportFromFile(filepath)
-	if err != nil {
+	return s.importFile(filepath, mode, "", opts)
+}
+
+// ImportWithPassphrase completes an import that ImportData reported as
+// needing a passphrase. A wrong passphrase can be retried.
+func (s *ExportService) ImportWithPassphrase(mode, passphrase string, opts models.MergeOptions) (models.ImportResult, error) {
+	s.mu.Lock()
+	filepath := s.pendingImport
+	s.mu.Unlock()
+
+	if filepath == "" {
 		return models.ImportResult{
 			Success:  false,
-			Message:  "Failed to parse import file: " + err.Error(),
+			Message:  "No encrypted import is waiting for a passphrase",
 			Warnings: []string{},
 		}, nil
 	}
-
-	// Validate data
-	warnings := []string{}
-	if importedData.Version == "" {
-		warnings = append(warnings, "No version specified in import file")
+	if passphrase == "" {
+		return models.ImportResult{
+			Success:            false,
+			Message:            "Enter the passphrase for this backup",
+			Warnings:           []string{},
+			PassphraseRequired: true,
+			Encrypted:          true,
+		}, nil
 	}
+	return s.importFile(filepath, mode, passphrase, opts)
+}
+
+// loadedImport is a parsed backup file
+type loadedImport struct {
+	data             *models.LLMDeskData
+	format           models.ImportFormat
+	encrypted        bool
+	plaintextSecrets bool
+	warnings         []string
+	issues           []models.ImportIssue
+	quarantined      []models.Provider        // Invalid items left out of data
+	present          map[string]presentFields // Fields each provider had in the source file
+}
 
-	// Get current providers
-	currentProviders, err := s.storage.Load()
+// readImportFile reads, decrypts if needed and parses a backup file. An
+// encrypted file without passphrase is remembered for ImportWithPassphrase.
+// On failure the returned ImportResult explains why.
+func (s *ExportService) readImportFile(filepath, passphrase string) (*loadedImport, *models.ImportResult) {
+	raw, err := s.storage.ReadAtRest(filepath)
 	if err != nil {
-		currentProviders = []models.Provider{}
+		return nil, &models.ImportResult{
+			Success:  false,
+			Message:  "Failed to read import file: " + err.Error(),
+			Warnings: []string{},
+		}
+	}
+	modified := ""
+	if info, err := os.Stat(filepath); err == nil {
+		modified = info.ModTime().UTC().Format(time.RFC3339)
 	}
 
-	var finalProviders []models.Provider
-	importedProviderCount := len(importedData.Providers)
-	importedModelCount := 0
+	loaded, failed := decodeImport(raw, filepath, modified, passphrase)
+	s.mu.Lock()
+	if failed != nil && failed.PassphraseRequired {
+		s.pendingImport = filepath
+	} else {
+		s.pendingImport = ""
+	}
+	s.mu.Unlock()
+	return loaded, failed
+}
 
-	for _, p := range importedData.Providers {
-		importedModelCount += len(p.Models)
+// schemaFailure returns the import result for a file that does not match
+// the data schema, or nil if err is not a schema error
+func schemaFailure(err error, encrypted bool) *models.ImportResult {
+	var schemaErr *SchemaValidationError
+	if !errors.As(err, &schemaErr) {
+		return nil
 	}
+	return &models.ImportResult{
+		Success:      false,
+		Message:      "Failed to parse import file: " + schemaErr.Error(),
+		Warnings:     []string{},
+		Encrypted:    encrypted,
+		SchemaErrors: schemaErr.Errors,
+	}
+}
 
-	importMode := models.ImportMode(mode)
+// decodeImport decrypts if needed, parses and validates backup content.
+// name is the source file name and modified its modification time, both
+// optional. On failure the returned ImportResult explains why.
+func decodeImport(raw []byte, name, modified, passphrase string) (*loadedImport, *models.ImportResult) {
+	encrypted := storage.IsEncrypted(raw)
 
-	switch importMode {
-	case models.ImportModeReplace:
-		// Replace all data
-		finalProviders = importedData.Providers
-
-	case models.ImportModeMerge:
-		// Merge with existing data
-		finalProviders = currentProviders
-
-		for _, importedProvider := range importedData.Providers {
-			found := false
-			for i, existingProvider := range finalProviders {
-				if existingProvider.ID == importedProvider.ID {
-					// Update existing provider
-					finalProviders[i] = importedProvider
-					found = true
-					break
-				}
+	// Read and parse file
+	var importedData *models.LLMDeskData
+	format := models.ImportFormatLLMDesk
+	var formatWarnings []string
+	var presence map[string]presentFields
+	source := raw
+	if encrypted {
+		if passphrase == "" {
+			return nil, &models.ImportResult{
+				Success:            false,
+				Message:            "This backup is encrypted. Enter its passphrase to import it.",
+				Warnings:           []string{},
+				PassphraseRequired: true,
+				Encrypted:          true,
+			}
+		}
+		plaintext, err := storage.Decrypt(raw, passphrase)
+		if err == nil {
+			source = plaintext
+			importedData, err = decodeLLMDeskData(plaintext, nil)
+			if failed := schemaFailure(err, true); failed != nil {
+				return nil, failed
+			}
+		}
+		if err != nil {
+			return nil, &models.ImportResult{
+				Success:            false,
+				Message:            "Failed to decrypt import file: " + err.Error(),
+				Warnings:           []string{},
+				PassphraseRequired: true,
+				Encrypted:          true,
 			}
-			if !found {
-				// Add new provider
-				finalProviders = append(finalProviders, importedProvider)
+		}
+	} else {
+		var err error
+		importedData, format, formatWarnings, presence, err = parsePlainImport(name, raw, modified)
+		if failed := schemaFailure(err, false); failed != nil {
+			return nil, failed
+		}
+		if err != nil {
+			return nil, &models.ImportResult{
+				Success:  false,
+				Message:  "Failed to parse import file: " + err.Error(),
+				Warnings: []string{},
 			}
 		}
+	}
 
-	default:
+	// Validate data
+	loaded := &loadedImport{data: importedData, format: format, encrypted: encrypted, warnings: []string{}}
+	loaded.warnings = append(loaded.warnings, formatWarnings...)
+	loaded.present = presence
+	if format == models.ImportFormatLLMDesk {
+		loaded.present = sourcePresence(source)
+	}
+	warning, err := checkImportVersion(importedData.Version)
+	if err != nil {
+		return nil, &models.ImportResult{
+			Success:   false,
+			Message:   "Unsupported import file: " + err.Error(),
+			Warnings:  []string{},
+			Encrypted: encrypted,
+		}
+	}
+	if warning != "" {
+		loaded.warnings = append(loaded.warnings, warning)
+	}
+	importedData.Providers, loaded.quarantined, loaded.issues = sanitizeImport(importedData.Providers)
+	if len(loaded.quarantined) > 0 {
+		loaded.warnings = append(loaded.warnings, fmt.Sprintf("Some imported items failed validation and were left out (%d issues)", countQuarantined(loaded.issues)))
+	}
+	loaded.plaintextSecrets = !encrypted && hasSecrets(importedData.Providers)
+	if loaded.plaintextSecrets {
+		loaded.warnings = append(loaded.warnings, plaintextSecretsWarning)
+	}
+	return loaded, nil
+}
+
+// importFile reads, decrypts if needed and imports a backup file
+func (s *ExportService) importFile(filepath, mode, passphrase string, opts models.MergeOptions) (models.ImportResult, error) {
+	importMode := models.ImportMode(mode)
+	if importMode != models.ImportModeReplace && importMode != models.ImportModeMerge {
 		return models.ImportResult{
 			Success: false,
 			Message: "Invalid import mode: " + mode,
 		}, nil
 	}
+	opts, err := normalizeMergeOptions(opts)
+	if err != nil {
+		return models.ImportResult{Message: err.Error(), Warnings: []string{}}, nil
+	}
+
+	loaded, failed := s.readImportFile(filepath, passphrase)
+	if failed != nil {
+		return *failed, nil
+	}
+	return s.applyImport(loaded, importMode, opts)
+}
+
+// ImportFile imports a backup or another tool's config file. It is
+// ImportData without the dialog; a failed import also returns an error.
+func (s *ExportService) ImportFile(filepath, mode string, opts models.ImportOptions) (models.ImportResult, error) {
+	if opts.Name == "" {
+		opts.Name = filepath
+	}
+	raw, err := s.storage.ReadAtRest(filepath)
+	if err != nil {
+		return models.ImportResult{Message: "Failed to read import file: " + err.Error(), Warnings: []string{}}, err
+	}
+	return s.Import(bytes.NewReader(raw), mode, opts)
+}
 
-	// Save the merged/replaced data
-	if err := s.storage.Save(finalProviders); err != nil {
+// Import reads a backup or another tool's config from r and imports it in
+// mode. opts.Name helps recognise the format, opts.Passphrase decrypts an
+// encrypted backup and opts.Merge configures merge mode. A failed import
+// also returns an error.
+func (s *ExportService) Import(r io.Reader, mode string, opts models.ImportOptions) (models.ImportResult, error) {
+	importMode := models.ImportMode(mode)
+	if importMode != models.ImportModeReplace && importMode != models.ImportModeMerge {
+		err := fmt.Errorf("invalid import mode: %s", mode)
+		return models.ImportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	mergeOpts, err := normalizeMergeOptions(opts.Merge)
+	if err != nil {
+		return models.ImportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return models.ImportResult{Message: "Failed to read import data: " + err.Error(), Warnings: []string{}}, err
+	}
+
+	loaded, failed := decodeImport(raw, opts.Name, "", opts.Passphrase)
+	if failed != nil {
+		return *failed, errors.New(failed.Message)
+	}
+	result, err := s.applyImport(loaded, importMode, mergeOpts)
+	if err == nil && !result.Success {
+		err = errors.New(result.Message)
+	}
+	return result, err
+}
+
+// applyImport saves a parsed import and quarantines its invalid items
+func (s *ExportService) applyImport(loaded *loadedImport, mode models.ImportMode, opts models.MergeOptions) (models.ImportResult, error) {
+	importedProviderCount := len(loaded.data.Providers)
+	importedModelCount := 0
+
+	for _, p := range loaded.data.Providers {
+		importedModelCount += len(p.Models)
+	}
+
+	// Merge and save in one transaction so concurrent edits are not lost
+	times := s.mergeTimes(mode, opts)
+	var warnings []string
+	err := s.storage.UpdateOp("import:"+string(mode), func(currentProviders *[]models.Provider) error {
+		target, conflicts := importTarget(*currentProviders, loaded, mode, opts, times)
+		warnings = mergeWarningTexts(conflicts, nil)
+		if mode == models.ImportModeMerge {
+			warnings = append(warnings, duplicateWarnings(*currentProviders, loaded.data.Providers, opts)...)
+		}
+		*currentProviders = target
+		return nil
+	})
+	if err != nil {
 		return models.ImportResult{
 			Success: false,
 			Message: "Failed to save imported data: " + err.Error(),
 		}, nil
 	}
 
+	result := importResult(loaded, importedProviderCount, importedModelCount)
+	result.Warnings = append(result.Warnings, warnings...)
+	s.quarantine(loaded, &result)
+	return result, nil
+}
+
+// mergeTimes reads from the journal when local fields and models last
+// changed, which only MergeNewestWins needs. Without a journal each field
+// counts as changed when its provider last was.
+func (s *ExportService) mergeTimes(mode models.ImportMode, opts models.MergeOptions) changeTimes {
+	if mode != models.ImportModeMerge || opts.Policy != models.MergeNewestWins {
+		return nil
+	}
+	entries, err := s.storage.History(0)
+	if err != nil {
+		logger.Warn("Failed to read change times for merge", "error", err)
+		return nil
+	}
+	// History is newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return journalChangeTimes(entries)
+}
+
+// importResult reports a successful import
+func importResult(loaded *loadedImport, providerCount, modelCount int) models.ImportResult {
 	return models.ImportResult{
-		Success:  true,
-		Message:  "Successfully imported data",
-		Warnings: warnings,
+		Success:                  true,
+		Message:                  "Successfully imported data",
+		Warnings:                 append([]string{}, loaded.warnings...),
+		Encrypted:                loaded.encrypted,
+		Format:                   loaded.format,
+		ContainsPlaintextSecrets: loaded.plaintextSecrets,
+		Issues:                   loaded.issues,
 		Imported: struct {
 			Providers int `json:"providers"`
 			Models    int `json:"models"`
 		}{
-			Providers: importedProviderCount,
-			Models:    importedModelCount,
+			Providers: providerCount,
+			Models:    modelCount,
 		},
-	}, nil
+	}
+}
+
+// ReencryptBackup rewrites a user-selected encrypted backup in the current
+// encryption format. It reports false if the user cancelled or the file was
+// already current.
+func (s *ExportService) ReencryptBackup(passphrase string) (bool, error) {
+	filepath, err := s.fileDialogs().OpenFile(runtime.OpenDialogOptions{
+		Title: "Re-encrypt LLM Desk Backup",
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// User cancelled
+	if filepath == "" {
+		return false, nil
+	}
+
+	return storage.ReencryptFile(filepath, passphrase)
 }
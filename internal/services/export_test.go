@@ -0,0 +1,143 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// setupTestExportService creates a storage holding one provider with a key
+func setupTestExportService(t *testing.T) (*ExportService, *storage.Storage, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := storage.NewWithDir(filepath.Join(dir, "data"), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	err = store.Save([]models.Provider{{
+		ID:          "openai",
+		Name:        "OpenAI",
+		Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-export-secret")},
+		Models:      []models.Model{{ID: "gpt-4o", Context: models.Context{MaxInput: 128000}}},
+	}})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return NewExportService(store), store, dir
+}
+
+func TestExport_SecretsOptions(t *testing.T) {
+	service, _, dir := setupTestExportService(t)
+
+	tests := []struct {
+		secrets   models.ExportSecrets
+		plaintext bool
+		encrypted bool
+	}{
+		{models.ExportSecretsInclude, true, false},
+		{models.ExportSecretsExclude, false, false},
+		{"", false, false}, // Defaults to exclude
+		{models.ExportSecretsEncrypt, false, true},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, "backup-"+string(tt.secrets))
+		result, err := service.ExportFile(path, models.ExportOptions{Secrets: tt.secrets, Passphrase: "pw"})
+		if err != nil {
+			t.Fatalf("%q: export failed: %v", tt.secrets, err)
+		}
+		if result.ContainsPlaintextSecrets != tt.plaintext || result.Encrypted != tt.encrypted {
+			t.Errorf("%q: unexpected result %+v", tt.secrets, result)
+		}
+		if tt.plaintext && len(result.Warnings) == 0 {
+			t.Errorf("%q: expected a plaintext warning", tt.secrets)
+		}
+
+		raw, _ := os.ReadFile(path)
+		if strings.Contains(string(raw), "sk-export-secret") != tt.plaintext {
+			t.Errorf("%q: expected secret in file = %v", tt.secrets, tt.plaintext)
+		}
+		if !tt.plaintext && !tt.encrypted && (strings.Contains(string(raw), `"fingerprint"`) || strings.Contains(string(raw), `"hint"`)) {
+			t.Errorf("%q: expected no fingerprint or hint in file", tt.secrets)
+		}
+	}
+
+	if _, err := service.ExportFile(filepath.Join(dir, "x"), models.ExportOptions{Secrets: models.ExportSecretsEncrypt}); err == nil {
+		t.Error("Expected encrypt without passphrase to fail")
+	}
+}
+
+func TestImport_EncryptedNeedsPassphrase(t *testing.T) {
+	service, store, dir := setupTestExportService(t)
+
+	path := filepath.Join(dir, "backup.json.enc")
+	if _, err := service.ExportFile(path, models.ExportOptions{Secrets: models.ExportSecretsEncrypt, Passphrase: "pw"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if err := store.Save(nil); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	result, _ := service.importFile(path, "replace", "", models.MergeOptions{})
+	if result.Success || !result.PassphraseRequired || !result.Encrypted {
+		t.Fatalf("Expected passphrase to be required, got %+v", result)
+	}
+
+	result, _ = service.ImportWithPassphrase("replace", "wrong", models.MergeOptions{})
+	if result.Success || !result.PassphraseRequired {
+		t.Fatalf("Expected wrong passphrase to be retryable, got %+v", result)
+	}
+
+	result, _ = service.ImportWithPassphrase("replace", "pw", models.MergeOptions{})
+	if !result.Success || result.ContainsPlaintextSecrets {
+		t.Fatalf("Expected encrypted import to succeed, got %+v", result)
+	}
+	providers, _ := store.Load()
+	if len(providers) != 1 || len(providers[0].Credentials.APIKeys) != 1 || providers[0].Credentials.APIKeys[0].Key != "sk-export-secret" {
+		t.Errorf("Expected provider and key to be restored, got %+v", providers)
+	}
+
+	// The pending file is consumed by a successful import
+	if result, _ := service.ImportWithPassphrase("replace", "pw", models.MergeOptions{}); result.Success {
+		t.Error("Expected no pending import after success")
+	}
+}
+
+func TestImport_WarnsAboutPlaintextSecrets(t *testing.T) {
+	service, _, dir := setupTestExportService(t)
+
+	path := filepath.Join(dir, "backup.json")
+	if _, err := service.ExportFile(path, models.ExportOptions{Secrets: models.ExportSecretsInclude}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	result, _ := service.importFile(path, "merge", "", models.MergeOptions{})
+	if !result.Success || !result.ContainsPlaintextSecrets || len(result.Warnings) == 0 {
+		t.Errorf("Expected plaintext warning, got %+v", result)
+	}
+}
+
+func TestImport_ExcludedSecretsKeepStoredKeys(t *testing.T) {
+	service, store, dir := setupTestExportService(t)
+
+	path := filepath.Join(dir, "backup.json")
+	if _, err := service.ExportFile(path, models.ExportOptions{Secrets: models.ExportSecretsExclude}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	for _, mode := range []string{"replace", "merge"} {
+		result, _ := service.importFile(path, mode, "", models.MergeOptions{})
+		if !result.Success || result.ContainsPlaintextSecrets {
+			t.Fatalf("%s: expected import to succeed without warnings, got %+v", mode, result)
+		}
+		providers, _ := store.Load()
+		if len(providers[0].Credentials.APIKeys) != 1 || providers[0].Credentials.APIKeys[0].Key != "sk-export-secret" {
+			t.Errorf("%s: expected stored key to be kept, got %+v", mode, providers[0].Credentials.APIKeys)
+		}
+	}
+}
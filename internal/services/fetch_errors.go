@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// bodyExcerptLength is how much of a response body a FetchError keeps
+const bodyExcerptLength = 300
+
+// maxErrorBody is how much of an error response is read
+const maxErrorBody = 16 << 10
+
+// FetchError is a classified failure to read a model list from one
+// endpoint. Endpoint, Body and Err have secrets redacted.
+type FetchError struct {
+	Kind       models.FetchErrorKind
+	Endpoint   string
+	Status     int // HTTP status, if a response arrived
+	RetryAfter int // Seconds, when rate limited
+	Body       string
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	var parts []string
+	if e.Status != 0 && e.Status != http.StatusOK {
+		parts = append(parts, fmt.Sprintf("HTTP %d", e.Status))
+	}
+	if e.Err != nil {
+		parts = append(parts, e.Err.Error())
+	}
+	if len(parts) == 0 {
+		return string(e.Kind)
+	}
+	return strings.Join(parts, ": ")
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Advice says what went wrong in words the user can act on
+func (e *FetchError) Advice() string {
+	host := e.Endpoint
+	if u, err := url.Parse(e.Endpoint); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	switch e.Kind {
+	case models.FetchErrorAuth:
+		return "The provider rejected the API key (HTTP 401). Check that the key is complete, belongs to this provider and has not been revoked."
+	case models.FetchErrorForbidden:
+		return "The API key is not allowed to list models (HTTP 403). Check the key's permissions, project or region."
+	case models.FetchErrorNotFound:
+		return fmt.Sprintf("There is no model list at %s (HTTP 404). Check the base URL; OpenAI-compatible APIs usually end in /v1.", e.Endpoint)
+	case models.FetchErrorRateLimited:
+		if e.RetryAfter > 0 {
+			return fmt.Sprintf("The provider is rate limiting requests (HTTP 429). Try again in %s.", time.Duration(e.RetryAfter)*time.Second)
+		}
+		return "The provider is rate limiting requests (HTTP 429). Wait a moment and try again."
+	case models.FetchErrorHTTP:
+		if e.Status >= 500 {
+			return fmt.Sprintf("The provider had a server error (HTTP %d). Try again later.", e.Status)
+		}
+		if e.Status != 0 {
+			return fmt.Sprintf("The provider answered HTTP %d when listing models.", e.Status)
+		}
+	case models.FetchErrorTLS:
+		return fmt.Sprintf("Could not make a secure connection to %s: %v. Check whether the URL should use http or https, and the server's certificate.", host, e.Err)
+	case models.FetchErrorDNS:
+		return fmt.Sprintf("Could not find the host %s. Check the host name in the base URL.", host)
+	case models.FetchErrorTimeout:
+		return fmt.Sprintf("%s did not answer in time. Check the base URL and your network connection.", host)
+	case models.FetchErrorNetwork:
+		return fmt.Sprintf("Could not connect to %s: %v. Check that the server is running and the port is right.", host, e.Err)
+	case models.FetchErrorNotJSON:
+		return fmt.Sprintf("%s did not answer with JSON. The base URL may point at a web page instead of the API.", e.Endpoint)
+	case models.FetchErrorNoModels:
+		return fmt.Sprintf("%s answered, but listed no models.", e.Endpoint)
+	case models.FetchErrorInvalidURL:
+		return fmt.Sprintf("The URL is not valid: %v.", e.Err)
+	}
+	return e.Error()
+}
+
+// fetchErrorRank orders kinds from most to least telling. A wrong guess at
+// the protocol usually ends in not found, not JSON or no models, so those
+// come last.
+var fetchErrorRank = map[models.FetchErrorKind]int{
+	models.FetchErrorAuth:        1,
+	models.FetchErrorForbidden:   2,
+	models.FetchErrorRateLimited: 3,
+	models.FetchErrorTLS:         4,
+	models.FetchErrorDNS:         5,
+	models.FetchErrorTimeout:     6,
+	models.FetchErrorNetwork:     7,
+	models.FetchErrorInvalidURL:  8,
+	models.FetchErrorHTTP:        9,
+	models.FetchErrorNoModels:    10,
+	models.FetchErrorNotJSON:     11,
+	models.FetchErrorNotFound:    12,
+}
+
+// asFetchError classifies err, which an adapter returned for endpoint
+func asFetchError(err error, endpoint string) *FetchError {
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe
+	}
+	return &FetchError{Kind: models.FetchErrorHTTP, Endpoint: endpoint, Err: err}
+}
+
+// transportKind classifies an error from sending a request
+func transportKind(err error) models.FetchErrorKind {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return models.FetchErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return models.FetchErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.FetchErrorTimeout
+	}
+	return models.FetchErrorNetwork
+}
+
+// statusKind classifies an unexpected HTTP status
+func statusKind(status int) models.FetchErrorKind {
+	switch status {
+	case http.StatusUnauthorized:
+		return models.FetchErrorAuth
+	case http.StatusForbidden:
+		return models.FetchErrorForbidden
+	case http.StatusNotFound:
+		return models.FetchErrorNotFound
+	case http.StatusTooManyRequests:
+		return models.FetchErrorRateLimited
+	}
+	return models.FetchErrorHTTP
+}
+
+// retryAfter reads how many seconds to wait from Retry-After, in seconds
+// or as a date, or from OpenAI's x-ratelimit-reset-requests ("6m0s")
+func retryAfter(header http.Header, now time.Time) int {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return n
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return int(math.Ceil(t.Sub(now).Seconds()))
+	}
+	if d, err := time.ParseDuration(header.Get("X-Ratelimit-Reset-Requests")); err == nil && d > 0 {
+		return int(math.Ceil(d.Seconds()))
+	}
+	return 0
+}
+
+// excerpt returns the start of a response body on one line
+func excerpt(body []byte) string {
+	text := strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "�")), " ")
+	if runes := []rune(text); len(runes) > bodyExcerptLength {
+		text = string(runes[:bodyExcerptLength]) + "…"
+	}
+	return text
+}
+
+// secretParams are query parameters that carry an API key
+var secretParams = []string{"key", "api-key", "api_key", "apikey", "access_token", "token"}
+
+// secretHeaders are request headers that carry an API key
+var secretHeaders = []string{"Authorization", "X-Api-Key", "Api-Key"}
+
+// keyPattern matches strings that look like API keys even when they are not
+// the key that was sent, such as a key echoed back in part
+var keyPattern = regexp.MustCompile(`\b(?:sk|pk|rk|gsk|xai)-[A-Za-z0-9_\-]{16,}|\bAIza[0-9A-Za-z_\-]{30,}|(?i:bearer\s+)[^\s"',]+`)
+
+// redactor hides the secrets of one request in what is shown to the user
+type redactor []string
+
+// newRedactor collects the secrets sent in an endpoint's query and in the
+// credential headers
+func newRedactor(endpoint string, header http.Header) redactor {
+	var r redactor
+	if u, err := url.Parse(endpoint); err == nil {
+		query := u.Query()
+		for _, name := range secretParams {
+			r = append(r, query[name]...)
+		}
+	}
+	for _, name := range secretHeaders {
+		for _, value := range header.Values(name) {
+			r = append(r, strings.TrimSpace(strings.TrimPrefix(value, "Bearer ")))
+		}
+	}
+	return r
+}
+
+// text replaces the secrets, and anything that looks like a key, in s
+func (r redactor) text(s string) string {
+	for _, secret := range r {
+		if len(secret) >= 4 {
+			s = strings.ReplaceAll(s, secret, "[redacted]")
+		}
+	}
+	return keyPattern.ReplaceAllString(s, "[redacted]")
+}
+
+// url returns endpoint with the values of secret query parameters hidden
+func (r redactor) url(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return r.text(endpoint)
+	}
+	query := u.Query()
+	changed := false
+	for _, name := range secretParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+	u.User = nil
+	return u.String()
+}
+
+// err returns err with secrets hidden. The URL in a *url.Error is dropped,
+// since the error is reported next to the redacted endpoint.
+func (r redactor) err(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return errors.New(r.text(err.Error()))
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+func TestGetJSONClassifiesResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		body   string
+		kind   models.FetchErrorKind
+		retry  int
+	}{
+		{"auth", http.StatusUnauthorized, nil, `{"error": {"message": "Incorrect API key provided: sk-live-abcdefghijklmnop1234"}}`, models.FetchErrorAuth, 0},
+		{"forbidden", http.StatusForbidden, nil, `{"error": "model listing disabled"}`, models.FetchErrorForbidden, 0},
+		{"not found", http.StatusNotFound, nil, `404 page not found`, models.FetchErrorNotFound, 0},
+		{"rate limited", http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}, `{"error": "slow down"}`, models.FetchErrorRateLimited, 7},
+		{"reset header", http.StatusTooManyRequests, map[string]string{"X-Ratelimit-Reset-Requests": "1m30s"}, ``, models.FetchErrorRateLimited, 90},
+		{"server error", http.StatusBadGateway, nil, `<html>Bad gateway</html>`, models.FetchErrorHTTP, 0},
+		{"web page", http.StatusOK, map[string]string{"Content-Type": "text/html"}, `<!doctype html><title>Welcome</title>`, models.FetchErrorNotJSON, 0},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range tt.header {
+				w.Header().Set(name, value)
+			}
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		}))
+		_, err := getJSON(context.Background(), server.Client(), server.URL+"/models?key=gm-secret-key", nil)
+		server.Close()
+
+		var fe *FetchError
+		if !errors.As(err, &fe) {
+			t.Fatalf("%s: expected a FetchError, got %v", tt.name, err)
+		}
+		if fe.Kind != tt.kind || fe.RetryAfter != tt.retry {
+			t.Errorf("%s: expected %s retrying after %d, got %s after %d", tt.name, tt.kind, tt.retry, fe.Kind, fe.RetryAfter)
+		}
+		if strings.Contains(fe.Endpoint, "gm-secret-key") || strings.Contains(fe.Body, "sk-live") || strings.Contains(fe.Advice(), "gm-secret-key") {
+			t.Errorf("%s: expected secrets to be redacted, got %q, %q", tt.name, fe.Endpoint, fe.Body)
+		}
+		if fe.Advice() == "" {
+			t.Errorf("%s: expected advice", tt.name)
+		}
+	}
+}
+
+func TestGetJSONRedactsSentKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"error": "key %s is not valid"}`, r.Header.Get("X-Api-Key"))
+	}))
+	defer server.Close()
+
+	_, err := getJSON(context.Background(), server.Client(), server.URL, http.Header{"X-Api-Key": {"custom-1234"}})
+	var fe *FetchError
+	if !errors.As(err, &fe) || fe.Body != `{"error": "key [redacted] is not valid"}` {
+		t.Errorf("Expected the echoed key to be redacted, got %v", err)
+	}
+}
+
+func TestGetJSONClassifiesTransport(t *testing.T) {
+	// An untrusted certificate
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	_, err := getJSON(context.Background(), &http.Client{}, tlsServer.URL, nil)
+	if fe := asFetchError(err, ""); fe.Kind != models.FetchErrorTLS {
+		t.Errorf("Expected a TLS error, got %s (%v)", fe.Kind, err)
+	}
+
+	// No answer in time
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	_, err = getJSON(context.Background(), &http.Client{Timeout: 20 * time.Millisecond}, slow.URL, nil)
+	if fe := asFetchError(err, ""); fe.Kind != models.FetchErrorTimeout {
+		t.Errorf("Expected a timeout, got %s (%v)", fe.Kind, err)
+	}
+
+	// Nothing listening
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+	_, err = getJSON(context.Background(), &http.Client{}, closed.URL+"?key=gm-secret-key", nil)
+	if fe := asFetchError(err, ""); fe.Kind != models.FetchErrorNetwork || strings.Contains(fe.Error(), "gm-secret-key") {
+		t.Errorf("Expected a redacted network error, got %s (%v)", fe.Kind, err)
+	}
+
+	if kind := transportKind(&net.DNSError{Err: "no such host", Name: "api.example.invalid", IsNotFound: true}); kind != models.FetchErrorDNS {
+		t.Errorf("Expected a DNS error, got %s", kind)
+	}
+	if kind := transportKind(fmt.Errorf("wrapped: %w", x509.UnknownAuthorityError{})); kind != models.FetchErrorTLS {
+		t.Errorf("Expected a TLS error, got %s", kind)
+	}
+}
+
+func TestRetryAfterDate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	header := http.Header{"Retry-After": {now.Add(2 * time.Minute).Format(http.TimeFormat)}}
+	if got := retryAfter(header, now); got != 120 {
+		t.Errorf("Expected 120 seconds, got %d", got)
+	}
+	if got := retryAfter(http.Header{"Retry-After": {"soon"}}, now); got != 0 {
+		t.Errorf("Expected 0 for an unreadable header, got %d", got)
+	}
+}
+
+func TestFetchModelsReportsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/anthropic/models" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": {"code": "invalid_api_key"}}`)
+	}))
+	defer server.Close()
+
+	anthropic := server.URL + "/anthropic"
+	result := NewModelFetcher(nil).FetchModels(server.URL+"/v1", "sk-test", &anthropic)
+	if result.ErrorKind != models.FetchErrorAuth || !strings.Contains(result.Error, "API key") {
+		t.Errorf("Expected the auth failure to be reported, got %s: %s", result.ErrorKind, result.Error)
+	}
+	if len(result.Attempts) != 2 {
+		t.Fatalf("Expected two attempts, got %+v", result.Attempts)
+	}
+	first, second := result.Attempts[0], result.Attempts[1]
+	if first.Protocol != models.ProtocolOpenAI || first.Status != http.StatusUnauthorized || first.BodyExcerpt == "" {
+		t.Errorf("Expected the OpenAI attempt first, got %+v", first)
+	}
+	if second.Protocol != models.ProtocolAnthropic || second.ErrorKind != models.FetchErrorNotFound || !strings.HasPrefix(second.Endpoint, anthropic+"/models") {
+		t.Errorf("Expected the Anthropic attempt second, got %+v", second)
+	}
+}
+
+func TestFetchModelsNoModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": []}`)
+	}))
+	defer server.Close()
+
+	result := NewModelFetcher(nil).FetchProtocolModels(models.ProtocolOpenAI, server.URL, "", nil)
+	if result.ErrorKind != models.FetchErrorNoModels || len(result.Attempts) != 1 {
+		t.Errorf("Expected an empty list to be reported, got %+v", result)
+	}
+}
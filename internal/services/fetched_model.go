@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// parseModelList reads the models of a model-list response body
+func parseModelList(body []byte) ([]models.FetchedModel, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	fetched := modelsIn(doc)
+	if len(fetched) == 0 {
+		return nil, fmt.Errorf("no models found in response")
+	}
+	return fetched, nil
+}
+
+// modelsIn reads the models of a decoded model-list response: an object
+// with a "data" or "models" array, or a bare array
+func modelsIn(doc interface{}) []models.FetchedModel {
+	items := asSlice(asMap(doc)["data"])
+	if len(items) == 0 {
+		items = asSlice(asMap(doc)["models"])
+	}
+	if len(items) == 0 {
+		items = asSlice(doc)
+	}
+
+	fetched := make([]models.FetchedModel, 0, len(items))
+	for _, item := range items {
+		if m := fetchedModelFrom(asMap(item)); m.ID != "" {
+			fetched = append(fetched, m)
+		}
+	}
+	return fetched
+}
+
+// fetchedModelFrom reads one entry of a model list. Besides the OpenAI
+// fields it understands the metadata of OpenRouter, Anthropic, Together,
+// Fireworks, Groq, Mistral, DeepInfra, Gemini, Ollama, Azure and Cohere.
+func fetchedModelFrom(e map[string]interface{}) models.FetchedModel {
+	// DeepInfra nests its metadata
+	meta := asMap(e["metadata"])
+
+	m := models.FetchedModel{
+		ID:          asString(e["id"]),
+		Object:      asString(e["object"]),
+		OwnedBy:     firstString(e["owned_by"], e["organization"]),
+		DisplayName: firstString(e["display_name"], e["displayName"]),
+		Description: firstString(e["description"], meta["description"]),
+	}
+
+	// Ollama reports the size as "8.0B"
+	if size := asString(asMap(e["details"])["parameter_size"]); size != "" {
+		m.Parameters = strings.Replace(size, ".0B", "B", 1)
+	}
+
+	// Gemini and Ollama name models ("models/<id>") and have no id field
+	name := asString(e["name"])
+	if m.ID == "" {
+		m.ID = strings.TrimPrefix(name, "models/")
+	} else if m.DisplayName == "" && name != m.ID && strings.Contains(name, " ") {
+		// OpenRouter's name is a display name; Mistral's is another ID
+		m.DisplayName = name
+	}
+
+	if n, ok := asFloat(e["created"]); ok && n > 0 {
+		m.Created = int64(n)
+	} else if n, ok := asFloat(e["created_at"]); ok && n > 0 {
+		m.Created = int64(n) // Azure
+	} else if t, err := time.Parse(time.RFC3339, asString(e["created_at"])); err == nil {
+		m.Created = t.Unix()
+	}
+
+	m.ContextLength = firstInt(
+		e["context_length"],     // OpenRouter, Together, Fireworks
+		e["context_window"],     // Groq
+		e["max_context_length"], // Mistral
+		e["inputTokenLimit"],    // Gemini
+		e["max_input_tokens"],   // LiteLLM proxies
+		e["max_model_len"],      // vLLM
+		meta["context_length"],
+		asMap(e["top_provider"])["context_length"],
+	)
+	if n := firstInt(
+		asMap(e["top_provider"])["max_completion_tokens"], // OpenRouter
+		e["max_completion_tokens"],                        // Groq
+		e["outputTokenLimit"],                             // Gemini
+		e["max_output_tokens"],
+		meta["max_tokens"],
+	); n > 0 {
+		m.MaxOutputTokens = &n
+	}
+
+	m.Pricing = fetchedPricing(asMap(e["pricing"]))
+	m.Modalities = fetchedModalities(e)
+
+	// Capabilities: OpenRouter's supported parameters, Fireworks' supports_*
+	// flags, Mistral's capabilities and Gemini's thinking flag
+	capabilities := asMap(e["capabilities"])
+	for _, param := range asSlice(e["supported_parameters"]) {
+		switch asString(param) {
+		case "tools":
+			setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.ToolCalling })
+		case "reasoning":
+			setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Reasoning })
+		}
+	}
+	if asBool(e["supports_tools"]) || asBool(capabilities["function_calling"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.ToolCalling })
+	}
+	if asBool(e["thinking"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Reasoning })
+	}
+	if asBool(e["supports_image_input"]) || asBool(capabilities["vision"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Vision })
+	}
+	return m
+}
+
+// fetchedPricing reads per-token prices (OpenRouter: prompt, completion,
+// input_cache_read) or per-million prices (Together: input, output).
+// Negative OpenRouter prices mean the price varies and are left out.
+func fetchedPricing(pricing map[string]interface{}) *models.Pricing {
+	p := models.Pricing{Currency: "USD"}
+	found := false
+	if v, ok := asFloat(pricing["prompt"]); ok && v >= 0 {
+		p.Input, found = perMillion(v), true
+	} else if v, ok := asFloat(pricing["input"]); ok && v >= 0 {
+		p.Input, found = v, true
+	}
+	if v, ok := asFloat(pricing["completion"]); ok && v >= 0 {
+		p.Output, found = perMillion(v), true
+	} else if v, ok := asFloat(pricing["output"]); ok && v >= 0 {
+		p.Output, found = v, true
+	}
+	if v, ok := asFloat(pricing["input_cache_read"]); ok && v >= 0 {
+		cached := perMillion(v)
+		p.Cached, found = &cached, true
+	}
+	if !found {
+		return nil
+	}
+	return &p
+}
+
+// fetchedModalities reads the input modalities from OpenRouter's
+// architecture, or from image capabilities reported elsewhere
+func fetchedModalities(e map[string]interface{}) []string {
+	architecture := asMap(e["architecture"])
+	modalities := modalitiesFrom(asSlice(architecture["input_modalities"]))
+	if len(modalities) == 0 {
+		// Older form: "text+image->text"
+		inputs, _, _ := strings.Cut(asString(architecture["modality"]), "->")
+		for _, input := range strings.Split(inputs, "+") {
+			modalities = append(modalities, modalitiesFrom([]interface{}{input})...)
+		}
+	}
+	if asBool(e["supports_image_input"]) || asBool(asMap(e["capabilities"])["vision"]) {
+		modalities = appendModality(appendModality(modalities, "text"), "vision")
+	}
+	return modalities
+}
+
+// firstString returns the first non-empty string among values
+func firstString(values ...interface{}) string {
+	for _, v := range values {
+		if s := asString(v); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// firstInt returns the first positive whole number among values, or 0
+func firstInt(values ...interface{}) int {
+	for _, v := range values {
+		if n, ok := asInt(v); ok {
+			return n
+		}
+	}
+	return 0
+}
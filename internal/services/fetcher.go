@@ -1,156 +1,162 @@
 package services
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
 )
 
 // ModelFetcher handles fetching models from LLM provider APIs
 type ModelFetcher struct {
-	client *http.Client
+	client   *http.Client
+	settings *SettingsService
+	adapters map[models.ProviderProtocol]ProtocolAdapter
 }
 
-// NewModelFetcher creates a new ModelFetcher
-func NewModelFetcher() *ModelFetcher {
+// NewModelFetcher creates a new ModelFetcher. settings supplies the page
+// and model caps; without it the defaults apply.
+func NewModelFetcher(settings *SettingsService) *ModelFetcher {
 	return &ModelFetcher{
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		settings: settings,
+		adapters: defaultProtocolAdapters(),
 	}
 }
 
-// FetchModels fetches available models from a provider's API
-func (f *ModelFetcher) FetchModels(baseURL, apiKey string, anthropicURL *string) models.FetchModelsResult {
-	// Try OpenAI-compatible endpoint first
-	result, err := f.fetchOpenAIStyle(baseURL, apiKey)
-	if err == nil && len(result.Models) > 0 {
-		return result
-	}
-
-	// Try Anthropic endpoint if provided
-	if anthropicURL != nil && *anthropicURL != "" {
-		result, err = f.fetchAnthropicStyle(*anthropicURL, apiKey)
-		if err == nil && len(result.Models) > 0 {
-			return result
-		}
-	}
-
-	return models.FetchModelsResult{
-		Models: []models.FetchedModel{},
-		Error:  "Could not fetch models. This may be due to invalid credentials, or the endpoint not supporting model listing.",
-	}
+// SetAdapter sets the adapter used for a protocol
+func (f *ModelFetcher) SetAdapter(protocol models.ProviderProtocol, adapter ProtocolAdapter) {
+	f.adapters[protocol] = adapter
 }
 
-func (f *ModelFetcher) fetchOpenAIStyle(baseURL, apiKey string) (models.FetchModelsResult, error) {
-	url := strings.TrimSuffix(baseURL, "/") + "/models"
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return models.FetchModelsResult{}, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return models.FetchModelsResult{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return models.FetchModelsResult{}, fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return models.FetchModelsResult{}, err
-	}
-
-	// Try to parse as OpenAI response format
-	var openAIResp struct {
-		Data   []models.FetchedModel `json:"data"`
-		Models []models.FetchedModel `json:"models"`
-	}
-
-	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return models.FetchModelsResult{}, err
-	}
-
-	// Data field takes priority (standard OpenAI format)
-	if len(openAIResp.Data) > 0 {
-		return models.FetchModelsResult{Models: openAIResp.Data}, nil
-	}
-	if len(openAIResp.Models) > 0 {
-		return models.FetchModelsResult{Models: openAIResp.Models}, nil
-	}
-
-	// Try parsing as direct array
-	var directModels []models.FetchedModel
-	if err := json.Unmarshal(body, &directModels); err == nil && len(directModels) > 0 {
-		return models.FetchModelsResult{Models: directModels}, nil
-	}
-
-	return models.FetchModelsResult{}, fmt.Errorf("no models found in response")
+// FetchModels fetches available models from a provider's API, detecting
+// the protocol it speaks
+func (f *ModelFetcher) FetchModels(baseURL, apiKey string, anthropicURL *string) models.FetchModelsResult {
+	return f.FetchProtocolModels("", baseURL, apiKey, anthropicURL)
 }
 
-func (f *ModelFetcher) fetchAnthropicStyle(baseURL, apiKey string) (models.FetchModelsResult, error) {
-	url := strings.TrimSuffix(baseURL, "/") + "/models"
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return models.FetchModelsResult{}, err
+// FetchProtocolModels fetches available models with the adapter for
+// protocol. Without a protocol, one recognized from the base URL is tried
+// first, then the OpenAI API and finally the Anthropic API. When every
+// attempt fails, the most telling failure is reported along with the log
+// of attempts.
+func (f *ModelFetcher) FetchProtocolModels(protocol models.ProviderProtocol, baseURL, apiKey string, anthropicURL *string) models.FetchModelsResult {
+	attempts := f.fetchAttempts(protocol, baseURL, anthropicURL)
+	if len(attempts) == 0 {
+		return models.FetchModelsResult{
+			Models:    []models.FetchedModel{},
+			Error:     fmt.Sprintf("Unknown provider protocol %q.", protocol),
+			ErrorKind: models.FetchErrorProtocol,
+		}
 	}
 
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-	req.Header.Set("Content-Type", "application/json")
+	limits := storage.DefaultModelFetchSettings()
+	if f.settings != nil {
+		limits = f.settings.GetModelFetchSettings()
+	}
+	var log []models.FetchAttempt
+	var failure *FetchError
+	for _, attempt := range attempts {
+		req := ListRequest{Client: f.client, BaseURL: attempt.baseURL, APIKey: apiKey, Limits: limits}
+		list, err := attempt.adapter.ListModels(context.Background(), req)
+		if err != nil {
+			fe := asFetchError(err, attempt.baseURL)
+			log = append(log, models.FetchAttempt{
+				Protocol:    attempt.protocol,
+				Endpoint:    fe.Endpoint,
+				Status:      fe.Status,
+				ErrorKind:   fe.Kind,
+				Message:     fe.Error(),
+				RetryAfter:  fe.RetryAfter,
+				BodyExcerpt: fe.Body,
+			})
+			if failure == nil || fetchErrorRank[fe.Kind] < fetchErrorRank[failure.Kind] {
+				failure = fe
+			}
+			continue
+		}
 
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return models.FetchModelsResult{}, err
+		log = append(log, models.FetchAttempt{
+			Protocol: attempt.protocol,
+			Endpoint: list.Endpoint,
+			Status:   http.StatusOK,
+			Models:   len(list.Models),
+		})
+		result := models.FetchModelsResult{Models: list.Models, Attempts: log, Pages: list.Pages, Truncated: list.Truncated}
+		if list.Truncated {
+			result.Warning = fmt.Sprintf("Only the first %d models were fetched; the provider lists more. Raise the page or model limit in settings to fetch them all.", len(list.Models))
+		}
+		return result
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return models.FetchModelsResult{}, fmt.Errorf("HTTP %d", resp.StatusCode)
+	return models.FetchModelsResult{
+		Models:     []models.FetchedModel{},
+		Error:      failure.Advice(),
+		ErrorKind:  failure.Kind,
+		RetryAfter: failure.RetryAfter,
+		Attempts:   log,
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return models.FetchModelsResult{}, err
-	}
+// fetchAttempt is one adapter to try, with the base URL to give it
+type fetchAttempt struct {
+	protocol models.ProviderProtocol
+	adapter  ProtocolAdapter
+	baseURL  string
+}
 
-	var anthropicResp struct {
-		Data   []models.FetchedModel `json:"data"`
-		Models []models.FetchedModel `json:"models"`
+// fetchAttempts returns the adapters to try in order
+func (f *ModelFetcher) fetchAttempts(protocol models.ProviderProtocol, baseURL string, anthropicURL *string) []fetchAttempt {
+	anthropic := baseURL
+	if anthropicURL != nil && *anthropicURL != "" {
+		anthropic = *anthropicURL
 	}
 
-	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return models.FetchModelsResult{}, err
+	var attempts []fetchAttempt
+	add := func(protocol models.ProviderProtocol, baseURL string) {
+		adapter, ok := f.adapters[protocol]
+		if !ok {
+			return
+		}
+		for _, a := range attempts {
+			if a.protocol == protocol {
+				return
+			}
+		}
+		attempts = append(attempts, fetchAttempt{protocol: protocol, adapter: adapter, baseURL: baseURL})
 	}
 
-	if len(anthropicResp.Data) > 0 {
-		return models.FetchModelsResult{Models: anthropicResp.Data}, nil
-	}
-	if len(anthropicResp.Models) > 0 {
-		return models.FetchModelsResult{Models: anthropicResp.Models}, nil
+	switch protocol {
+	case "":
+		if detected := detectProtocol(baseURL); detected != "" {
+			add(detected, baseURL)
+		}
+		if baseURL != "" {
+			add(models.ProtocolOpenAI, baseURL)
+		}
+		if anthropicURL != nil && *anthropicURL != "" {
+			add(models.ProtocolAnthropic, anthropic)
+		}
+	case models.ProtocolAnthropic:
+		add(protocol, anthropic)
+	default:
+		add(protocol, baseURL)
 	}
-
-	return models.FetchModelsResult{}, fmt.Errorf("no models found in response")
+	return attempts
 }
 
-// TransformFetchedModel converts a fetched model to our Model type
+// TransformFetchedModel converts a fetched model to our Model type, using
+// the metadata the API reported and defaults for the rest
 func TransformFetchedModel(fetched models.FetchedModel) models.Model {
-	return models.Model{
+	m := models.Model{
 		ID:         fetched.ID,
-		Name:       formatModelName(fetched.ID),
+		Name:       fetched.DisplayName,
 		Enabled:    true,
 		Parameters: nil,
 		Pricing: models.Pricing{
@@ -160,13 +166,30 @@ func TransformFetchedModel(fetched models.FetchedModel) models.Model {
 			Currency: "USD",
 		},
 		Context: models.Context{
-			MaxInput:  128000,
-			MaxOutput: nil,
+			MaxInput:  fetched.ContextLength,
+			MaxOutput: fetched.MaxOutputTokens,
 		},
-		Modalities: []string{"text"},
-		Features:   nil,
+		Modalities: fetched.Modalities,
+		Features:   fetched.Features,
 		Limits:     []models.Limit{},
 	}
+	if m.Name == "" {
+		m.Name = formatModelName(fetched.ID)
+	}
+	if fetched.Parameters != "" {
+		parameters := fetched.Parameters
+		m.Parameters = &parameters
+	}
+	if fetched.Pricing != nil {
+		m.Pricing = *fetched.Pricing
+	}
+	if m.Context.MaxInput <= 0 {
+		m.Context.MaxInput = defaultContextWindow
+	}
+	if len(m.Modalities) == 0 {
+		m.Modalities = []string{"text"}
+	}
+	return m
 }
 
 // formatModelName formats a model ID into a readable name
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+func TestParseModelListMetadata(t *testing.T) {
+	cached := 1.25
+	tests := []struct {
+		name     string
+		body     string
+		id       string
+		display  string
+		context  int
+		output   int
+		pricing  *models.Pricing
+		modality []string
+	}{
+		{
+			name: "openrouter",
+			body: `{"data": [{"id": "openai/gpt-4o", "name": "OpenAI: GPT-4o", "created": 1715367049,
+				"context_length": 128000, "top_provider": {"context_length": 128000, "max_completion_tokens": 16384},
+				"pricing": {"prompt": "0.0000025", "completion": "0.00001", "input_cache_read": "0.00000125"},
+				"architecture": {"input_modalities": ["text", "image"], "output_modalities": ["text"]},
+				"supported_parameters": ["tools", "temperature"]}]}`,
+			id: "openai/gpt-4o", display: "OpenAI: GPT-4o", context: 128000, output: 16384,
+			pricing:  &models.Pricing{Input: 2.5, Output: 10, Cached: &cached, Currency: "USD"},
+			modality: []string{"text", "vision"},
+		},
+		{
+			name: "anthropic",
+			body: `{"data": [{"type": "model", "id": "claude-3-5-sonnet-20241022", "display_name": "Claude 3.5 Sonnet (New)",
+				"created_at": "2024-10-22T00:00:00Z"}], "has_more": false}`,
+			id: "claude-3-5-sonnet-20241022", display: "Claude 3.5 Sonnet (New)",
+		},
+		{
+			name: "together",
+			body: `[{"id": "meta-llama/Llama-3-70b-chat-hf", "object": "model", "type": "chat", "display_name": "Llama 3 70B Chat",
+				"organization": "Meta", "context_length": 8192, "pricing": {"hourly": 0, "input": 0.9, "output": 0.9}}]`,
+			id: "meta-llama/Llama-3-70b-chat-hf", display: "Llama 3 70B Chat", context: 8192,
+			pricing: &models.Pricing{Input: 0.9, Output: 0.9, Currency: "USD"},
+		},
+		{
+			name: "fireworks",
+			body: `{"data": [{"id": "accounts/fireworks/models/llama-v3p2-11b-vision-instruct", "object": "model",
+				"supports_image_input": true, "supports_tools": false, "context_length": 131072}]}`,
+			id: "accounts/fireworks/models/llama-v3p2-11b-vision-instruct", context: 131072,
+			modality: []string{"text", "vision"},
+		},
+		{
+			name: "mistral",
+			body: `{"object": "list", "data": [{"id": "mistral-large-latest", "name": "mistral-large-2411",
+				"max_context_length": 131072, "capabilities": {"function_calling": true, "vision": false}}]}`,
+			id: "mistral-large-latest", context: 131072,
+		},
+		{
+			name: "gemini",
+			body: `{"models": [{"name": "models/gemini-1.5-pro", "displayName": "Gemini 1.5 Pro",
+				"inputTokenLimit": 2000000, "outputTokenLimit": 8192}]}`,
+			id: "gemini-1.5-pro", display: "Gemini 1.5 Pro", context: 2000000, output: 8192,
+		},
+	}
+
+	for _, tt := range tests {
+		fetched, err := parseModelList([]byte(tt.body))
+		if err != nil || len(fetched) != 1 {
+			t.Fatalf("%s: expected one model, got %+v (%v)", tt.name, fetched, err)
+		}
+		m := fetched[0]
+		if m.ID != tt.id || m.DisplayName != tt.display || m.ContextLength != tt.context {
+			t.Errorf("%s: expected %s %q %d, got %s %q %d", tt.name, tt.id, tt.display, tt.context, m.ID, m.DisplayName, m.ContextLength)
+		}
+		if (m.MaxOutputTokens == nil && tt.output != 0) || (m.MaxOutputTokens != nil && *m.MaxOutputTokens != tt.output) {
+			t.Errorf("%s: expected max output %d, got %v", tt.name, tt.output, m.MaxOutputTokens)
+		}
+		if !reflect.DeepEqual(m.Pricing, tt.pricing) {
+			t.Errorf("%s: expected pricing %+v, got %+v", tt.name, tt.pricing, m.Pricing)
+		}
+		if !reflect.DeepEqual(m.Modalities, tt.modality) {
+			t.Errorf("%s: expected modalities %v, got %v", tt.name, tt.modality, m.Modalities)
+		}
+	}
+}
+
+func TestParseModelListCapabilities(t *testing.T) {
+	fetched, err := parseModelList([]byte(`{"data": [
+		{"id": "a", "created_at": "2024-10-22T00:00:00Z", "supported_parameters": ["tools", "reasoning"]},
+		{"id": "b", "capabilities": {"function_calling": true, "vision": true}},
+		{"id": ""}
+	]}`))
+	if err != nil || len(fetched) != 2 {
+		t.Fatalf("Expected two models, got %+v (%v)", fetched, err)
+	}
+	if fetched[0].Created != 1729555200 {
+		t.Errorf("Expected created_at as Unix time, got %d", fetched[0].Created)
+	}
+	if f := fetched[0].Features; f == nil || f.ToolCalling == nil || f.Reasoning == nil || f.Vision != nil {
+		t.Errorf("Expected tool calling and reasoning, got %+v", f)
+	}
+	if f := fetched[1].Features; f == nil || f.ToolCalling == nil || f.Vision == nil {
+		t.Errorf("Expected tool calling and vision, got %+v", f)
+	}
+
+	if _, err := parseModelList([]byte(`{"data": []}`)); err == nil {
+		t.Error("Expected an error for an empty list")
+	}
+}
+
+func TestTransformFetchedModel(t *testing.T) {
+	m := TransformFetchedModel(models.FetchedModel{ID: "gpt-4o-mini"})
+	if m.Name != "Gpt 4o Mini" || m.Context.MaxInput != defaultContextWindow || !reflect.DeepEqual(m.Modalities, []string{"text"}) {
+		t.Errorf("Expected defaults, got %+v", m)
+	}
+
+	output := 8192
+	m = TransformFetchedModel(models.FetchedModel{
+		ID:              "gemini-1.5-pro",
+		DisplayName:     "Gemini 1.5 Pro",
+		ContextLength:   2000000,
+		MaxOutputTokens: &output,
+		Pricing:         &models.Pricing{Input: 1.25, Output: 5, Currency: "USD"},
+		Modalities:      []string{"text", "vision"},
+	})
+	if m.Name != "Gemini 1.5 Pro" || m.Context.MaxInput != 2000000 || *m.Context.MaxOutput != 8192 || m.Pricing.Input != 1.25 {
+		t.Errorf("Expected the fetched metadata, got %+v", m)
+	}
+	if result := ValidateModel(&m); !result.Valid {
+		t.Errorf("Expected a valid model, got %+v", result.Errors)
+	}
+}
+
+func TestFetchModelsMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "llama3-70b-8192", "object": "model", "owned_by": "Meta",
+			"context_window": 8192, "max_completion_tokens": 4096}]}`))
+	}))
+	defer server.Close()
+
+	result := NewModelFetcher(nil).FetchModels(server.URL+"/v1", "sk-test", nil)
+	if result.Error != "" || len(result.Models) != 1 {
+		t.Fatalf("Expected one model, got %+v", result)
+	}
+	m := TransformFetchedModel(result.Models[0])
+	if m.Context.MaxInput != 8192 || m.Context.MaxOutput == nil || *m.Context.MaxOutput != 4096 {
+		t.Errorf("Expected Groq's context window, got %+v", m.Context)
+	}
+}
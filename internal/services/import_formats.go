@@ -0,0 +1,774 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// knownProvider is a provider other tools refer to by name
+type knownProvider struct {
+	name      string
+	endpoint  string
+	anthropic bool // endpoint is an Anthropic-style API
+}
+
+// knownProviders maps the provider names used by LiteLLM, Continue and Aider
+// to their display names and default endpoints
+var knownProviders = map[string]knownProvider{
+	"openai":       {name: "OpenAI", endpoint: "https://api.openai.com/v1"},
+	"anthropic":    {name: "Anthropic", endpoint: "https://api.anthropic.com/v1", anthropic: true},
+	"openrouter":   {name: "OpenRouter", endpoint: "https://openrouter.ai/api/v1"},
+	"groq":         {name: "Groq", endpoint: "https://api.groq.com/openai/v1"},
+	"mistral":      {name: "Mistral", endpoint: "https://api.mistral.ai/v1"},
+	"deepseek":     {name: "DeepSeek", endpoint: "https://api.deepseek.com/v1"},
+	"together":     {name: "Together AI", endpoint: "https://api.together.xyz/v1"},
+	"together_ai":  {name: "Together AI", endpoint: "https://api.together.xyz/v1"},
+	"fireworks_ai": {name: "Fireworks AI", endpoint: "https://api.fireworks.ai/inference/v1"},
+	"xai":          {name: "xAI", endpoint: "https://api.x.ai/v1"},
+	"gemini":       {name: "Google Gemini", endpoint: "https://generativelanguage.googleapis.com/v1beta/openai"},
+	"ollama":       {name: "Ollama", endpoint: "http://localhost:11434/v1"},
+	"lmstudio":     {name: "LM Studio", endpoint: "http://localhost:1234/v1"},
+	"azure":        {name: "Azure OpenAI"},
+}
+
+// readPlainImport reads an unencrypted import file, detects its format and
+// converts files from other tools into LLM Desk data
+func readPlainImport(path string) (*models.LLMDeskData, models.ImportFormat, []string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	modified := ""
+	if info, err := os.Stat(path); err == nil {
+		modified = info.ModTime().UTC().Format(time.RFC3339)
+	}
+	data, format, warnings, _, err := parsePlainImport(path, raw, modified)
+	return data, format, warnings, err
+}
+
+// parsePlainImport is readPlainImport for content already read. name is the
+// file name, used to recognise YAML; modified is the file's modification
+// time, or empty if unknown. For converted formats it also returns the
+// fields the source set, by provider ID.
+func parsePlainImport(name string, raw []byte, modified string) (*models.LLMDeskData, models.ImportFormat, []string, map[string]presentFields, error) {
+	format, doc, err := detectImportFormat(name, raw)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	if format == models.ImportFormatLLMDesk {
+		data, err := decodeLLMDeskData(raw, doc)
+		return data, format, nil, nil, err
+	}
+
+	catalog := newImportCatalog()
+	switch format {
+	case models.ImportFormatLiteLLM:
+		catalog.addLiteLLM(asMap(doc))
+	case models.ImportFormatContinue:
+		catalog.addContinue(asMap(doc))
+	case models.ImportFormatAider:
+		catalog.addAider(asMap(doc))
+	case models.ImportFormatOpenRouter:
+		catalog.addOpenRouter(doc)
+	}
+	if catalog.defaulted > 0 {
+		catalog.warn(fmt.Sprintf("%d models have no context window in the file; %d tokens was assumed", catalog.defaulted, defaultContextWindow))
+	}
+
+	description := fmt.Sprintf("Converted from %s", filepath.Base(name))
+	data := &models.LLMDeskData{
+		Version: schemaVersion,
+		Metadata: models.Metadata{
+			CreatedAt:   modified,
+			ModifiedAt:  modified,
+			Generator:   string(format),
+			Description: &description,
+		},
+		Providers: catalog.list(),
+	}
+	return data, format, catalog.warnings, catalog.present, nil
+}
+
+// decodeLLMDeskData checks an LLM Desk document against the data schema
+// before decoding it, so that mistakes are reported with their location.
+// doc is the already decoded document, or nil.
+func decodeLLMDeskData(raw []byte, doc interface{}) (*models.LLMDeskData, error) {
+	if doc == nil {
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+	}
+	if errs := ValidateData(doc); len(errs) > 0 {
+		return nil, &SchemaValidationError{Errors: errs}
+	}
+
+	var data models.LLMDeskData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// detectImportFormat parses an import file and works out which tool wrote it
+func detectImportFormat(path string, raw []byte) (models.ImportFormat, interface{}, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		doc, err := parseYAML(raw)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, ok := asMap(doc)["model_list"]; ok {
+			return models.ImportFormatLiteLLM, doc, nil
+		}
+		return "", nil, fmt.Errorf("YAML file has no LiteLLM model_list")
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		// Content without a file name may still be a LiteLLM YAML config
+		if filepath.Ext(path) == "" {
+			if yamlDoc, yamlErr := parseYAML(raw); yamlErr == nil {
+				if _, ok := asMap(yamlDoc)["model_list"]; ok {
+					return models.ImportFormatLiteLLM, yamlDoc, nil
+				}
+			}
+		}
+		return "", nil, err
+	}
+	if list, ok := doc.([]interface{}); ok && isOpenRouterList(list) {
+		return models.ImportFormatOpenRouter, doc, nil
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("unrecognized import file format")
+	}
+	_, hasProviders := obj["providers"]
+	_, hasVersion := obj["version"]
+	_, hasModelList := obj["model_list"]
+	switch {
+	case hasProviders || hasVersion:
+		return models.ImportFormatLLMDesk, doc, nil
+	case hasModelList:
+		return models.ImportFormatLiteLLM, doc, nil
+	case isOpenRouterList(asSlice(obj["data"])):
+		return models.ImportFormatOpenRouter, doc, nil
+	case isContinueConfig(obj):
+		return models.ImportFormatContinue, doc, nil
+	case isAiderMetadata(obj):
+		return models.ImportFormatAider, doc, nil
+	}
+	return "", nil, fmt.Errorf("unrecognized import file format")
+}
+
+// isOpenRouterList reports whether list looks like OpenRouter's model list
+func isOpenRouterList(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		m := asMap(item)
+		_, hasContext := m["context_length"]
+		_, hasPricing := m["pricing"]
+		if asString(m["id"]) == "" || (!hasContext && !hasPricing) {
+			return false
+		}
+	}
+	return true
+}
+
+// isContinueConfig reports whether obj looks like a Continue config.json
+func isContinueConfig(obj map[string]interface{}) bool {
+	list, ok := obj["models"].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := asMap(item)["provider"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isAiderMetadata reports whether obj looks like Aider's model metadata,
+// a map from LiteLLM model names to model info
+func isAiderMetadata(obj map[string]interface{}) bool {
+	if len(obj) == 0 {
+		return false
+	}
+	for _, v := range obj {
+		info, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		_, hasProvider := info["litellm_provider"]
+		_, hasMax := info["max_tokens"]
+		_, hasInput := info["max_input_tokens"]
+		_, hasCost := info["input_cost_per_token"]
+		if !hasProvider && !hasMax && !hasInput && !hasCost {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultContextWindow is assumed for imported and fetched models whose
+// context window is unknown
+const defaultContextWindow = 128000
+
+// importCatalog collects providers converted from another tool's config.
+// Providers are keyed by kind and endpoint, so entries that share them
+// become one provider.
+type importCatalog struct {
+	providers []*models.Provider
+	byKey     map[string]*models.Provider
+	present   map[string]presentFields // Fields taken from the source, by provider ID
+	warnings  []string
+	defaulted int // Models whose context window was assumed
+}
+
+func newImportCatalog() *importCatalog {
+	return &importCatalog{
+		byKey:    make(map[string]*models.Provider),
+		present:  make(map[string]presentFields),
+		warnings: []string{},
+	}
+}
+
+// warn records a conversion warning
+func (c *importCatalog) warn(message string) {
+	c.warnings = append(c.warnings, message)
+}
+
+// list returns the collected providers in the order they were first seen
+func (c *importCatalog) list() []models.Provider {
+	providers := make([]models.Provider, len(c.providers))
+	for i, p := range c.providers {
+		providers[i] = *p
+	}
+	return providers
+}
+
+// provider returns the provider of kind at baseURL, creating it on first use.
+// An empty baseURL means the kind's default endpoint.
+func (c *importCatalog) provider(kind, baseURL string) *models.Provider {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		kind = "openai"
+	}
+	known := knownProviders[kind]
+	baseURL = strings.TrimSpace(baseURL)
+	fromSource := baseURL != ""
+	if baseURL == "" {
+		baseURL = known.endpoint
+	}
+
+	key := kind + "|" + normalizeEndpoint(baseURL)
+	if p, ok := c.byKey[key]; ok {
+		return p
+	}
+
+	id := slugify(kind)
+	name := known.name
+	if name == "" {
+		name = formatModelName(kind)
+	}
+	if baseURL != "" && normalizeEndpoint(baseURL) != normalizeEndpoint(known.endpoint) {
+		if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+			id += "-" + slugify(u.Host)
+			name += " (" + u.Host + ")"
+		}
+	}
+
+	p := &models.Provider{
+		ID:          id,
+		Name:        name,
+		Enabled:     true,
+		Credentials: models.Credentials{APIKeys: []models.APIKey{}},
+		Limits:      []models.Limit{},
+		Models:      []models.Model{},
+	}
+	fields := presentFields{provider: map[string]bool{"id": true}, models: map[string]map[string]bool{}}
+	if known.anthropic {
+		endpoint := baseURL
+		p.Endpoints.Anthropic = &endpoint
+		fields.provider["endpoints.anthropic"] = fromSource
+	} else {
+		p.Endpoints.OpenAI = baseURL
+		fields.provider["endpoints.openai"] = fromSource
+	}
+	if _, ok := c.present[id]; !ok {
+		c.present[id] = fields
+	}
+	c.byKey[key] = p
+	c.providers = append(c.providers, p)
+	return p
+}
+
+// addKey adds secret to p unless it is empty, already present or a reference
+// to an environment variable, which is reported instead
+func (c *importCatalog) addKey(p *models.Provider, secret string) {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return
+	}
+	if name, ok := envReference(secret); ok {
+		c.warn(fmt.Sprintf("%s: the API key is read from the environment variable %s and was not imported", p.Name, name))
+		return
+	}
+	for _, k := range p.Credentials.APIKeys {
+		if k.Key == secret {
+			return
+		}
+	}
+	p.Credentials.APIKeys = append(p.Credentials.APIKeys, models.NewAPIKey(secret))
+}
+
+// addModel adds m to p unless p already has a model with its ID. A model
+// without a context window gets the default one. set lists the fields the
+// source gave, for merging.
+func (c *importCatalog) addModel(p *models.Provider, m models.Model, set map[string]bool) {
+	for _, existing := range p.Models {
+		if existing.ID == m.ID {
+			return
+		}
+	}
+	if m.Context.MaxInput == 0 {
+		m.Context.MaxInput = defaultContextWindow
+		c.defaulted++
+	}
+	p.Models = append(p.Models, m)
+	if fields, ok := c.present[p.ID]; ok {
+		if _, taken := fields.models[m.ID]; !taken {
+			fields.models[m.ID] = set
+		}
+	}
+}
+
+// envReferencePattern matches ${NAME} and ${{ secrets.NAME }} references
+var envReferencePattern = regexp.MustCompile(`^\$\{\{?\s*(?:secrets\.|env\.)?([A-Za-z_][A-Za-z0-9_]*)\s*\}?\}$`)
+
+// envReference returns the variable a key refers to instead of holding a
+// secret, as in LiteLLM's "os.environ/NAME"
+func envReference(secret string) (string, bool) {
+	if name, ok := strings.CutPrefix(secret, "os.environ/"); ok {
+		return name, true
+	}
+	if m := envReferencePattern.FindStringSubmatch(secret); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+// newImportedModel returns a model with the defaults of a fetched model and
+// no context window, and the fields set from the source so far
+func newImportedModel(id, name string) (models.Model, map[string]bool) {
+	m := TransformFetchedModel(models.FetchedModel{ID: id})
+	m.Context.MaxInput = 0
+	set := map[string]bool{"id": true}
+	if name = strings.TrimSpace(name); name != "" {
+		m.Name = name
+		set["name"] = true
+	}
+	return m, set
+}
+
+// addLiteLLM converts a LiteLLM proxy config's model_list
+func (c *importCatalog) addLiteLLM(doc map[string]interface{}) {
+	for i, entry := range asSlice(doc["model_list"]) {
+		e := asMap(entry)
+		params := asMap(e["litellm_params"])
+		target := asString(params["model"])
+		if target == "" {
+			c.warn(fmt.Sprintf("model_list[%d] has no litellm_params.model and was skipped", i))
+			continue
+		}
+		kind, id := splitProviderPrefix(target)
+		baseURL := asString(params["api_base"])
+		if baseURL == "" {
+			baseURL = asString(params["base_url"])
+		}
+
+		p := c.provider(kind, baseURL)
+		c.addKey(p, asString(params["api_key"]))
+		m, set := newImportedModel(id, asString(e["model_name"]))
+		applyLiteLLMInfo(&m, set, params)
+		applyLiteLLMInfo(&m, set, asMap(e["model_info"]))
+		c.addModel(p, m, set)
+	}
+}
+
+// addContinue converts the models of a Continue config.json
+func (c *importCatalog) addContinue(doc map[string]interface{}) {
+	entries := asSlice(doc["models"])
+	switch tab := doc["tabAutocompleteModel"].(type) {
+	case map[string]interface{}:
+		entries = append(entries, tab)
+	case []interface{}:
+		entries = append(entries, tab...)
+	}
+
+	for i, entry := range entries {
+		e := asMap(entry)
+		id := asString(e["model"])
+		if id == "" {
+			c.warn(fmt.Sprintf("models[%d] has no model ID and was skipped", i))
+			continue
+		}
+		p := c.provider(asString(e["provider"]), asString(e["apiBase"]))
+		c.addKey(p, asString(e["apiKey"]))
+		m, set := newImportedModel(id, asString(e["title"]))
+		if n, ok := asInt(e["contextLength"]); ok {
+			m.Context.MaxInput = n
+			set["context.maxInput"] = true
+		}
+		if n, ok := asInt(asMap(e["completionOptions"])["maxTokens"]); ok {
+			m.Context.MaxOutput = &n
+			set["context.maxOutput"] = true
+		}
+		c.addModel(p, m, set)
+	}
+}
+
+// addAider converts Aider's model metadata. The file has no endpoints or
+// keys, so providers get their default endpoints.
+func (c *importCatalog) addAider(doc map[string]interface{}) {
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		info := asMap(doc[name])
+		kind, id := splitProviderPrefix(name)
+		if provider := asString(info["litellm_provider"]); provider != "" && provider != kind {
+			kind, id = provider, name
+		}
+		p := c.provider(kind, "")
+		m, set := newImportedModel(id, "")
+		applyLiteLLMInfo(&m, set, info)
+		c.addModel(p, m, set)
+	}
+}
+
+// addOpenRouter converts an OpenRouter-style model list, either the bare
+// array or the API's {"data": [...]} response
+func (c *importCatalog) addOpenRouter(doc interface{}) {
+	list, ok := doc.([]interface{})
+	if !ok {
+		list = asSlice(asMap(doc)["data"])
+	}
+
+	p := c.provider("openrouter", "")
+	for _, item := range list {
+		e := asMap(item)
+		m, set := newImportedModel(asString(e["id"]), asString(e["name"]))
+		if n, ok := asInt(e["context_length"]); ok {
+			m.Context.MaxInput = n
+			set["context.maxInput"] = true
+		}
+		if n, ok := asInt(asMap(e["top_provider"])["max_completion_tokens"]); ok {
+			m.Context.MaxOutput = &n
+			set["context.maxOutput"] = true
+		}
+
+		// Prices are per token; negative prices mean the price varies
+		pricing := asMap(e["pricing"])
+		if v, ok := asFloat(pricing["prompt"]); ok && v >= 0 {
+			m.Pricing.Input = perMillion(v)
+			set["pricing.input"] = true
+		}
+		if v, ok := asFloat(pricing["completion"]); ok && v >= 0 {
+			m.Pricing.Output = perMillion(v)
+			set["pricing.output"] = true
+		}
+		if v, ok := asFloat(pricing["input_cache_read"]); ok && v >= 0 {
+			cached := perMillion(v)
+			m.Pricing.Cached = &cached
+			set["pricing.cached"] = true
+		}
+
+		if modalities := modalitiesFrom(asSlice(asMap(e["architecture"])["input_modalities"])); len(modalities) > 0 {
+			m.Modalities = modalities
+			set["modalities"] = true
+		}
+		for _, param := range asSlice(e["supported_parameters"]) {
+			switch asString(param) {
+			case "tools":
+				setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.ToolCalling })
+				set["features.toolCalling"] = true
+			case "reasoning":
+				setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Reasoning })
+				set["features.reasoning"] = true
+			}
+		}
+		c.addModel(p, m, set)
+	}
+}
+
+// applyLiteLLMInfo copies the context window, prices and capabilities from a
+// LiteLLM model info map, as used by LiteLLM and Aider, and adds the fields
+// it set to set
+func applyLiteLLMInfo(m *models.Model, set map[string]bool, info map[string]interface{}) {
+	if n, ok := asInt(info["max_input_tokens"]); ok {
+		m.Context.MaxInput = n
+		set["context.maxInput"] = true
+	} else if n, ok := asInt(info["max_tokens"]); ok && m.Context.MaxInput == 0 {
+		m.Context.MaxInput = n
+		set["context.maxInput"] = true
+	}
+	if n, ok := asInt(info["max_output_tokens"]); ok {
+		m.Context.MaxOutput = &n
+		set["context.maxOutput"] = true
+	}
+	if v, ok := asFloat(info["input_cost_per_token"]); ok {
+		m.Pricing.Input = perMillion(v)
+		set["pricing.input"] = true
+	}
+	if v, ok := asFloat(info["output_cost_per_token"]); ok {
+		m.Pricing.Output = perMillion(v)
+		set["pricing.output"] = true
+	}
+	if v, ok := asFloat(info["cache_read_input_token_cost"]); ok {
+		cached := perMillion(v)
+		m.Pricing.Cached = &cached
+		set["pricing.cached"] = true
+	}
+
+	if asBool(info["supports_function_calling"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.ToolCalling })
+		set["features.toolCalling"] = true
+	}
+	if asBool(info["supports_reasoning"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Reasoning })
+		set["features.reasoning"] = true
+	}
+	if asBool(info["supports_web_search"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Search })
+		set["features.search"] = true
+	}
+	if asBool(info["supports_vision"]) {
+		setFeature(&m.Features, func(f *models.ModelFeatures) **bool { return &f.Vision })
+		m.Modalities = appendModality(m.Modalities, "vision")
+		set["features.vision"] = true
+		set["modalities"] = true
+	}
+	if asBool(info["supports_audio_input"]) {
+		m.Modalities = appendModality(m.Modalities, "audio")
+		set["modalities"] = true
+	}
+}
+
+// setFeature turns on the feature field selects, creating features if needed
+func setFeature(features **models.ModelFeatures, field func(*models.ModelFeatures) **bool) {
+	if *features == nil {
+		*features = &models.ModelFeatures{}
+	}
+	enabled := true
+	*field(*features) = &enabled
+}
+
+// modalitiesFrom maps other tools' input modalities to LLM Desk's
+func modalitiesFrom(values []interface{}) []string {
+	var modalities []string
+	for _, v := range values {
+		switch asString(v) {
+		case "text":
+			modalities = appendModality(modalities, "text")
+		case "image":
+			modalities = appendModality(modalities, "vision")
+		case "audio":
+			modalities = appendModality(modalities, "audio")
+		case "video":
+			modalities = appendModality(modalities, "video")
+		}
+	}
+	return modalities
+}
+
+// appendModality adds modality unless it is already listed
+func appendModality(modalities []string, modality string) []string {
+	for _, m := range modalities {
+		if m == modality {
+			return modalities
+		}
+	}
+	return append(modalities, modality)
+}
+
+// splitProviderPrefix splits a LiteLLM model name such as "openai/gpt-4o"
+// into its provider and model ID. Names without a prefix are OpenAI models.
+func splitProviderPrefix(name string) (string, string) {
+	kind, id, ok := strings.Cut(strings.TrimSpace(name), "/")
+	if !ok {
+		return "openai", kind
+	}
+	return kind, id
+}
+
+// perMillion converts a per-token price to the per-million-token prices
+// LLM Desk stores, rounded to a millionth of a unit
+func perMillion(perToken float64) float64 {
+	return math.Round(perToken*1e12) / 1e6
+}
+
+// slugPattern matches runs of characters not allowed in generated IDs
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify lowercases s and replaces other characters with dashes
+func slugify(s string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// parseYAML decodes a YAML document into the values encoding/json produces,
+// except that numbers become json.Number so their text survives ("3.10")
+func parseYAML(data []byte) (interface{}, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	return yamlValue(root.Content[0])
+}
+
+// yamlValue converts a decoded YAML node
+func yamlValue(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			v, err := yamlValue(value)
+			if err != nil {
+				return nil, err
+			}
+			if key.Tag == "!!merge" {
+				// "<<: *defaults" fills in keys the mapping does not set
+				for k, inherited := range asMap(v) {
+					if _, ok := m[k]; !ok {
+						m[k] = inherited
+					}
+				}
+				continue
+			}
+			if _, ok := m[key.Value]; ok {
+				return nil, fmt.Errorf("yaml: line %d: key %q is already defined", key.Line, key.Value)
+			}
+			m[key.Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(n.Content))
+		for _, item := range n.Content {
+			v, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		err := n.Decode(&b)
+		return b, err
+	case "!!int", "!!float":
+		var f float64
+		if err := n.Decode(&f); err != nil {
+			return nil, err
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return n.Value, nil
+		}
+		if _, err := strconv.ParseFloat(n.Value, 64); err == nil {
+			return json.Number(n.Value), nil
+		}
+		// Hexadecimal, octal and underscored forms
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64)), nil
+	}
+	return n.Value, nil
+}
+
+// asMap returns v as a JSON object, or nil
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// asSlice returns v as a JSON array, or nil
+func asSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+// asString returns a string or number as a string
+func asString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// asFloat returns a finite number, or a string holding one, as a float64
+func asFloat(v interface{}) (float64, bool) {
+	var f float64
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case string, json.Number:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	// YAML and numeric strings can spell out nan and inf, which JSON cannot hold
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// asInt returns a positive whole number as an int
+func asInt(v interface{}) (int, bool) {
+	f, ok := asFloat(v)
+	if !ok || f <= 0 || f != math.Trunc(f) || f > math.MaxInt32 {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// asBool reports whether v is true
+func asBool(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// writeImportFixture writes content to name in a temporary directory
+func writeImportFixture(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	return path
+}
+
+// findProvider returns the provider with id, failing the test if missing
+func findProvider(t *testing.T, providers []models.Provider, id string) models.Provider {
+	t.Helper()
+	for _, p := range providers {
+		if p.ID == id {
+			return p
+		}
+	}
+	t.Fatalf("Expected provider %q, got %+v", id, providers)
+	return models.Provider{}
+}
+
+const liteLLMConfig = `model_list:
+  - model_name: GPT-4o
+    litellm_params:
+      model: openai/gpt-4o
+      api_key: sk-literal
+    model_info:
+      max_input_tokens: 128000
+      max_output_tokens: 16384
+      input_cost_per_token: 0.0000025
+      output_cost_per_token: 0.00001
+      supports_vision: true
+  - model_name: GPT-4o mini
+    litellm_params:
+      model: openai/gpt-4o-mini
+      api_key: os.environ/OPENAI_API_KEY
+  - model_name: Team Llama
+    litellm_params:
+      model: openai/llama-3-70b
+      api_base: https://llm.example.com/v1
+      api_key: sk-team
+    model_info:
+      max_tokens: 8192
+`
+
+func TestReadPlainImport_LiteLLM(t *testing.T) {
+	data, format, warnings, err := readPlainImport(writeImportFixture(t, "config.yaml", liteLLMConfig))
+	if err != nil {
+		t.Fatalf("readPlainImport failed: %v", err)
+	}
+	if format != models.ImportFormatLiteLLM || data.Version != schemaVersion {
+		t.Errorf("Expected a converted LiteLLM file, got %q %q", format, data.Version)
+	}
+	if len(data.Providers) != 2 {
+		t.Fatalf("Expected providers grouped by endpoint, got %+v", data.Providers)
+	}
+
+	openai := findProvider(t, data.Providers, "openai")
+	if openai.Endpoints.OpenAI != "https://api.openai.com/v1" || len(openai.Credentials.APIKeys) != 1 || openai.Credentials.APIKeys[0].Key != "sk-literal" {
+		t.Errorf("Unexpected openai provider: %+v", openai)
+	}
+	gpt := openai.Models[0]
+	if gpt.ID != "gpt-4o" || gpt.Name != "GPT-4o" || gpt.Context.MaxInput != 128000 || *gpt.Context.MaxOutput != 16384 {
+		t.Errorf("Unexpected model: %+v", gpt)
+	}
+	if gpt.Pricing.Input != 2.5 || gpt.Pricing.Output != 10 {
+		t.Errorf("Expected per-million prices, got %+v", gpt.Pricing)
+	}
+	if gpt.Features == nil || gpt.Features.Vision == nil || !*gpt.Features.Vision {
+		t.Errorf("Expected vision support, got %+v", gpt.Features)
+	}
+	if openai.Models[1].Context.MaxInput != defaultContextWindow {
+		t.Errorf("Expected default context window, got %d", openai.Models[1].Context.MaxInput)
+	}
+
+	team := findProvider(t, data.Providers, "openai-llm-example-com")
+	if team.Endpoints.OpenAI != "https://llm.example.com/v1" || team.Models[0].Context.MaxInput != 8192 {
+		t.Errorf("Unexpected team provider: %+v", team)
+	}
+
+	if !strings.Contains(strings.Join(warnings, "\n"), "OPENAI_API_KEY") {
+		t.Errorf("Expected a warning about the environment key, got %v", warnings)
+	}
+}
+
+func TestReadPlainImport_NonFinitePrices(t *testing.T) {
+	data, _, _, err := readPlainImport(writeImportFixture(t, "config.yaml", `model_list:
+  - model_name: odd
+    litellm_params:
+      model: openai/odd
+    model_info:
+      input_cost_per_token: nan
+      output_cost_per_token: inf
+`))
+	if err != nil {
+		t.Fatalf("readPlainImport failed: %v", err)
+	}
+	m := data.Providers[0].Models[0]
+	if m.Pricing.Input != 0 || m.Pricing.Output != 0 {
+		t.Errorf("Expected non-finite prices to be ignored, got %+v", m.Pricing)
+	}
+	if _, err := json.Marshal(data); err != nil {
+		t.Errorf("Expected the converted file to be savable, got %v", err)
+	}
+}
+
+func TestReadPlainImport_Continue(t *testing.T) {
+	path := writeImportFixture(t, "config.json", `{
+		"models": [
+			{"title": "Claude", "provider": "anthropic", "model": "claude-sonnet-4", "apiKey": "sk-ant", "contextLength": 200000,
+			 "completionOptions": {"maxTokens": 8192}},
+			{"title": "Local", "provider": "ollama", "model": "llama3", "apiBase": "http://gpu-box:11434/v1"}
+		],
+		"tabAutocompleteModel": {"title": "Autocomplete", "provider": "ollama", "model": "qwen-coder", "apiBase": "http://gpu-box:11434/v1"}
+	}`)
+	data, format, _, err := readPlainImport(path)
+	if err != nil || format != models.ImportFormatContinue {
+		t.Fatalf("Expected a Continue file, got %q (%v)", format, err)
+	}
+
+	anthropic := findProvider(t, data.Providers, "anthropic")
+	if anthropic.Endpoints.Anthropic == nil || *anthropic.Endpoints.Anthropic != "https://api.anthropic.com/v1" {
+		t.Errorf("Expected the Anthropic endpoint, got %+v", anthropic.Endpoints)
+	}
+	claude := anthropic.Models[0]
+	if claude.Context.MaxInput != 200000 || *claude.Context.MaxOutput != 8192 || anthropic.Credentials.APIKeys[0].Key != "sk-ant" {
+		t.Errorf("Unexpected Claude import: %+v", anthropic)
+	}
+
+	ollama := findProvider(t, data.Providers, "ollama-gpu-box-11434")
+	if len(ollama.Models) != 2 {
+		t.Errorf("Expected chat and autocomplete models on one provider, got %+v", ollama.Models)
+	}
+}
+
+func TestReadPlainImport_Aider(t *testing.T) {
+	path := writeImportFixture(t, ".aider.model.metadata.json", `{
+		"openrouter/deepseek/deepseek-chat": {"max_tokens": 8192, "max_input_tokens": 64000, "input_cost_per_token": 0.00000014,
+			"output_cost_per_token": 0.00000028, "litellm_provider": "openrouter", "mode": "chat"},
+		"gpt-4o": {"max_input_tokens": 128000, "litellm_provider": "openai", "supports_function_calling": true}
+	}`)
+	data, format, _, err := readPlainImport(path)
+	if err != nil || format != models.ImportFormatAider {
+		t.Fatalf("Expected an Aider file, got %q (%v)", format, err)
+	}
+
+	openrouter := findProvider(t, data.Providers, "openrouter")
+	deepseek := openrouter.Models[0]
+	if deepseek.ID != "deepseek/deepseek-chat" || deepseek.Context.MaxInput != 64000 || deepseek.Pricing.Input != 0.14 {
+		t.Errorf("Unexpected model: %+v", deepseek)
+	}
+	gpt := findProvider(t, data.Providers, "openai").Models[0]
+	if gpt.ID != "gpt-4o" || gpt.Features == nil || gpt.Features.ToolCalling == nil {
+		t.Errorf("Unexpected model: %+v", gpt)
+	}
+}
+
+func TestReadPlainImport_OpenRouter(t *testing.T) {
+	path := writeImportFixture(t, "models.json", `{"data": [
+		{"id": "openai/gpt-4o", "name": "OpenAI: GPT-4o", "context_length": 128000,
+		 "pricing": {"prompt": "0.0000025", "completion": "0.00001", "input_cache_read": "0.00000125"},
+		 "top_provider": {"max_completion_tokens": 16384},
+		 "architecture": {"input_modalities": ["text", "image", "file"]},
+		 "supported_parameters": ["tools", "temperature"]},
+		{"id": "openrouter/auto", "name": "Auto Router", "context_length": 2000000, "pricing": {"prompt": "-1", "completion": "-1"}}
+	]}`)
+	data, format, _, err := readPlainImport(path)
+	if err != nil || format != models.ImportFormatOpenRouter {
+		t.Fatalf("Expected an OpenRouter file, got %q (%v)", format, err)
+	}
+	if len(data.Providers) != 1 || data.Providers[0].Endpoints.OpenAI != "https://openrouter.ai/api/v1" {
+		t.Fatalf("Expected one OpenRouter provider, got %+v", data.Providers)
+	}
+
+	gpt := data.Providers[0].Models[0]
+	if gpt.Pricing.Input != 2.5 || gpt.Pricing.Output != 10 || *gpt.Pricing.Cached != 1.25 {
+		t.Errorf("Unexpected pricing: %+v", gpt.Pricing)
+	}
+	if len(gpt.Modalities) != 2 || gpt.Modalities[1] != "vision" || gpt.Features.ToolCalling == nil {
+		t.Errorf("Unexpected capabilities: %+v %+v", gpt.Modalities, gpt.Features)
+	}
+	if auto := data.Providers[0].Models[1]; auto.Pricing.Input != 0 {
+		t.Errorf("Expected variable pricing to be left empty, got %+v", auto.Pricing)
+	}
+}
+
+func TestReadPlainImport_Unrecognized(t *testing.T) {
+	if _, _, _, err := readPlainImport(writeImportFixture(t, "other.json", `{"hello": "world"}`)); err == nil {
+		t.Error("Expected an unrecognized file to be rejected")
+	}
+	if _, _, _, err := readPlainImport(writeImportFixture(t, "other.yaml", "hello: world\n")); err == nil {
+		t.Error("Expected YAML without model_list to be rejected")
+	}
+}
+
+func TestImportPreview_ForeignFormat(t *testing.T) {
+	store, err := storage.NewWithDir(filepath.Join(t.TempDir(), "data"), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	err = store.Save([]models.Provider{{
+		ID:        "openai",
+		Name:      "OpenAI",
+		Endpoints: models.Endpoints{OpenAI: "https://api.openai.com/v1"},
+		Models:    []models.Model{{ID: "o1", Name: "o1", Context: models.Context{MaxInput: 200000}}},
+	}})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	service := NewExportService(store)
+
+	preview, _ := service.previewFile(writeImportFixture(t, "config.yaml", liteLLMConfig), "merge", "", models.MergeOptions{})
+	if preview.Format != models.ImportFormatLiteLLM || preview.Summary.ProvidersAdded != 1 || preview.Summary.ModelsAdded != 3 {
+		t.Fatalf("Unexpected preview: %+v", preview)
+	}
+
+	result, _ := service.CommitImport(preview.Token, models.ImportSelection{Providers: []string{"openai", "openai-llm-example-com"}})
+	if !result.Success || result.Format != models.ImportFormatLiteLLM {
+		t.Fatalf("CommitImport failed: %+v", result)
+	}
+	providers, _ := store.Load()
+	openai := findProvider(t, providers, "openai")
+	if len(openai.Models) != 3 || len(openai.Credentials.APIKeys) != 1 {
+		t.Errorf("Expected models and key merged into openai, got %+v", openai)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	doc := `
+# LiteLLM proxy config
+defaults: &defaults
+  rpm: 60
+model_list:
+  - model_name: "gpt-4o"   # alias
+    litellm_params:
+      <<: *defaults
+      model: openai/gpt-4o
+      api_key: 'sk-it''s'
+      api_version: 3.10
+  -
+    model_name: local
+    tags: [fast, "cheap", 0x10]
+general_settings:
+  master_key: sk-1234 # not a comment#inside
+  flags: {debug: true, level: 2}
+  empty:
+  note: |
+    line one
+      indented
+  escaped: "a\\b\u00e9"
+`
+	got, err := parseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseYAML failed: %v", err)
+	}
+	want := map[string]interface{}{
+		"defaults": map[string]interface{}{"rpm": json.Number("60")},
+		"model_list": []interface{}{
+			map[string]interface{}{
+				"model_name": "gpt-4o",
+				"litellm_params": map[string]interface{}{
+					"model":       "openai/gpt-4o",
+					"api_key":     "sk-it's",
+					"api_version": json.Number("3.10"),
+					"rpm":         json.Number("60"),
+				},
+			},
+			map[string]interface{}{
+				"model_name": "local",
+				"tags":       []interface{}{"fast", "cheap", json.Number("16")},
+			},
+		},
+		"general_settings": map[string]interface{}{
+			"master_key": "sk-1234",
+			"flags":      map[string]interface{}{"debug": true, "level": json.Number("2")},
+			"empty":      nil,
+			"note":       "line one\n  indented\n",
+			"escaped":    "a\\bé",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+	if v := asMap(asSlice(asMap(got)["model_list"])[0])["litellm_params"]; asString(asMap(v)["api_version"]) != "3.10" {
+		t.Errorf("Expected the number's text to survive, got %v", v)
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	for _, doc := range []string{
+		"a: 1\n   b: 2\n",
+		"a: 1\na: 2\n",
+		"a:\n\t- b\n",
+		"a: 'open\n",
+	} {
+		if _, err := parseYAML([]byte(doc)); err == nil {
+			t.Errorf("Expected an error for %q", doc)
+		}
+	}
+}
@@ -0,0 +1,510 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"llm-desk/internal/models"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// importPreviewTTL is how long a preview token can be committed
+const importPreviewTTL = 15 * time.Minute
+
+// importPreview is a parsed import waiting to be committed
+type importPreview struct {
+	mode    models.ImportMode
+	opts    models.MergeOptions
+	loaded  *loadedImport
+	expires time.Time
+}
+
+// PreviewImport parses a user-selected backup and lists what importing it
+// in mode would change, without applying anything. opts sets the merge
+// policy and which duplicates to merge. Encrypted files set
+// PassphraseRequired; PreviewImportWithPassphrase continues with them.
+func (s *ExportService) PreviewImport(mode string, opts models.MergeOptions) (models.ImportPreview, error) {
+	filepath, err := s.fileDialogs().OpenFile(runtime.OpenDialogOptions{
+		Title: "Preview LLM Desk Import",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "LLM Desk Backups (*.json, *.enc)", Pattern: "*.json;*.enc"},
+			{DisplayName: "LiteLLM, Continue, Aider or OpenRouter Files (*.yaml, *.yml, *.json)", Pattern: "*.yaml;*.yml;*.json"},
+			{DisplayName: "All Files", Pattern: "*"},
+		},
+	})
+	if err != nil {
+		return models.ImportPreview{Message: err.Error(), Warnings: []string{}}, err
+	}
+
+	// User cancelled
+	if filepath == "" {
+		return models.ImportPreview{Message: "Import cancelled", Warnings: []string{}}, nil
+	}
+
+	return s.previewFile(filepath, mode, "", opts)
+}
+
+// PreviewImportWithPassphrase previews the encrypted backup selected by
+// PreviewImport or ImportData
+func (s *ExportService) PreviewImportWithPassphrase(mode, passphrase string, opts models.MergeOptions) (models.ImportPreview, error) {
+	s.mu.Lock()
+	filepath := s.pendingImport
+	s.mu.Unlock()
+
+	if filepath == "" {
+		return models.ImportPreview{Message: "No encrypted import is waiting for a passphrase", Warnings: []string{}}, nil
+	}
+	return s.previewFile(filepath, mode, passphrase, opts)
+}
+
+// previewFile reads a backup file and diffs it against the current catalog
+func (s *ExportService) previewFile(filepath, mode, passphrase string, opts models.MergeOptions) (models.ImportPreview, error) {
+	importMode := models.ImportMode(mode)
+	if importMode != models.ImportModeReplace && importMode != models.ImportModeMerge {
+		return models.ImportPreview{Message: "Invalid import mode: " + mode, Warnings: []string{}}, nil
+	}
+	opts, err := normalizeMergeOptions(opts)
+	if err != nil {
+		return models.ImportPreview{Message: err.Error(), Warnings: []string{}}, nil
+	}
+
+	loaded, failed := s.readImportFile(filepath, passphrase)
+	if failed != nil {
+		return models.ImportPreview{
+			Mode:               importMode,
+			Message:            failed.Message,
+			Warnings:           failed.Warnings,
+			PassphraseRequired: failed.PassphraseRequired,
+			Encrypted:          failed.Encrypted,
+		}, nil
+	}
+
+	current, err := s.storage.Load()
+	if err != nil {
+		return models.ImportPreview{Message: "Failed to load current data: " + err.Error(), Warnings: []string{}}, err
+	}
+
+	target, conflicts := importTarget(current, loaded, importMode, opts, s.mergeTimes(importMode, opts))
+	diffs, summary := diffCatalog(current, target)
+	duplicates := []models.DuplicateProvider{}
+	if importMode == models.ImportModeMerge {
+		for _, d := range findDuplicates(current, loaded.data.Providers) {
+			if _, merging := opts.MergeDuplicates[d.IncomingID]; !merging {
+				duplicates = append(duplicates, d)
+			}
+		}
+	}
+
+	token, err := newPreviewToken()
+	if err != nil {
+		return models.ImportPreview{Message: err.Error(), Warnings: []string{}}, err
+	}
+	expires := time.Now().Add(importPreviewTTL)
+
+	s.mu.Lock()
+	s.pruneExpiredPreviews()
+	s.previews[token] = &importPreview{mode: importMode, opts: opts, loaded: loaded, expires: expires}
+	s.mu.Unlock()
+
+	return models.ImportPreview{
+		Token:                    token,
+		Mode:                     importMode,
+		Format:                   loaded.format,
+		Message:                  fmt.Sprintf("%d providers would change", len(diffs)),
+		Warnings:                 append(append([]string{}, loaded.warnings...), mergeWarningTexts(conflicts, nil)...),
+		Encrypted:                loaded.encrypted,
+		ContainsPlaintextSecrets: loaded.plaintextSecrets,
+		Policy:                   opts.Policy,
+		Providers:                diffs,
+		Duplicates:               duplicates,
+		Issues:                   loaded.issues,
+		Summary:                  summary,
+		ExpiresAt:                expires.Format(time.RFC3339),
+	}, nil
+}
+
+// CommitImport applies the selected changes of a preview. The diff is
+// recomputed against the catalog at commit time, so edits made since the
+// preview are not lost. A token can be committed once.
+func (s *ExportService) CommitImport(token string, selection models.ImportSelection) (models.ImportResult, error) {
+	// The preview is taken out while it is committed so it cannot be
+	// committed twice, and put back if the commit fails so it can be retried
+	s.mu.Lock()
+	s.pruneExpiredPreviews()
+	preview, ok := s.previews[token]
+	delete(s.previews, token)
+	s.mu.Unlock()
+
+	if !ok {
+		return models.ImportResult{
+			Success:  false,
+			Message:  "Import preview not found or expired",
+			Warnings: []string{},
+		}, nil
+	}
+
+	selected := make(map[string]bool, len(selection.Providers))
+	for _, id := range selection.Providers {
+		selected[id] = true
+	}
+	times := s.mergeTimes(preview.mode, preview.opts)
+	var providerCount, modelCount int
+	var warnings []string
+	err := s.storage.UpdateOp("import:"+string(preview.mode), func(currentProviders *[]models.Provider) error {
+		target, conflicts := importTarget(*currentProviders, preview.loaded, preview.mode, preview.opts, times)
+		warnings = mergeWarningTexts(conflicts, func(id string) bool { return selected[id] })
+		diffs, _ := diffCatalog(*currentProviders, target)
+		providerCount, modelCount = countSelected(diffs, selection)
+		*currentProviders = applySelection(*currentProviders, target, selection)
+		return nil
+	})
+	if err != nil {
+		s.mu.Lock()
+		s.previews[token] = preview
+		s.mu.Unlock()
+		return models.ImportResult{
+			Success:  false,
+			Message:  "Failed to save imported data: " + err.Error(),
+			Warnings: []string{},
+		}, err
+	}
+
+	result := importResult(preview.loaded, providerCount, modelCount)
+	result.Warnings = append(result.Warnings, warnings...)
+	s.quarantine(preview.loaded, &result)
+	return result, nil
+}
+
+// DiscardImportPreview forgets a preview that will not be committed
+func (s *ExportService) DiscardImportPreview(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.previews, token)
+}
+
+// pruneExpiredPreviews drops previews past their TTL
+// NOTE: Caller MUST hold s.mu
+func (s *ExportService) pruneExpiredPreviews() {
+	now := time.Now()
+	for token, p := range s.previews {
+		if now.After(p.expires) {
+			delete(s.previews, token)
+		}
+	}
+}
+
+// newPreviewToken returns a random preview token
+func newPreviewToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate preview token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// modelSelected reports whether the change to modelID of providerID is selected
+func modelSelected(selection models.ImportSelection, providerID, modelID string) bool {
+	ids, narrowed := selection.Models[providerID]
+	if !narrowed {
+		return true
+	}
+	for _, id := range ids {
+		if id == modelID {
+			return true
+		}
+	}
+	return false
+}
+
+// applySelection moves current towards target for the selected providers
+// and models only. Unselected models keep their current state.
+func applySelection(current, target []models.Provider, selection models.ImportSelection) []models.Provider {
+	selected := make(map[string]bool, len(selection.Providers))
+	for _, id := range selection.Providers {
+		selected[id] = true
+	}
+	targetByID := make(map[string]models.Provider, len(target))
+	for _, p := range target {
+		targetByID[p.ID] = p
+	}
+
+	result := make([]models.Provider, 0, len(target))
+	existing := make(map[string]bool, len(current))
+	for _, cur := range current {
+		existing[cur.ID] = true
+		tgt, inTarget := targetByID[cur.ID]
+		switch {
+		case !selected[cur.ID]:
+			result = append(result, cur)
+		case !inTarget:
+			// Removal selected
+		default:
+			result = append(result, mergeSelectedModels(cur, tgt, selection))
+		}
+	}
+
+	for _, tgt := range target {
+		if existing[tgt.ID] || !selected[tgt.ID] {
+			continue
+		}
+		added := tgt
+		added.Models = nil
+		for _, m := range tgt.Models {
+			if modelSelected(selection, tgt.ID, m.ID) {
+				added.Models = append(added.Models, m)
+			}
+		}
+		result = append(result, added)
+	}
+	return result
+}
+
+// mergeSelectedModels takes tgt's provider fields, and tgt's version of each
+// selected model while keeping cur's version of the others
+func mergeSelectedModels(cur, tgt models.Provider, selection models.ImportSelection) models.Provider {
+	curModels := make(map[string]models.Model, len(cur.Models))
+	for _, m := range cur.Models {
+		curModels[m.ID] = m
+	}
+
+	merged := tgt
+	merged.Models = nil
+	inTarget := make(map[string]bool, len(tgt.Models))
+	for _, m := range tgt.Models {
+		inTarget[m.ID] = true
+		if modelSelected(selection, tgt.ID, m.ID) {
+			merged.Models = append(merged.Models, m)
+		} else if old, ok := curModels[m.ID]; ok {
+			merged.Models = append(merged.Models, old)
+		}
+	}
+	for _, m := range cur.Models {
+		if !inTarget[m.ID] && !modelSelected(selection, tgt.ID, m.ID) {
+			merged.Models = append(merged.Models, m)
+		}
+	}
+	return merged
+}
+
+// countSelected counts the providers and models a selection changes
+func countSelected(diffs []models.ProviderDiff, selection models.ImportSelection) (int, int) {
+	selected := make(map[string]bool, len(selection.Providers))
+	for _, id := range selection.Providers {
+		selected[id] = true
+	}
+	providers, modelCount := 0, 0
+	for _, d := range diffs {
+		if !selected[d.ProviderID] {
+			continue
+		}
+		providers++
+		for _, m := range d.Models {
+			if modelSelected(selection, d.ProviderID, m.ModelID) {
+				modelCount++
+			}
+		}
+	}
+	return providers, modelCount
+}
+
+// diffCatalog lists the providers and models that differ between current and
+// target. Unchanged providers are left out.
+func diffCatalog(current, target []models.Provider) ([]models.ProviderDiff, models.ImportSummary) {
+	var summary models.ImportSummary
+	diffs := []models.ProviderDiff{}
+
+	curByID := make(map[string]models.Provider, len(current))
+	for _, p := range current {
+		curByID[p.ID] = p
+	}
+	inTarget := make(map[string]bool, len(target))
+
+	for _, tgt := range target {
+		inTarget[tgt.ID] = true
+		cur, exists := curByID[tgt.ID]
+		if !exists {
+			d := models.ProviderDiff{
+				ProviderID: tgt.ID,
+				Name:       tgt.Name,
+				Action:     models.DiffAdded,
+				Models:     diffModels(nil, tgt.Models),
+				KeysAfter:  len(tgt.Credentials.APIKeys),
+			}
+			summary.ProvidersAdded++
+			summary.ModelsAdded += len(d.Models)
+			diffs = append(diffs, d)
+			continue
+		}
+
+		d := models.ProviderDiff{
+			ProviderID: tgt.ID,
+			Name:       tgt.Name,
+			Action:     models.DiffChanged,
+			Fields:     diffFields(providerFields(cur), providerFields(tgt)),
+			Models:     diffModels(cur.Models, tgt.Models),
+			KeysBefore: len(cur.Credentials.APIKeys),
+			KeysAfter:  len(tgt.Credentials.APIKeys),
+		}
+		keysChanged := !sameKeys(cur.Credentials.APIKeys, tgt.Credentials.APIKeys)
+		if len(d.Fields) == 0 && len(d.Models) == 0 && !keysChanged {
+			continue
+		}
+		summary.ProvidersChanged++
+		for _, m := range d.Models {
+			switch m.Action {
+			case models.DiffAdded:
+				summary.ModelsAdded++
+			case models.DiffChanged:
+				summary.ModelsChanged++
+			case models.DiffRemoved:
+				summary.ModelsRemoved++
+			}
+		}
+		diffs = append(diffs, d)
+	}
+
+	for _, cur := range current {
+		if inTarget[cur.ID] {
+			continue
+		}
+		d := models.ProviderDiff{
+			ProviderID: cur.ID,
+			Name:       cur.Name,
+			Action:     models.DiffRemoved,
+			Models:     diffModels(cur.Models, nil),
+			KeysBefore: len(cur.Credentials.APIKeys),
+		}
+		summary.ProvidersRemoved++
+		summary.ModelsRemoved += len(d.Models)
+		diffs = append(diffs, d)
+	}
+	return diffs, summary
+}
+
+// diffModels lists the models added, changed or removed between two lists
+func diffModels(before, after []models.Model) []models.ModelDiff {
+	old := make(map[string]models.Model, len(before))
+	for _, m := range before {
+		old[m.ID] = m
+	}
+
+	var diffs []models.ModelDiff
+	seen := make(map[string]bool, len(after))
+	for _, m := range after {
+		seen[m.ID] = true
+		prev, existed := old[m.ID]
+		if !existed {
+			diffs = append(diffs, models.ModelDiff{ModelID: m.ID, Name: m.Name, Action: models.DiffAdded})
+			continue
+		}
+		if fields := diffFields(flattenJSON(prev), flattenJSON(m)); len(fields) > 0 {
+			diffs = append(diffs, models.ModelDiff{ModelID: m.ID, Name: m.Name, Action: models.DiffChanged, Fields: fields})
+		}
+	}
+	for _, m := range before {
+		if !seen[m.ID] {
+			diffs = append(diffs, models.ModelDiff{ModelID: m.ID, Name: m.Name, Action: models.DiffRemoved})
+		}
+	}
+	return diffs
+}
+
+// providerFields flattens the provider-level fields of p. Models, keys and
+// the storage revision and timestamp are compared separately or not at all.
+func providerFields(p models.Provider) map[string]interface{} {
+	p.Models = nil
+	p.Credentials = models.Credentials{}
+	p.Revision = 0
+	p.UpdatedAt = ""
+	fields := flattenJSON(p)
+	delete(fields, "models")
+	delete(fields, "credentials.apiKeys")
+	return fields
+}
+
+// flattenJSON returns the JSON fields of v keyed by dotted path. Arrays are
+// kept whole.
+func flattenJSON(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return map[string]interface{}{}
+	}
+	out := make(map[string]interface{})
+	flattenInto(out, "", decoded)
+	return out
+}
+
+// flattenInto adds the leaves of value to out under prefix
+func flattenInto(out map[string]interface{}, prefix string, value interface{}) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(obj) == 0 {
+		if prefix != "" {
+			out[prefix] = value
+		}
+		return
+	}
+	for key, v := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flattenInto(out, path, v)
+	}
+}
+
+// diffFields compares two flattened objects, sorted by field
+func diffFields(before, after map[string]interface{}) []models.FieldChange {
+	var changes []models.FieldChange
+	for field, a := range after {
+		if b, ok := before[field]; !ok || !reflect.DeepEqual(a, b) {
+			changes = append(changes, models.FieldChange{Field: field, Before: before[field], After: a})
+		}
+	}
+	for field, b := range before {
+		if _, ok := after[field]; !ok {
+			changes = append(changes, models.FieldChange{Field: field, Before: b})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// sameKeys reports whether two lists hold the same keys by fingerprint
+func sameKeys(a, b []models.APIKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, k := range a {
+		set[keyIdentity(k)] = true
+	}
+	for _, k := range b {
+		if !set[keyIdentity(k)] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyIdentity identifies a key record by its fingerprint, or its secret's
+// fingerprint when the record has none yet. A record with neither, as
+// exported without secrets, is identified by its ID.
+func keyIdentity(k models.APIKey) string {
+	if k.Fingerprint != "" {
+		return k.Fingerprint
+	}
+	if k.Key == "" {
+		return "id:" + k.ID
+	}
+	return models.KeyFingerprint(k.Key)
+}
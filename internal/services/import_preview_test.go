@@ -0,0 +1,198 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// setupTestImportPreview stores a current catalog and writes a backup that
+// renames and edits openai, adds anthropic and drops local
+func setupTestImportPreview(t *testing.T) (*ExportService, *storage.Storage, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := storage.NewWithDir(filepath.Join(dir, "data"), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	err = store.Save([]models.Provider{
+		{
+			ID:          "openai",
+			Name:        "OpenAI",
+			Endpoints:   models.Endpoints{OpenAI: "https://api.openai.com/v1"},
+			Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-current")},
+			Models: []models.Model{
+				{ID: "gpt-4o", Name: "GPT-4o", Context: models.Context{MaxInput: 128000}},
+				{ID: "gpt-3.5", Name: "GPT-3.5"},
+			},
+		},
+		{ID: "local", Name: "Local", Models: []models.Model{{ID: "llama", Name: "Llama"}}},
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	backup := &models.LLMDeskData{
+		Version: schemaVersion,
+		Providers: []models.Provider{
+			{
+				ID:          "openai",
+				Name:        "OpenAI (work)",
+				Endpoints:   models.Endpoints{OpenAI: "https://gw.example.com/v1"},
+				Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-current", "sk-second")},
+				Models: []models.Model{
+					{ID: "gpt-4o", Name: "GPT-4o", Context: models.Context{MaxInput: 200000}},
+					{ID: "o1", Name: "o1", Context: models.Context{MaxInput: 200000}},
+				},
+			},
+			{ID: "anthropic", Name: "Anthropic", Models: []models.Model{{ID: "claude", Name: "Claude", Context: models.Context{MaxInput: 200000}}}},
+		},
+	}
+	path := filepath.Join(dir, "backup.json")
+	if err := store.ExportToFile(path, backup); err != nil {
+		t.Fatalf("ExportToFile failed: %v", err)
+	}
+	return NewExportService(store), store, path
+}
+
+func TestImportPreview_Diff(t *testing.T) {
+	service, store, path := setupTestImportPreview(t)
+
+	preview, err := service.previewFile(path, "replace", "", models.MergeOptions{})
+	if err != nil {
+		t.Fatalf("previewFile failed: %v", err)
+	}
+	if preview.Token == "" {
+		t.Fatal("Expected a preview token")
+	}
+
+	want := models.ImportSummary{
+		ProvidersAdded: 1, ProvidersChanged: 1, ProvidersRemoved: 1,
+		ModelsAdded: 2, ModelsChanged: 1, ModelsRemoved: 2,
+	}
+	if preview.Summary != want {
+		t.Errorf("Expected summary %+v, got %+v", want, preview.Summary)
+	}
+
+	diffs := map[string]models.ProviderDiff{}
+	for _, d := range preview.Providers {
+		diffs[d.ProviderID] = d
+	}
+	openai := diffs["openai"]
+	if openai.Action != models.DiffChanged || openai.KeysBefore != 1 || openai.KeysAfter != 2 {
+		t.Errorf("Unexpected openai diff: %+v", openai)
+	}
+	fields := map[string]models.FieldChange{}
+	for _, f := range openai.Fields {
+		fields[f.Field] = f
+	}
+	if len(fields) != 2 || fields["name"].After != "OpenAI (work)" || fields["endpoints.openai"].Before != "https://api.openai.com/v1" {
+		t.Errorf("Unexpected field changes: %+v", openai.Fields)
+	}
+	actions := map[string]models.DiffAction{}
+	for _, m := range openai.Models {
+		actions[m.ModelID] = m.Action
+		if m.ModelID == "gpt-4o" && (len(m.Fields) != 1 || m.Fields[0].Field != "context.maxInput") {
+			t.Errorf("Unexpected gpt-4o field changes: %+v", m.Fields)
+		}
+	}
+	if actions["gpt-4o"] != models.DiffChanged || actions["o1"] != models.DiffAdded || actions["gpt-3.5"] != models.DiffRemoved {
+		t.Errorf("Unexpected model actions: %v", actions)
+	}
+	if diffs["anthropic"].Action != models.DiffAdded || diffs["local"].Action != models.DiffRemoved {
+		t.Errorf("Unexpected provider actions: %+v", preview.Providers)
+	}
+
+	// Previewing changes nothing
+	providers, _ := store.Load()
+	if len(providers) != 2 || providers[0].Name != "OpenAI" {
+		t.Errorf("Expected catalog to be untouched, got %+v", providers)
+	}
+}
+
+func TestImportPreview_CommitSelection(t *testing.T) {
+	service, store, path := setupTestImportPreview(t)
+
+	preview, _ := service.previewFile(path, "replace", "", models.MergeOptions{})
+
+	// Take openai's provider changes and the new o1 only, and drop local
+	result, err := service.CommitImport(preview.Token, models.ImportSelection{
+		Providers: []string{"openai", "local"},
+		Models:    map[string][]string{"openai": {"o1"}},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("CommitImport failed: %+v (%v)", result, err)
+	}
+	if result.Imported.Providers != 2 || result.Imported.Models != 2 {
+		t.Errorf("Expected 2 providers and 2 models, got %+v", result.Imported)
+	}
+
+	providers, _ := store.Load()
+	if len(providers) != 1 {
+		t.Fatalf("Expected local removed and anthropic skipped, got %d providers", len(providers))
+	}
+	openai := providers[0]
+	if openai.Name != "OpenAI (work)" || len(openai.Credentials.APIKeys) != 2 {
+		t.Errorf("Expected provider changes applied, got %+v", openai)
+	}
+	byID := map[string]models.Model{}
+	for _, m := range openai.Models {
+		byID[m.ID] = m
+	}
+	if _, ok := byID["o1"]; !ok {
+		t.Error("Expected selected model o1 to be added")
+	}
+	if byID["gpt-4o"].Context.MaxInput != 128000 {
+		t.Errorf("Expected unselected gpt-4o change to be skipped, got %d", byID["gpt-4o"].Context.MaxInput)
+	}
+	if _, ok := byID["gpt-3.5"]; !ok {
+		t.Error("Expected unselected removal of gpt-3.5 to be skipped")
+	}
+
+	// Tokens are single use
+	if result, _ := service.CommitImport(preview.Token, models.ImportSelection{}); result.Success {
+		t.Error("Expected a committed token to be rejected")
+	}
+}
+
+func TestImportPreview_FailedCommitKeepsToken(t *testing.T) {
+	service, _, path := setupTestImportPreview(t)
+	preview, _ := service.previewFile(path, "merge", "", models.MergeOptions{})
+
+	dataFile := filepath.Join(filepath.Dir(path), "data", "providers.json")
+	original, err := os.ReadFile(dataFile)
+	if err != nil {
+		t.Fatalf("Failed to read data file: %v", err)
+	}
+	if err := os.WriteFile(dataFile, []byte("not json"), 0600); err != nil {
+		t.Fatalf("Failed to corrupt data file: %v", err)
+	}
+	result, err := service.CommitImport(preview.Token, models.ImportSelection{Providers: []string{"openai"}})
+	if err == nil || result.Success {
+		t.Fatalf("Expected the commit to fail, got %+v (%v)", result, err)
+	}
+
+	if err := os.WriteFile(dataFile, original, 0600); err != nil {
+		t.Fatalf("Failed to restore data file: %v", err)
+	}
+	if result, err := service.CommitImport(preview.Token, models.ImportSelection{Providers: []string{"openai"}}); err != nil || !result.Success {
+		t.Errorf("Expected the retried commit to succeed, got %+v (%v)", result, err)
+	}
+}
+
+func TestImportPreview_ExpiredToken(t *testing.T) {
+	service, _, path := setupTestImportPreview(t)
+
+	preview, _ := service.previewFile(path, "merge", "", models.MergeOptions{})
+	service.previews[preview.Token].expires = time.Now().Add(-time.Second)
+
+	result, _ := service.CommitImport(preview.Token, models.ImportSelection{Providers: []string{"openai"}})
+	if result.Success {
+		t.Error("Expected an expired token to be rejected")
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/models"
+)
+
+// minImportVersion is the oldest backup format this version can import.
+// Backups up to schemaVersion's major version are accepted.
+const minImportVersion = "1.0.0"
+
+// parseVersion parses a "major.minor.patch" version. Missing minor and patch
+// parts count as zero.
+func parseVersion(v string) ([3]int, bool) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	if len(parts) > 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer
+// than b
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkImportVersion checks a backup's version against the supported range.
+// A missing version or a newer minor version only yields a warning.
+func checkImportVersion(version string) (string, error) {
+	if version == "" {
+		return "No version specified in import file", nil
+	}
+	found, ok := parseVersion(version)
+	if !ok {
+		return "", fmt.Errorf("unrecognized backup version %q", version)
+	}
+	current, _ := parseVersion(schemaVersion)
+	oldest, _ := parseVersion(minImportVersion)
+	switch {
+	case compareVersions(found, oldest) < 0:
+		return "", fmt.Errorf("backup version %s is older than the oldest supported version %s", version, minImportVersion)
+	case found[0] > current[0]:
+		return "", fmt.Errorf("backup version %s was written by a newer version of LLM Desk; please upgrade LLM Desk", version)
+	case compareVersions(found, current) > 0:
+		return fmt.Sprintf("Backup version %s is newer than %s; fields this version does not know are ignored", version, schemaVersion), nil
+	}
+	return "", nil
+}
+
+// sanitizeImport validates imported providers and models the way the editor
+// does. Missing or colliding provider and key IDs are regenerated. Invalid
+// providers, invalid models and repeated model IDs are left out and
+// returned as quarantined; a provider that only lost models is quarantined
+// as a copy holding just those models.
+func sanitizeImport(providers []models.Provider) ([]models.Provider, []models.Provider, []models.ImportIssue) {
+	clean := make([]models.Provider, 0, len(providers))
+	quarantined := []models.Provider{}
+	issues := []models.ImportIssue{}
+	seen := make(map[string]bool, len(providers))
+
+	for _, p := range providers {
+		p.Name = strings.TrimSpace(p.Name)
+		p.ID = strings.TrimSpace(p.ID)
+		if p.ID == "" || seen[p.ID] {
+			message := "Missing provider ID"
+			if p.ID != "" {
+				message = "Duplicate provider ID " + p.ID
+			}
+			p.ID = generateProviderID(p.Name)
+			issues = append(issues, models.ImportIssue{
+				ProviderID: p.ID,
+				Field:      "id",
+				Message:    message + "; generated " + p.ID,
+				Action:     models.ImportIssueFixed,
+			})
+		}
+		seen[p.ID] = true
+
+		keyIDs := make(map[string]bool, len(p.Credentials.APIKeys))
+		keys := make([]models.APIKey, len(p.Credentials.APIKeys))
+		for i, k := range p.Credentials.APIKeys {
+			if k.ID == "" || keyIDs[k.ID] {
+				k.ID = models.NewAPIKeyID()
+				issues = append(issues, models.ImportIssue{
+					ProviderID: p.ID,
+					Field:      fmt.Sprintf("credentials.apiKeys[%d].id", i),
+					Message:    "Missing or duplicate API key ID; generated a new one",
+					Action:     models.ImportIssueFixed,
+				})
+			}
+			keyIDs[k.ID] = true
+			keys[i] = k
+		}
+		p.Credentials.APIKeys = keys
+
+		if result := ValidateProvider(&p); !result.Valid {
+			for _, e := range result.Errors {
+				issues = append(issues, models.ImportIssue{
+					ProviderID: p.ID,
+					Field:      e.Field,
+					Message:    e.Message,
+					Action:     models.ImportIssueQuarantined,
+				})
+			}
+			quarantined = append(quarantined, p)
+			continue
+		}
+
+		valid := make([]models.Model, 0, len(p.Models))
+		rejected := []models.Model{}
+		modelIDs := make(map[string]bool, len(p.Models))
+		for _, m := range p.Models {
+			m.ID = strings.TrimSpace(m.ID)
+			if modelIDs[m.ID] {
+				issues = append(issues, models.ImportIssue{
+					ProviderID: p.ID,
+					ModelID:    m.ID,
+					Field:      "id",
+					Message:    "Duplicate model ID",
+					Action:     models.ImportIssueQuarantined,
+				})
+				rejected = append(rejected, m)
+				continue
+			}
+			if result := ValidateModel(&m); !result.Valid {
+				for _, e := range result.Errors {
+					issues = append(issues, models.ImportIssue{
+						ProviderID: p.ID,
+						ModelID:    m.ID,
+						Field:      e.Field,
+						Message:    e.Message,
+						Action:     models.ImportIssueQuarantined,
+					})
+				}
+				rejected = append(rejected, m)
+				continue
+			}
+			modelIDs[m.ID] = true
+			valid = append(valid, m)
+		}
+
+		if len(rejected) > 0 {
+			q := p
+			q.Credentials = models.Credentials{}
+			q.Models = rejected
+			quarantined = append(quarantined, q)
+		}
+		p.Models = valid
+		clean = append(clean, p)
+	}
+	return clean, quarantined, issues
+}
+
+// quarantine saves the items an import left out and records the file in
+// result. API keys are not written to the quarantine file.
+func (s *ExportService) quarantine(loaded *loadedImport, result *models.ImportResult) {
+	if len(loaded.quarantined) == 0 {
+		return
+	}
+	now := time.Now().Format(time.RFC3339)
+	description := "Items left out of an import because they failed validation"
+	path, err := s.storage.QuarantineImport(&models.LLMDeskData{
+		Version: schemaVersion,
+		Metadata: models.Metadata{
+			CreatedAt:   now,
+			ModifiedAt:  now,
+			Generator:   "llm-desk",
+			Description: &description,
+			Secrets:     models.ExportSecretsExclude,
+		},
+		Providers: withoutSecrets(loaded.quarantined),
+	})
+	if err != nil {
+		logger.Warn("Failed to save quarantined import items", "error", err)
+		result.Warnings = append(result.Warnings, "Failed to save the items left out of the import: "+err.Error())
+		return
+	}
+	result.QuarantinePath = path
+}
+
+// countQuarantined counts the issues that left an item out
+func countQuarantined(issues []models.ImportIssue) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Action == models.ImportIssueQuarantined {
+			n++
+		}
+	}
+	return n
+}
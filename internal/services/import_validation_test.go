@@ -0,0 +1,136 @@
+package services
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+func TestCheckImportVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		warn    bool
+		fail    bool
+	}{
+		{"1.0.0", false, false},
+		{"1.0", false, false},
+		{"", true, false},
+		{"1.4.0", true, false},
+		{"2.0.0", false, true},
+		{"0.9.0", false, true},
+		{"latest", false, true},
+	}
+	for _, tt := range tests {
+		warning, err := checkImportVersion(tt.version)
+		if (err != nil) != tt.fail || (warning != "") != tt.warn {
+			t.Errorf("%q: expected warn=%v fail=%v, got %q, %v", tt.version, tt.warn, tt.fail, warning, err)
+		}
+	}
+}
+
+func TestSanitizeImport(t *testing.T) {
+	key := models.NewAPIKey("sk-one")
+	dupKey := models.NewAPIKey("sk-two")
+	dupKey.ID = key.ID
+
+	clean, quarantined, issues := sanitizeImport([]models.Provider{
+		{
+			ID:          "openai",
+			Name:        " OpenAI ",
+			Credentials: models.Credentials{APIKeys: []models.APIKey{key, dupKey}},
+			Models: []models.Model{
+				{ID: "gpt-4o", Context: models.Context{MaxInput: 128000}},
+				{ID: "gpt-4o", Context: models.Context{MaxInput: 64000}},
+				{ID: "free", Pricing: models.Pricing{Input: -1}, Context: models.Context{MaxInput: 8000}},
+				{ID: "tiny"},
+			},
+		},
+		{ID: "openai", Name: "OpenAI copy"},
+		{ID: "broken", Name: "Broken", Endpoints: models.Endpoints{OpenAI: "not a url"}},
+	})
+
+	if len(clean) != 2 {
+		t.Fatalf("Expected 2 clean providers, got %+v", clean)
+	}
+	openai := clean[0]
+	if openai.Name != "OpenAI" || len(openai.Models) != 1 || openai.Models[0].Name == "" {
+		t.Errorf("Expected trimmed provider with one valid model, got %+v", openai)
+	}
+	if openai.Credentials.APIKeys[0].ID == openai.Credentials.APIKeys[1].ID {
+		t.Error("Expected duplicate key ID to be regenerated")
+	}
+	if clean[1].ID == "openai" || !strings.HasPrefix(clean[1].ID, "openai-copy-") {
+		t.Errorf("Expected duplicate provider ID to be regenerated, got %q", clean[1].ID)
+	}
+
+	if len(quarantined) != 2 || quarantined[0].ID != "openai" || len(quarantined[0].Models) != 3 || quarantined[1].ID != "broken" {
+		t.Fatalf("Unexpected quarantine: %+v", quarantined)
+	}
+	if len(quarantined[0].Credentials.APIKeys) != 0 {
+		t.Error("Expected quarantined models not to carry the provider's keys")
+	}
+
+	fixed, left := 0, 0
+	for _, issue := range issues {
+		switch issue.Action {
+		case models.ImportIssueFixed:
+			fixed++
+		case models.ImportIssueQuarantined:
+			left++
+		}
+	}
+	if fixed != 2 || left != 4 {
+		t.Errorf("Expected 2 fixed and 4 quarantined issues, got %+v", issues)
+	}
+}
+
+func TestImport_QuarantinesInvalidItems(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewWithDir(filepath.Join(dir, "data"), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	path := filepath.Join(dir, "backup.json")
+	err = store.ExportToFile(path, &models.LLMDeskData{Version: schemaVersion, Providers: []models.Provider{
+		{ID: "ok", Name: "OK", Models: []models.Model{
+			{ID: "good", Context: models.Context{MaxInput: 1000}},
+			{ID: "bad"},
+		}},
+		{ID: "nameless"},
+	}})
+	if err != nil {
+		t.Fatalf("ExportToFile failed: %v", err)
+	}
+	service := NewExportService(store)
+
+	result, _ := service.importFile(path, "replace", "", models.MergeOptions{})
+	if !result.Success || result.Imported.Providers != 1 || result.Imported.Models != 1 {
+		t.Fatalf("Expected only valid items to be imported, got %+v", result)
+	}
+	if len(result.Issues) != 2 || result.QuarantinePath == "" {
+		t.Fatalf("Expected issues and a quarantine file, got %+v", result)
+	}
+	quarantined, err := store.ImportFromFile(result.QuarantinePath)
+	if err != nil {
+		t.Fatalf("Failed to read quarantine file: %v", err)
+	}
+	if len(quarantined.Providers) != 2 {
+		t.Errorf("Expected 2 quarantined providers, got %+v", quarantined.Providers)
+	}
+
+	providers, _ := store.Load()
+	if len(providers) != 1 || len(providers[0].Models) != 1 {
+		t.Errorf("Expected only valid items to be stored, got %+v", providers)
+	}
+
+	// Backups from a newer major version are refused
+	if err := store.ExportToFile(path, &models.LLMDeskData{Version: "2.0.0"}); err != nil {
+		t.Fatalf("ExportToFile failed: %v", err)
+	}
+	if result, _ := service.importFile(path, "replace", "", models.MergeOptions{}); result.Success {
+		t.Error("Expected a newer major version to be rejected")
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/storage"
+)
+
+// KeyBackendStatus describes which backend holds API keys and why
+type KeyBackendStatus struct {
+	Active           string `json:"active"`     // "keyring" or "vault"
+	Preference       string `json:"preference"` // "auto", "keyring" or "vault"
+	KeyringAvailable bool   `json:"keyringAvailable"`
+	KeyringError     string `json:"keyringError,omitempty"`
+	VaultPath        string `json:"vaultPath"`
+	VaultExists      bool   `json:"vaultExists"`
+	VaultLocked      bool   `json:"vaultLocked"`
+	Reason           string `json:"reason"`
+}
+
+// KeyBackendService selects between the OS keyring and the encrypted file
+// vault, and moves keys between them
+type KeyBackendService struct {
+	mu       sync.Mutex
+	storage  *storage.Storage
+	settings *SettingsService
+	keyring  storage.KeyringManager
+	vault    *storage.FileVault
+	probe    func() error
+	portable bool
+
+	active     string
+	reason     string
+	keyringErr error
+}
+
+// NewKeyBackendService creates a KeyBackendService. probe reports whether
+// the OS keyring is usable; portable makes "auto" prefer the vault so keys
+// travel with the data directory.
+func NewKeyBackendService(s *storage.Storage, settings *SettingsService, keyring storage.KeyringManager, vault *storage.FileVault, probe func() error, portable bool) *KeyBackendService {
+	return &KeyBackendService{
+		storage:  s,
+		settings: settings,
+		keyring:  keyring,
+		vault:    vault,
+		probe:    probe,
+		portable: portable,
+	}
+}
+
+// Init chooses the active backend from the saved preference and a keyring
+// probe, and installs it in storage. The vault starts locked; keys become
+// available once UnlockVault succeeds.
+func (k *KeyBackendService) Init() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keyringErr = k.probe()
+
+	switch k.settings.GetKeyBackend() {
+	case storage.KeyBackendKeyring:
+		k.use(storage.KeyBackendKeyring, "Selected in settings")
+	case storage.KeyBackendVault:
+		k.use(storage.KeyBackendVault, "Selected in settings")
+	default:
+		switch {
+		case k.portable:
+			k.use(storage.KeyBackendVault, "Portable mode keeps keys next to the data")
+		case k.keyringErr != nil:
+			k.use(storage.KeyBackendVault, "OS keyring is unavailable")
+		default:
+			k.use(storage.KeyBackendKeyring, "OS keyring is available")
+		}
+	}
+
+	if k.keyringErr != nil {
+		logger.Warn("OS keyring probe failed", "error", k.keyringErr)
+	}
+	logger.Info("Key backend selected", "backend", k.active, "reason", k.reason)
+}
+
+// use installs backend in storage without moving keys.
+// NOTE: Caller MUST hold k.mu
+func (k *KeyBackendService) use(backend, reason string) {
+	k.active = backend
+	k.reason = reason
+	if backend == storage.KeyBackendVault {
+		k.storage.SetKeyring(k.vault)
+	} else {
+		k.storage.SetKeyring(k.keyring)
+	}
+}
+
+// Status returns the current backend state
+func (k *KeyBackendService) Status() KeyBackendStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	status := KeyBackendStatus{
+		Active:           k.active,
+		Preference:       k.settings.GetKeyBackend(),
+		KeyringAvailable: k.keyringErr == nil,
+		VaultPath:        k.vault.Path(),
+		VaultExists:      k.vault.Exists(),
+		VaultLocked:      !k.vault.IsUnlocked(),
+		Reason:           k.reason,
+	}
+	if k.keyringErr != nil {
+		status.KeyringError = k.keyringErr.Error()
+	}
+	return status
+}
+
+// UnlockVault unlocks (or creates) the vault with the master passphrase.
+// A data key kept in the vault becomes usable too.
+func (k *KeyBackendService) UnlockVault(passphrase string) error {
+	if err := k.vault.Unlock(passphrase); err != nil {
+		return err
+	}
+	if err := k.storage.ReloadDataKey(); err != nil {
+		logger.Warn("Data key is still unavailable after unlocking the vault", "error", err)
+	}
+	return nil
+}
+
+// LockVault locks the vault, making its keys unavailable until unlocked again
+func (k *KeyBackendService) LockVault() {
+	k.vault.Lock()
+}
+
+// SetBackend switches to backend, moving every stored key across, and saves
+// the preference. passphrase unlocks the vault when it is the source or the
+// target and is still locked. "auto" only saves the preference.
+func (k *KeyBackendService) SetBackend(backend, passphrase string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	switch backend {
+	case storage.KeyBackendAuto:
+		return k.settings.SetKeyBackend(backend)
+	case storage.KeyBackendKeyring:
+		if k.keyringErr = k.probe(); k.keyringErr != nil {
+			return k.keyringErr
+		}
+	case storage.KeyBackendVault:
+	default:
+		return fmt.Errorf("invalid key backend: %s", backend)
+	}
+
+	if backend == k.active {
+		return k.settings.SetKeyBackend(backend)
+	}
+
+	// The vault is either the source or the target of the move
+	if !k.vault.IsUnlocked() {
+		if passphrase == "" {
+			return storage.ErrVaultLocked
+		}
+		if err := k.vault.Unlock(passphrase); err != nil {
+			return err
+		}
+	}
+
+	target := storage.KeyringManager(k.keyring)
+	if backend == storage.KeyBackendVault {
+		target = k.vault
+	}
+
+	moved, err := k.storage.SwitchKeyring(target)
+	if err != nil {
+		return fmt.Errorf("failed to migrate keys to %s: %w", backend, err)
+	}
+	logger.Info("Migrated API keys", "to", backend, "providers", moved)
+
+	k.active = backend
+	k.reason = "Selected in settings"
+	return k.settings.SetKeyBackend(backend)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+func setupTestKeyBackendService(t *testing.T, probeErr error, portable bool) (*KeyBackendService, *storage.Storage, storage.KeyringManager) {
+	t.Helper()
+
+	dir := t.TempDir()
+	keyring := storage.NewMemoryKeyring()
+	store, err := storage.NewWithDir(dir, keyring)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	settings := NewSettingsService(store)
+	vault := storage.NewFileVault(filepath.Join(dir, storage.VaultFilename))
+	svc := NewKeyBackendService(store, settings, keyring, vault, func() error { return probeErr }, portable)
+	svc.Init()
+	return svc, store, keyring
+}
+
+func TestKeyBackendService_AutoSelection(t *testing.T) {
+	svc, _, _ := setupTestKeyBackendService(t, nil, false)
+	if status := svc.Status(); status.Active != storage.KeyBackendKeyring || !status.KeyringAvailable {
+		t.Errorf("Expected keyring when probe succeeds, got %+v", status)
+	}
+
+	svc, _, _ = setupTestKeyBackendService(t, errors.New("no secret service"), false)
+	status := svc.Status()
+	if status.Active != storage.KeyBackendVault || status.KeyringAvailable || !status.VaultLocked {
+		t.Errorf("Expected locked vault when probe fails, got %+v", status)
+	}
+	if status.KeyringError == "" {
+		t.Error("Expected keyring error to be surfaced")
+	}
+
+	svc, _, _ = setupTestKeyBackendService(t, nil, true)
+	if status := svc.Status(); status.Active != storage.KeyBackendVault {
+		t.Errorf("Expected vault in portable mode, got %+v", status)
+	}
+}
+
+func TestKeyBackendService_MigrateBothWays(t *testing.T) {
+	svc, store, keyring := setupTestKeyBackendService(t, nil, false)
+
+	if err := store.Save([]models.Provider{{ID: "p1", Name: "P1", Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-1")}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := svc.SetBackend(storage.KeyBackendVault, ""); !errors.Is(err, storage.ErrVaultLocked) {
+		t.Fatalf("Expected ErrVaultLocked without passphrase, got %v", err)
+	}
+	if err := svc.SetBackend(storage.KeyBackendVault, "master"); err != nil {
+		t.Fatalf("SetBackend(vault) failed: %v", err)
+	}
+	if keys, _ := keyring.GetKeys("p1"); len(keys) != 0 {
+		t.Errorf("Expected keys moved out of keyring, got %v", keys)
+	}
+	if svc.Status().Preference != storage.KeyBackendVault {
+		t.Error("Expected preference to be saved")
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded[0].Credentials.APIKeys) != 1 {
+		t.Errorf("Expected key served from vault, got %v", loaded[0].Credentials.APIKeys)
+	}
+	if _, err := os.Stat(svc.Status().VaultPath); err != nil {
+		t.Errorf("Expected vault file to exist: %v", err)
+	}
+
+	if err := svc.SetBackend(storage.KeyBackendKeyring, ""); err != nil {
+		t.Fatalf("SetBackend(keyring) failed: %v", err)
+	}
+	if keys, _ := keyring.GetKeys("p1"); len(keys) != 1 {
+		t.Errorf("Expected keys back in keyring, got %v", keys)
+	}
+}
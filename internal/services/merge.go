@@ -0,0 +1,610 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// normalizeMergeOptions applies defaults and rejects unknown policies
+func normalizeMergeOptions(opts models.MergeOptions) (models.MergeOptions, error) {
+	switch opts.Policy {
+	case "":
+		opts.Policy = models.MergeTakeIncoming
+	case models.MergeKeepLocal, models.MergeTakeIncoming, models.MergeNewestWins:
+	default:
+		return opts, fmt.Errorf("invalid merge policy: %s", opts.Policy)
+	}
+	return opts, nil
+}
+
+// importTarget returns the catalog an import would produce: the imported
+// providers alone in replace mode, or the current catalog with imported
+// providers merged into those with the same ID in merge mode. times says
+// when local fields and models last changed, for MergeNewestWins; it may be
+// nil. The returned warnings describe the merge conflicts and how each was
+// decided.
+func importTarget(current []models.Provider, loaded *loadedImport, mode models.ImportMode, opts models.MergeOptions, times changeTimes) ([]models.Provider, []mergeWarning) {
+	imported := reattachKeys(current, loaded.data.Providers)
+	if mode == models.ImportModeReplace {
+		// Replace all data
+		return append([]models.Provider{}, imported...), nil
+	}
+
+	// Merge with existing data
+	var warnings []mergeWarning
+	finalProviders := append([]models.Provider{}, current...)
+	index := make(map[string]int, len(finalProviders))
+	for i, p := range finalProviders {
+		index[p.ID] = i
+	}
+	for _, importedProvider := range imported {
+		targetID := importedProvider.ID
+		if localID, ok := opts.MergeDuplicates[importedProvider.ID]; ok {
+			if _, exists := index[localID]; exists {
+				targetID = localID
+			}
+		}
+
+		if i, ok := index[targetID]; ok {
+			m := &merger{
+				policy:     opts.Policy,
+				incomingAt: importedProvider.UpdatedAt,
+				times:      times.provider(finalProviders[i]),
+				present:    loaded.present[importedProvider.ID],
+			}
+			if m.incomingAt == "" {
+				m.incomingAt = loaded.data.Metadata.ModifiedAt
+			}
+			finalProviders[i] = m.mergeProvider(finalProviders[i], importedProvider)
+			for _, text := range m.warnings(finalProviders[i].Name) {
+				warnings = append(warnings, mergeWarning{providerID: targetID, text: text})
+			}
+			continue
+		}
+
+		// Add new provider
+		index[importedProvider.ID] = len(finalProviders)
+		finalProviders = append(finalProviders, importedProvider)
+	}
+	return finalProviders, warnings
+}
+
+// mergeWarning describes how conflicts in one merged provider were decided
+type mergeWarning struct {
+	providerID string
+	text       string
+}
+
+// mergeWarningTexts returns the texts of the warnings about providers
+// selected by include, or of all warnings if include is nil
+func mergeWarningTexts(warnings []mergeWarning, include func(providerID string) bool) []string {
+	texts := []string{}
+	for _, w := range warnings {
+		if include == nil || include(w.providerID) {
+			texts = append(texts, w.text)
+		}
+	}
+	return texts
+}
+
+// duplicateWarnings describes the incoming providers that were added next
+// to a local provider with the same endpoint instead of being merged
+func duplicateWarnings(current, imported []models.Provider, opts models.MergeOptions) []string {
+	warnings := []string{}
+	for _, d := range findDuplicates(current, imported) {
+		if _, merging := opts.MergeDuplicates[d.IncomingID]; !merging {
+			warnings = append(warnings, fmt.Sprintf("%s was added next to your provider %s, which uses the same endpoint", d.IncomingName, d.LocalName))
+		}
+	}
+	return warnings
+}
+
+// incomingWins decides a conflict under policy. With MergeNewestWins the
+// incoming side wins only if its timestamp is later; a side without a
+// readable timestamp counts as oldest.
+func incomingWins(policy models.MergePolicy, localAt, incomingAt string) bool {
+	switch policy {
+	case models.MergeKeepLocal:
+		return false
+	case models.MergeNewestWins:
+		incoming, err := time.Parse(time.RFC3339, incomingAt)
+		if err != nil {
+			return false
+		}
+		local, err := time.Parse(time.RFC3339, localAt)
+		return err != nil || incoming.After(local)
+	}
+	return true
+}
+
+// presentFields lists the fields an imported provider and its models had
+// in the source file, as flattened paths. Only listed fields take part in
+// a conflict, and a listed field does so even when it is empty, so a merge
+// can clear a value but defaults filled in on import never replace one.
+type presentFields struct {
+	provider map[string]bool
+	models   map[string]map[string]bool
+}
+
+// sourcePresence reads which fields each provider of an LLM Desk file has,
+// keyed by provider ID. The converters of other formats record their own.
+func sourcePresence(raw []byte) map[string]presentFields {
+	var doc struct {
+		Providers []map[string]interface{} `json:"providers"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+
+	presence := make(map[string]presentFields, len(doc.Providers))
+	for _, p := range doc.Providers {
+		id, _ := p["id"].(string)
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		fields := presentFields{provider: map[string]bool{}, models: map[string]map[string]bool{}}
+		for key, value := range p {
+			switch key {
+			case "models":
+				for _, raw := range asSlice(value) {
+					m := asMap(raw)
+					modelID, _ := m["id"].(string)
+					fields.models[strings.TrimSpace(modelID)] = flattenedPaths(m)
+				}
+			case "credentials", "revision", "updatedAt":
+			default:
+				flat := make(map[string]interface{})
+				flattenInto(flat, key, value)
+				for path := range flat {
+					fields.provider[path] = true
+				}
+			}
+		}
+		presence[id] = fields
+	}
+	return presence
+}
+
+// flattenedPaths returns the dotted paths of the leaves of obj
+func flattenedPaths(obj map[string]interface{}) map[string]bool {
+	flat := make(map[string]interface{})
+	for key, value := range obj {
+		flattenInto(flat, key, value)
+	}
+	paths := make(map[string]bool, len(flat))
+	for path := range flat {
+		paths[path] = true
+	}
+	return paths
+}
+
+// changeTimes says when each local provider's fields and models last
+// changed, keyed by provider ID
+type changeTimes map[string]*providerTimes
+
+// providerTimes holds the journaled change times of one provider
+type providerTimes struct {
+	created  string            // When the provider was created, if journaled
+	fields   map[string]string // Flattened field path to time
+	models   map[string]string // Model ID to time
+	fallback string            // The provider's updatedAt, for untracked changes
+}
+
+// journalChangeTimes replays the journal, oldest entry first, recording
+// when each provider field and model last changed
+func journalChangeTimes(entries []storage.JournalEntry) changeTimes {
+	times := make(changeTimes)
+	for _, entry := range entries {
+		for _, change := range entry.Changes {
+			switch change.Action {
+			case storage.ChangeDeleted:
+				delete(times, change.ProviderID)
+			case storage.ChangeCreated:
+				times[change.ProviderID] = &providerTimes{created: entry.Timestamp, fields: map[string]string{}, models: map[string]string{}}
+			case storage.ChangeUpdated:
+				t := times[change.ProviderID]
+				if t == nil {
+					t = &providerTimes{fields: map[string]string{}, models: map[string]string{}}
+					times[change.ProviderID] = t
+				}
+				if change.Before != nil && change.After != nil {
+					before, after := providerFields(*change.Before), providerFields(*change.After)
+					for path := range unionKeys(before, after) {
+						if !reflect.DeepEqual(before[path], after[path]) {
+							t.fields[path] = entry.Timestamp
+						}
+					}
+				}
+				for _, id := range change.ModelIDs {
+					t.models[id] = entry.Timestamp
+				}
+			}
+		}
+	}
+	return times
+}
+
+// provider returns the change times of local, falling back to its updatedAt
+// for what the journal does not cover
+func (c changeTimes) provider(local models.Provider) *providerTimes {
+	t := &providerTimes{fallback: local.UpdatedAt}
+	if journaled := c[local.ID]; journaled != nil {
+		*t = *journaled
+		t.fallback = local.UpdatedAt
+	}
+	return t
+}
+
+// field returns when the field at path last changed
+func (t *providerTimes) field(path string) string {
+	if at, ok := t.fields[path]; ok {
+		return at
+	}
+	// A parent or child of path may have been recorded instead
+	latest := ""
+	for recorded, at := range t.fields {
+		if strings.HasPrefix(path, recorded+".") || strings.HasPrefix(recorded, path+".") {
+			if at > latest {
+				latest = at
+			}
+		}
+	}
+	if latest != "" {
+		return latest
+	}
+	return t.since()
+}
+
+// model returns when the model with id last changed
+func (t *providerTimes) model(id string) string {
+	if at, ok := t.models[id]; ok {
+		return at
+	}
+	return t.since()
+}
+
+// since returns the time untracked fields last changed: the creation time
+// if the journal saw it, otherwise the provider's updatedAt
+func (t *providerTimes) since() string {
+	if t.created != "" {
+		return t.created
+	}
+	return t.fallback
+}
+
+// merger merges one imported provider into a local one and records how
+// each conflict was decided
+type merger struct {
+	policy     models.MergePolicy
+	incomingAt string         // When the imported provider last changed
+	times      *providerTimes // When the local fields and models last changed
+	present    presentFields
+	kept       []string // Conflicting fields where the local value stayed
+	took       []string // Conflicting fields where the imported value won
+}
+
+// mergeProvider merges incoming into local: provider fields field by field,
+// models by ID and API keys as a union. The result keeps local's ID.
+func (m *merger) mergeProvider(local, incoming models.Provider) models.Provider {
+	fields, kept, took := mergeFlat(providerFields(local), providerFields(incoming), m.present.provider, func(field string) bool {
+		return incomingWins(m.policy, m.times.field(field), m.incomingAt)
+	})
+	m.kept, m.took = append(m.kept, kept...), append(m.took, took...)
+
+	var merged models.Provider
+	if !unflattenInto(fields, &merged) {
+		merged = local
+	}
+	merged.ID = local.ID
+	merged.Revision = local.Revision
+	merged.UpdatedAt = local.UpdatedAt
+	merged.Credentials = models.Credentials{APIKeys: unionAPIKeys(local.Credentials.APIKeys, incoming.Credentials.APIKeys)}
+	merged.Models = m.mergeModels(local.Models, incoming.Models)
+	return merged
+}
+
+// mergeModels keeps every local model, merges models present on both sides
+// field by field and appends incoming models that are new
+func (m *merger) mergeModels(local, incoming []models.Model) []models.Model {
+	byID := make(map[string]models.Model, len(incoming))
+	for _, model := range incoming {
+		byID[model.ID] = model
+	}
+
+	merged := make([]models.Model, 0, len(local)+len(incoming))
+	seen := make(map[string]bool, len(local))
+	for _, model := range local {
+		seen[model.ID] = true
+		if in, ok := byID[model.ID]; ok {
+			preferIncoming := incomingWins(m.policy, m.times.model(model.ID), m.incomingAt)
+			fields, kept, took := mergeFlat(flattenJSON(model), flattenJSON(in), m.present.models[model.ID], func(string) bool {
+				return preferIncoming
+			})
+			for _, field := range kept {
+				m.kept = append(m.kept, model.ID+" "+field)
+			}
+			for _, field := range took {
+				m.took = append(m.took, model.ID+" "+field)
+			}
+			var combined models.Model
+			if unflattenInto(fields, &combined) {
+				model = combined
+			}
+		}
+		merged = append(merged, model)
+	}
+	for _, model := range incoming {
+		if !seen[model.ID] {
+			merged = append(merged, model)
+			seen[model.ID] = true
+		}
+	}
+	return merged
+}
+
+// warnings describes the conflicts of the provider named name
+func (m *merger) warnings(name string) []string {
+	var warnings []string
+	if len(m.took) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: replaced local values with imported ones for %s", name, strings.Join(m.took, ", ")))
+	}
+	if len(m.kept) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: kept local values instead of imported ones for %s", name, strings.Join(m.kept, ", ")))
+	}
+	return warnings
+}
+
+// reattachKeys returns copies of imported whose key records, exported
+// without secrets, get the fingerprint and hint of the local record with the
+// same provider and key ID back, so the stored secrets stay attached
+func reattachKeys(current, imported []models.Provider) []models.Provider {
+	local := make(map[string]models.APIKey)
+	for _, p := range current {
+		for _, k := range p.Credentials.APIKeys {
+			local[p.ID+"/"+k.ID] = k
+		}
+	}
+
+	reattached := make([]models.Provider, len(imported))
+	for i, p := range imported {
+		keys := make([]models.APIKey, len(p.Credentials.APIKeys))
+		for j, k := range p.Credentials.APIKeys {
+			if match, ok := local[p.ID+"/"+k.ID]; ok && k.Key == "" && k.Fingerprint == "" {
+				k.Fingerprint = match.Fingerprint
+				k.Hint = match.Hint
+			}
+			keys[j] = k
+		}
+		p.Credentials.APIKeys = keys
+		reattached[i] = p
+	}
+	return reattached
+}
+
+// unionAPIKeys returns local's key records followed by incoming records
+// whose key is not already present
+func unionAPIKeys(local, incoming []models.APIKey) []models.APIKey {
+	keys := append([]models.APIKey{}, local...)
+	have := make(map[string]bool, len(local))
+	ids := make(map[string]bool, len(local))
+	for _, k := range local {
+		have[keyIdentity(k)] = true
+		ids[k.ID] = true
+	}
+	for _, k := range incoming {
+		if have[keyIdentity(k)] {
+			continue
+		}
+		if ids[k.ID] {
+			k.ID = models.NewAPIKeyID()
+		}
+		have[keyIdentity(k)] = true
+		ids[k.ID] = true
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// unionKeys returns the keys of a and b
+func unionKeys(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+// mergeFlat merges two flattened objects. A field the incoming side does
+// not have, or that present does not list, never replaces a local value;
+// without present, empty, zero and false values count as missing. A missing
+// or empty local field takes the incoming value; any other difference is a
+// conflict that goes to the incoming side where preferIncoming says so. It
+// returns the merged fields and the conflicting fields that were kept and
+// taken.
+func mergeFlat(local, incoming map[string]interface{}, present map[string]bool, preferIncoming func(field string) bool) (map[string]interface{}, []string, []string) {
+	merged := make(map[string]interface{}, len(local)+len(incoming))
+	for field, l := range local {
+		merged[field] = l
+	}
+
+	fields := make([]string, 0, len(incoming)+len(present))
+	for field := range incoming {
+		fields = append(fields, field)
+	}
+	for field := range present {
+		if _, ok := incoming[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var kept, took []string
+	for _, field := range fields {
+		in, inOK := incoming[field]
+		if !hasIncoming(present, field, in) {
+			if _, ok := merged[field]; !ok && inOK {
+				merged[field] = in
+			}
+			continue
+		}
+
+		l, ok := merged[field]
+		children := childFields(merged, field)
+		switch {
+		case (!ok || isEmptyValue(l)) && len(children) == 0:
+			merged[field] = in
+		case ok && reflect.DeepEqual(l, in):
+		case preferIncoming(field):
+			for _, child := range children {
+				delete(merged, child)
+			}
+			merged[field] = in
+			took = append(took, field)
+		default:
+			kept = append(kept, field)
+		}
+	}
+	return merged, kept, took
+}
+
+// hasIncoming reports whether the incoming value of field came from the
+// source: listed in present, itself or through an enclosing object, or
+// without present, not empty or zero
+func hasIncoming(present map[string]bool, field string, in interface{}) bool {
+	if present == nil {
+		return !isEmptyValue(in) && in != float64(0) && in != false
+	}
+	for path := field; ; {
+		if present[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// childFields returns the fields of flat nested under field
+func childFields(flat map[string]interface{}, field string) []string {
+	var children []string
+	for path := range flat {
+		if strings.HasPrefix(path, field+".") {
+			children = append(children, path)
+		}
+	}
+	return children
+}
+
+// isEmptyValue reports whether a decoded JSON value carries no information
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// unflattenInto rebuilds dotted-path fields into a JSON object and decodes it
+// into out
+func unflattenInto(fields map[string]interface{}, out interface{}) bool {
+	// Parents sort before their children, so an empty parent never replaces
+	// children set later
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	root := make(map[string]interface{})
+	for _, path := range paths {
+		value := fields[path]
+		node := root
+		parts := strings.Split(path, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = value
+	}
+	data, err := json.Marshal(root)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+// findDuplicates lists incoming providers whose ID is new but whose endpoint
+// matches a local provider
+func findDuplicates(current, imported []models.Provider) []models.DuplicateProvider {
+	localIDs := make(map[string]bool, len(current))
+	byEndpoint := make(map[string]models.Provider)
+	for _, p := range current {
+		localIDs[p.ID] = true
+		for _, e := range providerEndpoints(p) {
+			if _, taken := byEndpoint[e]; !taken {
+				byEndpoint[e] = p
+			}
+		}
+	}
+
+	duplicates := []models.DuplicateProvider{}
+	for _, in := range imported {
+		if localIDs[in.ID] {
+			continue
+		}
+		for _, e := range providerEndpoints(in) {
+			if local, ok := byEndpoint[e]; ok {
+				duplicates = append(duplicates, models.DuplicateProvider{
+					IncomingID:   in.ID,
+					IncomingName: in.Name,
+					LocalID:      local.ID,
+					LocalName:    local.Name,
+					Endpoint:     e,
+				})
+				break
+			}
+		}
+	}
+	return duplicates
+}
+
+// providerEndpoints returns p's endpoint URLs normalized for comparison
+func providerEndpoints(p models.Provider) []string {
+	var endpoints []string
+	if e := normalizeEndpoint(p.Endpoints.OpenAI); e != "" {
+		endpoints = append(endpoints, e)
+	}
+	if p.Endpoints.Anthropic != nil {
+		if e := normalizeEndpoint(*p.Endpoints.Anthropic); e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// normalizeEndpoint lowercases an endpoint URL and drops trailing slashes
+func normalizeEndpoint(endpoint string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(endpoint)), "/")
+}
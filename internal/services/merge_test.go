@@ -0,0 +1,268 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// loadedFrom wraps providers as a parsed import file
+func loadedFrom(modifiedAt string, providers ...models.Provider) *loadedImport {
+	return &loadedImport{data: &models.LLMDeskData{
+		Metadata:  models.Metadata{ModifiedAt: modifiedAt},
+		Providers: providers,
+	}}
+}
+
+func TestMerge_ModelsAndKeys(t *testing.T) {
+	local := models.Provider{
+		ID:          "openai",
+		Name:        "OpenAI",
+		Endpoints:   models.Endpoints{OpenAI: "https://api.openai.com/v1"},
+		Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-mine")},
+		Models: []models.Model{
+			{ID: "mine", Name: "Mine"},
+			{ID: "shared", Name: "Shared", Pricing: models.Pricing{Input: 1, Currency: "USD"}},
+		},
+	}
+	incoming := models.Provider{
+		ID:          "openai",
+		Name:        "OpenAI",
+		Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-mine", "sk-teammate")},
+		Models: []models.Model{
+			{ID: "shared", Name: "Shared", Pricing: models.Pricing{Input: 2}},
+			{ID: "theirs", Name: "Theirs"},
+		},
+	}
+
+	merged, _ := importTarget([]models.Provider{local}, loadedFrom("", incoming), models.ImportModeMerge,
+		models.MergeOptions{Policy: models.MergeTakeIncoming}, nil)
+	if len(merged) != 1 {
+		t.Fatalf("Expected 1 provider, got %d", len(merged))
+	}
+	p := merged[0]
+
+	if p.Endpoints.OpenAI != "https://api.openai.com/v1" {
+		t.Errorf("Expected empty incoming endpoint not to overwrite local, got %q", p.Endpoints.OpenAI)
+	}
+	if len(p.Models) != 3 || p.Models[0].ID != "mine" || p.Models[2].ID != "theirs" {
+		t.Fatalf("Expected models merged by ID, got %+v", p.Models)
+	}
+	shared := p.Models[1]
+	if shared.Pricing.Input != 2 || shared.Pricing.Currency != "USD" {
+		t.Errorf("Expected field-level model merge, got %+v", shared.Pricing)
+	}
+	if len(p.Credentials.APIKeys) != 2 || p.Credentials.APIKeys[1].Key != "sk-teammate" {
+		t.Errorf("Expected key union, got %+v", p.Credentials.APIKeys)
+	}
+}
+
+func TestMerge_Policies(t *testing.T) {
+	local := models.Provider{ID: "p", Name: "Local name", UpdatedAt: "2026-01-02T00:00:00Z"}
+	incoming := models.Provider{ID: "p", Name: "Incoming name"}
+
+	tests := []struct {
+		policy     models.MergePolicy
+		modifiedAt string
+		want       string
+	}{
+		{models.MergeKeepLocal, "", "Local name"},
+		{models.MergeTakeIncoming, "", "Incoming name"},
+		{models.MergeNewestWins, "2026-01-01T00:00:00Z", "Local name"},
+		{models.MergeNewestWins, "2026-01-03T00:00:00Z", "Incoming name"},
+		{models.MergeNewestWins, "", "Local name"}, // No incoming timestamp
+	}
+	for _, tt := range tests {
+		merged, _ := importTarget([]models.Provider{local}, loadedFrom(tt.modifiedAt, incoming), models.ImportModeMerge,
+			models.MergeOptions{Policy: tt.policy}, nil)
+		if merged[0].Name != tt.want {
+			t.Errorf("%s (%q): expected %q, got %q", tt.policy, tt.modifiedAt, tt.want, merged[0].Name)
+		}
+	}
+
+	// A provider's own timestamp takes precedence over the file's
+	incoming.UpdatedAt = "2026-01-05T00:00:00Z"
+	merged, _ := importTarget([]models.Provider{local}, loadedFrom("2026-01-01T00:00:00Z", incoming), models.ImportModeMerge,
+		models.MergeOptions{Policy: models.MergeNewestWins}, nil)
+	if merged[0].Name != "Incoming name" {
+		t.Errorf("Expected provider updatedAt to win, got %q", merged[0].Name)
+	}
+
+	if _, err := normalizeMergeOptions(models.MergeOptions{Policy: "coinFlip"}); err == nil {
+		t.Error("Expected unknown policy to be rejected")
+	}
+}
+
+func TestMerge_DuplicateEndpoints(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewWithDir(filepath.Join(dir, "data"), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	err = store.Save([]models.Provider{{
+		ID:        "openai",
+		Name:      "OpenAI",
+		Endpoints: models.Endpoints{OpenAI: "https://api.openai.com/v1"},
+		Models:    []models.Model{{ID: "gpt-4o"}},
+	}})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	path := filepath.Join(dir, "backup.json")
+	err = store.ExportToFile(path, &models.LLMDeskData{Version: schemaVersion, Providers: []models.Provider{{
+		ID:        "openai-team",
+		Name:      "OpenAI (team)",
+		Endpoints: models.Endpoints{OpenAI: "https://API.openai.com/v1/"},
+		Models:    []models.Model{{ID: "o1", Context: models.Context{MaxInput: 200000}}},
+	}}})
+	if err != nil {
+		t.Fatalf("ExportToFile failed: %v", err)
+	}
+	service := NewExportService(store)
+
+	preview, _ := service.previewFile(path, "merge", "", models.MergeOptions{})
+	if len(preview.Duplicates) != 1 || preview.Duplicates[0].LocalID != "openai" || preview.Duplicates[0].IncomingID != "openai-team" {
+		t.Fatalf("Expected duplicate to be detected, got %+v", preview.Duplicates)
+	}
+
+	// Accepting the duplicate merges it into the local provider
+	preview, _ = service.previewFile(path, "merge", "", models.MergeOptions{
+		Policy:          models.MergeKeepLocal,
+		MergeDuplicates: map[string]string{"openai-team": "openai"},
+	})
+	if len(preview.Duplicates) != 0 {
+		t.Errorf("Expected accepted duplicate not to be reported again, got %+v", preview.Duplicates)
+	}
+	result, _ := service.CommitImport(preview.Token, models.ImportSelection{Providers: []string{"openai"}})
+	if !result.Success {
+		t.Fatalf("CommitImport failed: %+v", result)
+	}
+
+	providers, _ := store.Load()
+	if len(providers) != 1 || providers[0].ID != "openai" || providers[0].Name != "OpenAI" || len(providers[0].Models) != 2 {
+		t.Errorf("Expected duplicate merged into openai, got %+v", providers)
+	}
+}
+
+func TestMerge_PresentEmptyFieldClears(t *testing.T) {
+	local := models.Provider{ID: "p", Name: "P", Endpoints: models.Endpoints{OpenAI: "https://old.example.com/v1"},
+		Models: []models.Model{{ID: "m", Name: "M", Modalities: []string{"text"}}}}
+	loaded := loadedFrom("", models.Provider{ID: "p", Name: "P", Models: []models.Model{{ID: "m", Name: "M"}}})
+	loaded.present = sourcePresence([]byte(`{"providers": [{"id": "p", "name": "P", "endpoints": {"openai": ""},
+		"models": [{"id": "m", "name": "M", "modalities": []}]}]}`))
+
+	merged, warnings := importTarget([]models.Provider{local}, loaded, models.ImportModeMerge,
+		models.MergeOptions{Policy: models.MergeTakeIncoming}, nil)
+	if merged[0].Endpoints.OpenAI != "" || len(merged[0].Models[0].Modalities) != 0 {
+		t.Errorf("Expected the empty imported values to clear local ones, got %+v", merged[0])
+	}
+	if texts := mergeWarningTexts(warnings, nil); len(texts) != 1 || !strings.Contains(texts[0], "endpoints.openai") || !strings.Contains(texts[0], "m modalities") {
+		t.Errorf("Expected the conflicts to be reported, got %v", texts)
+	}
+
+	merged, warnings = importTarget([]models.Provider{local}, loaded, models.ImportModeMerge,
+		models.MergeOptions{Policy: models.MergeKeepLocal}, nil)
+	if merged[0].Endpoints.OpenAI != local.Endpoints.OpenAI {
+		t.Errorf("Expected keepLocal to keep the endpoint, got %q", merged[0].Endpoints.OpenAI)
+	}
+	if texts := mergeWarningTexts(warnings, nil); len(texts) != 1 || !strings.Contains(texts[0], "kept local values") {
+		t.Errorf("Expected the kept conflicts to be reported, got %v", texts)
+	}
+}
+
+func TestMerge_ConvertedFileKeepsUnsetFields(t *testing.T) {
+	local := models.Provider{ID: "openai", Name: "OpenAI (work)", Endpoints: models.Endpoints{OpenAI: "https://proxy.example.com/v1"},
+		Models: []models.Model{{ID: "gpt-4o", Name: "GPT-4o", Enabled: true, Modalities: []string{"text", "vision"},
+			Pricing: models.Pricing{Input: 2.5, Output: 10, Currency: "USD"}, Context: models.Context{MaxInput: 111000}}}}
+	data, _, _, presence, err := parsePlainImport("config.yaml", []byte(`model_list:
+  - model_name: GPT-4o
+    litellm_params:
+      model: openai/gpt-4o
+    model_info:
+      max_output_tokens: 16384
+`), "2030-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("parsePlainImport failed: %v", err)
+	}
+	loaded := &loadedImport{data: data, present: presence}
+
+	for _, policy := range []models.MergePolicy{models.MergeTakeIncoming, models.MergeNewestWins} {
+		merged, warnings := importTarget([]models.Provider{local}, loaded, models.ImportModeMerge, models.MergeOptions{Policy: policy}, nil)
+		p, m := merged[0], merged[0].Models[0]
+		if p.Name != local.Name || p.Endpoints.OpenAI != local.Endpoints.OpenAI {
+			t.Errorf("%s: expected the provider's defaults not to replace local values, got %q at %q", policy, p.Name, p.Endpoints.OpenAI)
+		}
+		if m.Pricing.Input != 2.5 || m.Pricing.Output != 10 || m.Context.MaxInput != 111000 || !m.Enabled || len(m.Modalities) != 2 {
+			t.Errorf("%s: expected fields the file does not set to stay, got %+v", policy, m)
+		}
+		if m.Context.MaxOutput == nil || *m.Context.MaxOutput != 16384 {
+			t.Errorf("%s: expected the file's max output to be taken, got %v", policy, m.Context.MaxOutput)
+		}
+		if texts := mergeWarningTexts(warnings, nil); len(texts) != 0 {
+			t.Errorf("%s: expected no conflicts, got %v", policy, texts)
+		}
+	}
+}
+
+func TestMerge_NewestWinsPerField(t *testing.T) {
+	before := models.Provider{ID: "p", Name: "Old name", Endpoints: models.Endpoints{OpenAI: "https://local.example.com/v1"},
+		Models: []models.Model{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}}
+	after := before
+	after.Name = "New name"
+	after.Models = []models.Model{{ID: "a", Name: "A"}, {ID: "b", Name: "B (edited)"}}
+	after.UpdatedAt = "2026-03-01T00:00:00Z"
+	times := journalChangeTimes([]storage.JournalEntry{
+		{Timestamp: "2026-01-01T00:00:00Z", Changes: []storage.ProviderChange{{ProviderID: "p", Action: storage.ChangeCreated, After: &before}}},
+		{Timestamp: "2026-03-01T00:00:00Z", Changes: []storage.ProviderChange{{ProviderID: "p", Action: storage.ChangeUpdated,
+			Before: &before, After: &after, ModelIDs: []string{"b"}}}},
+	})
+
+	// The import is newer than the creation but older than the rename
+	incoming := models.Provider{ID: "p", Name: "Their name", UpdatedAt: "2026-02-01T00:00:00Z",
+		Endpoints: models.Endpoints{OpenAI: "https://theirs.example.com/v1"},
+		Models:    []models.Model{{ID: "a", Name: "A (theirs)"}, {ID: "b", Name: "B (theirs)"}}}
+	merged, _ := importTarget([]models.Provider{after}, loadedFrom("", incoming), models.ImportModeMerge,
+		models.MergeOptions{Policy: models.MergeNewestWins}, times)
+	p := merged[0]
+	if p.Name != "New name" || p.Endpoints.OpenAI != "https://theirs.example.com/v1" {
+		t.Errorf("Expected the renamed field kept and the untouched one taken, got %q and %q", p.Name, p.Endpoints.OpenAI)
+	}
+	if p.Models[0].Name != "A (theirs)" || p.Models[1].Name != "B (edited)" {
+		t.Errorf("Expected only the unedited model taken, got %+v", p.Models)
+	}
+}
+
+func TestImportData_AppliesPolicyAndWarns(t *testing.T) {
+	service, store, dir := setupTestExportService(t)
+	path := filepath.Join(dir, "team.json")
+	content := `{"version": "1.0.0", "metadata": {}, "providers": [
+		{"id": "openai", "name": "OpenAI (team)", "enabled": true, "credentials": {"apiKeys": []}, "endpoints": {"openai": ""}, "limits": [], "features": {}, "models": []},
+		{"id": "openai-team", "name": "Team", "enabled": true, "credentials": {"apiKeys": []}, "endpoints": {"openai": "https://api.openai.com/v1"}, "limits": [], "features": {}, "models": []}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write import: %v", err)
+	}
+	if err := store.Update(func(providers *[]models.Provider) error {
+		(*providers)[0].Endpoints.OpenAI = "https://api.openai.com/v1"
+		return nil
+	}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	service.SetDialogs(&fakeDialogs{openPath: path})
+
+	result, err := service.ImportData("merge", models.MergeOptions{Policy: models.MergeKeepLocal})
+	if err != nil || !result.Success {
+		t.Fatalf("ImportData failed: %+v (%v)", result, err)
+	}
+	providers, _ := store.Load()
+	if providers[0].Name != "OpenAI" || providers[0].Endpoints.OpenAI != "https://api.openai.com/v1" {
+		t.Errorf("Expected keepLocal to apply, got %+v", providers[0])
+	}
+	warnings := strings.Join(result.Warnings, "\n")
+	if !strings.Contains(warnings, "kept local values") || !strings.Contains(warnings, "same endpoint") {
+		t.Errorf("Expected conflict and duplicate warnings, got %v", result.Warnings)
+	}
+}
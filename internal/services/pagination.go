@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// ModelList is what an adapter read from a model-list API
+type ModelList struct {
+	Models    []models.FetchedModel
+	Endpoint  string // First page requested, with secrets redacted
+	Pages     int    // Pages requested
+	Truncated bool   // Stopped at the page or model cap with more models left
+}
+
+// nextPage says how to request the page after the current one: with a
+// query parameter set on the current URL, or at another URL
+type nextPage struct {
+	param, value string
+	url          string
+}
+
+// pageCursor finds the next page in a decoded list response. It reports
+// false on the last page.
+type pageCursor func(doc interface{}) (nextPage, bool)
+
+// modelPager reads a model list page by page
+type modelPager struct {
+	client *http.Client
+	header http.Header
+	limits storage.ModelFetchSettings
+	cursor pageCursor                                  // Nil for lists without pages
+	read   func(doc interface{}) []models.FetchedModel // Nil means modelsIn
+}
+
+// list reads the list starting at endpoint. It follows the cursor until the
+// last page or a cap, keeping the first model seen for each ID.
+func (p modelPager) list(ctx context.Context, endpoint string) (ModelList, error) {
+	read := p.read
+	if read == nil {
+		read = modelsIn
+	}
+
+	list := ModelList{Endpoint: newRedactor(endpoint, p.header).url(endpoint)}
+	seen := make(map[string]bool)
+	visited := map[string]bool{endpoint: true}
+	for {
+		doc, err := getJSON(ctx, p.client, endpoint, p.header)
+		if err != nil {
+			return ModelList{}, err
+		}
+		list.Pages++
+
+		for _, m := range read(doc) {
+			if seen[m.ID] {
+				continue
+			}
+			if len(list.Models) >= p.limits.MaxModels {
+				list.Truncated = true
+				return list, nil
+			}
+			seen[m.ID] = true
+			list.Models = append(list.Models, m)
+		}
+
+		if p.cursor == nil {
+			break
+		}
+		next, ok := p.cursor(doc)
+		if !ok {
+			break
+		}
+		if endpoint, err = next.resolve(endpoint); err != nil {
+			return ModelList{}, &FetchError{Kind: models.FetchErrorInvalidURL, Endpoint: list.Endpoint, Err: err}
+		}
+		if visited[endpoint] {
+			break // The cursor does not advance
+		}
+		if list.Pages >= p.limits.MaxPages {
+			list.Truncated = true
+			break
+		}
+		visited[endpoint] = true
+	}
+
+	if len(list.Models) == 0 {
+		return ModelList{}, &FetchError{
+			Kind:     models.FetchErrorNoModels,
+			Endpoint: list.Endpoint,
+			Status:   http.StatusOK,
+			Err:      fmt.Errorf("no models found in response"),
+		}
+	}
+	return list, nil
+}
+
+// resolve returns the URL of the next page after current. A next page URL
+// must stay on the scheme and host of current, since the request carries
+// the API key.
+func (n nextPage) resolve(current string) (string, error) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	if n.url != "" {
+		next, err := base.Parse(n.url)
+		if err != nil {
+			return "", fmt.Errorf("invalid next page URL: %s", n.url)
+		}
+		if next.Scheme != base.Scheme || !strings.EqualFold(next.Host, base.Host) {
+			return "", fmt.Errorf("next page is on another host: %s://%s", next.Scheme, next.Host)
+		}
+		return next.String(), nil
+	}
+	query := base.Query()
+	query.Set(n.param, n.value)
+	base.RawQuery = query.Encode()
+	return base.String(), nil
+}
+
+// tokenCursor follows a page token named field in the response, sent back
+// as the query parameter param
+func tokenCursor(field, param string) pageCursor {
+	return func(doc interface{}) (nextPage, bool) {
+		token := asString(asMap(doc)[field])
+		return nextPage{param: param, value: token}, token != ""
+	}
+}
+
+// afterCursor follows has_more and last_id, sent back as the query
+// parameter param. Without last_id the ID of the last item is used.
+func afterCursor(param string) pageCursor {
+	return func(doc interface{}) (nextPage, bool) {
+		obj := asMap(doc)
+		if !asBool(obj["has_more"]) {
+			return nextPage{}, false
+		}
+		last := asString(obj["last_id"])
+		if items := asSlice(obj["data"]); last == "" && len(items) > 0 {
+			last = asString(asMap(items[len(items)-1])["id"])
+		}
+		return nextPage{param: param, value: last}, last != ""
+	}
+}
+
+// openAICursor follows the pagination of OpenAI-compatible aggregators:
+// has_more with last_id, a next page URL, or a next cursor value
+func openAICursor(doc interface{}) (nextPage, bool) {
+	if next, ok := afterCursor("after")(doc); ok {
+		return next, true
+	}
+	obj := asMap(doc)
+	for _, field := range []string{"next", "next_page", "next_page_url"} {
+		if next := asString(obj[field]); next != "" {
+			return nextPage{url: next}, true
+		}
+	}
+	for _, field := range []string{"next_cursor", "nextCursor"} {
+		if cursor := asString(obj[field]); cursor != "" {
+			return nextPage{param: "cursor", value: cursor}, true
+		}
+	}
+	return nextPage{}, false
+}
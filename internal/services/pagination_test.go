@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// pagedServer serves an OpenAI-style list of n models, size per page,
+// following has_more and the after parameter
+func pagedServer(t *testing.T, n, size int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := 0
+		if after := r.URL.Query().Get("after"); after != "" {
+			fmt.Sscanf(after, "m%d", &start)
+			start++
+		}
+		end := min(start+size, n)
+		fmt.Fprint(w, `{"object": "list", "data": [`)
+		for i := start; i < end; i++ {
+			if i > start {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id": "m%d"}`, i)
+		}
+		fmt.Fprintf(w, `], "has_more": %t}`, end < n)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestModelPagerFollowsPages(t *testing.T) {
+	server := pagedServer(t, 7, 3)
+	list, err := openAIAdapter{}.ListModels(context.Background(), ListRequest{
+		Client:  server.Client(),
+		BaseURL: server.URL,
+		Limits:  storage.DefaultModelFetchSettings(),
+	})
+	if err != nil || len(list.Models) != 7 || list.Pages != 3 || list.Truncated {
+		t.Fatalf("Expected 7 models on 3 pages, got %d on %d (truncated %t, %v)", len(list.Models), list.Pages, list.Truncated, err)
+	}
+	if list.Models[6].ID != "m6" {
+		t.Errorf("Expected the models in order, got %+v", list.Models)
+	}
+}
+
+func TestModelPagerCaps(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    storage.ModelFetchSettings
+		models    int
+		pages     int
+		truncated bool
+	}{
+		{"page cap", storage.ModelFetchSettings{MaxPages: 2, MaxModels: 100}, 6, 2, true},
+		{"model cap", storage.ModelFetchSettings{MaxPages: 10, MaxModels: 4}, 4, 2, true},
+		{"exact fit", storage.ModelFetchSettings{MaxPages: 3, MaxModels: 7}, 7, 3, false},
+	}
+	for _, tt := range tests {
+		server := pagedServer(t, 7, 3)
+		list, err := openAIAdapter{}.ListModels(context.Background(), ListRequest{
+			Client:  server.Client(),
+			BaseURL: server.URL,
+			Limits:  tt.limits,
+		})
+		if err != nil || len(list.Models) != tt.models || list.Pages != tt.pages || list.Truncated != tt.truncated {
+			t.Errorf("%s: expected %d models on %d pages (truncated %t), got %d on %d (%t, %v)",
+				tt.name, tt.models, tt.pages, tt.truncated, len(list.Models), list.Pages, list.Truncated, err)
+		}
+	}
+}
+
+func TestModelPagerDuplicatesAndStalls(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"data": [{"id": "a"}, {"id": "b"}], "next_cursor": "2"}`)
+		default:
+			// Repeats a model and hands back the same cursor forever
+			fmt.Fprint(w, `{"data": [{"id": "b"}, {"id": "c"}], "next_cursor": "2"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	list, err := openAIAdapter{}.ListModels(context.Background(), ListRequest{
+		Client:  server.Client(),
+		BaseURL: server.URL,
+		Limits:  storage.DefaultModelFetchSettings(),
+	})
+	if err != nil || len(list.Models) != 3 || list.Truncated {
+		t.Fatalf("Expected a, b and c once, got %+v (%v)", list, err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected the pager to stop at a repeated cursor, got %d requests", requests)
+	}
+}
+
+func TestModelPagerNextURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" {
+			fmt.Fprint(w, `{"data": [{"id": "a"}], "next": "/v1/models/page/2"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": [{"id": "b"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	list, err := openAIAdapter{}.ListModels(context.Background(), ListRequest{
+		Client:  server.Client(),
+		BaseURL: server.URL + "/v1",
+		Limits:  storage.DefaultModelFetchSettings(),
+	})
+	if err != nil || len(list.Models) != 2 || list.Pages != 2 {
+		t.Fatalf("Expected a relative next URL to be followed, got %+v (%v)", list, err)
+	}
+}
+
+func TestModelPagerStaysOnHost(t *testing.T) {
+	contacted := false
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contacted = true
+		fmt.Fprint(w, `{"data": [{"id": "stolen"}]}`)
+	}))
+	t.Cleanup(foreign.Close)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": [{"id": "a"}], "next": %q}`, foreign.URL+"/models?page=2")
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := openAIAdapter{}.ListModels(context.Background(), ListRequest{
+		Client:  server.Client(),
+		BaseURL: server.URL,
+		APIKey:  "sk-secret-123",
+		Limits:  storage.DefaultModelFetchSettings(),
+	})
+	if fe := asFetchError(err, ""); fe.Kind != models.FetchErrorInvalidURL {
+		t.Errorf("Expected a next page on another host to be refused, got %v", err)
+	}
+	if contacted {
+		t.Error("Expected the other host never to be contacted")
+	}
+}
+
+func TestFetchModelsTruncationWarning(t *testing.T) {
+	store, err := storage.NewWithDir(t.TempDir(), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	settings := NewSettingsService(store)
+	if err := settings.SetModelFetchSettings(storage.ModelFetchSettings{MaxPages: 0, MaxModels: 10}); err == nil {
+		t.Error("Expected a page cap of 0 to be rejected")
+	}
+	if err := settings.SetModelFetchSettings(storage.ModelFetchSettings{MaxPages: 1, MaxModels: 10}); err != nil {
+		t.Fatalf("Failed to set the caps: %v", err)
+	}
+
+	server := pagedServer(t, 5, 2)
+	result := NewModelFetcher(settings).FetchProtocolModels(models.ProtocolOpenAI, server.URL, "", nil)
+	if len(result.Models) != 2 || !result.Truncated || result.Pages != 1 || result.Warning == "" {
+		t.Errorf("Expected a truncated first page with a warning, got %+v", result)
+	}
+}
+
+func TestModelFetchSettingsDefaultEachCap(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewWithDir(dir, storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := os.WriteFile(store.SettingsPath(), []byte(`{"theme": "dark", "modelFetch": {"maxPages": 3}}`), 0600); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	got := NewSettingsService(store).GetModelFetchSettings()
+	if got.MaxPages != 3 || got.MaxModels != storage.DefaultModelFetchSettings().MaxModels {
+		t.Errorf("Expected the missing model cap to default, got %+v", got)
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// Default base URLs of protocols whose providers usually run at one place
+const (
+	geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	cohereBaseURL = "https://api.cohere.com/v1"
+)
+
+// azureDeploymentsAPIVersion is the newest Azure OpenAI API version that
+// lists deployments. It is always sent: the api-version of a base URL is
+// for inference and usually too new for the deployment listing.
+const azureDeploymentsAPIVersion = "2022-12-01"
+
+// ProtocolAdapter lists the models of a provider that speaks one protocol
+type ProtocolAdapter interface {
+	// ListModels fetches the models served at req.BaseURL, following pages
+	// up to req.Limits
+	ListModels(ctx context.Context, req ListRequest) (ModelList, error)
+}
+
+// ListRequest is what an adapter needs to list models. BaseURL may be
+// empty for protocols with a default.
+type ListRequest struct {
+	Client  *http.Client
+	BaseURL string
+	APIKey  string
+	Limits  storage.ModelFetchSettings
+}
+
+// pager returns a pager for req's client and limits
+func (req ListRequest) pager(header http.Header, cursor pageCursor) modelPager {
+	return modelPager{client: req.Client, header: header, limits: req.Limits, cursor: cursor}
+}
+
+// protocols lists the protocols with a built-in adapter
+var protocols = []models.ProviderProtocol{
+	models.ProtocolOpenAI,
+	models.ProtocolAnthropic,
+	models.ProtocolGemini,
+	models.ProtocolOllama,
+	models.ProtocolAzure,
+	models.ProtocolCohere,
+}
+
+// knownProtocol reports whether protocol has a built-in adapter
+func knownProtocol(protocol models.ProviderProtocol) bool {
+	for _, p := range protocols {
+		if p == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultProtocolAdapters returns the built-in adapters by protocol
+func defaultProtocolAdapters() map[models.ProviderProtocol]ProtocolAdapter {
+	return map[models.ProviderProtocol]ProtocolAdapter{
+		models.ProtocolOpenAI:    openAIAdapter{},
+		models.ProtocolAnthropic: anthropicAdapter{},
+		models.ProtocolGemini:    geminiAdapter{},
+		models.ProtocolOllama:    ollamaAdapter{},
+		models.ProtocolAzure:     azureAdapter{},
+		models.ProtocolCohere:    cohereAdapter{},
+	}
+}
+
+// detectProtocol guesses the protocol of a base URL from well-known hosts
+// and ports. OpenAI-compatible paths of those services, and unknown hosts,
+// return "".
+func detectProtocol(baseURL string) models.ProviderProtocol {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.ToLower(strings.TrimSuffix(u.Path, "/"))
+	switch {
+	case host == "generativelanguage.googleapis.com" && !strings.Contains(path, "/openai"):
+		return models.ProtocolGemini
+	case (strings.HasSuffix(host, ".openai.azure.com") || strings.HasSuffix(host, ".cognitiveservices.azure.com")) && !strings.HasSuffix(path, "/v1"):
+		return models.ProtocolAzure
+	case strings.Contains(host, "cohere") && !strings.Contains(path, "compatibility"):
+		return models.ProtocolCohere
+	case u.Port() == "11434" && !strings.HasSuffix(path, "/v1"):
+		return models.ProtocolOllama
+	}
+	return ""
+}
+
+// getJSON sends a GET request and decodes the JSON body of a 200 response.
+// Failures are returned as a *FetchError.
+func getJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header) (interface{}, error) {
+	redact := newRedactor(endpoint, header)
+	shown := redact.url(endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, &FetchError{Kind: models.FetchErrorInvalidURL, Endpoint: shown, Err: redact.err(err)}
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &FetchError{Kind: transportKind(err), Endpoint: shown, Err: redact.err(err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &FetchError{
+			Kind:       statusKind(resp.StatusCode),
+			Endpoint:   shown,
+			Status:     resp.StatusCode,
+			RetryAfter: retryAfter(resp.Header, time.Now()),
+			Body:       redact.text(excerpt(body)),
+		}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &FetchError{Kind: transportKind(err), Endpoint: shown, Status: resp.StatusCode, Err: redact.err(err)}
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, &FetchError{
+			Kind:     models.FetchErrorNotJSON,
+			Endpoint: shown,
+			Status:   resp.StatusCode,
+			Body:     redact.text(excerpt(body)),
+			Err:      fmt.Errorf("response is not JSON (Content-Type %q)", resp.Header.Get("Content-Type")),
+		}
+	}
+	return doc, nil
+}
+
+// joinURL appends path to a base URL, ignoring a trailing slash
+func joinURL(baseURL, path string) string {
+	return strings.TrimSuffix(baseURL, "/") + path
+}
+
+// openAIAdapter lists models with a Bearer token from GET /models
+type openAIAdapter struct{}
+
+func (openAIAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	header := http.Header{"Authorization": {"Bearer " + req.APIKey}}
+	return req.pager(header, openAICursor).list(ctx, joinURL(req.BaseURL, "/models"))
+}
+
+// anthropicAdapter lists models with an x-api-key header from GET /models,
+// following has_more and last_id
+type anthropicAdapter struct{}
+
+func (anthropicAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	header := http.Header{
+		"X-Api-Key":         {req.APIKey},
+		"Anthropic-Version": {"2023-06-01"},
+	}
+	return req.pager(header, afterCursor("after_id")).list(ctx, joinURL(req.BaseURL, "/models?limit=1000"))
+}
+
+// geminiAdapter lists Gemini models, named "models/<id>", with the key in
+// the query and following nextPageToken
+type geminiAdapter struct{}
+
+func (geminiAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	baseURL := req.BaseURL
+	if baseURL == "" {
+		baseURL = geminiBaseURL
+	}
+	query := url.Values{"key": {req.APIKey}, "pageSize": {"1000"}}
+	return req.pager(nil, tokenCursor("nextPageToken", "pageToken")).list(ctx, joinURL(baseURL, "/models?"+query.Encode()))
+}
+
+// ollamaAdapter lists the local models of an Ollama server from
+// GET /api/tags. The base URL may be the server or its /v1 endpoint.
+type ollamaAdapter struct{}
+
+func (ollamaAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	baseURL := strings.TrimSuffix(req.BaseURL, "/")
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/v1"), "/api")
+	header := http.Header{}
+	if req.APIKey != "" {
+		// Ollama needs no key, but a proxy in front of it may
+		header.Set("Authorization", "Bearer "+req.APIKey)
+	}
+	return req.pager(header, nil).list(ctx, baseURL+"/api/tags")
+}
+
+// azureAdapter lists the deployments of an Azure OpenAI resource with an
+// api-key header. Deployment names are what requests use as the model, so
+// they become the model IDs.
+type azureAdapter struct{}
+
+func (azureAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	u, err := url.Parse(req.BaseURL)
+	if err != nil || u.Host == "" {
+		return ModelList{}, &FetchError{
+			Kind:     models.FetchErrorInvalidURL,
+			Endpoint: req.BaseURL,
+			Err:      fmt.Errorf("invalid Azure OpenAI endpoint: %s", req.BaseURL),
+		}
+	}
+	// Keep whatever the base URL has before /openai, such as a gateway prefix
+	prefix := strings.TrimRight(u.Path, "/")
+	if i := strings.Index(prefix+"/", "/openai/"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	endpoint := u.Scheme + "://" + u.Host + prefix + "/openai/deployments?api-version=" + azureDeploymentsAPIVersion
+	pager := req.pager(http.Header{"Api-Key": {req.APIKey}}, nil)
+	pager.read = readyDeployments
+	return pager.list(ctx, endpoint)
+}
+
+// readyDeployments reads an Azure OpenAI deployment list, leaving out
+// deployments that are not ready
+func readyDeployments(doc interface{}) []models.FetchedModel {
+	status := make(map[string]string)
+	base := make(map[string]string)
+	for _, item := range asSlice(asMap(doc)["data"]) {
+		e := asMap(item)
+		status[asString(e["id"])] = asString(e["status"])
+		base[asString(e["id"])] = asString(e["model"])
+	}
+
+	fetched := modelsIn(doc)
+	ready := make([]models.FetchedModel, 0, len(fetched))
+	for _, m := range fetched {
+		if s := status[m.ID]; s != "" && s != "succeeded" {
+			continue
+		}
+		if model := base[m.ID]; model != "" {
+			m.Description = "Deployment of " + model
+		}
+		ready = append(ready, m)
+	}
+	return ready
+}
+
+// cohereAdapter lists Cohere models with a Bearer token, following
+// next_page_token
+type cohereAdapter struct{}
+
+func (cohereAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	baseURL := req.BaseURL
+	if baseURL == "" {
+		baseURL = cohereBaseURL
+	}
+	header := http.Header{"Authorization": {"Bearer " + req.APIKey}}
+	return req.pager(header, tokenCursor("next_page_token", "page_token")).list(ctx, joinURL(baseURL, "/models?page_size=1000"))
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// listModels runs adapter against a stand-in server serving handler
+func listModels(t *testing.T, adapter ProtocolAdapter, path, apiKey string, handler http.HandlerFunc) ([]models.FetchedModel, error) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	list, err := adapter.ListModels(context.Background(), ListRequest{
+		Client:  server.Client(),
+		BaseURL: server.URL + path,
+		APIKey:  apiKey,
+		Limits:  storage.DefaultModelFetchSettings(),
+	})
+	return list.Models, err
+}
+
+func TestGeminiAdapter(t *testing.T) {
+	fetched, err := listModels(t, geminiAdapter{}, "/v1beta", "gm-key", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models" || r.URL.Query().Get("key") != "gm-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			fmt.Fprint(w, `{"models": [{"name": "models/gemini-1.5-pro", "displayName": "Gemini 1.5 Pro",
+				"inputTokenLimit": 2000000, "outputTokenLimit": 8192}], "nextPageToken": "page-2"}`)
+		case "page-2":
+			fmt.Fprint(w, `{"models": [{"name": "models/gemini-1.5-flash", "inputTokenLimit": 1000000}]}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	if err != nil || len(fetched) != 2 {
+		t.Fatalf("Expected both pages, got %+v (%v)", fetched, err)
+	}
+	if fetched[0].ID != "gemini-1.5-pro" || fetched[0].ContextLength != 2000000 || fetched[1].ID != "gemini-1.5-flash" {
+		t.Errorf("Expected Gemini model names as IDs, got %+v", fetched)
+	}
+}
+
+func TestOllamaAdapter(t *testing.T) {
+	for _, path := range []string{"", "/v1", "/v1/"} {
+		fetched, err := listModels(t, ollamaAdapter{}, path, "", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/tags" || r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"models": [{"name": "llama3:latest", "model": "llama3:latest",
+				"details": {"family": "llama", "parameter_size": "8.0B"}}]}`)
+		})
+		if err != nil || len(fetched) != 1 {
+			t.Fatalf("%q: expected one model, got %+v (%v)", path, fetched, err)
+		}
+		if fetched[0].ID != "llama3:latest" || fetched[0].Parameters != "8B" {
+			t.Errorf("%q: expected llama3 with 8B parameters, got %+v", path, fetched[0])
+		}
+	}
+}
+
+func TestAzureAdapter(t *testing.T) {
+	// The base URL's api-version is for inference; the listing needs its own
+	tests := []struct {
+		base, path string
+	}{
+		{"/openai/deployments/chat?api-version=2024-10-21", "/openai/deployments"},
+		{"/", "/openai/deployments"},
+		{"/gateway/openai?api-version=2023-05-15", "/gateway/openai/deployments"},
+	}
+	for _, tt := range tests {
+		fetched, err := listModels(t, azureAdapter{}, tt.base, "az-key", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != tt.path || r.URL.Query().Get("api-version") != azureDeploymentsAPIVersion || r.Header.Get("api-key") != "az-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"data": [
+				{"id": "chat", "model": "gpt-4o", "status": "succeeded", "object": "deployment", "created_at": 1700000000},
+				{"id": "pending", "model": "gpt-4o-mini", "status": "running", "object": "deployment"}
+			], "object": "list"}`)
+		})
+		if err != nil || len(fetched) != 1 {
+			t.Fatalf("%q: expected the ready deployment, got %+v (%v)", tt.base, fetched, err)
+		}
+		if fetched[0].ID != "chat" || fetched[0].Description != "Deployment of gpt-4o" || fetched[0].Created != 1700000000 {
+			t.Errorf("%q: expected the deployment name as ID, got %+v", tt.base, fetched[0])
+		}
+	}
+}
+
+func TestCohereAdapter(t *testing.T) {
+	fetched, err := listModels(t, cohereAdapter{}, "/v1", "co-key", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer co-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("page_token") == "" {
+			fmt.Fprint(w, `{"models": [{"name": "command-r-plus", "endpoints": ["chat"], "context_length": 128000}], "next_page_token": "next"}`)
+			return
+		}
+		fmt.Fprint(w, `{"models": [{"name": "embed-english-v3.0", "endpoints": ["embed"], "context_length": 512}]}`)
+	})
+	if err != nil || len(fetched) != 2 {
+		t.Fatalf("Expected both pages, got %+v (%v)", fetched, err)
+	}
+	if fetched[0].ID != "command-r-plus" || fetched[0].ContextLength != 128000 {
+		t.Errorf("Expected Cohere names as IDs, got %+v", fetched[0])
+	}
+}
+
+func TestAnthropicAdapter(t *testing.T) {
+	fetched, err := listModels(t, anthropicAdapter{}, "/v1", "sk-ant", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.Header.Get("x-api-key") != "sk-ant" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"data": [{"type": "model", "id": "claude-3-5-haiku-20241022", "display_name": "Claude 3.5 Haiku"}]}`)
+	})
+	if err != nil || len(fetched) != 1 || fetched[0].DisplayName != "Claude 3.5 Haiku" {
+		t.Fatalf("Expected the Anthropic model, got %+v (%v)", fetched, err)
+	}
+}
+
+func TestDetectProtocol(t *testing.T) {
+	tests := map[string]models.ProviderProtocol{
+		"https://generativelanguage.googleapis.com/v1beta":         models.ProtocolGemini,
+		"https://generativelanguage.googleapis.com/v1beta/openai/": "",
+		"https://my-resource.openai.azure.com":                     models.ProtocolAzure,
+		"https://my-resource.openai.azure.com/openai/v1":           "",
+		"https://api.cohere.com/v1":                                models.ProtocolCohere,
+		"https://api.cohere.ai/compatibility/v1":                   "",
+		"http://localhost:11434":                                   models.ProtocolOllama,
+		"http://localhost:11434/v1":                                "",
+		"https://api.openai.com/v1":                                "",
+	}
+	for baseURL, want := range tests {
+		if got := detectProtocol(baseURL); got != want {
+			t.Errorf("%s: expected %q, got %q", baseURL, want, got)
+		}
+	}
+}
+
+// fakeAdapter returns a fixed list and records the base URL it was given
+type fakeAdapter struct {
+	baseURL *string
+	fetched []models.FetchedModel
+}
+
+func (a fakeAdapter) ListModels(ctx context.Context, req ListRequest) (ModelList, error) {
+	*a.baseURL = req.BaseURL
+	if len(a.fetched) == 0 {
+		return ModelList{}, fmt.Errorf("HTTP 404")
+	}
+	return ModelList{Models: a.fetched, Pages: 1}, nil
+}
+
+func TestFetchProtocolModels(t *testing.T) {
+	fetcher := NewModelFetcher(nil)
+	var ollamaURL, openAIURL, anthropicURL string
+	fetcher.SetAdapter(models.ProtocolOllama, fakeAdapter{baseURL: &ollamaURL, fetched: []models.FetchedModel{{ID: "llama3"}}})
+	fetcher.SetAdapter(models.ProtocolOpenAI, fakeAdapter{baseURL: &openAIURL})
+	fetcher.SetAdapter(models.ProtocolAnthropic, fakeAdapter{baseURL: &anthropicURL, fetched: []models.FetchedModel{{ID: "claude"}}})
+
+	result := fetcher.FetchProtocolModels(models.ProtocolOllama, "http://gpu-box:8080", "", nil)
+	if len(result.Models) != 1 || result.Models[0].ID != "llama3" || ollamaURL != "http://gpu-box:8080" {
+		t.Errorf("Expected the Ollama adapter, got %+v at %q", result, ollamaURL)
+	}
+
+	// Detected from the port
+	ollamaURL = ""
+	result = fetcher.FetchModels("http://localhost:11434", "", nil)
+	if len(result.Models) != 1 || ollamaURL != "http://localhost:11434" {
+		t.Errorf("Expected the Ollama adapter to be detected, got %+v", result)
+	}
+
+	// OpenAI first, then Anthropic at its own URL
+	anthropic := "https://api.anthropic.com/v1"
+	result = fetcher.FetchModels("https://proxy.example.com/v1", "sk", &anthropic)
+	if len(result.Models) != 1 || result.Models[0].ID != "claude" || openAIURL != "https://proxy.example.com/v1" || anthropicURL != anthropic {
+		t.Errorf("Expected the OpenAI then the Anthropic adapter, got %+v", result)
+	}
+
+	result = fetcher.FetchProtocolModels("grpc", "https://example.com", "", nil)
+	if result.Error == "" || len(result.Models) != 0 {
+		t.Errorf("Expected an unknown protocol error, got %+v", result)
+	}
+}
+
+func TestValidateProviderProtocol(t *testing.T) {
+	p := models.Provider{Name: "Local", Protocol: models.ProtocolOllama}
+	if result := ValidateProvider(&p); !result.Valid {
+		t.Errorf("Expected a known protocol to be valid, got %+v", result.Errors)
+	}
+	p.Protocol = "grpc"
+	if result := ValidateProvider(&p); result.Valid || result.Errors[0].Field != "protocol" {
+		t.Errorf("Expected an unknown protocol to be rejected, got %+v", result)
+	}
+}
@@ -59,11 +59,6 @@ func (s *ProviderService) CreateProvider(p models.Provider) (*models.Provider, e
 		return nil, validation.ToError()
 	}
 
-	providers, err := s.storage.Load()
-	if err != nil {
-		return nil, err
-	}
-
 	// Generate ID if not provided
 	if p.ID == "" {
 		p.ID = generateProviderID(p.Name)
@@ -71,7 +66,7 @@ func (s *ProviderService) CreateProvider(p models.Provider) (*models.Provider, e
 
 	// Set defaults
 	if p.Credentials.APIKeys == nil {
-		p.Credentials.APIKeys = []string{}
+		p.Credentials.APIKeys = []models.APIKey{}
 	}
 	if p.Models == nil {
 		p.Models = []models.Model{}
@@ -82,170 +77,149 @@ func (s *ProviderService) CreateProvider(p models.Provider) (*models.Provider, e
 
 	p.IsCustom = true
 
-	providers = append(providers, p)
-	if err := s.storage.Save(providers); err != nil {
+	err := s.storage.UpdateOp("createProvider", func(providers *[]models.Provider) error {
+		*providers = append(*providers, p)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	p.Revision = 1
 	return &p, nil
 }
 
-// UpdateProvider updates an existing provider
+// UpdateProvider updates an existing provider. If updates carries a
+// revision, it must match the stored one or a storage.ConflictError is
+// returned.
 func (s *ProviderService) UpdateProvider(id string, updates models.Provider) error {
 	// Validate before saving (STRICT)
 	if validation := ValidateProvider(&updates); !validation.Valid {
 		return validation.ToError()
 	}
 
-	providers, err := s.storage.Load()
-	if err != nil {
-		return err
-	}
-
-	found := false
-	for i, p := range providers {
-		if p.ID == id {
-			// Preserve ID and models from existing provider
-			updates.ID = id
-			if updates.Models == nil {
-				updates.Models = p.Models
-			}
-			providers[i] = updates
-			found = true
-			break
+	return s.updateProvider("updateProvider", id, updates.Revision, func(p *models.Provider) error {
+		// Preserve ID and models from existing provider
+		updates.ID = id
+		if updates.Models == nil {
+			updates.Models = p.Models
 		}
-	}
-
-	if !found {
-		return fmt.Errorf("provider not found: %s", id)
-	}
-
-	return s.storage.Save(providers)
+		updates.Credentials.APIKeys = keepKeyRecords(p.Credentials.APIKeys, updates.Credentials.APIKeys)
+		*p = updates
+		return nil
+	})
 }
 
-// DeleteProvider deletes a provider by ID
+// DeleteProvider deletes a provider by ID. Storage removes its keys with it.
 func (s *ProviderService) DeleteProvider(id string) error {
-	providers, err := s.storage.Load()
-	if err != nil {
-		return err
-	}
-
-	newProviders := make([]models.Provider, 0, len(providers)-1)
-	found := false
-	for _, p := range providers {
-		if p.ID == id {
-			found = true
-			continue
+	return s.storage.UpdateOp("deleteProvider", func(providers *[]models.Provider) error {
+		newProviders := make([]models.Provider, 0, len(*providers))
+		for _, p := range *providers {
+			if p.ID != id {
+				newProviders = append(newProviders, p)
+			}
 		}
-		newProviders = append(newProviders, p)
-	}
-
-	if !found {
-		return fmt.Errorf("provider not found: %s", id)
-	}
-
-	return s.storage.Save(newProviders)
-}
-
-// UpdateCredentials updates provider API keys
-func (s *ProviderService) UpdateCredentials(id string, keys []string) error {
-	providers, err := s.storage.Load()
-	if err != nil {
-		return err
-	}
-
-	for i, p := range providers {
-		if p.ID == id {
-			providers[i].Credentials.APIKeys = keys
-			return s.storage.Save(providers)
+		if len(newProviders) == len(*providers) {
+			return fmt.Errorf("provider not found: %s", id)
 		}
-	}
-
-	return fmt.Errorf("provider not found: %s", id)
+		*providers = newProviders
+		return nil
+	})
 }
 
-// AddModel adds a model to a provider
-func (s *ProviderService) AddModel(providerID string, m models.Model) error {
+// AddModel adds a model to a provider. A non-zero revision must match the
+// stored one or a storage.ConflictError is returned; the same holds for the
+// other model and key operations.
+func (s *ProviderService) AddModel(providerID string, m models.Model, revision int64) error {
 	// Validate model before saving (STRICT) - also auto-fills name from ID
 	if validation := ValidateModel(&m); !validation.Valid {
 		return validation.ToError()
 	}
 
-	providers, err := s.storage.Load()
-	if err != nil {
-		return err
-	}
-
-	for i, p := range providers {
-		if p.ID == providerID {
-			providers[i].Models = append(providers[i].Models, m)
-			return s.storage.Save(providers)
-		}
-	}
-
-	return fmt.Errorf("provider not found: %s", providerID)
+	return s.updateProvider("addModel", providerID, revision, func(p *models.Provider) error {
+		p.Models = append(p.Models, m)
+		return nil
+	})
 }
 
 // UpdateModel updates a model in a provider
-func (s *ProviderService) UpdateModel(providerID, modelID string, updates models.Model) error {
+func (s *ProviderService) UpdateModel(providerID, modelID string, updates models.Model, revision int64) error {
 	// Validate model before saving (STRICT) - also auto-fills name from ID
 	if validation := ValidateModel(&updates); !validation.Valid {
 		return validation.ToError()
 	}
 
-	providers, err := s.storage.Load()
-	if err != nil {
-		return err
-	}
-
-	for i, p := range providers {
-		if p.ID == providerID {
-			for j, m := range p.Models {
-				if m.ID == modelID {
-					updates.ID = modelID
-					providers[i].Models[j] = updates
-					return s.storage.Save(providers)
-				}
+	return s.updateProvider("updateModel", providerID, revision, func(p *models.Provider) error {
+		for j, m := range p.Models {
+			if m.ID == modelID {
+				updates.ID = modelID
+				p.Models[j] = updates
+				return nil
 			}
-			return fmt.Errorf("model not found: %s", modelID)
 		}
-	}
-
-	return fmt.Errorf("provider not found: %s", providerID)
+		return fmt.Errorf("model not found: %s", modelID)
+	})
 }
 
 // DeleteModel removes a model from a provider
-func (s *ProviderService) DeleteModel(providerID, modelID string) error {
-	providers, err := s.storage.Load()
-	if err != nil {
-		return err
-	}
-
-	for i, p := range providers {
-		if p.ID == providerID {
-			newModels := make([]models.Model, 0, len(p.Models)-1)
-			found := false
-			for _, m := range p.Models {
-				if m.ID == modelID {
-					found = true
-					continue
-				}
+func (s *ProviderService) DeleteModel(providerID, modelID string, revision int64) error {
+	return s.updateProvider("deleteModel", providerID, revision, func(p *models.Provider) error {
+		newModels := make([]models.Model, 0, len(p.Models))
+		for _, m := range p.Models {
+			if m.ID != modelID {
 				newModels = append(newModels, m)
 			}
-			if !found {
-				return fmt.Errorf("model not found: %s", modelID)
+		}
+		if len(newModels) == len(p.Models) {
+			return fmt.Errorf("model not found: %s", modelID)
+		}
+		p.Models = newModels
+		return nil
+	})
+}
+
+// SaveProviders saves all providers (bulk operation). Providers that carry a
+// revision must still be at that revision, and every stored provider must be
+// in the list, otherwise nothing is saved. Providers are removed with
+// DeleteProvider, never by leaving them out.
+func (s *ProviderService) SaveProviders(providers []models.Provider) error {
+	return s.storage.UpdateOp("saveProviders", func(current *[]models.Provider) error {
+		for _, existing := range *current {
+			p := providerByID(providers, existing.ID)
+			if p == nil {
+				return &storage.ConflictError{ProviderID: existing.ID, Actual: existing.Revision, Missing: true}
+			}
+			if err := storage.CheckRevision(existing, p.Revision); err != nil {
+				return err
 			}
-			providers[i].Models = newModels
-			return s.storage.Save(providers)
 		}
-	}
+		*current = providers
+		return nil
+	})
+}
 
-	return fmt.Errorf("provider not found: %s", providerID)
+// updateProvider applies fn to one provider in a storage transaction journaled
+// as op. A non-zero revision must match the stored one.
+func (s *ProviderService) updateProvider(op, providerID string, revision int64, fn func(p *models.Provider) error) error {
+	return s.storage.UpdateOp(op, func(providers *[]models.Provider) error {
+		if p := providerByID(*providers, providerID); p != nil {
+			if err := storage.CheckRevision(*p, revision); err != nil {
+				return err
+			}
+			return fn(p)
+		}
+		return fmt.Errorf("provider not found: %s", providerID)
+	})
 }
 
-// SaveProviders saves all providers (bulk operation)
-func (s *ProviderService) SaveProviders(providers []models.Provider) error {
-	return s.storage.Save(providers)
+// providerByID returns the provider with id, or nil
+func providerByID(providers []models.Provider, id string) *models.Provider {
+	for i := range providers {
+		if providers[i].ID == id {
+			return &providers[i]
+		}
+	}
+	return nil
 }
 
 // ClearAllData removes all stored data
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,14 +23,16 @@ func setupTestProviderService(t *testing.T) (*ProviderService, func()) {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 
-	// Create storage for testing
-	testStore, _ := storage.New()
+	// Create isolated storage with an in-memory keyring for testing
+	testStore, err := storage.NewWithDir(tempDir, storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
 
 	service := NewProviderService(testStore)
 
 	cleanup := func() {
 		os.RemoveAll(tempDir)
-		testStore.Clear()
 	}
 
 	return service, cleanup
@@ -81,7 +86,7 @@ func TestProviderService_CreateProvider(t *testing.T) {
 			OpenAI: "https://api.example.com/v1",
 		},
 		Credentials: models.Credentials{
-			APIKeys: []string{},
+			APIKeys: []models.APIKey{},
 		},
 		Models: []models.Model{},
 		Limits: []models.Limit{},
@@ -230,7 +235,7 @@ func TestProviderService_AddModel(t *testing.T) {
 		},
 	}
 
-	err = service.AddModel(created.ID, model)
+	err = service.AddModel(created.ID, model, 0)
 	if err != nil {
 		t.Fatalf("AddModel failed: %v", err)
 	}
@@ -284,3 +289,130 @@ func TestProviderService_ClearAllData(t *testing.T) {
 		t.Errorf("Expected 0 providers after clear, got %d", len(providers))
 	}
 }
+
+func TestProviderService_UpdateProvider_Conflict(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+
+	created, err := service.CreateProvider(models.Provider{
+		Name:      "Conflict Provider",
+		Endpoints: models.Endpoints{OpenAI: "https://api.example.com/v1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+
+	// Two editors read revision 1
+	first := *created
+	second := *created
+
+	first.Name = "First Edit"
+	if err := service.UpdateProvider(created.ID, first); err != nil {
+		t.Fatalf("First update failed: %v", err)
+	}
+
+	second.Name = "Second Edit"
+	err = service.UpdateProvider(created.ID, second)
+	if !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+
+	p, _ := service.GetProvider(created.ID)
+	if p.Name != "First Edit" || p.Revision != 2 {
+		t.Errorf("Expected first edit at revision 2, got %q at %d", p.Name, p.Revision)
+	}
+
+	// SaveProviders with the stale copy must also be rejected
+	if err := service.SaveProviders([]models.Provider{second}); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("Expected conflict from SaveProviders, got %v", err)
+	}
+}
+
+func TestProviderService_ModelAndKeyOps_Conflict(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+
+	created, err := service.CreateProvider(models.Provider{
+		Name:      "Stale Provider",
+		Endpoints: models.Endpoints{OpenAI: "https://api.example.com/v1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+
+	// The editor loaded revision 1; another window then added a model
+	m := models.Model{ID: "model-a", Context: models.Context{MaxInput: 1000}}
+	if err := service.AddModel(created.ID, m, created.Revision); err != nil {
+		t.Fatalf("AddModel failed: %v", err)
+	}
+
+	stale := created.Revision
+	if err := service.DeleteModel(created.ID, "model-a", stale); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("Expected conflict from DeleteModel, got %v", err)
+	}
+	if err := service.UpdateModel(created.ID, "model-a", m, stale); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("Expected conflict from UpdateModel, got %v", err)
+	}
+	if _, err := service.AddAPIKey(created.ID, models.APIKey{Key: "sk-stale-000000001"}, stale); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("Expected conflict from AddAPIKey, got %v", err)
+	}
+
+	p, _ := service.GetProvider(created.ID)
+	if len(p.Models) != 1 || len(p.Credentials.APIKeys) != 0 {
+		t.Errorf("Stale operations changed the provider: %d models, %d keys", len(p.Models), len(p.Credentials.APIKeys))
+	}
+	if _, err := service.AddAPIKey(created.ID, models.APIKey{Key: "sk-fresh-000000001"}, p.Revision); err != nil {
+		t.Errorf("AddAPIKey at the current revision failed: %v", err)
+	}
+}
+
+func TestProviderService_SaveProviders_MissingProvider(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+
+	kept, _ := service.CreateProvider(models.Provider{Name: "Kept", Endpoints: models.Endpoints{OpenAI: "https://a.example.com/v1"}})
+	// Created by another window after the caller loaded the list
+	added, _ := service.CreateProvider(models.Provider{Name: "Added", Endpoints: models.Endpoints{OpenAI: "https://b.example.com/v1"}})
+
+	err := service.SaveProviders([]models.Provider{*kept})
+	var conflict *storage.ConflictError
+	if !errors.As(err, &conflict) || !conflict.Missing || conflict.ProviderID != added.ID {
+		t.Fatalf("Expected a missing-provider conflict for %s, got %v", added.ID, err)
+	}
+
+	providers, _ := service.GetAllProviders()
+	if len(providers) != 2 {
+		t.Errorf("Expected both providers to be kept, got %d", len(providers))
+	}
+}
+
+func TestProviderService_ConcurrentAddModel(t *testing.T) {
+	service, cleanup := setupTestProviderService(t)
+	defer cleanup()
+
+	created, err := service.CreateProvider(models.Provider{
+		Name:      "Busy Provider",
+		Endpoints: models.Endpoints{OpenAI: "https://api.example.com/v1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m := models.Model{ID: fmt.Sprintf("model-%d", i), Context: models.Context{MaxInput: 1000}}
+			if err := service.AddModel(created.ID, m, 0); err != nil {
+				t.Errorf("AddModel failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	p, _ := service.GetProvider(created.ID)
+	if len(p.Models) != 10 {
+		t.Errorf("Expected 10 models, got %d", len(p.Models))
+	}
+}
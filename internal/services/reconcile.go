@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"llm-desk/internal/storage"
+)
+
+// Kinds of key storage problems found by reconciliation
+const (
+	KeyIssueOrphaned   = "orphaned"   // Keys stored for a provider that no longer exists
+	KeyIssueLoadFailed = "loadFailed" // Keys for an existing provider could not be read
+	KeyIssuePlaintext  = "plaintext"  // Keys still stored in providers.json
+)
+
+// Keyring health states
+const (
+	KeyringHealthOK          = "ok"
+	KeyringHealthDegraded    = "degraded"
+	KeyringHealthUnavailable = "unavailable"
+)
+
+// KeyIssue is a single reconciliation finding with its repair action
+type KeyIssue struct {
+	Kind         string `json:"kind"`
+	ProviderID   string `json:"providerId"`
+	ProviderName string `json:"providerName,omitempty"`
+	Detail       string `json:"detail"`
+	Repair       string `json:"repair"`
+}
+
+// KeyringHealth summarizes the state of API key storage for the UI
+type KeyringHealth struct {
+	Status    string     `json:"status"`
+	Issues    []KeyIssue `json:"issues"`
+	Listable  bool       `json:"listable"`
+	Message   string     `json:"message,omitempty"`
+	CheckedAt string     `json:"checkedAt"`
+}
+
+// ReconcileService compares providers.json with the key backend and repairs
+// mismatches between them
+type ReconcileService struct {
+	storage *storage.Storage
+}
+
+// NewReconcileService creates a new ReconcileService
+func NewReconcileService(s *storage.Storage) *ReconcileService {
+	return &ReconcileService{storage: s}
+}
+
+// Health returns a cheap status based on the key errors seen by the last
+// load, without touching the key backend
+func (r *ReconcileService) Health() KeyringHealth {
+	health := KeyringHealth{
+		Status:    KeyringHealthOK,
+		Issues:    []KeyIssue{},
+		CheckedAt: time.Now().Format(time.RFC3339),
+	}
+
+	errs := r.storage.KeyErrors()
+	ids := make([]string, 0, len(errs))
+	for id := range errs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		health.Issues = append(health.Issues, loadFailedIssue(id, "", errs[id]))
+	}
+	if len(health.Issues) > 0 {
+		health.Status = KeyringHealthDegraded
+	}
+	return health
+}
+
+// Scan checks every provider against the key backend and lists orphaned,
+// unreadable and plaintext keys
+func (r *ReconcileService) Scan() (KeyringHealth, error) {
+	health := KeyringHealth{
+		Status:    KeyringHealthOK,
+		Issues:    []KeyIssue{},
+		CheckedAt: time.Now().Format(time.RFC3339),
+	}
+
+	report, err := r.storage.InspectKeys()
+	if err != nil {
+		return health, err
+	}
+	health.Listable = report.Listable
+	if !report.Listable {
+		health.Message = "Orphaned keys cannot be detected: " + report.ListError
+	}
+
+	for _, id := range report.Orphaned() {
+		health.Issues = append(health.Issues, KeyIssue{
+			Kind:       KeyIssueOrphaned,
+			ProviderID: id,
+			Detail:     "Keys are stored for a provider that no longer exists",
+			Repair:     "Delete the stored keys",
+		})
+	}
+
+	loadFailed := make([]string, 0, len(report.LoadErrors))
+	for id := range report.LoadErrors {
+		loadFailed = append(loadFailed, id)
+	}
+	sort.Strings(loadFailed)
+	for _, id := range loadFailed {
+		health.Issues = append(health.Issues, loadFailedIssue(id, report.Providers[id], report.LoadErrors[id]))
+	}
+
+	for _, id := range report.Plaintext {
+		health.Issues = append(health.Issues, KeyIssue{
+			Kind:         KeyIssuePlaintext,
+			ProviderID:   id,
+			ProviderName: report.Providers[id],
+			Detail:       "API keys are stored unencrypted in providers.json",
+			Repair:       "Move the keys to secure storage",
+		})
+	}
+
+	switch {
+	case len(loadFailed) > 0 && len(loadFailed) == len(report.Providers):
+		health.Status = KeyringHealthUnavailable
+	case len(health.Issues) > 0:
+		health.Status = KeyringHealthDegraded
+	}
+	return health, nil
+}
+
+// Repair applies the repair action for an issue reported by Scan
+func (r *ReconcileService) Repair(kind, providerID string) error {
+	switch kind {
+	case KeyIssueOrphaned:
+		providers, err := r.storage.Load()
+		if err != nil {
+			return err
+		}
+		for _, p := range providers {
+			if p.ID == providerID {
+				return fmt.Errorf("provider %s exists; its keys are not orphaned", providerID)
+			}
+		}
+		return r.storage.DeleteProviderKeys(providerID)
+
+	case KeyIssueLoadFailed:
+		// Retry first; only discard the entry if it is still unreadable
+		_, err := r.storage.Keyring().GetKeys(providerID)
+		if err == nil {
+			// Reload so the recorded key errors are refreshed
+			_, err = r.storage.Load()
+			return err
+		}
+		if errors.Is(err, storage.ErrVaultLocked) {
+			// The keys are intact, just not accessible yet
+			return err
+		}
+		// Only discard when the backend can read its index, so a backend
+		// that is briefly unreachable does not lose valid keys
+		lister, ok := r.storage.Keyring().(storage.KeyLister)
+		if !ok {
+			return fmt.Errorf("keys of %s were kept: the key backend cannot list its entries: %w", providerID, err)
+		}
+		if _, listErr := lister.ListProviderIDs(); listErr != nil {
+			return fmt.Errorf("keys of %s were kept: the key backend is not reachable: %w", providerID, listErr)
+		}
+		return r.storage.DeleteProviderKeys(providerID)
+
+	case KeyIssuePlaintext:
+		return r.storage.SecurePlaintextKeys(providerID)
+
+	default:
+		return fmt.Errorf("unknown key issue: %s", kind)
+	}
+}
+
+// loadFailedIssue builds the issue for a provider whose keys could not be read
+func loadFailedIssue(id, name, detail string) KeyIssue {
+	return KeyIssue{
+		Kind:         KeyIssueLoadFailed,
+		ProviderID:   id,
+		ProviderName: name,
+		Detail:       detail,
+		Repair:       "Retry, or discard the unreadable entry so keys can be re-entered",
+	}
+}
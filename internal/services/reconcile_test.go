@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+func setupTestReconcileService(t *testing.T) (*ReconcileService, *storage.Storage, *storage.MemoryKeyring) {
+	t.Helper()
+
+	keyring := storage.NewMemoryKeyring()
+	store, err := storage.NewWithDir(t.TempDir(), keyring)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return NewReconcileService(store), store, keyring
+}
+
+func findIssue(issues []KeyIssue, kind, providerID string) *KeyIssue {
+	for i := range issues {
+		if issues[i].Kind == kind && issues[i].ProviderID == providerID {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestReconcileService_DeleteProviderRemovesKeys(t *testing.T) {
+	_, store, keyring := setupTestReconcileService(t)
+	service := NewProviderService(store)
+
+	created, err := service.CreateProvider(models.Provider{
+		Name:        "Keyed",
+		Endpoints:   models.Endpoints{OpenAI: "https://api.example.com"},
+		Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-1")},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider failed: %v", err)
+	}
+	if keys, _ := keyring.GetKeys(created.ID); len(keys) != 1 {
+		t.Fatalf("Expected key in keyring, got %v", keys)
+	}
+
+	if err := service.DeleteProvider(created.ID); err != nil {
+		t.Fatalf("DeleteProvider failed: %v", err)
+	}
+	if ids, _ := keyring.ListProviderIDs(); len(ids) != 0 {
+		t.Errorf("Expected no keyring entries after delete, got %v", ids)
+	}
+}
+
+func TestReconcileService_ScanAndRepair(t *testing.T) {
+	reconciler, store, keyring := setupTestReconcileService(t)
+
+	if err := store.Save([]models.Provider{{ID: "live", Name: "Live", Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-live")}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// A leftover entry from a provider deleted by an older version
+	keyring.SetKeys("gone", []string{"sk-gone"})
+
+	// A provider whose keys never left providers.json
+	plaintext := `{"schemaVersion": 2, "providers": [
+		{"id": "live", "name": "Live", "credentials": {"apiKeys": []}},
+		{"id": "leaky", "name": "Leaky", "credentials": {"apiKeys": ["sk-leak"]}}
+	]}`
+	if err := os.WriteFile(filepath.Join(store.GetDataDir(), "providers.json"), []byte(plaintext), 0644); err != nil {
+		t.Fatalf("Failed to write providers: %v", err)
+	}
+
+	health, err := reconciler.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if health.Status != KeyringHealthDegraded {
+		t.Errorf("Expected degraded status, got %s", health.Status)
+	}
+	if findIssue(health.Issues, KeyIssueOrphaned, "gone") == nil {
+		t.Error("Expected orphaned issue for 'gone'")
+	}
+	if findIssue(health.Issues, KeyIssuePlaintext, "leaky") == nil {
+		t.Error("Expected plaintext issue for 'leaky'")
+	}
+
+	if err := reconciler.Repair(KeyIssueOrphaned, "live"); err == nil {
+		t.Error("Expected refusal to delete keys of an existing provider")
+	}
+	if err := reconciler.Repair(KeyIssueOrphaned, "gone"); err != nil {
+		t.Fatalf("Repair orphaned failed: %v", err)
+	}
+	if err := reconciler.Repair(KeyIssuePlaintext, "leaky"); err != nil {
+		t.Fatalf("Repair plaintext failed: %v", err)
+	}
+
+	health, err = reconciler.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if health.Status != KeyringHealthOK {
+		t.Errorf("Expected ok after repairs, got %s with %+v", health.Status, health.Issues)
+	}
+	if keys, _ := keyring.GetKeys("leaky"); len(keys) != 1 || keys[0] != "sk-leak" {
+		t.Errorf("Expected plaintext key moved to keyring, got %v", keys)
+	}
+	if keys, _ := keyring.GetKeys("live"); len(keys) != 1 {
+		t.Errorf("Expected other providers' keys untouched, got %v", keys)
+	}
+}
+
+func TestReconcileService_HealthReportsLockedVault(t *testing.T) {
+	reconciler, store, _ := setupTestReconcileService(t)
+
+	if err := store.Save([]models.Provider{{ID: "p1", Name: "P1"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	store.SetKeyring(storage.NewFileVault(filepath.Join(store.GetDataDir(), storage.VaultFilename)))
+
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	health := reconciler.Health()
+	if health.Status != KeyringHealthDegraded || findIssue(health.Issues, KeyIssueLoadFailed, "p1") == nil {
+		t.Errorf("Expected load failure to be surfaced, got %+v", health)
+	}
+
+	if err := reconciler.Repair(KeyIssueLoadFailed, "p1"); err == nil {
+		t.Error("Repair must not discard keys of a locked vault")
+	}
+}
+
+// unreachableKeyring fails every read, like an OS keyring that is briefly
+// unavailable
+type unreachableKeyring struct {
+	*storage.MemoryKeyring
+}
+
+func (k unreachableKeyring) GetKeys(providerID string) ([]string, error) {
+	return nil, errors.New("keyring daemon not responding")
+}
+
+func (k unreachableKeyring) ListProviderIDs() ([]string, error) {
+	return nil, errors.New("keyring daemon not responding")
+}
+
+func TestReconcileService_RepairKeepsKeysOfUnreachableBackend(t *testing.T) {
+	reconciler, store, keyring := setupTestReconcileService(t)
+
+	if err := store.Save([]models.Provider{{ID: "p1", Name: "P1", Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-1")}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	store.SetKeyring(unreachableKeyring{keyring})
+
+	if err := reconciler.Repair(KeyIssueLoadFailed, "p1"); err == nil {
+		t.Error("Expected Repair to refuse while the backend is unreachable")
+	}
+	if keys, _ := keyring.GetKeys("p1"); len(keys) != 1 {
+		t.Errorf("Expected the keys to be kept, got %v", keys)
+	}
+}
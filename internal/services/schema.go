@@ -0,0 +1,433 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"llm-desk/internal/models"
+)
+
+// DataSchemaID is where the published JSON Schema of the exchange format
+// lives. The committed copy is website/schema/llmdesk-data.schema.json.
+const DataSchemaID = "https://raw.githubusercontent.com/shantoislamdev/LLM-Desk/main/website/schema/llmdesk-data.schema.json"
+
+// maxSchemaErrors caps the errors reported for one document
+const maxSchemaErrors = 50
+
+// legacySchemas lists the older encodings accepted by types with a custom
+// UnmarshalJSON, next to their normal object form
+var legacySchemas = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(models.APIKey{}): {
+		"type":        "string",
+		"description": "Legacy form: the bare API key",
+	},
+}
+
+// schemaEnums holds the allowed values of fields tagged with a bare enum
+// rule, by type and field name. They come from the declared constants so
+// the schema follows when one is added.
+var schemaEnums = map[string][]string{
+	"Provider.Protocol": protocolNames(),
+	"Metadata.Secrets": {
+		string(models.ExportSecretsInclude),
+		string(models.ExportSecretsExclude),
+		string(models.ExportSecretsEncrypt),
+	},
+	"APIKey.Status": {models.KeyStatusUnknown, models.KeyStatusValid, models.KeyStatusInvalid},
+}
+
+// protocolNames returns the protocols with a built-in adapter as strings
+func protocolNames() []string {
+	names := make([]string, len(protocols))
+	for i, p := range protocols {
+		names[i] = string(p)
+	}
+	return names
+}
+
+var (
+	dataSchemaOnce sync.Once
+	dataSchema     map[string]interface{}
+	dataSchemaJSON []byte
+)
+
+// DataSchema returns the JSON Schema of models.LLMDeskData, the format of
+// exports, backups and imports
+func DataSchema() []byte {
+	loadDataSchema()
+	return dataSchemaJSON
+}
+
+// loadDataSchema generates the schema once
+func loadDataSchema() {
+	dataSchemaOnce.Do(func() {
+		dataSchema = generateDataSchema()
+		raw, err := json.MarshalIndent(dataSchema, "", "  ")
+		if err != nil {
+			panic(fmt.Sprintf("data schema: %v", err))
+		}
+		dataSchemaJSON = append(raw, '\n')
+
+		// Use the decoded form so validation sees what the file holds
+		dataSchema = nil
+		if err := json.Unmarshal(dataSchemaJSON, &dataSchema); err != nil {
+			panic(fmt.Sprintf("data schema: %v", err))
+		}
+	})
+}
+
+// generateDataSchema builds the schema from the json and schema tags of the
+// models. It describes the structure; value rules such as non-negative prices
+// are left to sanitizeImport, which repairs or quarantines single items.
+func generateDataSchema() map[string]interface{} {
+	g := &schemaGenerator{defs: map[string]interface{}{}}
+	root := g.structSchema(reflect.TypeOf(models.LLMDeskData{}))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = DataSchemaID
+	root["title"] = "LLM Desk data"
+	root["description"] = fmt.Sprintf("Providers, models and API keys exported by LLM Desk (format version %s)", schemaVersion)
+	root["$defs"] = g.defs
+	return root
+}
+
+// schemaGenerator collects the definitions of named struct types
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// typeSchema returns the schema of values of type t
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.typeSchema(t.Elem()))
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = true // Placeholder against recursion
+			g.defs[t.Name()] = g.defSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		return nullable(map[string]interface{}{"type": "array", "items": g.typeSchema(t.Elem())})
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(t.Elem())})
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	panic(fmt.Sprintf("data schema: unsupported type %s", t))
+}
+
+// defSchema returns the definition of a named struct type, including the
+// legacy encodings its UnmarshalJSON accepts
+func (g *schemaGenerator) defSchema(t reflect.Type) map[string]interface{} {
+	def := g.structSchema(t)
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		legacy, ok := legacySchemas[t]
+		if !ok {
+			panic(fmt.Sprintf("data schema: %s decodes JSON itself and needs an entry in legacySchemas", t))
+		}
+		def = map[string]interface{}{"oneOf": []interface{}{legacy, def}}
+	}
+	return def
+}
+
+// structSchema returns the object schema of a struct type
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := g.typeSchema(f.Type)
+		for _, rule := range strings.Split(f.Tag.Get("schema"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "":
+			case "required":
+				required = append(required, name)
+			case "enum":
+				if value != "" {
+					prop["enum"] = strings.Split(value, "|")
+					break
+				}
+				values, ok := schemaEnums[t.Name()+"."+f.Name]
+				if !ok {
+					panic(fmt.Sprintf("data schema: %s.%s needs an entry in schemaEnums", t.Name(), f.Name))
+				}
+				prop["enum"] = values
+			default:
+				panic(fmt.Sprintf("data schema: %s.%s: unknown schema rule %q", t.Name(), f.Name, rule))
+			}
+		}
+		properties[name] = prop
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// nullable lets a schema also accept null, which encoding/json writes for
+// nil pointers, slices and maps
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+		return schema
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+// ValidateData checks a decoded JSON document against the data schema and
+// returns where it does not match. At most maxSchemaErrors are returned.
+func ValidateData(doc interface{}) []models.SchemaError {
+	loadDataSchema()
+	v := &schemaValidator{root: dataSchema, errors: []models.SchemaError{}}
+	v.validate(dataSchema, doc, "")
+	return v.errors
+}
+
+// ValidateDataJSON is ValidateData for raw JSON
+func ValidateDataJSON(raw []byte) ([]models.SchemaError, error) {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return ValidateData(doc), nil
+}
+
+// SchemaValidationError reports a document that does not match the data
+// schema
+type SchemaValidationError struct {
+	Errors []models.SchemaError
+}
+
+func (e *SchemaValidationError) Error() string {
+	first := e.Errors[0]
+	msg := fmt.Sprintf("%s: %s", schemaLocation(first.Pointer), first.Message)
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more problems)", len(e.Errors)-1)
+	}
+	return "file does not match the LLM Desk format at " + msg
+}
+
+// schemaLocation names a JSON pointer for messages; the document root is ""
+func schemaLocation(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
+
+// schemaValidator checks documents against the subset of JSON Schema the
+// generator emits: type, properties, required, items, additionalProperties,
+// enum, anyOf, oneOf and local $ref
+type schemaValidator struct {
+	root   map[string]interface{}
+	errors []models.SchemaError
+}
+
+// fail records a problem at pointer
+func (v *schemaValidator) fail(pointer, format string, args ...interface{}) {
+	if len(v.errors) < maxSchemaErrors {
+		v.errors = append(v.errors, models.SchemaError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// validate checks value, found at pointer, against schema
+func (v *schemaValidator) validate(schema map[string]interface{}, value interface{}, pointer string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		v.validate(v.resolve(ref), value, pointer)
+		return
+	}
+	if alternatives, ok := schema["anyOf"].([]interface{}); ok {
+		v.validateAlternatives(alternatives, value, pointer)
+		return
+	}
+	if alternatives, ok := schema["oneOf"].([]interface{}); ok {
+		v.validateAlternatives(alternatives, value, pointer)
+		return
+	}
+
+	if types := schemaTypes(schema); types != nil && !typeAllowed(types, value) {
+		v.fail(pointer, "expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && value != nil {
+		found := false
+		for _, allowed := range enum {
+			if allowed == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(enum))
+			for i, allowed := range enum {
+				names[i] = fmt.Sprint(allowed)
+			}
+			v.fail(pointer, "must be one of %s, got %q", strings.Join(names, ", "), fmt.Sprint(value))
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, pointer)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				v.validate(items, item, pointer+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+}
+
+// validateObject checks the members of an object
+func (v *schemaValidator) validateObject(schema map[string]interface{}, value map[string]interface{}, pointer string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, present := value[name.(string)]; !present {
+				v.fail(pointer+"/"+escapePointer(name.(string)), "is required")
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, _ := schema["additionalProperties"].(map[string]interface{})
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if prop, ok := properties[name].(map[string]interface{}); ok {
+			v.validate(prop, value[name], pointer+"/"+escapePointer(name))
+		} else if additional != nil {
+			v.validate(additional, value[name], pointer+"/"+escapePointer(name))
+		}
+	}
+}
+
+// validateAlternatives checks value against the alternative whose type fits
+// it, so that errors point inside the value rather than just saying that no
+// alternative matched
+func (v *schemaValidator) validateAlternatives(alternatives []interface{}, value interface{}, pointer string) {
+	var expected []string
+	for _, alternative := range alternatives {
+		schema := alternative.(map[string]interface{})
+		types := v.acceptedTypes(schema)
+		if types == nil || typeAllowed(types, value) {
+			v.validate(schema, value, pointer)
+			return
+		}
+		expected = append(expected, types...)
+	}
+	v.fail(pointer, "expected %s, got %s", strings.Join(expected, " or "), jsonType(value))
+}
+
+// acceptedTypes returns the JSON types a schema allows, or nil for any
+func (v *schemaValidator) acceptedTypes(schema map[string]interface{}) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return v.acceptedTypes(v.resolve(ref))
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if alternatives, ok := schema[key].([]interface{}); ok {
+			var types []string
+			for _, alternative := range alternatives {
+				more := v.acceptedTypes(alternative.(map[string]interface{}))
+				if more == nil {
+					return nil
+				}
+				types = append(types, more...)
+			}
+			return types
+		}
+	}
+	return schemaTypes(schema)
+}
+
+// resolve looks up a local "#/$defs/Name" reference
+func (v *schemaValidator) resolve(ref string) map[string]interface{} {
+	defs, _ := v.root["$defs"].(map[string]interface{})
+	def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+	if !ok {
+		panic(fmt.Sprintf("data schema: unresolved reference %s", ref))
+	}
+	return def
+}
+
+// schemaTypes returns the "type" keyword as a list, or nil if it is absent
+func schemaTypes(schema map[string]interface{}) []string {
+	switch typ := schema["type"].(type) {
+	case string:
+		return []string{typ}
+	case []interface{}:
+		types := make([]string, len(typ))
+		for i, t := range typ {
+			types[i] = t.(string)
+		}
+		return types
+	}
+	return nil
+}
+
+// typeAllowed reports whether value has one of the JSON types
+func typeAllowed(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, typ := range types {
+		if typ == actual || (typ == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// escapePointer escapes a member name for a JSON pointer (RFC 6901)
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
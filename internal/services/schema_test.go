@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+// publishedSchema is the committed copy of the data schema
+var publishedSchema = filepath.Join("..", "..", "website", "schema", "llmdesk-data.schema.json")
+
+func TestDataSchemaMatchesPublished(t *testing.T) {
+	published, err := os.ReadFile(publishedSchema)
+	if err != nil {
+		t.Fatalf("Failed to read published schema: %v", err)
+	}
+	if !bytes.Equal(published, DataSchema()) {
+		t.Errorf("Published schema is out of date with the models; run: go run . schema --out website/schema/llmdesk-data.schema.json")
+	}
+}
+
+func TestDataSchemaAcceptsExports(t *testing.T) {
+	service, _, _ := setupTestExportService(t)
+
+	for _, secrets := range []models.ExportSecrets{models.ExportSecretsExclude, models.ExportSecretsInclude} {
+		var buf bytes.Buffer
+		if _, err := service.Export(&buf, models.ExportOptions{Secrets: secrets}); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+		errs, err := ValidateDataJSON(buf.Bytes())
+		if err != nil || len(errs) != 0 {
+			t.Errorf("Expected the %s export to match the schema, got %+v (%v)", secrets, errs, err)
+		}
+	}
+
+	// Empty collections are written as null by older versions
+	errs, _ := ValidateDataJSON([]byte(`{"providers": [{"id": "p", "name": "P", "limits": null, "models": null,
+		"credentials": {"apiKeys": ["sk-legacy"]}}]}`))
+	if len(errs) != 0 {
+		t.Errorf("Expected nulls and legacy keys to be accepted, got %+v", errs)
+	}
+}
+
+func TestDataSchemaEnumsFollowConstants(t *testing.T) {
+	for _, protocol := range protocols {
+		doc := fmt.Sprintf(`{"metadata": {"secrets": %q}, "providers": [{"id": "p", "name": "P", "protocol": %q,
+			"credentials": {"apiKeys": [{"id": "k", "label": "", "createdAt": "", "enabled": true, "status": %q}]}}]}`,
+			models.ExportSecretsEncrypt, protocol, models.KeyStatusInvalid)
+		if errs, err := ValidateDataJSON([]byte(doc)); err != nil || len(errs) != 0 {
+			t.Errorf("Expected protocol %s to match the schema, got %+v (%v)", protocol, errs, err)
+		}
+	}
+}
+
+func TestValidateData(t *testing.T) {
+	errs, err := ValidateDataJSON([]byte(`{
+		"version": "1.0.0",
+		"metadata": {"secrets": "plain"},
+		"providers": [
+			{"id": "p", "name": "P", "enabled": "yes", "models": [
+				{"id": "m1", "context": {"maxInput": 1000}},
+				{"id": "m2", "context": {"maxInput": "lots"}, "limits": [{"type": "calls", "limit": 1}]}
+			], "credentials": {"apiKeys": [42]}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+
+	want := map[string]string{
+		"/metadata/secrets":                      "must be one of include, exclude, encrypt",
+		"/providers/0/enabled":                   "expected boolean, got string",
+		"/providers/0/models/1/context/maxInput": "expected integer, got string",
+		"/providers/0/models/1/limits/0/type":    "must be one of requests, tokens",
+		"/providers/0/models/1/limits/0/window":  "is required",
+		"/providers/0/credentials/apiKeys/0":     "expected string or object, got integer",
+	}
+	got := map[string]string{}
+	for _, e := range errs {
+		got[e.Pointer] = e.Message
+	}
+	for pointer, message := range want {
+		if !strings.HasPrefix(got[pointer], message) {
+			t.Errorf("Expected %s: %q, got %q", pointer, message, got[pointer])
+		}
+	}
+	if len(errs) != len(want) {
+		t.Errorf("Expected %d errors, got %+v", len(want), errs)
+	}
+
+	errs, _ = ValidateDataJSON([]byte(`[]`))
+	if len(errs) != 1 || errs[0].Pointer != "" {
+		t.Errorf("Expected a root type error, got %+v", errs)
+	}
+	if escapePointer("a/b~c") != "a~1b~0c" {
+		t.Errorf("Expected RFC 6901 escaping, got %q", escapePointer("a/b~c"))
+	}
+}
+
+func TestImportRejectsSchemaErrors(t *testing.T) {
+	service, store, _ := setupTestExportService(t)
+
+	content := `{"version": "1.0.0", "providers": [{"id": "p", "name": "P", "models": [{"id": "m", "pricing": {"input": "free"}}]}]}`
+	result, err := service.Import(strings.NewReader(content), "replace", models.ImportOptions{})
+	if err == nil || result.Success {
+		t.Fatalf("Expected the import to fail, got %+v", result)
+	}
+	if len(result.SchemaErrors) != 1 || result.SchemaErrors[0].Pointer != "/providers/0/models/0/pricing/input" {
+		t.Errorf("Expected the error located at the price, got %+v", result.SchemaErrors)
+	}
+	if !strings.Contains(result.Message, "/providers/0/models/0/pricing/input") {
+		t.Errorf("Expected the location in the message, got %q", result.Message)
+	}
+
+	providers, _ := store.Load()
+	if len(providers) != 1 || providers[0].ID != "openai" {
+		t.Errorf("Expected the catalog unchanged, got %+v", providers)
+	}
+}
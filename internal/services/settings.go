@@ -1,11 +1,22 @@
 package services
 
 import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
 	"llm-desk/internal/storage"
 )
 
+// Upper bounds for the model fetch caps
+const (
+	maxFetchPages  = 1000
+	maxFetchModels = 100000
+)
+
 // SettingsService handles app settings persistence
 type SettingsService struct {
+	mu       sync.RWMutex
 	storage  *storage.Storage
 	settings storage.AppSettings
 }
@@ -14,12 +25,12 @@ type SettingsService struct {
 func NewSettingsService(s *storage.Storage) *SettingsService {
 	svc := &SettingsService{
 		storage:  s,
-		settings: storage.AppSettings{Theme: "dark", FollowSystemTheme: true, EnableCrashReporting: true}, // Default
+		settings: storage.AppSettings{Theme: "dark", FollowSystemTheme: true, EnableCrashReporting: true, KeyBackend: storage.KeyBackendAuto, Backup: storage.DefaultBackupSettings(), ModelFetch: storage.DefaultModelFetchSettings()}, // Default
 	}
 
 	// Load settings on initialization
 	if loaded, err := s.LoadSettings(); err == nil && loaded != nil {
-		svc.settings = *loaded
+		svc.settings = withDefaults(*loaded)
 	}
 
 	return svc
@@ -27,6 +38,8 @@ func NewSettingsService(s *storage.Storage) *SettingsService {
 
 // GetTheme returns the current theme
 func (s *SettingsService) GetTheme() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if s.settings.Theme == "" {
 		return "dark"
 	}
@@ -40,33 +53,149 @@ func (s *SettingsService) SetTheme(theme string) error {
 		theme = "dark"
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.settings.Theme = theme
 	return s.storage.SaveSettings(&s.settings)
 }
 
 // GetFollowSystemTheme returns if system theme should be followed
 func (s *SettingsService) GetFollowSystemTheme() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.settings.FollowSystemTheme
 }
 
 // SetFollowSystemTheme sets the system theme preference
 func (s *SettingsService) SetFollowSystemTheme(follow bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.settings.FollowSystemTheme = follow
 	return s.storage.SaveSettings(&s.settings)
 }
 
 // GetSettings returns all settings
 func (s *SettingsService) GetSettings() storage.AppSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.settings
 }
 
 // GetCrashReporting returns if crash reporting is enabled
 func (s *SettingsService) GetCrashReporting() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.settings.EnableCrashReporting
 }
 
 // SetCrashReporting sets the crash reporting preference
 func (s *SettingsService) SetCrashReporting(enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.settings.EnableCrashReporting = enabled
 	return s.storage.SaveSettings(&s.settings)
 }
+
+// GetKeyBackend returns the preferred API key backend
+func (s *SettingsService) GetKeyBackend() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.settings.KeyBackend == "" {
+		return storage.KeyBackendAuto
+	}
+	return s.settings.KeyBackend
+}
+
+// SetKeyBackend sets the preferred API key backend
+func (s *SettingsService) SetKeyBackend(backend string) error {
+	switch backend {
+	case storage.KeyBackendAuto, storage.KeyBackendKeyring, storage.KeyBackendVault:
+	default:
+		return fmt.Errorf("invalid key backend: %s", backend)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.KeyBackend = backend
+	return s.storage.SaveSettings(&s.settings)
+}
+
+// GetBackupSettings returns the automatic backup settings
+func (s *SettingsService) GetBackupSettings() storage.BackupSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.Backup
+}
+
+// SetBackupSettings validates and persists the automatic backup settings
+func (s *SettingsService) SetBackupSettings(backup storage.BackupSettings) error {
+	if backup.IntervalMinutes < 0 {
+		return fmt.Errorf("backup interval cannot be negative")
+	}
+	if backup.KeepLast < 1 {
+		return fmt.Errorf("at least one backup must be kept")
+	}
+	if backup.KeepDaily < 0 || backup.KeepWeekly < 0 {
+		return fmt.Errorf("backup retention cannot be negative")
+	}
+	if backup.Directory != "" && !filepath.IsAbs(backup.Directory) {
+		return fmt.Errorf("backup directory must be an absolute path: %s", backup.Directory)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.Backup = backup
+	return s.storage.SaveSettings(&s.settings)
+}
+
+// GetModelFetchSettings returns the caps for fetching model lists
+func (s *SettingsService) GetModelFetchSettings() storage.ModelFetchSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.ModelFetch
+}
+
+// SetModelFetchSettings validates and persists the caps for fetching model
+// lists
+func (s *SettingsService) SetModelFetchSettings(fetch storage.ModelFetchSettings) error {
+	if fetch.MaxPages < 1 || fetch.MaxPages > maxFetchPages {
+		return fmt.Errorf("page cap must be between 1 and %d", maxFetchPages)
+	}
+	if fetch.MaxModels < 1 || fetch.MaxModels > maxFetchModels {
+		return fmt.Errorf("model cap must be between 1 and %d", maxFetchModels)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings.ModelFetch = fetch
+	return s.storage.SaveSettings(&s.settings)
+}
+
+// Reload re-reads settings.json, picking up changes made outside the app
+func (s *SettingsService) Reload() (storage.AppSettings, error) {
+	loaded, err := s.storage.LoadSettings()
+	if err != nil {
+		return s.GetSettings(), err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if loaded != nil {
+		s.settings = withDefaults(*loaded)
+	}
+	return s.settings, nil
+}
+
+// withDefaults fills in the default backup and model fetch settings for a
+// settings file written before they existed
+func withDefaults(settings storage.AppSettings) storage.AppSettings {
+	if settings.Backup == (storage.BackupSettings{}) {
+		settings.Backup = storage.DefaultBackupSettings()
+	}
+	// Each cap separately, so a file with only one of them set still works
+	defaults := storage.DefaultModelFetchSettings()
+	if settings.ModelFetch.MaxPages <= 0 {
+		settings.ModelFetch.MaxPages = defaults.MaxPages
+	}
+	if settings.ModelFetch.MaxModels <= 0 {
+		settings.ModelFetch.MaxModels = defaults.MaxModels
+	}
+	return settings
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"llm-desk/internal/models"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// shareStringPrefix starts every template share string. The rest is the
+// deflated template JSON in unpadded base64url, a dot and a checksum.
+const shareStringPrefix = "llmdesk-template-1:"
+
+// shareChecksumLength is the number of hex digits of SHA-256 kept as checksum
+const shareChecksumLength = 8
+
+// maxTemplateSize limits how much a share string may inflate to
+const maxTemplateSize = 8 << 20
+
+// BuildTemplate turns the given providers into a template bundle, leaving
+// out credentials and local IDs
+func (s *ExportService) BuildTemplate(providerIDs []string) (*models.TemplateBundle, error) {
+	if len(providerIDs) == 0 {
+		return nil, fmt.Errorf("select at least one provider to share")
+	}
+	providers, err := s.storage.Load()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Provider, len(providers))
+	for _, p := range providers {
+		byID[p.ID] = p
+	}
+
+	bundle := &models.TemplateBundle{
+		Kind:      models.TemplateKind,
+		Version:   models.TemplateVersion,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Templates: []models.ProviderTemplate{},
+	}
+	for _, id := range providerIDs {
+		p, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("provider not found: %s", id)
+		}
+		bundle.Templates = append(bundle.Templates, templateFromProvider(p))
+	}
+	return bundle, nil
+}
+
+// templateFromProvider copies the shareable parts of p
+func templateFromProvider(p models.Provider) models.ProviderTemplate {
+	tmpl := models.ProviderTemplate{
+		Name:      p.Name,
+		Endpoints: p.Endpoints,
+		Protocol:  p.Protocol,
+		Limits:    append([]models.Limit(nil), p.Limits...),
+		Features:  p.Features,
+		Models:    append([]models.Model{}, p.Models...),
+	}
+	if p.Endpoints.Anthropic != nil {
+		anthropic := *p.Endpoints.Anthropic
+		tmpl.Endpoints.Anthropic = &anthropic
+	}
+	return tmpl
+}
+
+// ShareTemplate returns the given providers as a template share string
+func (s *ExportService) ShareTemplate(providerIDs []string) (models.TemplateExportResult, error) {
+	bundle, err := s.BuildTemplate(providerIDs)
+	if err != nil {
+		return models.TemplateExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	share, err := encodeShareString(bundle)
+	if err != nil {
+		return models.TemplateExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	result := templateExportResult(bundle, "Copied template")
+	result.ShareString = share
+	return result, nil
+}
+
+// ExportTemplate saves the given providers as a template to a user-selected
+// file
+func (s *ExportService) ExportTemplate(providerIDs []string) (models.TemplateExportResult, error) {
+	filepath, err := s.fileDialogs().SaveFile(runtime.SaveDialogOptions{
+		DefaultFilename: "llm-desk-template.json",
+		Title:           "Export Provider Template",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Provider Templates (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return models.TemplateExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+
+	// User cancelled
+	if filepath == "" {
+		return models.TemplateExportResult{Cancelled: true, Message: "Export cancelled", Warnings: []string{}}, nil
+	}
+
+	return s.WriteTemplate(filepath, providerIDs)
+}
+
+// WriteTemplate writes the given providers as a template file to filepath,
+// without any dialog
+func (s *ExportService) WriteTemplate(filepath string, providerIDs []string) (models.TemplateExportResult, error) {
+	bundle, err := s.BuildTemplate(providerIDs)
+	if err != nil {
+		return models.TemplateExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	content, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return models.TemplateExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	// Templates hold no secrets and are meant to be passed on
+	if err := os.WriteFile(filepath, append(content, '\n'), 0644); err != nil {
+		return models.TemplateExportResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+	result := templateExportResult(bundle, "Exported template")
+	result.Path = filepath
+	return result, nil
+}
+
+// templateExportResult reports a shared bundle
+func templateExportResult(bundle *models.TemplateBundle, verb string) models.TemplateExportResult {
+	result := models.TemplateExportResult{Success: true, Providers: len(bundle.Templates), Warnings: []string{}}
+	for _, tmpl := range bundle.Templates {
+		result.Models += len(tmpl.Models)
+	}
+	result.Message = fmt.Sprintf("%s with %d providers and %d models", verb, result.Providers, result.Models)
+	return result
+}
+
+// encodeShareString renders a bundle as a compact, copy-pasteable string
+func encodeShareString(bundle *models.TemplateBundle) (string, error) {
+	content, err := json.Marshal(bundle)
+	if err != nil {
+		return "", err
+	}
+	var compressed bytes.Buffer
+	w, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(content); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(compressed.Bytes())
+	return shareStringPrefix + payload + "." + shareChecksum(payload), nil
+}
+
+// shareChecksum returns the checksum of a share string payload
+func shareChecksum(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])[:shareChecksumLength]
+}
+
+// decodeShareString parses a share string. Whitespace, e.g. from line
+// wrapping in a chat message, is ignored.
+func decodeShareString(share string) (*models.TemplateBundle, error) {
+	share = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, share)
+	rest, ok := strings.CutPrefix(share, shareStringPrefix)
+	if !ok {
+		return nil, fmt.Errorf("not an LLM Desk template share string")
+	}
+	payload, checksum, ok := strings.Cut(rest, ".")
+	if !ok || checksum != shareChecksum(payload) {
+		return nil, fmt.Errorf("the share string is incomplete or was changed; copy it again")
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid share string: %w", err)
+	}
+	content, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxTemplateSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid share string: %w", err)
+	}
+	if len(content) > maxTemplateSize {
+		return nil, fmt.Errorf("the template is too large")
+	}
+	return parseTemplateJSON(content)
+}
+
+// parseTemplate reads a template file's content or a share string
+func parseTemplate(text []byte) (*models.TemplateBundle, error) {
+	trimmed := bytes.TrimSpace(text)
+	if bytes.HasPrefix(trimmed, []byte(shareStringPrefix)) {
+		return decodeShareString(string(trimmed))
+	}
+	return parseTemplateJSON(trimmed)
+}
+
+// parseTemplateJSON parses and checks a template bundle
+func parseTemplateJSON(content []byte) (*models.TemplateBundle, error) {
+	var bundle models.TemplateBundle
+	if err := json.Unmarshal(content, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if bundle.Kind != models.TemplateKind {
+		return nil, fmt.Errorf("not an LLM Desk provider template")
+	}
+	if bundle.Version != models.TemplateVersion {
+		return nil, fmt.Errorf("unsupported template version %q; update LLM Desk to use it", bundle.Version)
+	}
+	if len(bundle.Templates) == 0 {
+		return nil, fmt.Errorf("the template has no providers")
+	}
+	return &bundle, nil
+}
+
+// ImportTemplate creates providers from a user-selected template file
+func (s *ExportService) ImportTemplate() (models.TemplateResult, error) {
+	filepath, err := s.fileDialogs().OpenFile(runtime.OpenDialogOptions{
+		Title: "Import Provider Template",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Provider Templates (*.json)", Pattern: "*.json"},
+			{DisplayName: "All Files", Pattern: "*"},
+		},
+	})
+	if err != nil {
+		return models.TemplateResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+
+	// User cancelled
+	if filepath == "" {
+		return models.TemplateResult{Cancelled: true, Message: "Import cancelled", Warnings: []string{}}, nil
+	}
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		return models.TemplateResult{Message: "Failed to read template: " + err.Error(), Warnings: []string{}}, err
+	}
+	return s.InstantiateTemplate(string(content))
+}
+
+// InstantiateTemplate creates a new provider, without API keys, for each
+// template in a share string or template file content. The result lists
+// the providers to prompt for keys.
+func (s *ExportService) InstantiateTemplate(text string) (models.TemplateResult, error) {
+	bundle, err := parseTemplate([]byte(text))
+	if err != nil {
+		return models.TemplateResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+
+	result := models.TemplateResult{Warnings: []string{}, Providers: []models.Provider{}, NeedsKeys: []string{}}
+	err = s.storage.UpdateOp("instantiateTemplate", func(providers *[]models.Provider) error {
+		created, warnings, err := providersFromTemplates(*providers, bundle.Templates)
+		if err != nil {
+			return err
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+		for _, d := range findDuplicates(*providers, created) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s uses the same endpoint as your provider %s", d.IncomingName, d.LocalName))
+		}
+		*providers = append(*providers, created...)
+		result.Providers = created
+		return nil
+	})
+	if err != nil {
+		return models.TemplateResult{Message: err.Error(), Warnings: []string{}}, err
+	}
+
+	for _, p := range result.Providers {
+		result.NeedsKeys = append(result.NeedsKeys, p.ID)
+	}
+	result.Success = true
+	result.Message = fmt.Sprintf("Created %d providers; add an API key to each to start using them", len(result.Providers))
+	return result, nil
+}
+
+// providersFromTemplates builds new providers with fresh IDs and, where a
+// name is taken, a numbered name. Nothing is created if any is invalid.
+// Invalid and repeated models are checked the way an import checks them,
+// left out and described in the returned warnings.
+func providersFromTemplates(current []models.Provider, templates []models.ProviderTemplate) ([]models.Provider, []string, error) {
+	ids := make(map[string]bool, len(current))
+	names := make(map[string]bool, len(current))
+	for _, p := range current {
+		ids[p.ID] = true
+		names[strings.ToLower(p.Name)] = true
+	}
+
+	created := make([]models.Provider, 0, len(templates))
+	warnings := []string{}
+	for _, tmpl := range templates {
+		p := models.Provider{
+			Name:        uniqueProviderName(strings.TrimSpace(tmpl.Name), names),
+			Enabled:     true,
+			Credentials: models.Credentials{APIKeys: []models.APIKey{}},
+			Endpoints:   tmpl.Endpoints,
+			Protocol:    tmpl.Protocol,
+			Limits:      tmpl.Limits,
+			Features:    tmpl.Features,
+			Models:      tmpl.Models,
+			IsCustom:    true,
+		}
+		if p.Limits == nil {
+			p.Limits = []models.Limit{}
+		}
+		if p.Models == nil {
+			p.Models = []models.Model{}
+		}
+		if validation := ValidateProvider(&p); !validation.Valid {
+			return nil, nil, fmt.Errorf("template %q is invalid: %w", tmpl.Name, validation.ToError())
+		}
+
+		p.ID = generateProviderID(p.Name)
+		for ids[p.ID] {
+			p.ID = generateProviderID(p.Name)
+		}
+		clean, _, issues := sanitizeImport([]models.Provider{p})
+		for _, issue := range issues {
+			warnings = append(warnings, fmt.Sprintf("%s: left out model %q: %s", p.Name, issue.ModelID, issue.Message))
+		}
+		p = clean[0]
+
+		ids[p.ID] = true
+		names[strings.ToLower(p.Name)] = true
+		created = append(created, p)
+	}
+	return created, warnings, nil
+}
+
+// uniqueProviderName returns name, or name with the first free number
+// appended if a provider already uses it
+func uniqueProviderName(name string, taken map[string]bool) string {
+	if !taken[strings.ToLower(name)] {
+		return name
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if !taken[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+}
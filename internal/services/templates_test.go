@@ -0,0 +1,137 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+func TestTemplate_ShareStringRoundTrip(t *testing.T) {
+	service, store, _ := setupTestExportService(t)
+
+	shared, err := service.ShareTemplate([]string{"openai"})
+	if err != nil || !shared.Success || shared.Providers != 1 || shared.Models != 1 {
+		t.Fatalf("ShareTemplate failed: %+v (%v)", shared, err)
+	}
+	if !strings.HasPrefix(shared.ShareString, shareStringPrefix) {
+		t.Errorf("Unexpected share string: %s", shared.ShareString)
+	}
+
+	bundle, err := decodeShareString(shared.ShareString)
+	if err != nil {
+		t.Fatalf("decodeShareString failed: %v", err)
+	}
+	content, _ := json.Marshal(bundle)
+	if strings.Contains(string(content), "sk-export-secret") || strings.Contains(string(content), `"id":"openai"`) {
+		t.Errorf("Expected no credentials or provider IDs in the template, got %s", content)
+	}
+
+	// Line wrapping added by chat clients is ignored
+	wrapped := shared.ShareString[:30] + "\n  " + shared.ShareString[30:]
+	result, err := service.InstantiateTemplate(wrapped)
+	if err != nil || !result.Success {
+		t.Fatalf("InstantiateTemplate failed: %+v (%v)", result, err)
+	}
+	created := result.Providers[0]
+	if created.ID == "openai" || created.Name != "OpenAI (2)" || len(created.Credentials.APIKeys) != 0 || created.Models[0].ID != "gpt-4o" {
+		t.Errorf("Unexpected provider: %+v", created)
+	}
+	if len(result.NeedsKeys) != 1 || result.NeedsKeys[0] != created.ID {
+		t.Errorf("Expected the new provider to need keys, got %v", result.NeedsKeys)
+	}
+
+	providers, _ := store.Load()
+	if len(providers) != 2 || len(findProvider(t, providers, "openai").Credentials.APIKeys) != 1 {
+		t.Errorf("Expected the template added next to the original, got %+v", providers)
+	}
+}
+
+func TestTemplate_RejectsDamagedShareStrings(t *testing.T) {
+	service, _, _ := setupTestExportService(t)
+	shared, _ := service.ShareTemplate([]string{"openai"})
+
+	payload, checksum, _ := strings.Cut(strings.TrimPrefix(shared.ShareString, shareStringPrefix), ".")
+	truncated := shareStringPrefix + payload[:len(payload)-4] + "." + checksum
+	for _, share := range []string{truncated, shared.ShareString[:len(shared.ShareString)-1], "llmdesk-template-9:abc.def", "hello"} {
+		if result, err := service.InstantiateTemplate(share); err == nil || result.Success {
+			t.Errorf("Expected %q to be rejected, got %+v", share, result)
+		}
+	}
+	if _, err := service.ShareTemplate([]string{"missing"}); err == nil {
+		t.Error("Expected an unknown provider to be rejected")
+	}
+	if _, err := service.ShareTemplate(nil); err == nil {
+		t.Error("Expected an empty selection to be rejected")
+	}
+}
+
+func TestTemplate_File(t *testing.T) {
+	service, store, dir := setupTestExportService(t)
+	path := filepath.Join(dir, "template.json")
+	dialogs := &fakeDialogs{savePath: path, openPath: path}
+	service.SetDialogs(dialogs)
+	err := store.Update(func(providers *[]models.Provider) error {
+		(*providers)[0].Endpoints.OpenAI = "https://api.openai.com/v1"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	exported, err := service.ExportTemplate([]string{"openai"})
+	if err != nil || !exported.Success || exported.Path != path {
+		t.Fatalf("ExportTemplate failed: %+v (%v)", exported, err)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "sk-export-secret") || !strings.Contains(string(content), models.TemplateKind) {
+		t.Errorf("Unexpected template file: %s", content)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	result, err := service.ImportTemplate()
+	if err != nil || !result.Success || result.Providers[0].Name != "OpenAI" {
+		t.Fatalf("ImportTemplate failed: %+v (%v)", result, err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no duplicate warnings in an empty catalog, got %v", result.Warnings)
+	}
+
+	// Importing again warns about the shared endpoint
+	result, _ = service.ImportTemplate()
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected a duplicate endpoint warning, got %v", result.Warnings)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"kind": "llm-desk-template", "version": "1", "templates": [{"name": ""}]}`), 0600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if result, err := service.ImportTemplate(); err == nil || result.Success {
+		t.Errorf("Expected an invalid template to be rejected, got %+v", result)
+	}
+}
+
+func TestTemplate_LeavesOutInvalidModels(t *testing.T) {
+	service, store, _ := setupTestExportService(t)
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	text := `{"kind": "llm-desk-template", "version": "1", "templates": [{"name": "Shared", "endpoints": {"openai": "https://shared.example.com/v1"},
+		"models": [{"id": "good", "context": {"maxInput": 1000}}, {"id": "good", "context": {"maxInput": 1000}}, {"id": "", "context": {"maxInput": 1000}}]}]}`
+	result, err := service.InstantiateTemplate(text)
+	if err != nil || !result.Success {
+		t.Fatalf("InstantiateTemplate failed: %+v (%v)", result, err)
+	}
+	if got := result.Providers[0].Models; len(got) != 1 || got[0].ID != "good" {
+		t.Errorf("Expected only the first valid model, got %+v", got)
+	}
+	if len(result.Warnings) != 2 || !strings.Contains(result.Warnings[0], "Duplicate model ID") {
+		t.Errorf("Expected a warning for each model left out, got %v", result.Warnings)
+	}
+}
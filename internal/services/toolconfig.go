@@ -0,0 +1,676 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gopkg.in/yaml.v3"
+)
+
+// toolConfigFilenames are the default file names of generated configs
+var toolConfigFilenames = map[models.ToolConfigFormat]string{
+	models.ToolConfigLiteLLM:   "config.yaml",
+	models.ToolConfigContinue:  "config.yaml",
+	models.ToolConfigAider:     ".aider.model.metadata.json",
+	models.ToolConfigOpenWebUI: "open-webui.env",
+	models.ToolConfigCline:     "cline-api-configurations.json",
+}
+
+// litellmNames maps knownProviders kinds to LiteLLM's provider prefixes
+// where they differ
+var litellmNames = map[string]string{
+	"together": "together_ai",
+	"lmstudio": "lm_studio",
+}
+
+// toolProvider is a provider prepared for config generation
+type toolProvider struct {
+	models.Provider
+	kind      string // knownProviders entry whose endpoint matches, or ""
+	baseURL   string
+	anthropic bool              // Only an Anthropic endpoint is configured
+	enabled   []models.Model    // Enabled models
+	envVar    string            // Variable the key is read from in env mode
+	key       string            // Key for tools that take one per provider
+	keys      map[string]string // Key for each enabled model, in inline mode
+}
+
+// keyFor returns the key written for model id, or "" in env mode and when
+// no usable key covers the model
+func (tp *toolProvider) keyFor(id string) string {
+	return tp.keys[id]
+}
+
+// toolConfig is a generated config held in memory
+type toolConfig struct {
+	content   []byte
+	warnings  []string
+	envVars   []string
+	plaintext bool
+	providers int
+	models    int
+}
+
+// ExportToolConfig generates a config file for another tool from the
+// catalog and saves it to a user-selected file
+func (s *ExportService) ExportToolConfig(opts models.ToolConfigOptions) (models.ToolConfigResult, error) {
+	opts, err := normalizeToolConfigOptions(opts)
+	if err != nil {
+		return models.ToolConfigResult{Message: err.Error(), Warnings: []string{}, EnvVars: []string{}}, err
+	}
+
+	filepath, err := s.fileDialogs().SaveFile(runtime.SaveDialogOptions{
+		DefaultFilename: toolConfigFilenames[opts.Format],
+		Title:           "Export " + toolConfigName(opts.Format) + " Config",
+	})
+	if err != nil {
+		return models.ToolConfigResult{Message: err.Error(), Warnings: []string{}, EnvVars: []string{}}, err
+	}
+
+	// User cancelled
+	if filepath == "" {
+		return models.ToolConfigResult{Cancelled: true, Message: "Export cancelled", Warnings: []string{}, EnvVars: []string{}}, nil
+	}
+
+	return s.WriteToolConfig(filepath, opts)
+}
+
+// WriteToolConfig generates a config file for another tool from the catalog
+// and writes it to filepath, without any dialog
+func (s *ExportService) WriteToolConfig(filepath string, opts models.ToolConfigOptions) (models.ToolConfigResult, error) {
+	providers, err := s.storage.Load()
+	if err != nil {
+		return models.ToolConfigResult{Message: err.Error(), Warnings: []string{}, EnvVars: []string{}}, err
+	}
+	config, err := generateToolConfig(providers, opts)
+	if err != nil {
+		return models.ToolConfigResult{Message: err.Error(), Warnings: []string{}, EnvVars: []string{}}, err
+	}
+	if err := storage.WriteFileAtomic(filepath, config.content, 0600); err != nil {
+		return models.ToolConfigResult{Message: err.Error(), Warnings: []string{}, EnvVars: []string{}}, err
+	}
+
+	return models.ToolConfigResult{
+		Success:                  true,
+		Message:                  fmt.Sprintf("Wrote %s config with %d models", toolConfigName(opts.Format), config.models),
+		Warnings:                 config.warnings,
+		Path:                     filepath,
+		Providers:                config.providers,
+		Models:                   config.models,
+		EnvVars:                  config.envVars,
+		ContainsPlaintextSecrets: config.plaintext,
+	}, nil
+}
+
+// normalizeToolConfigOptions applies defaults and rejects invalid options
+func normalizeToolConfigOptions(opts models.ToolConfigOptions) (models.ToolConfigOptions, error) {
+	if _, ok := toolConfigFilenames[opts.Format]; !ok {
+		return opts, fmt.Errorf("unsupported config format: %s", opts.Format)
+	}
+	switch opts.Keys {
+	case "":
+		opts.Keys = models.ToolConfigKeysEnv
+	case models.ToolConfigKeysEnv, models.ToolConfigKeysInline:
+	default:
+		return opts, fmt.Errorf("invalid key option: %s", opts.Keys)
+	}
+	return opts, nil
+}
+
+// toolConfigName returns the display name of a tool
+func toolConfigName(format models.ToolConfigFormat) string {
+	switch format {
+	case models.ToolConfigLiteLLM:
+		return "LiteLLM"
+	case models.ToolConfigContinue:
+		return "Continue"
+	case models.ToolConfigAider:
+		return "Aider"
+	case models.ToolConfigOpenWebUI:
+		return "Open WebUI"
+	case models.ToolConfigCline:
+		return "Cline"
+	}
+	return string(format)
+}
+
+// generateToolConfig renders the selected providers and their enabled
+// models in the format of opts
+func generateToolConfig(providers []models.Provider, opts models.ToolConfigOptions) (*toolConfig, error) {
+	opts, err := normalizeToolConfigOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	config := &toolConfig{warnings: []string{}, envVars: []string{}}
+	if opts.Format == models.ToolConfigAider && opts.Keys == models.ToolConfigKeysInline {
+		config.warnings = append(config.warnings, "Aider reads API keys from the environment; no keys were written")
+		opts.Keys = models.ToolConfigKeysEnv
+	}
+	selected, err := prepareToolProviders(providers, opts, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no enabled models to export")
+	}
+
+	switch opts.Format {
+	case models.ToolConfigLiteLLM:
+		config.content, err = renderLiteLLM(selected)
+	case models.ToolConfigContinue:
+		config.content, err = renderContinue(selected, opts.Keys)
+	case models.ToolConfigAider:
+		config.content, err = renderAider(selected, config)
+	case models.ToolConfigOpenWebUI:
+		config.content = renderOpenWebUI(selected, opts.Keys, config)
+	case models.ToolConfigCline:
+		config.content, err = renderCline(selected, opts.Keys, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// prepareToolProviders picks the providers to export, resolves their
+// endpoints and decides how each key is written
+func prepareToolProviders(providers []models.Provider, opts models.ToolConfigOptions, config *toolConfig) ([]*toolProvider, error) {
+	var chosen []models.Provider
+	if len(opts.Providers) == 0 {
+		for _, p := range providers {
+			if p.Enabled {
+				chosen = append(chosen, p)
+			}
+		}
+	} else {
+		byID := make(map[string]models.Provider, len(providers))
+		for _, p := range providers {
+			byID[p.ID] = p
+		}
+		for _, id := range opts.Providers {
+			p, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("provider not found: %s", id)
+			}
+			chosen = append(chosen, p)
+		}
+	}
+
+	usedVars := make(map[string]bool)
+	var selected []*toolProvider
+	for _, p := range chosen {
+		tp := &toolProvider{Provider: p, baseURL: strings.TrimRight(p.Endpoints.OpenAI, "/")}
+		if tp.baseURL == "" && p.Endpoints.Anthropic != nil {
+			tp.baseURL = strings.TrimRight(*p.Endpoints.Anthropic, "/")
+			tp.anthropic = true
+		}
+		tp.kind = knownKind(tp.baseURL)
+		for _, m := range p.Models {
+			if m.Enabled {
+				tp.enabled = append(tp.enabled, m)
+			}
+		}
+		if len(tp.enabled) == 0 {
+			config.warnings = append(config.warnings, fmt.Sprintf("%s has no enabled models and was left out", p.Name))
+			continue
+		}
+		if tp.baseURL == "" {
+			config.warnings = append(config.warnings, fmt.Sprintf("%s has no endpoint; tools will use their default", p.Name))
+		}
+
+		tp.envVar = uniqueEnvVar(envVarName(tp), p.ID, usedVars)
+		if opts.Keys == models.ToolConfigKeysInline {
+			var uncovered []string
+			tp.key, tp.keys, uncovered = usableKeys(p.Credentials, tp.enabled, time.Now())
+			if tp.key == "" {
+				config.warnings = append(config.warnings, fmt.Sprintf("%s has no usable API key; its key was left empty", p.Name))
+			} else {
+				config.plaintext = true
+				if len(uncovered) > 0 {
+					config.warnings = append(config.warnings, fmt.Sprintf("%s has no usable API key for %s; their keys were left empty", p.Name, strings.Join(uncovered, ", ")))
+				}
+			}
+		} else {
+			config.envVars = append(config.envVars, tp.envVar)
+		}
+
+		config.providers++
+		config.models += len(tp.enabled)
+		selected = append(selected, tp)
+	}
+	if config.plaintext {
+		config.warnings = append(config.warnings, "This config stores API keys in plaintext. Keep it somewhere safe, or generate it with environment variable references.")
+	}
+	return selected, nil
+}
+
+// knownKind returns the knownProviders entry whose default endpoint is
+// endpoint, or "" for custom endpoints
+func knownKind(endpoint string) string {
+	endpoint = normalizeEndpoint(endpoint)
+	if endpoint == "" {
+		return ""
+	}
+	kinds := make([]string, 0, len(knownProviders))
+	for kind := range knownProviders {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if normalizeEndpoint(knownProviders[kind].endpoint) == endpoint {
+			return kind
+		}
+	}
+	return ""
+}
+
+// envVarName returns the conventional key variable of a known provider,
+// or one derived from the provider's name
+func envVarName(tp *toolProvider) string {
+	base := tp.kind
+	if base == "" {
+		base = slugify(tp.Name)
+	}
+	if base == "" {
+		base = slugify(tp.ID)
+	}
+	return strings.ToUpper(strings.ReplaceAll(base, "-", "_")) + "_API_KEY"
+}
+
+// uniqueEnvVar returns name, or name qualified by the provider ID if another
+// provider already uses it
+func uniqueEnvVar(name, providerID string, used map[string]bool) string {
+	if used[name] {
+		name = strings.TrimSuffix(name, "_API_KEY") + "_" + strings.ToUpper(strings.ReplaceAll(slugify(providerID), "-", "_")) + "_API_KEY"
+	}
+	used[name] = true
+	return name
+}
+
+// usableKeys picks the keys written for enabled. Each model gets the first
+// usable key whose scope allows it; the provider key is the first usable key
+// that allows every model, or failing that the first usable key. uncovered
+// lists the models no usable key allows.
+func usableKeys(c models.Credentials, enabled []models.Model, now time.Time) (key string, keys map[string]string, uncovered []string) {
+	keys = make(map[string]string, len(enabled))
+	for _, m := range enabled {
+		if k := c.UsableKey(m.ID, now); k != nil {
+			keys[m.ID] = k.Key
+		} else {
+			uncovered = append(uncovered, m.ID)
+		}
+	}
+
+	for _, k := range c.APIKeys {
+		if !k.IsUsable(now) {
+			continue
+		}
+		all := true
+		for _, m := range enabled {
+			all = all && k.AllowsModel(m.ID)
+		}
+		if all {
+			return k.Key, keys, uncovered
+		}
+	}
+	if k := c.UsableKey("", now); k != nil {
+		key = k.Key
+	}
+	return key, keys, uncovered
+}
+
+// perToken converts a per-million-token price to a per-token price
+func perToken(perMillion float64) float64 {
+	return math.Round(perMillion*1e6) / 1e12
+}
+
+// modelNames returns the name each model is published under, keeping model
+// IDs unless two providers share one
+func modelNames(selected []*toolProvider) map[*toolProvider]map[string]string {
+	count := make(map[string]int)
+	for _, tp := range selected {
+		for _, m := range tp.enabled {
+			count[m.ID]++
+		}
+	}
+	names := make(map[*toolProvider]map[string]string, len(selected))
+	for _, tp := range selected {
+		names[tp] = make(map[string]string, len(tp.enabled))
+		for _, m := range tp.enabled {
+			if count[m.ID] > 1 {
+				names[tp][m.ID] = tp.ID + "/" + m.ID
+			} else {
+				names[tp][m.ID] = m.ID
+			}
+		}
+	}
+	return names
+}
+
+// yamlField is one entry of an ordered YAML mapping
+type yamlField struct {
+	key   string
+	value interface{}
+}
+
+// yamlMap is a YAML mapping that keeps its key order when written
+type yamlMap []yamlField
+
+// MarshalYAML writes the fields in order
+func (m yamlMap) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range m {
+		value, err := yamlNode(f.value)
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.key}, value)
+	}
+	return node, nil
+}
+
+// yamlNode encodes one value. Floats never use exponents, which YAML 1.1
+// readers such as PyYAML take for strings.
+func yamlNode(v interface{}) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case float64:
+		text := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: text}, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+	node := &yaml.Node{}
+	return node, node.Encode(v)
+}
+
+// marshalYAML writes v as a generated block-style YAML config
+func marshalYAML(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("# Generated by LLM Desk\n")
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// renderLiteLLM renders a LiteLLM proxy config.yaml. Anthropic endpoints use
+// the anthropic provider; every other endpoint is OpenAI-compatible.
+func renderLiteLLM(selected []*toolProvider) ([]byte, error) {
+	names := modelNames(selected)
+	list := []interface{}{}
+	for _, tp := range selected {
+		prefix := "openai/"
+		if tp.anthropic {
+			prefix = "anthropic/"
+		}
+		for _, m := range tp.enabled {
+			key := tp.keyFor(m.ID)
+			if key == "" {
+				key = "os.environ/" + tp.envVar
+			}
+			params := yamlMap{{"model", prefix + m.ID}}
+			if tp.baseURL != "" {
+				params = append(params, yamlField{"api_base", tp.baseURL})
+			}
+			params = append(params, yamlField{"api_key", key})
+
+			info := yamlMap{{"max_input_tokens", m.Context.MaxInput}}
+			if m.Context.MaxOutput != nil {
+				info = append(info, yamlField{"max_output_tokens", *m.Context.MaxOutput})
+			}
+			info = append(info,
+				yamlField{"input_cost_per_token", perToken(m.Pricing.Input)},
+				yamlField{"output_cost_per_token", perToken(m.Pricing.Output)},
+			)
+			if m.Pricing.Cached != nil {
+				info = append(info, yamlField{"cache_read_input_token_cost", perToken(*m.Pricing.Cached)})
+			}
+			info = append(info, litellmCapabilities(m)...)
+
+			list = append(list, yamlMap{
+				{"model_name", names[tp][m.ID]},
+				{"litellm_params", params},
+				{"model_info", info},
+			})
+		}
+	}
+	return marshalYAML(yamlMap{{"model_list", list}})
+}
+
+// litellmCapabilities lists a model's capabilities as LiteLLM model info
+func litellmCapabilities(m models.Model) yamlMap {
+	var fields yamlMap
+	if m.Features != nil {
+		if m.Features.ToolCalling != nil && *m.Features.ToolCalling {
+			fields = append(fields, yamlField{"supports_function_calling", true})
+		}
+		if m.Features.Reasoning != nil && *m.Features.Reasoning {
+			fields = append(fields, yamlField{"supports_reasoning", true})
+		}
+	}
+	if supportsVision(m) {
+		fields = append(fields, yamlField{"supports_vision", true})
+	}
+	return fields
+}
+
+// supportsVision reports whether a model accepts images
+func supportsVision(m models.Model) bool {
+	if m.Features != nil && m.Features.Vision != nil && *m.Features.Vision {
+		return true
+	}
+	for _, modality := range m.Modalities {
+		if modality == "vision" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderContinue renders a Continue config.yaml. Environment keys use
+// Continue's secrets syntax, which reads ~/.continue/.env.
+func renderContinue(selected []*toolProvider, keys models.ToolConfigKeys) ([]byte, error) {
+	list := []interface{}{}
+	for _, tp := range selected {
+		provider := "openai"
+		if tp.anthropic {
+			provider = "anthropic"
+		}
+		for _, m := range tp.enabled {
+			key := tp.keyFor(m.ID)
+			if keys == models.ToolConfigKeysEnv {
+				key = "${{ secrets." + tp.envVar + " }}"
+			}
+			entry := yamlMap{
+				{"name", m.Name},
+				{"provider", provider},
+				{"model", m.ID},
+			}
+			if tp.baseURL != "" {
+				entry = append(entry, yamlField{"apiBase", tp.baseURL})
+			}
+			if key != "" {
+				entry = append(entry, yamlField{"apiKey", key})
+			}
+			options := yamlMap{{"contextLength", m.Context.MaxInput}}
+			if m.Context.MaxOutput != nil {
+				options = append(options, yamlField{"maxTokens", *m.Context.MaxOutput})
+			}
+			entry = append(entry, yamlField{"defaultCompletionOptions", options})
+			list = append(list, entry)
+		}
+	}
+	return marshalYAML(yamlMap{
+		{"name", "LLM Desk"},
+		{"version", "1.0.0"},
+		{"schema", "v1"},
+		{"models", list},
+	})
+}
+
+// renderAider renders Aider's model metadata file. Aider reads endpoints
+// and keys from the environment, so they are listed as environment
+// variables instead.
+func renderAider(selected []*toolProvider, config *toolConfig) ([]byte, error) {
+	metadata := make(map[string]interface{})
+	customEndpoints := 0
+	for _, tp := range selected {
+		prefix := "openai"
+		switch {
+		case tp.anthropic:
+			prefix = "anthropic"
+		case tp.kind != "":
+			prefix = tp.kind
+			if name, ok := litellmNames[tp.kind]; ok {
+				prefix = name
+			}
+		default:
+			customEndpoints++
+		}
+		for _, m := range tp.enabled {
+			info := map[string]interface{}{
+				"litellm_provider":      prefix,
+				"mode":                  "chat",
+				"max_input_tokens":      m.Context.MaxInput,
+				"max_tokens":            m.Context.MaxInput,
+				"input_cost_per_token":  perToken(m.Pricing.Input),
+				"output_cost_per_token": perToken(m.Pricing.Output),
+			}
+			if m.Context.MaxOutput != nil {
+				info["max_output_tokens"] = *m.Context.MaxOutput
+			}
+			for _, f := range litellmCapabilities(m) {
+				info[f.key] = f.value
+			}
+			metadata[prefix+"/"+m.ID] = info
+		}
+	}
+	if customEndpoints > 1 {
+		config.warnings = append(config.warnings, "Aider uses a single OPENAI_API_BASE, so only one custom endpoint can be used at a time")
+	}
+
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// renderOpenWebUI renders an environment file that configures one Open WebUI
+// OpenAI connection per provider. Anthropic endpoints are used through
+// their OpenAI-compatible API.
+func renderOpenWebUI(selected []*toolProvider, keys models.ToolConfigKeys, config *toolConfig) []byte {
+	urls := make([]string, len(selected))
+	secrets := make([]string, len(selected))
+	for i, tp := range selected {
+		urls[i] = tp.baseURL
+		secrets[i] = tp.key
+		if keys == models.ToolConfigKeysEnv {
+			secrets[i] = "${" + tp.envVar + "}"
+		}
+	}
+	config.warnings = append(config.warnings, "Open WebUI lists the models of each connection itself; context windows and prices are not part of its configuration")
+
+	var b strings.Builder
+	b.WriteString("# Generated by LLM Desk\n")
+	b.WriteString("ENABLE_OPENAI_API=true\n")
+	fmt.Fprintf(&b, "OPENAI_API_BASE_URLS=%q\n", strings.Join(urls, ";"))
+	fmt.Fprintf(&b, "OPENAI_API_KEYS=%q\n", strings.Join(secrets, ";"))
+	return []byte(b.String())
+}
+
+// clineModelInfo is the model description Cline keeps for custom models.
+// Prices are per million tokens, like LLM Desk's.
+type clineModelInfo struct {
+	ContextWindow   int      `json:"contextWindow"`
+	MaxTokens       *int     `json:"maxTokens,omitempty"`
+	SupportsImages  bool     `json:"supportsImages"`
+	InputPrice      float64  `json:"inputPrice"`
+	OutputPrice     float64  `json:"outputPrice"`
+	CacheReadsPrice *float64 `json:"cacheReadsPrice,omitempty"`
+}
+
+// clineConfiguration is one Cline API configuration profile
+type clineConfiguration struct {
+	Name             string          `json:"name"`
+	APIProvider      string          `json:"apiProvider"`
+	APIKey           string          `json:"apiKey,omitempty"`
+	APIModelID       string          `json:"apiModelId,omitempty"`
+	AnthropicBaseURL string          `json:"anthropicBaseUrl,omitempty"`
+	OpenAIBaseURL    string          `json:"openAiBaseUrl,omitempty"`
+	OpenAIAPIKey     string          `json:"openAiApiKey,omitempty"`
+	OpenAIModelID    string          `json:"openAiModelId,omitempty"`
+	OpenAIModelInfo  *clineModelInfo `json:"openAiModelInfo,omitempty"`
+}
+
+// renderCline renders one Cline API configuration profile per model. Cline
+// does not expand environment variables, so env keys are written as
+// ${NAME} placeholders to fill in.
+func renderCline(selected []*toolProvider, keys models.ToolConfigKeys, config *toolConfig) ([]byte, error) {
+	if keys == models.ToolConfigKeysEnv {
+		config.warnings = append(config.warnings, "Cline does not read environment variables; replace the ${NAME} key placeholders before importing")
+	}
+
+	profiles := []clineConfiguration{}
+	for _, tp := range selected {
+		for _, m := range tp.enabled {
+			key := tp.keyFor(m.ID)
+			if keys == models.ToolConfigKeysEnv {
+				key = "${" + tp.envVar + "}"
+			}
+			profile := clineConfiguration{Name: tp.Name + ": " + m.Name}
+			if tp.anthropic {
+				profile.APIProvider = "anthropic"
+				profile.APIKey = key
+				profile.APIModelID = m.ID
+				profile.AnthropicBaseURL = tp.baseURL
+			} else {
+				profile.APIProvider = "openai"
+				profile.OpenAIBaseURL = tp.baseURL
+				profile.OpenAIAPIKey = key
+				profile.OpenAIModelID = m.ID
+				profile.OpenAIModelInfo = &clineModelInfo{
+					ContextWindow:   m.Context.MaxInput,
+					MaxTokens:       m.Context.MaxOutput,
+					SupportsImages:  supportsVision(m),
+					InputPrice:      m.Pricing.Input,
+					OutputPrice:     m.Pricing.Output,
+					CacheReadsPrice: m.Pricing.Cached,
+				}
+			}
+			profiles = append(profiles, profile)
+		}
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{"apiConfigurations": profiles}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+	"llm-desk/internal/storage"
+)
+
+// toolConfigCatalog returns an OpenAI provider, a custom Anthropic endpoint
+// and a disabled provider
+func toolConfigCatalog() []models.Provider {
+	maxOutput := 16384
+	cached := 1.25
+	vision := true
+	anthropic := "https://claude.example.com/v1"
+	return []models.Provider{
+		{
+			ID:          "openai",
+			Name:        "OpenAI",
+			Enabled:     true,
+			Endpoints:   models.Endpoints{OpenAI: "https://api.openai.com/v1/"},
+			Credentials: models.Credentials{APIKeys: []models.APIKey{{ID: "k1", Key: "sk-openai", Enabled: true}}},
+			Models: []models.Model{
+				{ID: "gpt-4o", Name: "GPT-4o", Enabled: true, Context: models.Context{MaxInput: 128000, MaxOutput: &maxOutput},
+					Pricing: models.Pricing{Input: 2.5, Output: 10, Cached: &cached}, Features: &models.ModelFeatures{Vision: &vision}},
+				{ID: "gpt-3.5", Name: "GPT-3.5", Context: models.Context{MaxInput: 16000}},
+			},
+		},
+		{
+			ID:          "team-claude",
+			Name:        "Team Claude",
+			Enabled:     true,
+			Endpoints:   models.Endpoints{Anthropic: &anthropic},
+			Credentials: models.Credentials{APIKeys: []models.APIKey{{ID: "k2", Key: "sk-ant", Enabled: true}}},
+			Models:      []models.Model{{ID: "claude-sonnet-4", Name: "Claude Sonnet 4", Enabled: true, Context: models.Context{MaxInput: 200000}}},
+		},
+		{
+			ID:        "off",
+			Name:      "Off",
+			Endpoints: models.Endpoints{OpenAI: "https://off.example.com/v1"},
+			Models:    []models.Model{{ID: "m", Name: "M", Enabled: true, Context: models.Context{MaxInput: 1000}}},
+		},
+	}
+}
+
+func TestGenerateToolConfig_LiteLLM(t *testing.T) {
+	config, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigLiteLLM})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	if config.providers != 2 || config.models != 2 || config.plaintext {
+		t.Errorf("Expected 2 providers and 2 models without secrets, got %+v", config)
+	}
+	if strings.Join(config.envVars, ",") != "OPENAI_API_KEY,TEAM_CLAUDE_API_KEY" {
+		t.Errorf("Unexpected env vars: %v", config.envVars)
+	}
+	if strings.Contains(string(config.content), "sk-") {
+		t.Errorf("Expected no keys in env mode, got:\n%s", config.content)
+	}
+
+	// The generated file is read back by the LiteLLM importer
+	data, format, _, err := readPlainImport(writeImportFixture(t, "config.yaml", string(config.content)))
+	if err != nil || format != models.ImportFormatLiteLLM {
+		t.Fatalf("Expected the config to import as LiteLLM, got %q (%v)", format, err)
+	}
+	gpt := findProvider(t, data.Providers, "openai").Models[0]
+	if gpt.ID != "gpt-4o" || gpt.Context.MaxInput != 128000 || *gpt.Context.MaxOutput != 16384 || gpt.Pricing.Input != 2.5 || *gpt.Pricing.Cached != 1.25 {
+		t.Errorf("Unexpected round-tripped model: %+v", gpt)
+	}
+	claude := findProvider(t, data.Providers, "anthropic-claude-example-com")
+	if claude.Models[0].ID != "claude-sonnet-4" {
+		t.Errorf("Unexpected round-tripped provider: %+v", claude)
+	}
+}
+
+func TestGenerateToolConfig_InlineKeys(t *testing.T) {
+	config, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigContinue, Keys: models.ToolConfigKeysInline})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	if !config.plaintext || len(config.envVars) != 0 {
+		t.Errorf("Expected plaintext keys without env vars, got %+v", config)
+	}
+	content := string(config.content)
+	if !strings.Contains(content, "apiKey: sk-openai") || !strings.Contains(content, "provider: anthropic") {
+		t.Errorf("Unexpected Continue config:\n%s", content)
+	}
+	if !strings.Contains(strings.Join(config.warnings, "\n"), "plaintext") {
+		t.Errorf("Expected a plaintext warning, got %v", config.warnings)
+	}
+
+	parsed, err := parseYAML(config.content)
+	if err != nil {
+		t.Fatalf("Generated YAML does not parse: %v", err)
+	}
+	list := asSlice(asMap(parsed)["models"])
+	if len(list) != 2 || asString(asMap(list[0])["apiBase"]) != "https://api.openai.com/v1" {
+		t.Errorf("Unexpected models: %+v", list)
+	}
+
+	// Aider never gets keys
+	config, err = generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigAider, Keys: models.ToolConfigKeysInline})
+	if err != nil || config.plaintext || strings.Contains(string(config.content), "sk-") {
+		t.Errorf("Expected Aider to fall back to env keys, got %+v (%v)", config, err)
+	}
+}
+
+func TestGenerateToolConfig_KeyScope(t *testing.T) {
+	expired := "2020-01-01T00:00:00Z"
+	catalog := toolConfigCatalog()[:1]
+	catalog[0].Models[1].Enabled = true
+	catalog[0].Credentials.APIKeys = []models.APIKey{
+		{ID: "old", Key: "sk-expired", Enabled: true, ExpiresAt: &expired},
+		{ID: "off", Key: "sk-disabled"},
+		{ID: "scoped", Key: "sk-scoped", Enabled: true, Models: []string{"gpt-3.5"}},
+	}
+
+	config, err := generateToolConfig(catalog, models.ToolConfigOptions{Format: models.ToolConfigCline, Keys: models.ToolConfigKeysInline})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	var parsed struct {
+		APIConfigurations []clineConfiguration `json:"apiConfigurations"`
+	}
+	if err := json.Unmarshal(config.content, &parsed); err != nil {
+		t.Fatalf("Generated JSON does not parse: %v", err)
+	}
+	keys := map[string]string{}
+	for _, profile := range parsed.APIConfigurations {
+		keys[profile.OpenAIModelID] = profile.OpenAIAPIKey
+	}
+	if keys["gpt-4o"] != "" || keys["gpt-3.5"] != "sk-scoped" {
+		t.Errorf("Expected only the scoped model to get the scoped key, got %v", keys)
+	}
+	if !strings.Contains(strings.Join(config.warnings, "\n"), "no usable API key for gpt-4o") {
+		t.Errorf("Expected a warning for the uncovered model, got %v", config.warnings)
+	}
+	if strings.Contains(string(config.content), "sk-expired") || strings.Contains(string(config.content), "sk-disabled") {
+		t.Errorf("Expected expired and disabled keys to be left out:\n%s", config.content)
+	}
+}
+
+func TestGenerateToolConfig_Formats(t *testing.T) {
+	aider, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigAider})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	var metadata map[string]map[string]interface{}
+	if err := json.Unmarshal(aider.content, &metadata); err != nil {
+		t.Fatalf("Invalid Aider metadata: %v", err)
+	}
+	if gpt := metadata["openai/gpt-4o"]; gpt == nil || gpt["litellm_provider"] != "openai" || gpt["supports_vision"] != true {
+		t.Errorf("Unexpected Aider metadata: %+v", metadata)
+	}
+	if _, ok := metadata["anthropic/claude-sonnet-4"]; !ok {
+		t.Errorf("Expected the Anthropic model, got %+v", metadata)
+	}
+
+	webui, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigOpenWebUI})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	if !strings.Contains(string(webui.content), `OPENAI_API_BASE_URLS="https://api.openai.com/v1;https://claude.example.com/v1"`) ||
+		!strings.Contains(string(webui.content), `OPENAI_API_KEYS="${OPENAI_API_KEY};${TEAM_CLAUDE_API_KEY}"`) {
+		t.Errorf("Unexpected Open WebUI config:\n%s", webui.content)
+	}
+
+	cline, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigCline, Keys: models.ToolConfigKeysInline})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	var profiles struct {
+		APIConfigurations []clineConfiguration `json:"apiConfigurations"`
+	}
+	if err := json.Unmarshal(cline.content, &profiles); err != nil || len(profiles.APIConfigurations) != 2 {
+		t.Fatalf("Unexpected Cline config: %s (%v)", cline.content, err)
+	}
+	gpt := profiles.APIConfigurations[0]
+	if gpt.OpenAIAPIKey != "sk-openai" || gpt.OpenAIModelInfo.ContextWindow != 128000 || !gpt.OpenAIModelInfo.SupportsImages {
+		t.Errorf("Unexpected Cline profile: %+v", gpt)
+	}
+	if claude := profiles.APIConfigurations[1]; claude.APIProvider != "anthropic" || claude.AnthropicBaseURL != "https://claude.example.com/v1" {
+		t.Errorf("Unexpected Cline profile: %+v", claude)
+	}
+}
+
+func TestGenerateToolConfig_Selection(t *testing.T) {
+	config, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigLiteLLM, Providers: []string{"team-claude", "off"}})
+	if err != nil {
+		t.Fatalf("generateToolConfig failed: %v", err)
+	}
+	if config.providers != 2 || strings.Contains(string(config.content), "gpt-4o") {
+		t.Errorf("Expected only the selected providers, got:\n%s", config.content)
+	}
+
+	if _, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigLiteLLM, Providers: []string{"missing"}}); err == nil {
+		t.Error("Expected an unknown provider to be rejected")
+	}
+	if _, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: "vim"}); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+	if _, err := generateToolConfig(toolConfigCatalog(), models.ToolConfigOptions{Format: models.ToolConfigLiteLLM, Keys: "plain"}); err == nil {
+		t.Error("Expected an unknown key option to be rejected")
+	}
+	if _, err := generateToolConfig(nil, models.ToolConfigOptions{Format: models.ToolConfigLiteLLM}); err == nil {
+		t.Error("Expected an empty catalog to be rejected")
+	}
+}
+
+func TestWriteToolConfig(t *testing.T) {
+	store, err := storage.NewWithDir(filepath.Join(t.TempDir(), "data"), storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.Save(toolConfigCatalog()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	service := NewExportService(store)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	result, err := service.WriteToolConfig(path, models.ToolConfigOptions{Format: models.ToolConfigLiteLLM})
+	if err != nil || !result.Success || result.Models != 2 || result.Path != path {
+		t.Fatalf("WriteToolConfig failed: %+v (%v)", result, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected the config to be written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("Expected no temp files next to the config, got %d entries", len(entries))
+	}
+}
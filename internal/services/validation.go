@@ -3,8 +3,10 @@ package services
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"strings"
+	"time"
 
 	"llm-desk/internal/models"
 )
@@ -13,6 +15,7 @@ const (
 	MaxProviderNameLength = 100
 	MaxModelIDLength      = 200
 	MaxModelNameLength    = 200
+	MaxKeyLabelLength     = 100
 )
 
 // ValidationError represents a single validation failure
@@ -93,8 +96,60 @@ func ValidateProvider(p *models.Provider) ValidationResult {
 		}
 	}
 
-	// API keys: no limit, just ensure array is initialized
-	// (No validation needed per user requirements)
+	// Protocol: empty (detect) or one with a model-list adapter
+	if p.Protocol != "" && !knownProtocol(p.Protocol) {
+		result.Valid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "protocol",
+			Message: fmt.Sprintf("Unknown provider protocol %q", p.Protocol),
+		})
+	}
+
+	// API keys: no limit on count, but each record must be well-formed
+	seen := make(map[string]bool)
+	for i := range p.Credentials.APIKeys {
+		k := &p.Credentials.APIKeys[i]
+		if k.ID != "" {
+			if seen[k.ID] {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("credentials.apiKeys[%d].id", i),
+					Message: "Duplicate API key ID",
+				})
+			}
+			seen[k.ID] = true
+		}
+		for _, e := range ValidateAPIKey(k).Errors {
+			result.Valid = false
+			e.Field = fmt.Sprintf("credentials.apiKeys[%d].%s", i, e.Field)
+			result.Errors = append(result.Errors, e)
+		}
+	}
+
+	return result
+}
+
+// ValidateAPIKey validates the metadata of an API key record
+func ValidateAPIKey(k *models.APIKey) ValidationResult {
+	result := ValidationResult{Valid: true, Errors: []ValidationError{}}
+
+	if len(k.Label) > MaxKeyLabelLength {
+		result.Valid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "label",
+			Message: fmt.Sprintf("Key label exceeds %d characters", MaxKeyLabelLength),
+		})
+	}
+
+	if k.ExpiresAt != nil && *k.ExpiresAt != "" {
+		if _, err := time.Parse(time.RFC3339, *k.ExpiresAt); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "expiresAt",
+				Message: "Expiry must be an RFC 3339 timestamp",
+			})
+		}
+	}
 
 	return result
 }
@@ -156,6 +211,23 @@ func ValidateModel(m *models.Model) ValidationResult {
 			Message: "Cached pricing cannot be negative",
 		})
 	}
+	prices := []struct {
+		field string
+		value *float64
+	}{
+		{"pricing.input", &m.Pricing.Input},
+		{"pricing.output", &m.Pricing.Output},
+		{"pricing.cached", m.Pricing.Cached},
+	}
+	for _, price := range prices {
+		if price.value != nil && (math.IsNaN(*price.value) || math.IsInf(*price.value, 0)) {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   price.field,
+				Message: "Pricing must be a finite number",
+			})
+		}
+	}
 
 	// Context validation: positive
 	if m.Context.MaxInput <= 0 {
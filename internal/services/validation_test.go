@@ -1,6 +1,7 @@
 package services
 
 import (
+	"math"
 	"testing"
 
 	"llm-desk/internal/models"
@@ -186,6 +187,20 @@ func TestValidateModel_NegativePricing(t *testing.T) {
 	}
 }
 
+func TestValidateModel_NonFinitePricing(t *testing.T) {
+	cached := math.Inf(1)
+	m := &models.Model{
+		ID:      "test-model",
+		Pricing: models.Pricing{Input: math.NaN(), Cached: &cached},
+		Context: models.Context{MaxInput: 128000},
+	}
+
+	result := ValidateModel(m)
+	if result.Valid || len(result.Errors) != 2 || result.Errors[0].Field != "pricing.input" || result.Errors[1].Field != "pricing.cached" {
+		t.Errorf("Expected NaN and infinite prices to be rejected, got %+v", result.Errors)
+	}
+}
+
 func TestValidateModel_ZeroMaxInput(t *testing.T) {
 	m := &models.Model{
 		ID: "test-model",
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/storage"
+)
+
+// Events emitted to the frontend
+const (
+	EventDataChanged     = "data:changed"      // A data file was changed outside the app
+	EventInstanceChanged = "instance:detected" // Another instance holds the data directory
+)
+
+// InstanceStatus reports whether this instance holds the data directory lock
+type InstanceStatus struct {
+	Owned  bool              `json:"owned"`
+	Holder *storage.LockInfo `json:"holder,omitempty"` // The other instance, when not owned
+}
+
+// WatchService watches the data directory for external edits and other
+// running instances, reloads cached settings and notifies the frontend
+type WatchService struct {
+	storage  *storage.Storage
+	settings *SettingsService
+	lock     *storage.InstanceLock
+	watcher  *storage.Watcher
+	emit     func(event string, data interface{})
+
+	mu     sync.Mutex
+	holder *storage.LockInfo
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewWatchService creates a WatchService. emit delivers events to the
+// frontend and may be called from a background goroutine.
+func NewWatchService(s *storage.Storage, settings *SettingsService, lock *storage.InstanceLock, interval time.Duration, emit func(event string, data interface{})) *WatchService {
+	w := &WatchService{
+		storage:  s,
+		settings: settings,
+		lock:     lock,
+		emit:     emit,
+	}
+	w.watcher = storage.NewWatcher(s, interval, w.handleChange)
+	return w
+}
+
+// Start takes the instance lock and begins watching. It returns the other
+// instance if one already holds the data directory.
+func (w *WatchService) Start() *storage.LockInfo {
+	holder := w.heartbeat()
+
+	w.mu.Lock()
+	if w.stop == nil {
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.runHeartbeat(w.stop, w.done)
+	}
+	w.mu.Unlock()
+
+	w.watcher.Start()
+	return holder
+}
+
+// Stop stops watching and releases the instance lock
+func (w *WatchService) Stop() {
+	w.watcher.Stop()
+
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	if err := w.lock.Release(); err != nil {
+		logger.Warn("Failed to release instance lock", "error", err)
+	}
+}
+
+// InstanceStatus returns the lock state from the last heartbeat
+func (w *WatchService) InstanceStatus() InstanceStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return InstanceStatus{Owned: w.lock.Owned(), Holder: w.holder}
+}
+
+// runHeartbeat refreshes the lock well within its stale timeout
+func (w *WatchService) runHeartbeat(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(storage.LockStaleAfter / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.heartbeat()
+		}
+	}
+}
+
+// heartbeat acquires or refreshes the lock and emits an event when another
+// instance appears
+func (w *WatchService) heartbeat() *storage.LockInfo {
+	holder, err := w.lock.Acquire()
+	if err != nil {
+		logger.Warn("Failed to update instance lock", "error", err)
+		return nil
+	}
+
+	w.mu.Lock()
+	appeared := holder != nil && (w.holder == nil || w.holder.PID != holder.PID || w.holder.StartedAt != holder.StartedAt)
+	w.holder = holder
+	w.mu.Unlock()
+
+	if appeared {
+		logger.Warn("Another instance is using the data directory",
+			"pid", holder.PID, "hostname", holder.Hostname, "startedAt", holder.StartedAt)
+		w.emitEvent(EventInstanceChanged, InstanceStatus{Owned: false, Holder: holder})
+	}
+	return holder
+}
+
+// handleChange reloads what the app caches and forwards the change
+func (w *WatchService) handleChange(event storage.ChangeEvent) {
+	if event.Path == w.storage.SettingsPath() {
+		if _, err := w.settings.Reload(); err != nil {
+			logger.Warn("Failed to reload settings after external change", "error", err)
+		}
+	}
+	w.emitEvent(EventDataChanged, event)
+}
+
+// emitEvent sends an event if an emitter is configured
+func (w *WatchService) emitEvent(event string, data interface{}) {
+	if w.emit != nil {
+		w.emit(event, data)
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"llm-desk/internal/storage"
+)
+
+func TestWatchService_ReloadsSettingsOnExternalChange(t *testing.T) {
+	tempDir := t.TempDir()
+	store, err := storage.NewWithDir(tempDir, storage.NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	settings := NewSettingsService(store)
+	if err := settings.SetTheme("light"); err != nil {
+		t.Fatalf("SetTheme failed: %v", err)
+	}
+
+	events := make(chan string, 4)
+	w := NewWatchService(store, settings, storage.NewInstanceLock(tempDir), 10*time.Millisecond,
+		func(event string, data interface{}) { events <- event })
+	if holder := w.Start(); holder != nil {
+		t.Fatalf("Expected to own a fresh data directory, got %+v", holder)
+	}
+	defer w.Stop()
+
+	if !w.InstanceStatus().Owned {
+		t.Error("Expected instance lock to be owned")
+	}
+
+	if err := os.WriteFile(store.SettingsPath(), []byte(`{"theme":"dark"}`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event != EventDataChanged {
+			t.Errorf("Expected %s, got %s", EventDataChanged, event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a data changed event")
+	}
+
+	if theme := settings.GetTheme(); theme != "dark" {
+		t.Errorf("Expected reloaded theme dark, got %s", theme)
+	}
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"llm-desk/internal/logger"
+)
+
+// DefaultBackupGenerations is the number of previous versions kept for each data file
+const DefaultBackupGenerations = 5
+
+// Snapshot describes one rolling backup generation of providers.json
+type Snapshot struct {
+	Generation int    `json:"generation"`
+	Path       string `json:"path"`
+	ModifiedAt string `json:"modifiedAt"`
+	Size       int64  `json:"size"`
+	Valid      bool   `json:"valid"`
+	Providers  int    `json:"providers"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ErrDataCorrupt is returned when a data file and every backup of it are
+// unreadable
+var ErrDataCorrupt = errors.New("data file is corrupt")
+
+// corruptError names the corrupt file and why it could not be read; it
+// matches ErrDataCorrupt
+type corruptError struct {
+	file string
+	err  error
+}
+
+func (e *corruptError) Error() string {
+	return fmt.Sprintf("%s is corrupt and no valid backup was found: %v", e.file, e.err)
+}
+
+func (e *corruptError) Unwrap() []error {
+	return []error{ErrDataCorrupt, e.err}
+}
+
+// RecoveryReport describes a fallback to an older generation after a corrupt read
+type RecoveryReport struct {
+	File        string `json:"file"`
+	Generation  int    `json:"generation"`
+	Reason      string `json:"reason"`
+	RecoveredAt string `json:"recoveredAt"`
+}
+
+// writeFileAtomic writes data to a temp file in the same directory, fsyncs it
+// and renames it over path so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// WriteFileAtomic writes data to path the way the data files are written, so
+// an interrupted write never leaves a truncated file behind
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomic(path, data, perm)
+}
+
+// syncDir flushes directory metadata so a completed rename survives a crash.
+// Not every platform supports fsync on directories, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// generationPath returns the path of backup generation n (providers.json.1, ...)
+func generationPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// rotateGenerations shifts path.1..N-1 up by one and writes previous, the
+// content path had before it was replaced, to path.1. The oldest generation
+// falls off the end. Callers rotate only once the new content is safely in
+// place, so a failed write leaves the generations untouched. A nil previous
+// means there was no file and nothing is rotated.
+func rotateGenerations(path string, previous []byte, keep int) error {
+	if keep <= 0 || previous == nil {
+		return nil
+	}
+
+	for n := keep - 1; n >= 1; n-- {
+		from := generationPath(path, n)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if err := os.Rename(from, generationPath(path, n+1)); err != nil {
+			return fmt.Errorf("failed to rotate backup %s: %w", from, err)
+		}
+	}
+
+	return writeFileAtomic(generationPath(path, 1), previous, 0600)
+}
+
+// readPrevious returns the current content of path for rotateGenerations,
+// or nil if there is none or it cannot be read
+func readPrevious(path string) []byte {
+	previous, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Failed to read file for backup", "file", filepath.Base(path), "error", err)
+		}
+		return nil
+	}
+	return previous
+}
+
+// listGenerations returns the existing backup generations of path, newest first
+func listGenerations(path string) []int {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	prefix := filepath.Base(path) + "."
+	var gens []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+		if err != nil || n <= 0 {
+			continue
+		}
+		gens = append(gens, n)
+	}
+	sort.Ints(gens)
+	return gens
+}
+
+// readWithFallback reads path and checks it with validate. If the file is
+// corrupt it walks the backup generations newest first and returns the first
+// one that validates, along with a report of what was recovered.
+func readWithFallback(path string, validate func([]byte) error) ([]byte, *RecoveryReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	primaryErr := validate(data)
+	if primaryErr == nil {
+		return data, nil, nil
+	}
+
+	// A file from a newer app version, or one we lack the key for, is not
+	// corrupt; never replace it with an older backup
+	var tooNew *SchemaTooNewError
+	if errors.As(primaryErr, &tooNew) || errors.Is(primaryErr, ErrDataLocked) {
+		return nil, nil, primaryErr
+	}
+
+	for _, n := range listGenerations(path) {
+		backup, err := os.ReadFile(generationPath(path, n))
+		if err != nil {
+			continue
+		}
+		if validate(backup) != nil {
+			continue
+		}
+		return backup, &RecoveryReport{
+			File:        filepath.Base(path),
+			Generation:  n,
+			Reason:      primaryErr.Error(),
+			RecoveredAt: time.Now().Format(time.RFC3339),
+		}, nil
+	}
+
+	return nil, nil, &corruptError{file: filepath.Base(path), err: primaryErr}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	if err := writeFileAtomic(path, []byte("one"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("two"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "two" {
+		t.Errorf("Expected 'two', got '%s'", content)
+	}
+
+	// No temp files should be left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the target file, got %d entries", len(entries))
+	}
+}
+
+func TestRotateGenerations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	for _, content := range []string{"a", "b", "c", "d"} {
+		previous := readPrevious(path)
+		if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writeFileAtomic failed: %v", err)
+		}
+		if err := rotateGenerations(path, previous, 2); err != nil {
+			t.Fatalf("rotateGenerations failed: %v", err)
+		}
+	}
+
+	gens := listGenerations(path)
+	if len(gens) != 2 || gens[0] != 1 || gens[1] != 2 {
+		t.Fatalf("Expected generations [1 2], got %v", gens)
+	}
+
+	newest, _ := os.ReadFile(generationPath(path, 1))
+	oldest, _ := os.ReadFile(generationPath(path, 2))
+	if string(newest) != "c" || string(oldest) != "b" {
+		t.Errorf("Expected generations c, b; got %s, %s", newest, oldest)
+	}
+}
@@ -0,0 +1,391 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"llm-desk/internal/logger"
+)
+
+// EncryptionConfigFilename records whether the data files are encrypted.
+// It holds no secrets and is always plaintext.
+const EncryptionConfigFilename = "encryption.json"
+
+// Where the data encryption key comes from
+const (
+	EncryptionKeyKeyring    = "keyring"    // Random key held in the active key backend
+	EncryptionKeyPassphrase = "passphrase" // Entered by the user on every start
+)
+
+// dataKeySecret is the keyring entry holding the random data key
+const dataKeySecret = "data-key"
+
+// verifierText is encrypted into the config so a passphrase can be checked
+const verifierText = "llm-desk"
+
+// atRestMagic prefixes every file encrypted at rest
+var atRestMagic = []byte("LLMDENC1")
+
+// journalLinePrefix marks an encrypted journal line
+const journalLinePrefix = "!"
+
+// ErrDataLocked is returned while encrypted data files cannot be read or
+// written because the key is not available
+var ErrDataLocked = errors.New("data files are encrypted and locked")
+
+// EncryptionConfig is the content of encryption.json
+type EncryptionConfig struct {
+	Enabled   bool   `json:"enabled"`
+	KeySource string `json:"keySource,omitempty"`
+	Verifier  string `json:"verifier,omitempty"` // Encrypted verifierText, base64
+}
+
+// EncryptionStatus describes encryption at rest for the UI
+type EncryptionStatus struct {
+	Enabled   bool   `json:"enabled"`
+	KeySource string `json:"keySource,omitempty"`
+	Locked    bool   `json:"locked"`
+	Error     string `json:"error,omitempty"`
+}
+
+// atRest encodes and decodes data files. When disabled it passes data
+// through unchanged. Plaintext files are always readable, so a file written
+// before encryption was turned on still loads.
+type atRest struct {
+	config EncryptionConfig
+	keys   *keyCache // Nil while locked
+	err    error     // Why the key could not be loaded
+}
+
+// unlocked returns a codec for config using key
+func unlocked(config EncryptionConfig, key string) atRest {
+	return atRest{config: config, keys: newKeyCache(key)}
+}
+
+// encode encrypts plaintext if encryption is enabled
+func (c *atRest) encode(plaintext []byte) ([]byte, error) {
+	if !c.config.Enabled {
+		return plaintext, nil
+	}
+	if c.keys == nil {
+		return nil, ErrDataLocked
+	}
+	encrypted, err := c.keys.seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, atRestMagic...), encrypted...), nil
+}
+
+// decode decrypts data if it is encrypted and returns plaintext as is
+func (c *atRest) decode(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, atRestMagic) {
+		return data, nil
+	}
+	if c.keys == nil {
+		return nil, ErrDataLocked
+	}
+	return c.keys.open(data[len(atRestMagic):])
+}
+
+// encodeLine encodes one journal line
+func (c *atRest) encodeLine(line []byte) ([]byte, error) {
+	if !c.config.Enabled {
+		return line, nil
+	}
+	encrypted, err := c.encode(line)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(journalLinePrefix + base64.StdEncoding.EncodeToString(encrypted)), nil
+}
+
+// decodeLine decodes one journal line
+func (c *atRest) decodeLine(line []byte) ([]byte, error) {
+	if !bytes.HasPrefix(line, []byte(journalLinePrefix)) {
+		return line, nil
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(string(line[len(journalLinePrefix):]))
+	if err != nil {
+		return nil, err
+	}
+	return c.decode(encrypted)
+}
+
+// loadEncryption reads encryption.json and, for keyring mode, the data key
+func (s *Storage) loadEncryption() {
+	data, err := os.ReadFile(s.encryptionConfigPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			s.crypt.err = err
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.crypt.config); err != nil {
+		s.crypt.err = fmt.Errorf("invalid %s: %w", EncryptionConfigFilename, err)
+		return
+	}
+	if !s.crypt.config.Enabled || s.crypt.config.KeySource != EncryptionKeyKeyring {
+		return
+	}
+	if err := s.loadDataKey(); err != nil {
+		logger.Warn("Failed to load data encryption key", "error", err)
+	}
+}
+
+// ReloadDataKey retries reading the data key from the active key backend,
+// for when it only became readable later, e.g. once the vault is unlocked
+func (s *Storage) ReloadDataKey() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.crypt.config.Enabled || s.crypt.config.KeySource != EncryptionKeyKeyring || s.crypt.keys != nil {
+		return nil
+	}
+	return s.loadDataKey()
+}
+
+// loadDataKey reads the random data key from the active key backend,
+// recording why it failed
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) loadDataKey() error {
+	secrets := s.activeSecrets()
+	if secrets == nil {
+		s.crypt.err = fmt.Errorf("the key backend cannot hold the data key")
+		return s.crypt.err
+	}
+	key, err := secrets.GetSecret(dataKeySecret)
+	if err != nil {
+		s.crypt.err = err
+		return err
+	}
+	s.crypt.keys = newKeyCache(key)
+	s.crypt.err = nil
+	return nil
+}
+
+// encryptionConfigPath returns the path of encryption.json
+func (s *Storage) encryptionConfigPath() string {
+	return filepath.Join(s.dataDir, EncryptionConfigFilename)
+}
+
+// EncryptionStatus reports whether the data files are encrypted and unlocked
+func (s *Storage) EncryptionStatus() EncryptionStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := EncryptionStatus{
+		Enabled:   s.crypt.config.Enabled,
+		KeySource: s.crypt.config.KeySource,
+		Locked:    s.crypt.config.Enabled && s.crypt.keys == nil,
+	}
+	if s.crypt.err != nil {
+		status.Error = s.crypt.err.Error()
+	}
+	return status
+}
+
+// UnlockData supplies the passphrase for passphrase-encrypted data files
+func (s *Storage) UnlockData(passphrase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.crypt.config.Enabled {
+		return nil
+	}
+	if s.crypt.config.KeySource != EncryptionKeyPassphrase {
+		return fmt.Errorf("data files are encrypted with a keyring key, not a passphrase")
+	}
+	next := unlocked(s.crypt.config, passphrase)
+	if err := verifyPassphrase(next); err != nil {
+		return err
+	}
+	s.crypt = next
+	return nil
+}
+
+// EnableEncryption encrypts providers.json, settings.json, their backups and
+// the journal. source is EncryptionKeyKeyring or EncryptionKeyPassphrase;
+// passphrase is only used with the latter.
+func (s *Storage) EnableEncryption(source, passphrase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.crypt.config.Enabled {
+		return fmt.Errorf("data files are already encrypted")
+	}
+
+	config := EncryptionConfig{Enabled: true, KeySource: source}
+	var key string
+	switch source {
+	case EncryptionKeyKeyring:
+		secrets := s.activeSecrets()
+		if secrets == nil {
+			return fmt.Errorf("the key backend cannot hold the data key")
+		}
+		raw := make([]byte, keySize)
+		if _, err := rand.Read(raw); err != nil {
+			return fmt.Errorf("failed to generate data key: %w", err)
+		}
+		key = hex.EncodeToString(raw)
+		if err := secrets.SetSecret(dataKeySecret, key); err != nil {
+			return err
+		}
+	case EncryptionKeyPassphrase:
+		if passphrase == "" {
+			return fmt.Errorf("passphrase cannot be empty")
+		}
+		key = passphrase
+	default:
+		return fmt.Errorf("invalid encryption key source: %s", source)
+	}
+
+	next := unlocked(config, key)
+	verifier, err := next.keys.seal([]byte(verifierText))
+	if err != nil {
+		return err
+	}
+	next.config.Verifier = base64.StdEncoding.EncodeToString(verifier)
+
+	// Write the config first: plaintext files stay readable while it says
+	// "enabled", so an interrupted rewrite leaves everything loadable
+	if err := s.writeEncryptionConfig(next.config); err != nil {
+		return err
+	}
+	previous := s.crypt
+	s.crypt = next
+	if err := s.recodeFiles(&previous, &s.crypt); err != nil {
+		return fmt.Errorf("failed to encrypt data files: %w", err)
+	}
+
+	logger.Info("Enabled encryption at rest", "keySource", source)
+	return nil
+}
+
+// DisableEncryption decrypts every data file and turns encryption off
+func (s *Storage) DisableEncryption() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.crypt.config.Enabled {
+		return nil
+	}
+	if s.crypt.keys == nil {
+		return ErrDataLocked
+	}
+
+	plain := atRest{}
+	if err := s.recodeFiles(&s.crypt, &plain); err != nil {
+		return fmt.Errorf("failed to decrypt data files: %w", err)
+	}
+
+	source := s.crypt.config.KeySource
+	if err := s.writeEncryptionConfig(plain.config); err != nil {
+		return err
+	}
+	s.crypt = plain
+
+	if secrets := s.activeSecrets(); source == EncryptionKeyKeyring && secrets != nil {
+		if err := secrets.DeleteSecret(dataKeySecret); err != nil {
+			logger.Warn("Failed to delete data key from the key backend", "error", err)
+		}
+	}
+	logger.Info("Disabled encryption at rest")
+	return nil
+}
+
+// configuredBackupDir returns the automatic backup directory set in
+// settings.json, which is decoded with c
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) configuredBackupDir(c *atRest) string {
+	var settings AppSettings
+	if data, err := os.ReadFile(s.settingsFilename()); err == nil {
+		if plaintext, err := c.decode(data); err == nil {
+			if err := json.Unmarshal(plaintext, &settings); err != nil {
+				logger.Warn("Failed to read the backup directory from settings", "error", err)
+			}
+		}
+	}
+	return s.BackupDir(settings.Backup)
+}
+
+// writeEncryptionConfig saves encryption.json
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) writeEncryptionConfig(config EncryptionConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.encryptionConfigPath(), data, 0600)
+}
+
+// recodeFiles rewrites every data file, backup generation, automatic backup
+// and the journal from one encoding to another.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) recodeFiles(from, to *atRest) error {
+	var paths []string
+	for _, base := range []string{s.filename, s.settingsFilename()} {
+		paths = append(paths, base)
+		for _, n := range listGenerations(base) {
+			paths = append(paths, generationPath(base, n))
+		}
+	}
+	backups, _ := filepath.Glob(s.filename + ".v*.bak")
+	paths = append(paths, backups...)
+
+	automatic, err := s.ListBackups(s.configuredBackupDir(from))
+	if err != nil {
+		return err
+	}
+	for _, b := range automatic {
+		paths = append(paths, b.Path)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		plaintext, err := from.decode(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		encoded, err := to.encode(plaintext)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path, encoded, 0600); err != nil {
+			return err
+		}
+		if path == s.filename || path == s.settingsFilename() {
+			s.written[path] = contentHash(encoded)
+		}
+	}
+
+	return s.journal.recode(from, to)
+}
+
+// verifyPassphrase checks the codec's key against its config's verifier.
+// Files sealed in the same session share the verifier's salt, so the derived
+// key is usually reused.
+func verifyPassphrase(c atRest) error {
+	verifier, err := base64.StdEncoding.DecodeString(c.config.Verifier)
+	if err != nil {
+		return fmt.Errorf("invalid encryption verifier: %w", err)
+	}
+	plaintext, err := c.keys.open(verifier)
+	if err != nil || string(plaintext) != verifierText {
+		return fmt.Errorf("incorrect passphrase")
+	}
+	return nil
+}
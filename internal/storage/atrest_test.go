@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+// sensitiveProvider has an endpoint that must never appear on disk in plaintext
+var sensitiveProvider = models.Provider{
+	ID:        "internal",
+	Name:      "Internal Gateway",
+	Endpoints: models.Endpoints{OpenAI: "https://llm-gw.corp.internal/v1"},
+}
+
+// assertNotOnDisk fails if text appears in any file of dir
+func assertNotOnDisk(t *testing.T, dir, text string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == EncryptionConfigFilename {
+			continue
+		}
+		data, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+		if bytes.Contains(data, []byte(text)) {
+			t.Errorf("Expected %s to be encrypted, found %q in plaintext", e.Name(), text)
+		}
+	}
+}
+
+func TestEncryption_KeyringRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	keyring := NewMemoryKeyring()
+	s, err := NewWithDir(dir, keyring)
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+
+	if err := s.Save([]models.Provider{sensitiveProvider}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.SaveSettings(&AppSettings{Theme: "light"}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+
+	if err := s.EnableEncryption(EncryptionKeyKeyring, ""); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+	assertNotOnDisk(t, dir, "llm-gw.corp.internal")
+
+	raw, _ := os.ReadFile(s.ProvidersPath())
+	if !bytes.HasPrefix(raw, atRestMagic) {
+		t.Error("Expected providers.json to be encrypted")
+	}
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(s.ProvidersPath())
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+		}
+	}
+
+	// A fresh instance finds the key in the keyring
+	reopened, err := NewWithDir(dir, keyring)
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if status := reopened.EncryptionStatus(); !status.Enabled || status.Locked {
+		t.Fatalf("Expected unlocked encryption, got %+v", status)
+	}
+	providers, err := reopened.Load()
+	if err != nil || len(providers) != 1 || providers[0].Endpoints.OpenAI != sensitiveProvider.Endpoints.OpenAI {
+		t.Fatalf("Expected provider to load, got %+v (%v)", providers, err)
+	}
+	settings, err := reopened.LoadSettings()
+	if err != nil || settings.Theme != "light" {
+		t.Fatalf("Expected settings to load, got %+v (%v)", settings, err)
+	}
+
+	// Writes after enabling stay encrypted, journal included
+	if err := reopened.Save([]models.Provider{sensitiveProvider, {ID: "second", Name: "Second"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	assertNotOnDisk(t, dir, "llm-gw.corp.internal")
+	if history, err := reopened.History(0); err != nil || len(history) == 0 {
+		t.Errorf("Expected encrypted journal to be readable, got %d entries (%v)", len(history), err)
+	}
+
+	if err := reopened.DisableEncryption(); err != nil {
+		t.Fatalf("DisableEncryption failed: %v", err)
+	}
+	raw, _ = os.ReadFile(reopened.ProvidersPath())
+	if !strings.Contains(string(raw), "llm-gw.corp.internal") {
+		t.Error("Expected providers.json to be plaintext after disabling")
+	}
+	if _, err := keyring.GetSecret(dataKeySecret); err == nil {
+		t.Error("Expected data key to be removed from the keyring")
+	}
+}
+
+func TestEncryption_DataKeyFollowsKeyBackend(t *testing.T) {
+	dir := t.TempDir()
+	keyring := NewMemoryKeyring()
+	s, err := NewWithDir(dir, keyring)
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if err := s.Save([]models.Provider{sensitiveProvider}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.EnableEncryption(EncryptionKeyKeyring, ""); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+
+	vaultPath := filepath.Join(dir, VaultFilename)
+	vault := NewFileVault(vaultPath)
+	if err := vault.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := s.SwitchKeyring(vault); err != nil {
+		t.Fatalf("SwitchKeyring failed: %v", err)
+	}
+	if _, err := vault.GetSecret(dataKeySecret); err != nil {
+		t.Errorf("Expected the data key to move to the vault: %v", err)
+	}
+	if _, err := keyring.GetSecret(dataKeySecret); err == nil {
+		t.Error("Expected the data key to be removed from the keyring")
+	}
+
+	// A fresh start only finds the key once the vault is unlocked
+	reopened, err := NewWithDir(dir, keyring)
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if status := reopened.EncryptionStatus(); !status.Locked {
+		t.Fatalf("Expected data to be locked before the vault is active, got %+v", status)
+	}
+	locked := NewFileVault(vaultPath)
+	reopened.SetKeyring(locked)
+	if err := locked.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := reopened.ReloadDataKey(); err != nil {
+		t.Fatalf("ReloadDataKey failed: %v", err)
+	}
+	if status := reopened.EncryptionStatus(); status.Locked || status.Error != "" {
+		t.Fatalf("Expected unlocked encryption, got %+v", status)
+	}
+	if providers, err := reopened.Load(); err != nil || len(providers) != 1 {
+		t.Fatalf("Expected provider to load, got %+v (%v)", providers, err)
+	}
+}
+
+func TestEncryption_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewWithDir(dir, NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if err := s.Save([]models.Provider{sensitiveProvider}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.EnableEncryption(EncryptionKeyPassphrase, ""); err == nil {
+		t.Error("Expected empty passphrase to be rejected")
+	}
+	if err := s.EnableEncryption(EncryptionKeyPassphrase, "correct horse"); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+
+	locked, err := NewWithDir(dir, NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if !locked.EncryptionStatus().Locked {
+		t.Fatal("Expected passphrase-encrypted data to start locked")
+	}
+	if _, err := locked.Load(); !errors.Is(err, ErrDataLocked) {
+		t.Fatalf("Expected ErrDataLocked, got %v", err)
+	}
+	if err := locked.Save(nil); !errors.Is(err, ErrDataLocked) {
+		t.Fatalf("Expected locked save to fail, got %v", err)
+	}
+	// A locked file must not be mistaken for a corrupt one
+	if locked.LastRecovery() != nil {
+		t.Error("Expected no recovery while locked")
+	}
+
+	if err := locked.UnlockData("wrong"); err == nil {
+		t.Error("Expected wrong passphrase to be rejected")
+	}
+	if err := locked.UnlockData("correct horse"); err != nil {
+		t.Fatalf("UnlockData failed: %v", err)
+	}
+	providers, err := locked.Load()
+	if err != nil || len(providers) != 1 {
+		t.Fatalf("Expected provider after unlock, got %d (%v)", len(providers), err)
+	}
+}
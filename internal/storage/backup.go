@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// BackupDirName is the default directory for automatic backups, inside the
+// data directory
+const BackupDirName = "backups"
+
+// backupPassphraseSecret is the keyring entry holding the passphrase that
+// encrypts automatic backups with API keys
+const backupPassphraseSecret = "backup-passphrase"
+
+// Automatic backup file names: llm-desk-auto-<timestamp>.json, with .enc
+// appended when the file is encrypted
+const (
+	backupPrefix     = "llm-desk-auto-"
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// BackupSettings configures automatic backups
+type BackupSettings struct {
+	Enabled         bool   `json:"enabled"`
+	Directory       string `json:"directory,omitempty"` // Empty means <dataDir>/backups
+	OnChange        bool   `json:"onChange"`            // Back up after every catalog change
+	IntervalMinutes int    `json:"intervalMinutes"`     // Scheduled backups; 0 disables the schedule
+	IncludeSecrets  bool   `json:"includeSecrets"`      // Include API keys, encrypted with the backup passphrase
+	KeepLast        int    `json:"keepLast"`            // Most recent backups always kept
+	KeepDaily       int    `json:"keepDaily"`           // Days for which the newest backup of the day is kept
+	KeepWeekly      int    `json:"keepWeekly"`          // Weeks for which the newest backup of the week is kept
+}
+
+// DefaultBackupSettings returns the settings used until the user changes
+// them: disabled, and when enabled, backups on change and every day, keeping
+// the last 10, one a day for a week and one a week for a month
+func DefaultBackupSettings() BackupSettings {
+	return BackupSettings{
+		OnChange:        true,
+		IntervalMinutes: 24 * 60,
+		KeepLast:        10,
+		KeepDaily:       7,
+		KeepWeekly:      4,
+	}
+}
+
+// BackupFile describes one automatic backup
+type BackupFile struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	CreatedAt string `json:"createdAt"`
+	Size      int64  `json:"size"`
+	Encrypted bool   `json:"encrypted"`
+
+	created time.Time
+}
+
+// Created returns when the backup was written
+func (b BackupFile) Created() time.Time {
+	return b.created
+}
+
+// BackupDir returns the directory automatic backups are written to
+func (s *Storage) BackupDir(settings BackupSettings) string {
+	if settings.Directory != "" {
+		return settings.Directory
+	}
+	return filepath.Join(s.dataDir, BackupDirName)
+}
+
+// SetBackupPassphrase stores the passphrase for encrypted automatic backups
+// in the active key backend. An empty passphrase removes it.
+func (s *Storage) SetBackupPassphrase(passphrase string) error {
+	secrets := s.backupSecrets()
+	if secrets == nil {
+		return fmt.Errorf("the key backend cannot hold the backup passphrase")
+	}
+	if passphrase == "" {
+		return secrets.DeleteSecret(backupPassphraseSecret)
+	}
+	return secrets.SetSecret(backupPassphraseSecret, passphrase)
+}
+
+// BackupPassphrase returns the stored backup passphrase, or "" if none is set
+func (s *Storage) BackupPassphrase() (string, error) {
+	secrets := s.backupSecrets()
+	if secrets == nil {
+		return "", nil
+	}
+	passphrase, err := secrets.GetSecret(backupPassphraseSecret)
+	if err != nil && isNotFound(err) {
+		return "", nil
+	}
+	return passphrase, err
+}
+
+// backupSecrets returns where the backup passphrase is kept
+func (s *Storage) backupSecrets() SecretStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.activeSecrets()
+}
+
+// WriteBackup writes data to a new backup file in dir. With a passphrase the
+// file is encrypted like an encrypted export. While encryption at rest is on,
+// every backup is also encrypted with the data key, like the data files.
+func (s *Storage) WriteBackup(dir string, data *models.LLMDeskData, passphrase string, at time.Time) (BackupFile, error) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return BackupFile{}, err
+	}
+	name := backupPrefix + at.UTC().Format(backupTimeFormat) + ".json"
+	if passphrase != "" {
+		if jsonData, err = Encrypt(jsonData, passphrase); err != nil {
+			return BackupFile{}, err
+		}
+		name += ".enc"
+	}
+	s.mu.RLock()
+	jsonData, err = s.crypt.encode(jsonData)
+	s.mu.RUnlock()
+	if err != nil {
+		return BackupFile{}, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return BackupFile{}, err
+	}
+	path := filepath.Join(dir, name)
+	if err := writeFileAtomic(path, jsonData, 0600); err != nil {
+		return BackupFile{}, err
+	}
+	return backupFile(path, int64(len(jsonData)))
+}
+
+// ListBackups returns the automatic backups in dir, newest first. Other
+// files in the directory are ignored.
+func (s *Storage) ListBackups(dir string) ([]BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []BackupFile{}, nil
+		}
+		return nil, err
+	}
+
+	backups := []BackupFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if b, err := backupFile(filepath.Join(dir, entry.Name()), info.Size()); err == nil {
+			backups = append(backups, b)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].created.After(backups[j].created)
+	})
+	return backups, nil
+}
+
+// FindBackup returns the automatic backup called name in dir
+func (s *Storage) FindBackup(dir, name string) (BackupFile, error) {
+	if name != filepath.Base(name) {
+		return BackupFile{}, fmt.Errorf("invalid backup name: %s", name)
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return BackupFile{}, fmt.Errorf("backup not found: %s", name)
+		}
+		return BackupFile{}, err
+	}
+	return backupFile(path, info.Size())
+}
+
+// ReadBackup reads and parses a backup, decrypting it with passphrase if it
+// is encrypted
+func (s *Storage) ReadBackup(b BackupFile, passphrase string) (*models.LLMDeskData, error) {
+	if b.Encrypted {
+		if passphrase == "" {
+			return nil, fmt.Errorf("backup %s is encrypted and no passphrase is available", b.Name)
+		}
+		return s.ImportEncryptedFromFile(b.Path, passphrase)
+	}
+	return s.ImportFromFile(b.Path)
+}
+
+// RemoveBackup deletes a backup file
+func (s *Storage) RemoveBackup(b BackupFile) error {
+	return os.Remove(b.Path)
+}
+
+// backupFile describes the backup at path, or fails if the name is not an
+// automatic backup name
+func backupFile(path string, size int64) (BackupFile, error) {
+	name := filepath.Base(path)
+	stamp, encrypted := strings.CutSuffix(name, ".enc")
+	stamp, ok := strings.CutSuffix(stamp, ".json")
+	if !ok || !strings.HasPrefix(stamp, backupPrefix) {
+		return BackupFile{}, fmt.Errorf("not an automatic backup: %s", name)
+	}
+	created, err := time.Parse(backupTimeFormat, strings.TrimPrefix(stamp, backupPrefix))
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("not an automatic backup: %s", name)
+	}
+	return BackupFile{
+		Name:      name,
+		Path:      path,
+		CreatedAt: created.Format(time.RFC3339),
+		Size:      size,
+		Encrypted: encrypted,
+		created:   created,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+func TestBackups_WriteListFind(t *testing.T) {
+	s, err := NewWithDir(t.TempDir(), NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	dir := s.BackupDir(BackupSettings{})
+	if dir != filepath.Join(s.GetDataDir(), BackupDirName) {
+		t.Errorf("Expected the default backup directory, got %s", dir)
+	}
+
+	data := &models.LLMDeskData{Version: "1.0.0", Providers: []models.Provider{{ID: "openai", Name: "OpenAI"}}}
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := s.WriteBackup(dir, data, "", start); err != nil {
+		t.Fatalf("WriteBackup failed: %v", err)
+	}
+	encrypted, err := s.WriteBackup(dir, data, "pw", start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("WriteBackup failed: %v", err)
+	}
+	if !encrypted.Encrypted || filepath.Ext(encrypted.Name) != ".enc" {
+		t.Errorf("Expected an encrypted backup, got %+v", encrypted)
+	}
+	// Other files in the directory are not backups
+	if err := os.WriteFile(filepath.Join(dir, "notes.json"), []byte("{}"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	backups, err := s.ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != encrypted.Name || !backups[1].Created().Equal(start) {
+		t.Fatalf("Expected two backups, newest first, got %+v", backups)
+	}
+
+	found, err := s.FindBackup(dir, encrypted.Name)
+	if err != nil {
+		t.Fatalf("FindBackup failed: %v", err)
+	}
+	if _, err := s.ReadBackup(found, ""); err == nil {
+		t.Error("Expected an encrypted backup to need a passphrase")
+	}
+	read, err := s.ReadBackup(found, "pw")
+	if err != nil || len(read.Providers) != 1 {
+		t.Errorf("Expected to read the backup, got %+v (%v)", read, err)
+	}
+
+	for _, name := range []string{"../providers.json", "notes.json", "missing.json"} {
+		if _, err := s.FindBackup(dir, name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+	if backups, err := s.ListBackups(filepath.Join(dir, "missing")); err != nil || len(backups) != 0 {
+		t.Errorf("Expected no backups in a missing directory, got %v (%v)", backups, err)
+	}
+}
+
+func TestBackups_EncryptedAtRest(t *testing.T) {
+	s, err := NewWithDir(t.TempDir(), NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	dir := s.BackupDir(BackupSettings{})
+	data := &models.LLMDeskData{Version: "1.0.0", Providers: []models.Provider{sensitiveProvider}}
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	before, err := s.WriteBackup(dir, data, "", start)
+	if err != nil {
+		t.Fatalf("WriteBackup failed: %v", err)
+	}
+
+	// Turning encryption on recodes existing backups and encrypts new ones
+	if err := s.EnableEncryption(EncryptionKeyKeyring, ""); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+	after, err := s.WriteBackup(dir, data, "", start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("WriteBackup failed: %v", err)
+	}
+	withSecrets, err := s.WriteBackup(dir, data, "pw", start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("WriteBackup failed: %v", err)
+	}
+	for _, b := range []BackupFile{before, after, withSecrets} {
+		raw, _ := os.ReadFile(b.Path)
+		if !bytes.HasPrefix(raw, atRestMagic) {
+			t.Errorf("Expected %s to be encrypted at rest", b.Name)
+		}
+	}
+	assertNotOnDisk(t, dir, "llm-gw.corp.internal")
+
+	if read, err := s.ReadBackup(after, ""); err != nil || len(read.Providers) != 1 {
+		t.Errorf("Expected to read the backup, got %+v (%v)", read, err)
+	}
+	if read, err := s.ReadBackup(withSecrets, "pw"); err != nil || len(read.Providers) != 1 {
+		t.Errorf("Expected to read the passphrase backup, got %+v (%v)", read, err)
+	}
+
+	// Turning it off restores plain backups
+	if err := s.DisableEncryption(); err != nil {
+		t.Fatalf("DisableEncryption failed: %v", err)
+	}
+	raw, _ := os.ReadFile(after.Path)
+	if !bytes.Contains(raw, []byte("llm-gw.corp.internal")) {
+		t.Error("Expected the backup to be plaintext after disabling")
+	}
+	if read, err := s.ReadBackup(withSecrets, "pw"); err != nil || len(read.Providers) != 1 {
+		t.Errorf("Expected the passphrase backup to stay readable, got %+v (%v)", read, err)
+	}
+}
+
+func TestBackupPassphrase(t *testing.T) {
+	s, err := NewWithDir(t.TempDir(), NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if passphrase, err := s.BackupPassphrase(); err != nil || passphrase != "" {
+		t.Errorf("Expected no passphrase, got %q (%v)", passphrase, err)
+	}
+	if err := s.SetBackupPassphrase("pw"); err != nil {
+		t.Fatalf("SetBackupPassphrase failed: %v", err)
+	}
+	if passphrase, _ := s.BackupPassphrase(); passphrase != "pw" {
+		t.Errorf("Expected 'pw', got %q", passphrase)
+	}
+	if err := s.SetBackupPassphrase(""); err != nil {
+		t.Fatalf("SetBackupPassphrase failed: %v", err)
+	}
+	if passphrase, _ := s.BackupPassphrase(); passphrase != "" {
+		t.Errorf("Expected the passphrase to be removed, got %q", passphrase)
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// hasPlaintextKeys reports whether any key record of p still carries its secret
+func hasPlaintextKeys(p models.Provider) bool {
+	for _, k := range p.Credentials.APIKeys {
+		if k.Key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprintSet returns the fingerprints of a list of key records
+func fingerprintSet(keys []models.APIKey) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.Fingerprint != "" {
+			set[k.Fingerprint] = true
+		}
+	}
+	return set
+}
+
+// sameSet reports whether two fingerprint sets are equal
+func sameSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for fp := range a {
+		if !b[fp] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeKeys fills in IDs, timestamps and derived fields of key records
+func normalizeKeys(p *models.Provider) {
+	now := time.Now().Format(time.RFC3339)
+	for i := range p.Credentials.APIKeys {
+		k := &p.Credentials.APIKeys[i]
+		if k.ID == "" {
+			k.ID = models.NewAPIKeyID()
+		}
+		if k.CreatedAt == "" {
+			k.CreatedAt = now
+		}
+		if k.Status == "" {
+			k.Status = models.KeyStatusUnknown
+		}
+		if k.Key != "" {
+			k.SetSecret(k.Key)
+		}
+	}
+}
+
+// injectSecrets attaches stored secrets to the key records of p by
+// fingerprint. Secrets with no matching record, such as those stored before
+// key records existed, get a new record. Returns true if records were added.
+func injectSecrets(p *models.Provider, secrets []string) bool {
+	used := make(map[string]bool, len(secrets))
+	for i := range p.Credentials.APIKeys {
+		k := &p.Credentials.APIKeys[i]
+		for _, secret := range secrets {
+			if !used[secret] && k.Fingerprint != "" && models.KeyFingerprint(secret) == k.Fingerprint {
+				k.Key = secret
+				used[secret] = true
+				break
+			}
+		}
+	}
+
+	added := false
+	for _, secret := range secrets {
+		if used[secret] {
+			continue
+		}
+		record := models.NewAPIKey(secret)
+		record.Label = fmt.Sprintf("Key %d", len(p.Credentials.APIKeys)+1)
+		p.Credentials.APIKeys = append(p.Credentials.APIKeys, record)
+		used[secret] = true
+		added = true
+	}
+	return added
+}
+
+// writeKeys stores the secrets of p's key records in the key backend.
+// previous holds the fingerprints currently on disk for p. Nothing is
+// written when no secret is present and the set of keys is unchanged, so
+// providers whose keys could not be loaded are never overwritten.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) writeKeys(p models.Provider, previous map[string]bool) error {
+	records := p.Credentials.APIKeys
+	if !hasPlaintextKeys(p) && sameSet(fingerprintSet(records), previous) {
+		return nil
+	}
+
+	if len(records) == 0 {
+		return s.keyring.DeleteKeys(p.ID)
+	}
+
+	// Records without a secret keep the one already stored for them
+	var stored []string
+	storedLoaded := false
+	secrets := make([]string, 0, len(records))
+	for _, k := range records {
+		if k.Key != "" {
+			secrets = append(secrets, k.Key)
+			continue
+		}
+		if k.Fingerprint == "" {
+			continue
+		}
+		if !storedLoaded {
+			var err error
+			if stored, err = s.keyring.GetKeys(p.ID); err != nil {
+				return fmt.Errorf("cannot update keys for %s: %w", p.ID, err)
+			}
+			storedLoaded = true
+		}
+		for _, secret := range stored {
+			if models.KeyFingerprint(secret) == k.Fingerprint {
+				secrets = append(secrets, secret)
+				break
+			}
+		}
+	}
+
+	return s.keyring.SetKeys(p.ID, secrets)
+}
+
+// scrubKeys returns a copy of p's key records without secrets
+func scrubKeys(keys []models.APIKey) []models.APIKey {
+	scrubbed := make([]models.APIKey, len(keys))
+	for i, k := range keys {
+		scrubbed[i] = k
+		scrubbed[i].Key = ""
+	}
+	return scrubbed
+}
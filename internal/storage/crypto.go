@@ -1,13 +1,20 @@
 package storage
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -17,81 +24,425 @@ const (
 	iterations = 100000
 )
 
-// Encrypt encrypts data using a passphrase with AES-GCM
-func Encrypt(data []byte, passphrase string) ([]byte, error) {
-	// 1. Generate salt
-	salt := make([]byte, saltSize)
-	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
-		return nil, fmt.Errorf("failed to generate salt: %w", err)
+// Encrypted container layout. Everything before the ciphertext is the header
+// and is authenticated as additional data, so its parameters cannot be
+// tampered with:
+//
+//	magic "LLMD" | version u8 | kdf u8 | iterations u32 | memoryKiB u32 |
+//	parallelism u8 | salt len u8 | salt | cipher u8 | nonce len u8 | nonce
+//
+// Data without the magic is the legacy format: salt || nonce || ciphertext
+// with PBKDF2-SHA256 at 100000 iterations.
+var containerMagic = []byte("LLMD")
+
+// ContainerVersion is the container format written by Encrypt
+const ContainerVersion = 1
+
+// KDFAlgorithm identifies how a key is derived from a passphrase
+type KDFAlgorithm uint8
+
+const (
+	KDFPBKDF2SHA256 KDFAlgorithm = 1
+	KDFArgon2id     KDFAlgorithm = 2
+)
+
+// CipherAlgorithm identifies the cipher of an encrypted container
+type CipherAlgorithm uint8
+
+const (
+	CipherAES256GCM CipherAlgorithm = 1
+)
+
+// Upper bounds accepted when reading a header. Headers are read before the
+// passphrase can be checked, so a crafted file must not be able to make key
+// derivation run for minutes or allocate much more than DefaultKDF does:
+// Argon2id gets twice DefaultKDF's passes and four times its memory.
+// PBKDF2 needs no memory and is only bounded in time.
+const (
+	maxPBKDF2Iterations = 2_000_000
+	maxArgon2Time       = 6
+	maxKDFMemoryKiB     = 256 * 1024 // 256 MiB
+	maxKDFParallelism   = 64
+)
+
+// KDFParams describes a key derivation. For Argon2id Iterations is the time
+// cost; MemoryKiB and Parallelism are unused by PBKDF2.
+type KDFParams struct {
+	Algorithm   KDFAlgorithm `json:"algorithm"`
+	Iterations  uint32       `json:"iterations"`
+	MemoryKiB   uint32       `json:"memoryKiB,omitempty"`
+	Parallelism uint8        `json:"parallelism,omitempty"`
+}
+
+// DefaultKDF is used for everything Encrypt writes
+var DefaultKDF = KDFParams{Algorithm: KDFArgon2id, Iterations: 3, MemoryKiB: 64 * 1024, Parallelism: 4}
+
+// legacyKDF is the fixed derivation of headerless data
+var legacyKDF = KDFParams{Algorithm: KDFPBKDF2SHA256, Iterations: iterations}
+
+// String returns a readable name such as "argon2id"
+func (a KDFAlgorithm) String() string {
+	switch a {
+	case KDFPBKDF2SHA256:
+		return "pbkdf2-sha256"
+	case KDFArgon2id:
+		return "argon2id"
+	}
+	return fmt.Sprintf("kdf(%d)", uint8(a))
+}
+
+// String returns a readable name such as "aes-256-gcm"
+func (c CipherAlgorithm) String() string {
+	if c == CipherAES256GCM {
+		return "aes-256-gcm"
 	}
+	return fmt.Sprintf("cipher(%d)", uint8(c))
+}
 
-	// 2. Derive key using PBKDF2
-	key := pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+// validate rejects unknown algorithms and out-of-range costs
+func (p KDFParams) validate() error {
+	switch p.Algorithm {
+	case KDFPBKDF2SHA256:
+		if p.Iterations == 0 || p.Iterations > maxPBKDF2Iterations {
+			return fmt.Errorf("invalid pbkdf2 iterations: %d", p.Iterations)
+		}
+	case KDFArgon2id:
+		if p.Iterations == 0 || p.Iterations > maxArgon2Time {
+			return fmt.Errorf("invalid argon2id time cost: %d", p.Iterations)
+		}
+		if p.MemoryKiB < 8*uint32(p.Parallelism) || p.MemoryKiB > maxKDFMemoryKiB {
+			return fmt.Errorf("invalid argon2id memory: %d KiB", p.MemoryKiB)
+		}
+		if p.Parallelism == 0 || p.Parallelism > maxKDFParallelism {
+			return fmt.Errorf("invalid argon2id parallelism: %d", p.Parallelism)
+		}
+	default:
+		return fmt.Errorf("unsupported key derivation: %s", p.Algorithm)
+	}
+	return nil
+}
 
-	// 3. Create AES cipher
-	block, err := aes.NewCipher(key)
+// deriveKey derives an AES-256 key from passphrase
+func (p KDFParams) deriveKey(passphrase string, salt []byte) []byte {
+	if p.Algorithm == KDFArgon2id {
+		return argon2.IDKey([]byte(passphrase), salt, p.Iterations, p.MemoryKiB, p.Parallelism, keySize)
+	}
+	return pbkdf2.Key([]byte(passphrase), salt, int(p.Iterations), keySize, sha256.New)
+}
+
+// weakerThan reports whether p costs less than other. Different algorithms
+// always count as weaker so old files move to the current default.
+func (p KDFParams) weakerThan(other KDFParams) bool {
+	if p.Algorithm != other.Algorithm {
+		return true
+	}
+	return p.Iterations < other.Iterations || p.MemoryKiB < other.MemoryKiB
+}
+
+// ContainerInfo describes how data was encrypted
+type ContainerInfo struct {
+	Legacy  bool      `json:"legacy"` // Headerless format written before versioned containers
+	Version int       `json:"version,omitempty"`
+	KDF     KDFParams `json:"kdf"`
+	Cipher  string    `json:"cipher"`
+}
+
+// containerHeader is a parsed container header
+type containerHeader struct {
+	version int
+	kdf     KDFParams
+	salt    []byte
+	cipher  CipherAlgorithm
+	nonce   []byte
+	raw     []byte // Header bytes, authenticated as additional data
+}
+
+// IsContainer reports whether data starts with the container magic
+func IsContainer(data []byte) bool {
+	return bytes.HasPrefix(data, containerMagic)
+}
+
+// InspectEncrypted reports the format and KDF parameters of encrypted data
+// without decrypting it
+func InspectEncrypted(data []byte) (ContainerInfo, error) {
+	if !IsContainer(data) {
+		if len(data) < saltSize+12 {
+			return ContainerInfo{}, fmt.Errorf("invalid encrypted data")
+		}
+		return ContainerInfo{Legacy: true, KDF: legacyKDF, Cipher: CipherAES256GCM.String()}, nil
+	}
+	header, _, err := parseContainer(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return ContainerInfo{}, err
 	}
+	return ContainerInfo{Version: header.version, KDF: header.kdf, Cipher: header.cipher.String()}, nil
+}
 
-	// 4. Create GCM
-	gcm, err := cipher.NewGCM(block)
+// NeedsReencrypt reports whether data uses the legacy format or a KDF weaker
+// than DefaultKDF
+func NeedsReencrypt(data []byte) bool {
+	info, err := InspectEncrypted(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gcm: %w", err)
+		return false
+	}
+	return info.Legacy || info.KDF.weakerThan(DefaultKDF)
+}
+
+// Encrypt encrypts data using a passphrase with AES-GCM in a versioned
+// container, deriving the key with DefaultKDF
+func Encrypt(data []byte, passphrase string) ([]byte, error) {
+	return newKeyCache(passphrase).seal(data)
+}
+
+// Decrypt decrypts data using a passphrase. Both versioned containers and the
+// legacy headerless format are accepted.
+func Decrypt(encryptedData []byte, passphrase string) ([]byte, error) {
+	return newKeyCache(passphrase).open(encryptedData)
+}
+
+// Reencrypt decrypts data and encrypts it again in the current container
+// format with DefaultKDF
+func Reencrypt(encryptedData []byte, passphrase string) ([]byte, error) {
+	plaintext, err := Decrypt(encryptedData, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return Encrypt(plaintext, passphrase)
+}
+
+// keyCache seals and opens data for one passphrase, remembering derived keys
+// so repeated writes pay for the KDF once rather than on every call. Safe for
+// concurrent use.
+type keyCache struct {
+	passphrase string
+
+	mu      sync.Mutex
+	salt    []byte            // Salt used for sealing, chosen on first use
+	derived map[string][]byte // Keys by KDF params and salt
+}
+
+// newKeyCache creates a keyCache for passphrase
+func newKeyCache(passphrase string) *keyCache {
+	return &keyCache{passphrase: passphrase, derived: make(map[string][]byte)}
+}
+
+// key returns the key for kdf and salt, deriving it on first use
+func (c *keyCache) key(kdf KDFParams, salt []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := fmt.Sprintf("%d/%d/%d/%d/%x", kdf.Algorithm, kdf.Iterations, kdf.MemoryKiB, kdf.Parallelism, salt)
+	if key, ok := c.derived[id]; ok {
+		return key
+	}
+	key := kdf.deriveKey(c.passphrase, salt)
+	c.derived[id] = key
+	return key
+}
+
+// sealingSalt returns the salt new containers are sealed with
+func (c *keyCache) sealingSalt() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.salt == nil {
+		salt := make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		c.salt = salt
+	}
+	return c.salt, nil
+}
+
+// seal encrypts data into a container with DefaultKDF
+func (c *keyCache) seal(data []byte) ([]byte, error) {
+	salt, err := c.sealingSalt()
+	if err != nil {
+		return nil, err
+	}
+	kdf := DefaultKDF
+	if err := kdf.validate(); err != nil {
+		return nil, err
 	}
 
-	// 5. Create nonce
+	gcm, err := newGCM(c.key(kdf, salt))
+	if err != nil {
+		return nil, err
+	}
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	// 6. Seal (encrypt)
-	ciphertext := gcm.Seal(nil, nonce, data, nil)
+	header := make([]byte, 0, 64)
+	header = append(header, containerMagic...)
+	header = append(header, ContainerVersion, byte(kdf.Algorithm))
+	header = binary.BigEndian.AppendUint32(header, kdf.Iterations)
+	header = binary.BigEndian.AppendUint32(header, kdf.MemoryKiB)
+	header = append(header, kdf.Parallelism, byte(len(salt)))
+	header = append(header, salt...)
+	header = append(header, byte(CipherAES256GCM), byte(len(nonce)))
+	header = append(header, nonce...)
 
-	// 7. Combine: salt + nonce + ciphertext
-	result := append(salt, nonce...)
-	result = append(result, ciphertext...)
+	return gcm.Seal(header, nonce, data, header), nil
+}
 
-	return result, nil
+// open decrypts a container or legacy data
+func (c *keyCache) open(data []byte) ([]byte, error) {
+	if !IsContainer(data) {
+		return c.openLegacy(data)
+	}
+
+	header, ciphertext, err := parseContainer(data)
+	if err != nil {
+		// Legacy data starts with a random salt, which can collide with the magic
+		if plaintext, legacyErr := c.openLegacy(data); legacyErr == nil {
+			return plaintext, nil
+		}
+		return nil, err
+	}
+
+	gcm, err := newGCM(c.key(header.kdf, header.salt))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, header.nonce, ciphertext, header.raw)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed (wrong passphrase?): %w", err)
+	}
+	return plaintext, nil
 }
 
-// Decrypt decrypts data using a passphrase with AES-GCM
-func Decrypt(encryptedData []byte, passphrase string) ([]byte, error) {
+// openLegacy decrypts headerless salt || nonce || ciphertext data
+func (c *keyCache) openLegacy(encryptedData []byte) ([]byte, error) {
 	if len(encryptedData) < saltSize+12 { // basic check for salt + nonce (usually 12 for GCM)
 		return nil, fmt.Errorf("invalid encrypted data")
 	}
 
-	// 1. Extract salt and nonce
 	salt := encryptedData[:saltSize]
 	remaining := encryptedData[saltSize:]
 
-	// 2. Derive key
-	key := pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+	gcm, err := newGCM(c.key(legacyKDF, salt))
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(remaining) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	plaintext, err := gcm.Open(nil, remaining[:nonceSize], remaining[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed (wrong passphrase?): %w", err)
+	}
+	return plaintext, nil
+}
 
+// parseContainer splits a container into its header and ciphertext
+func parseContainer(data []byte) (containerHeader, []byte, error) {
+	var h containerHeader
+	r := bytes.NewReader(data[len(containerMagic):])
+	errShort := errors.New("encrypted data header is truncated")
+
+	var fixed struct {
+		Version     uint8
+		KDF         uint8
+		Iterations  uint32
+		MemoryKiB   uint32
+		Parallelism uint8
+	}
+	if err := binary.Read(r, binary.BigEndian, &fixed); err != nil {
+		return h, nil, errShort
+	}
+	if fixed.Version != ContainerVersion {
+		return h, nil, fmt.Errorf("unsupported encrypted data version: %d", fixed.Version)
+	}
+	h.version = int(fixed.Version)
+	h.kdf = KDFParams{
+		Algorithm:   KDFAlgorithm(fixed.KDF),
+		Iterations:  fixed.Iterations,
+		MemoryKiB:   fixed.MemoryKiB,
+		Parallelism: fixed.Parallelism,
+	}
+	if err := h.kdf.validate(); err != nil {
+		return h, nil, err
+	}
+
+	readField := func() ([]byte, error) {
+		n, err := r.ReadByte()
+		if err != nil {
+			return nil, errShort
+		}
+		field := make([]byte, n)
+		if _, err := io.ReadFull(r, field); err != nil {
+			return nil, errShort
+		}
+		return field, nil
+	}
+
+	var err error
+	if h.salt, err = readField(); err != nil {
+		return h, nil, err
+	}
+	if len(h.salt) < 8 {
+		return h, nil, fmt.Errorf("encrypted data salt is too short")
+	}
+	cipherID, err := r.ReadByte()
+	if err != nil {
+		return h, nil, errShort
+	}
+	h.cipher = CipherAlgorithm(cipherID)
+	if h.cipher != CipherAES256GCM {
+		return h, nil, fmt.Errorf("unsupported cipher: %s", h.cipher)
+	}
+	if h.nonce, err = readField(); err != nil {
+		return h, nil, err
+	}
+	if len(h.nonce) != 12 {
+		return h, nil, fmt.Errorf("invalid nonce length: %d", len(h.nonce))
+	}
+
+	headerLen := len(data) - r.Len()
+	h.raw = data[:headerLen]
+	return h, data[headerLen:], nil
+}
+
+// newGCM creates an AES-GCM AEAD for key
+func newGCM(key []byte) (cipher.AEAD, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cipher: %w", err)
 	}
-
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gcm: %w", err)
 	}
+	return gcm, nil
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(remaining) < nonceSize {
-		return nil, fmt.Errorf("ciphertext too short")
+// ReencryptFile rewrites an encrypted file, such as an old encrypted backup,
+// in the current container format. It reports false if the file was already
+// current.
+func ReencryptFile(path, passphrase string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if _, err := InspectEncrypted(data); err != nil {
+		return false, fmt.Errorf("%s is not an encrypted file: %w", filepath.Base(path), err)
+	}
+	if !NeedsReencrypt(data) {
+		return false, nil
 	}
 
-	nonce := remaining[:nonceSize]
-	ciphertext := remaining[nonceSize:]
-
-	// 3. Open (decrypt)
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	reencrypted, err := Reencrypt(data, passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("decryption failed (wrong passphrase?): %w", err)
+		return false, err
 	}
-
-	return plaintext, nil
+	if err := writeFileAtomic(path, reencrypted, 0600); err != nil {
+		return false, err
+	}
+	return true, nil
 }
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -54,3 +57,155 @@ func TestCrypto_CorruptData(t *testing.T) {
 		t.Error("Decryption of corrupted data should fail")
 	}
 }
+
+// legacyEncrypt writes the headerless salt || nonce || ciphertext format
+func legacyEncrypt(t *testing.T, data []byte, passphrase string) []byte {
+	t.Helper()
+	salt := bytes.Repeat([]byte{7}, saltSize)
+	gcm, err := newGCM(legacyKDF.deriveKey(passphrase, salt))
+	if err != nil {
+		t.Fatalf("newGCM failed: %v", err)
+	}
+	nonce := bytes.Repeat([]byte{9}, gcm.NonceSize())
+	out := append(append(salt, nonce...), gcm.Seal(nil, nonce, data, nil)...)
+	return out
+}
+
+func TestCrypto_ContainerHeader(t *testing.T) {
+	encrypted, err := Encrypt([]byte("data"), "pass")
+	if err != nil {
+		t.Fatalf("Encryption failed: %v", err)
+	}
+	if !IsContainer(encrypted) {
+		t.Fatal("Expected container magic")
+	}
+
+	info, err := InspectEncrypted(encrypted)
+	if err != nil {
+		t.Fatalf("InspectEncrypted failed: %v", err)
+	}
+	if info.Legacy || info.Version != ContainerVersion || info.KDF != DefaultKDF || info.Cipher != "aes-256-gcm" {
+		t.Errorf("Unexpected container info: %+v", info)
+	}
+	if NeedsReencrypt(encrypted) {
+		t.Error("Expected current container not to need re-encryption")
+	}
+}
+
+func TestCrypto_HeaderIsAuthenticated(t *testing.T) {
+	encrypted, _ := Encrypt([]byte("data"), "pass")
+
+	// Lower the Argon2id time cost recorded in the header
+	tampered := append([]byte{}, encrypted...)
+	tampered[9] = 2
+	if _, err := Decrypt(tampered, "pass"); err == nil {
+		t.Error("Expected tampered header to fail")
+	}
+
+	// Unknown versions and out-of-range parameters are refused before derivation
+	tampered = append([]byte{}, encrypted...)
+	tampered[4] = 99
+	if _, err := Decrypt(tampered, "pass"); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("Expected unsupported version error, got %v", err)
+	}
+	tampered = append([]byte{}, encrypted...)
+	tampered[10] = 0xFF // memory cost
+	if _, err := InspectEncrypted(tampered); err == nil {
+		t.Error("Expected oversized memory cost to be rejected")
+	}
+}
+
+func TestCrypto_RejectsCostlyHeader(t *testing.T) {
+	encrypted, _ := Encrypt([]byte("data"), "pass")
+
+	// 2 GiB of memory, within a uint32 but far above DefaultKDF
+	tampered := append([]byte{}, encrypted...)
+	copy(tampered[10:14], []byte{0x00, 0x20, 0x00, 0x00})
+	if _, err := Decrypt(tampered, "pass"); err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Errorf("Expected 2 GiB of memory to be refused before derivation, got %v", err)
+	}
+
+	// 7 Argon2id passes
+	tampered = append([]byte{}, encrypted...)
+	tampered[9] = 7
+	if _, err := Decrypt(tampered, "pass"); err == nil || !strings.Contains(err.Error(), "time cost") {
+		t.Errorf("Expected 7 passes to be refused before derivation, got %v", err)
+	}
+}
+
+func TestKDFParams_CostBoundaries(t *testing.T) {
+	if maxArgon2Time != 2*DefaultKDF.Iterations || maxKDFMemoryKiB != 4*DefaultKDF.MemoryKiB {
+		t.Errorf("Expected the caps at twice the passes and four times the memory of DefaultKDF, got %d and %d KiB", maxArgon2Time, maxKDFMemoryKiB)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(p *KDFParams)
+		valid bool
+	}{
+		{"default", func(p *KDFParams) {}, true},
+		{"most passes", func(p *KDFParams) { p.Iterations = maxArgon2Time }, true},
+		{"one pass too many", func(p *KDFParams) { p.Iterations = maxArgon2Time + 1 }, false},
+		{"most memory", func(p *KDFParams) { p.MemoryKiB = maxKDFMemoryKiB }, true},
+		{"one KiB too much", func(p *KDFParams) { p.MemoryKiB = maxKDFMemoryKiB + 1 }, false},
+	}
+	for _, tt := range tests {
+		p := DefaultKDF
+		tt.edit(&p)
+		if err := p.validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestCrypto_LegacyFormat(t *testing.T) {
+	legacy := legacyEncrypt(t, []byte("old backup"), "pass")
+
+	info, err := InspectEncrypted(legacy)
+	if err != nil || !info.Legacy || info.KDF.Algorithm != KDFPBKDF2SHA256 {
+		t.Fatalf("Expected legacy info, got %+v (%v)", info, err)
+	}
+	if !NeedsReencrypt(legacy) {
+		t.Error("Expected legacy data to need re-encryption")
+	}
+
+	plaintext, err := Decrypt(legacy, "pass")
+	if err != nil || string(plaintext) != "old backup" {
+		t.Fatalf("Expected legacy data to decrypt, got %q (%v)", plaintext, err)
+	}
+
+	upgraded, err := Reencrypt(legacy, "pass")
+	if err != nil {
+		t.Fatalf("Reencrypt failed: %v", err)
+	}
+	if !IsContainer(upgraded) {
+		t.Error("Expected re-encrypted data to be a container")
+	}
+	if plaintext, _ := Decrypt(upgraded, "pass"); string(plaintext) != "old backup" {
+		t.Errorf("Expected re-encrypted data to round-trip, got %q", plaintext)
+	}
+}
+
+func TestCrypto_ReencryptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.json.enc")
+	if err := os.WriteFile(path, legacyEncrypt(t, []byte("{}"), "pass"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if _, err := ReencryptFile(path, "wrong"); err == nil {
+		t.Error("Expected wrong passphrase to fail")
+	}
+	upgraded, err := ReencryptFile(path, "pass")
+	if err != nil || !upgraded {
+		t.Fatalf("Expected file to be upgraded, got %v (%v)", upgraded, err)
+	}
+	upgraded, err = ReencryptFile(path, "pass")
+	if err != nil || upgraded {
+		t.Errorf("Expected current file to be left alone, got %v (%v)", upgraded, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if plaintext, err := Decrypt(data, "pass"); err != nil || string(plaintext) != "{}" {
+		t.Errorf("Expected upgraded file to decrypt, got %q (%v)", plaintext, err)
+	}
+}
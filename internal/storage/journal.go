@@ -0,0 +1,461 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/models"
+)
+
+// JournalFilename is the append-only change journal inside the data directory
+const JournalFilename = "journal.jsonl"
+
+// Journal retention: the journal is moved to journal.jsonl.1 once it grows
+// past maxJournalSize, and the oldest rotated file falls off the end
+const (
+	maxJournalSize     = 4 << 20
+	journalGenerations = 2
+)
+
+// Journal change actions
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// ProviderChange is the effect of a mutation on one provider. Before and
+// After never contain API key secrets.
+type ProviderChange struct {
+	ProviderID string           `json:"providerId"`
+	Action     string           `json:"action"`
+	ModelIDs   []string         `json:"modelIds,omitempty"` // Models added, changed or removed
+	Before     *models.Provider `json:"before,omitempty"`
+	After      *models.Provider `json:"after,omitempty"`
+}
+
+// JournalEntry records one catalog mutation
+type JournalEntry struct {
+	Seq       int64            `json:"seq"`
+	Timestamp string           `json:"timestamp"`
+	Operation string           `json:"operation"`
+	Changes   []ProviderChange `json:"changes"`
+}
+
+// journal appends entries to the journal file. Its methods are called with
+// the Storage lock held.
+type journal struct {
+	path    string
+	seq     int64   // Last sequence number written; -1 until read from the file
+	codec   *atRest // Encryption at rest of the owning Storage
+	maxSize int64   // Size past which the file is rotated
+	keep    int     // Number of rotated files kept
+}
+
+// newJournal creates a journal for the file at path
+func newJournal(path string, codec *atRest) *journal {
+	return &journal{path: path, seq: -1, codec: codec, maxSize: maxJournalSize, keep: journalGenerations}
+}
+
+// append writes an entry for the changes between before and after, if any.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (j *journal) append(op string, before, after []models.Provider) error {
+	changes := diffProviders(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if j.seq < 0 {
+		entries, err := j.read()
+		if err != nil {
+			return err
+		}
+		j.seq = 0
+		if len(entries) > 0 {
+			j.seq = entries[len(entries)-1].Seq
+		}
+	}
+
+	entry := JournalEntry{
+		Seq:       j.seq + 1,
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Operation: op,
+		Changes:   changes,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if line, err = j.codec.encodeLine(line); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	j.seq = entry.Seq
+	if err := j.rotate(); err != nil {
+		// The entry is written; the file just grows until the next try
+		logger.Warn("Failed to rotate journal", "error", err)
+	}
+	return nil
+}
+
+// rotate moves the journal aside once it is larger than maxSize, shifting
+// the rotated files up by one. The oldest falls off the end.
+func (j *journal) rotate() error {
+	info, err := os.Stat(j.path)
+	if err != nil || info.Size() <= j.maxSize {
+		return nil
+	}
+	if j.keep <= 0 {
+		return os.Remove(j.path)
+	}
+	for n := j.keep - 1; n >= 1; n-- {
+		from := generationPath(j.path, n)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if err := os.Rename(from, generationPath(j.path, n+1)); err != nil {
+			return err
+		}
+	}
+	return os.Rename(j.path, generationPath(j.path, 1))
+}
+
+// files returns the journal files that are kept, oldest first
+func (j *journal) files() []string {
+	files := make([]string, 0, j.keep+1)
+	for n := j.keep; n >= 1; n-- {
+		files = append(files, generationPath(j.path, n))
+	}
+	return append(files, j.path)
+}
+
+// read returns every entry in the kept journal files, oldest first. Lines
+// that cannot be parsed, such as one cut short by a crash, are skipped.
+func (j *journal) read() ([]JournalEntry, error) {
+	entries := []JournalEntry{}
+	for _, path := range j.files() {
+		read, err := j.readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, read...)
+	}
+	return entries, nil
+}
+
+// readFile returns the entries of one journal file
+func (j *journal) readFile(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		line, err := j.codec.decodeLine(line)
+		if errors.Is(err, ErrDataLocked) {
+			return nil, err
+		}
+		if err != nil {
+			logger.Warn("Skipping unreadable journal entry", "file", filepath.Base(path), "error", err)
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			logger.Warn("Skipping unreadable journal entry", "file", filepath.Base(path), "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// recode rewrites every line of the kept journal files from one encoding to
+// another
+// NOTE: Caller MUST hold s.mu.Lock()
+func (j *journal) recode(from, to *atRest) error {
+	for _, path := range j.files() {
+		if err := recodeFile(path, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recodeFile rewrites every line of one journal file
+func recodeFile(path string, from, to *atRest) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		plain, err := from.decodeLine(line)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		encoded, err := to.encodeLine(plain)
+		if err != nil {
+			return err
+		}
+		out.Write(encoded)
+		out.WriteByte('\n')
+	}
+	return writeFileAtomic(path, out.Bytes(), 0600)
+}
+
+// diffProviders lists the providers created, updated or deleted between
+// before and after, with secrets removed
+func diffProviders(before, after []models.Provider) []ProviderChange {
+	old := make(map[string]models.Provider, len(before))
+	for _, p := range before {
+		old[p.ID] = p
+	}
+
+	var changes []ProviderChange
+	seen := make(map[string]bool, len(after))
+	for _, p := range after {
+		seen[p.ID] = true
+		prev, existed := old[p.ID]
+		switch {
+		case !existed:
+			changes = append(changes, ProviderChange{
+				ProviderID: p.ID,
+				Action:     ChangeCreated,
+				ModelIDs:   changedModels(nil, p.Models),
+				After:      journalCopy(p),
+			})
+		case !bytes.Equal(revisionContent(withoutSecrets(prev)), revisionContent(withoutSecrets(p))):
+			changes = append(changes, ProviderChange{
+				ProviderID: p.ID,
+				Action:     ChangeUpdated,
+				ModelIDs:   changedModels(prev.Models, p.Models),
+				Before:     journalCopy(prev),
+				After:      journalCopy(p),
+			})
+		}
+	}
+
+	for _, p := range before {
+		if !seen[p.ID] {
+			changes = append(changes, ProviderChange{
+				ProviderID: p.ID,
+				Action:     ChangeDeleted,
+				ModelIDs:   changedModels(p.Models, nil),
+				Before:     journalCopy(p),
+			})
+		}
+	}
+	return changes
+}
+
+// changedModels returns the IDs of models that differ between two lists
+func changedModels(before, after []models.Model) []string {
+	old := make(map[string][]byte, len(before))
+	for _, m := range before {
+		data, _ := json.Marshal(m)
+		old[m.ID] = data
+	}
+
+	var ids []string
+	seen := make(map[string]bool, len(after))
+	for _, m := range after {
+		seen[m.ID] = true
+		data, _ := json.Marshal(m)
+		if prev, ok := old[m.ID]; !ok || !bytes.Equal(prev, data) {
+			ids = append(ids, m.ID)
+		}
+	}
+	for _, m := range before {
+		if !seen[m.ID] {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// withoutSecrets returns p with key secrets removed
+func withoutSecrets(p models.Provider) models.Provider {
+	p.Credentials.APIKeys = scrubKeys(p.Credentials.APIKeys)
+	return p
+}
+
+// journalCopy returns a deep copy of p without secrets, so later changes to
+// the caller's slices do not alter the recorded state
+func journalCopy(p models.Provider) *models.Provider {
+	data, err := json.Marshal(withoutSecrets(p))
+	if err != nil {
+		return nil
+	}
+	var c models.Provider
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil
+	}
+	return &c
+}
+
+// snapshotProviders returns a deep copy of providers without secrets
+func snapshotProviders(providers []models.Provider) []models.Provider {
+	snapshot := make([]models.Provider, 0, len(providers))
+	for _, p := range providers {
+		if c := journalCopy(p); c != nil {
+			snapshot = append(snapshot, *c)
+		}
+	}
+	return snapshot
+}
+
+// History returns the journal, newest entry first. A positive limit caps
+// the number of entries returned.
+func (s *Storage) History(limit int) ([]JournalEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.journal.read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	reversed := make([]JournalEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		reversed = append(reversed, entries[i])
+		if limit > 0 && len(reversed) == limit {
+			break
+		}
+	}
+	return reversed, nil
+}
+
+// stateAt rewinds current to how it was right after entry seq by undoing
+// every later entry. seq 0 rewinds to before the first entry.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) stateAt(current []models.Provider, seq int64) ([]models.Provider, error) {
+	entries, err := s.journal.read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	if seq < 0 || (len(entries) > 0 && seq > entries[len(entries)-1].Seq) || (len(entries) == 0 && seq != 0) {
+		return nil, fmt.Errorf("journal entry %d not found", seq)
+	}
+	if len(entries) > 0 && seq < entries[0].Seq-1 {
+		return nil, fmt.Errorf("journal entry %d is older than the kept history", seq)
+	}
+
+	state := make([]models.Provider, len(current))
+	copy(state, current)
+
+	for i := len(entries) - 1; i >= 0 && entries[i].Seq > seq; i-- {
+		changes := entries[i].Changes
+		for c := len(changes) - 1; c >= 0; c-- {
+			state = undoChange(state, changes[c])
+		}
+	}
+	return state, nil
+}
+
+// undoChange puts a provider back to its state before change
+func undoChange(state []models.Provider, change ProviderChange) []models.Provider {
+	idx := -1
+	for i, p := range state {
+		if p.ID == change.ProviderID {
+			idx = i
+			break
+		}
+	}
+
+	switch {
+	case change.Before == nil && idx >= 0:
+		return append(state[:idx], state[idx+1:]...)
+	case change.Before != nil && idx >= 0:
+		state[idx] = *change.Before
+	case change.Before != nil:
+		state = append(state, *change.Before)
+	}
+	return state
+}
+
+// RestoreCatalog returns every provider to its state right after journal
+// entry seq. API key secrets are not journaled; restored key records keep
+// secrets that are still stored.
+func (s *Storage) RestoreCatalog(seq int64) error {
+	return s.UpdateOp(fmt.Sprintf("restoreCatalog:%d", seq), func(providers *[]models.Provider) error {
+		state, err := s.stateAt(*providers, seq)
+		if err != nil {
+			return err
+		}
+		*providers = state
+		return nil
+	})
+}
+
+// RestoreProvider returns one provider to its state right after journal
+// entry seq, removing it if it did not exist then
+func (s *Storage) RestoreProvider(providerID string, seq int64) error {
+	return s.UpdateOp(fmt.Sprintf("restoreProvider:%d", seq), func(providers *[]models.Provider) error {
+		state, err := s.stateAt(*providers, seq)
+		if err != nil {
+			return err
+		}
+
+		var target *models.Provider
+		for i := range state {
+			if state[i].ID == providerID {
+				target = &state[i]
+				break
+			}
+		}
+
+		for i, p := range *providers {
+			if p.ID == providerID {
+				if target == nil {
+					*providers = append((*providers)[:i], (*providers)[i+1:]...)
+				} else {
+					(*providers)[i] = *target
+				}
+				return nil
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("provider %s did not exist at entry %d", providerID, seq)
+		}
+		*providers = append(*providers, *target)
+		return nil
+	})
+}
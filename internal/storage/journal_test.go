@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+func TestJournal_RecordsMutationsWithoutSecrets(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	err := s.UpdateOp("createProvider", func(providers *[]models.Provider) error {
+		*providers = append(*providers, models.Provider{
+			ID:          "p1",
+			Name:        "One",
+			Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-journal-secret-1")},
+			Models:      []models.Model{{ID: "m1"}},
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateOp failed: %v", err)
+	}
+
+	err = s.UpdateOp("addModel", func(providers *[]models.Provider) error {
+		(*providers)[0].Models = append((*providers)[0].Models, models.Model{ID: "m2"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateOp failed: %v", err)
+	}
+
+	// A no-op update is not journaled
+	if err := s.Update(func(*[]models.Provider) error { return nil }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	history, err := s.History(0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(history))
+	}
+	latest := history[0]
+	if latest.Seq != 2 || latest.Operation != "addModel" {
+		t.Errorf("Expected newest entry first, got seq %d %s", latest.Seq, latest.Operation)
+	}
+	change := latest.Changes[0]
+	if change.Action != ChangeUpdated || len(change.ModelIDs) != 1 || change.ModelIDs[0] != "m2" {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(s.GetDataDir(), JournalFilename))
+	if strings.Contains(string(raw), "sk-journal-secret-1") {
+		t.Error("Journal must not contain API key secrets")
+	}
+}
+
+func TestJournal_RestoreCatalog(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// A bad bulk save wipes one provider and renames the other
+	if err := s.Save([]models.Provider{{ID: "a", Name: "Broken"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := s.RestoreCatalog(1); err != nil {
+		t.Fatalf("RestoreCatalog failed: %v", err)
+	}
+
+	providers, _ := s.Load()
+	if len(providers) != 2 {
+		t.Fatalf("Expected 2 providers after restore, got %d", len(providers))
+	}
+	names := map[string]string{}
+	for _, p := range providers {
+		names[p.ID] = p.Name
+	}
+	if names["a"] != "A" || names["b"] != "B" {
+		t.Errorf("Expected original names, got %v", names)
+	}
+
+	// The restore itself is journaled and can be undone
+	history, _ := s.History(1)
+	if !strings.HasPrefix(history[0].Operation, "restoreCatalog") {
+		t.Errorf("Expected restore to be journaled, got %s", history[0].Operation)
+	}
+
+	if err := s.RestoreCatalog(99); err == nil {
+		t.Error("Expected error for unknown journal entry")
+	}
+}
+
+func TestJournal_RestoreProvider(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A1"}, {ID: "b", Name: "B1"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A2"}, {ID: "b", Name: "B2"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := s.RestoreProvider("a", 1); err != nil {
+		t.Fatalf("RestoreProvider failed: %v", err)
+	}
+
+	providers, _ := s.Load()
+	names := map[string]string{}
+	for _, p := range providers {
+		names[p.ID] = p.Name
+	}
+	if names["a"] != "A1" || names["b"] != "B2" {
+		t.Errorf("Expected only provider a to be restored, got %v", names)
+	}
+
+	// Before entry 1 provider a did not exist, so restoring to 0 removes it
+	if err := s.RestoreProvider("a", 0); err != nil {
+		t.Fatalf("RestoreProvider failed: %v", err)
+	}
+	providers, _ = s.Load()
+	if len(providers) != 1 || providers[0].ID != "b" {
+		t.Errorf("Expected provider a to be removed, got %+v", providers)
+	}
+}
+
+func TestJournal_ClearCanBeUndone(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	if err := s.RestoreCatalog(1); err != nil {
+		t.Fatalf("RestoreCatalog failed: %v", err)
+	}
+	providers, _ := s.Load()
+	if len(providers) != 1 || providers[0].Name != "A" {
+		t.Errorf("Expected cleared provider to be restored, got %+v", providers)
+	}
+}
+
+func TestJournal_RotatesWhenFull(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+	s.journal.maxSize = 1
+
+	for i := 1; i <= 4; i++ {
+		providers := make([]models.Provider, i)
+		for j := range providers {
+			providers[j] = models.Provider{ID: string(rune('a' + j)), Name: "P"}
+		}
+		if err := s.Save(providers); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+
+	path := filepath.Join(s.GetDataDir(), JournalFilename)
+	if gens := listGenerations(path); len(gens) != journalGenerations {
+		t.Errorf("Expected %d rotated journal files, got %v", journalGenerations, gens)
+	}
+
+	history, err := s.History(0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != journalGenerations || history[0].Seq != 4 || history[1].Seq != 3 {
+		t.Fatalf("Expected the newest entries 4 and 3, got %+v", history)
+	}
+
+	if err := s.RestoreCatalog(1); err == nil {
+		t.Error("Expected restoring past the kept history to fail")
+	}
+	if err := s.RestoreCatalog(2); err != nil {
+		t.Fatalf("RestoreCatalog to the oldest kept state failed: %v", err)
+	}
+	loaded, _ := s.Load()
+	if len(loaded) != 2 {
+		t.Errorf("Expected 2 providers after restore, got %d", len(loaded))
+	}
+}
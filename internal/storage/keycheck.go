@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"llm-desk/internal/logger"
+	"llm-desk/internal/models"
+)
+
+// KeyInspection is a point-in-time comparison of providers.json with the
+// active key backend
+type KeyInspection struct {
+	Providers  map[string]string `json:"providers"`           // Provider ID -> name
+	Plaintext  []string          `json:"plaintext"`           // Providers with keys still in providers.json
+	LoadErrors map[string]string `json:"loadErrors"`          // Providers whose keys could not be read
+	StoredIDs  []string          `json:"storedIds"`           // Providers the backend holds keys for
+	Listable   bool              `json:"listable"`            // Whether StoredIDs could be determined
+	ListError  string            `json:"listError,omitempty"` // Why the backend could not be enumerated
+}
+
+// Orphaned returns the IDs the backend holds keys for that have no provider
+func (k KeyInspection) Orphaned() []string {
+	orphaned := []string{}
+	for _, id := range k.StoredIDs {
+		if _, ok := k.Providers[id]; !ok {
+			orphaned = append(orphaned, id)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// KeyErrors returns the key read failures recorded by the last Load,
+// keyed by provider ID
+func (s *Storage) KeyErrors() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	errs := make(map[string]string, len(s.keyErrors))
+	for id, msg := range s.keyErrors {
+		errs[id] = msg
+	}
+	return errs
+}
+
+// InspectKeys reads providers.json without injecting keys and checks every
+// provider against the active key backend
+func (s *Storage) InspectKeys() (KeyInspection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := KeyInspection{
+		Providers:  map[string]string{},
+		Plaintext:  []string{},
+		LoadErrors: map[string]string{},
+		StoredIDs:  []string{},
+	}
+
+	providers, err := s.readRawProviders()
+	if err != nil {
+		return report, err
+	}
+
+	for _, p := range providers {
+		report.Providers[p.ID] = p.Name
+		if hasPlaintextKeys(p) {
+			report.Plaintext = append(report.Plaintext, p.ID)
+		}
+		if _, err := s.keyring.GetKeys(p.ID); err != nil {
+			report.LoadErrors[p.ID] = err.Error()
+		}
+	}
+
+	if lister, ok := s.keyring.(KeyLister); ok {
+		ids, err := lister.ListProviderIDs()
+		if err != nil {
+			report.ListError = err.Error()
+		} else {
+			report.StoredIDs = ids
+			report.Listable = true
+		}
+	} else {
+		report.ListError = "key backend cannot list its entries"
+	}
+
+	return report, nil
+}
+
+// DeleteProviderKeys removes the keys of a provider from the active backend
+func (s *Storage) DeleteProviderKeys(providerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleteKeys(providerID)
+}
+
+// deleteKeys removes the keys of a provider and forgets its load error.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) deleteKeys(providerID string) error {
+	if err := s.keyring.DeleteKeys(providerID); err != nil {
+		return err
+	}
+	delete(s.keyErrors, providerID)
+	return nil
+}
+
+// SecurePlaintextKeys moves keys still held in providers.json for a provider
+// into the active backend and scrubs them from the file
+func (s *Storage) SecurePlaintextKeys(providerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	providers, err := s.readRawProviders()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range providers {
+		if p.ID != providerID {
+			continue
+		}
+		if !hasPlaintextKeys(p) {
+			return nil
+		}
+
+		// saveToFile stores the plaintext secrets and scrubs them from the
+		// file; providers whose keys are unchanged are not touched
+		if err := s.saveToFile(providers); err != nil {
+			return err
+		}
+		logger.Info("Moved plaintext keys to key backend", "providerID", p.ID)
+		return nil
+	}
+
+	return fmt.Errorf("provider not found: %s", providerID)
+}
+
+// readRawProviders parses providers.json as stored, without touching keys.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) readRawProviders() ([]models.Provider, error) {
+	data, err := s.readData(s.filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []models.Provider{}, nil
+		}
+		return nil, err
+	}
+	providers, _, err := parseProviders(data)
+	return providers, err
+}
@@ -2,8 +2,9 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
+	"sync"
 
 	"llm-desk/internal/logger"
 
@@ -11,10 +12,24 @@ import (
 )
 
 const (
-	keyringService    = "llm-desk"
-	keyringUserPrefix = "provider_"
+	keyringService      = "llm-desk"
+	keyringUserPrefix   = "provider_"
+	keyringProbeUser    = "probe"
+	keyringIndexUser    = "index"
+	keyringSecretPrefix = "secret_"
 )
 
+// Key storage backends selectable in settings
+const (
+	KeyBackendAuto    = "auto"    // OS keyring when available, otherwise the vault
+	KeyBackendKeyring = "keyring" // OS-native keyring
+	KeyBackendVault   = "vault"   // Passphrase-protected local file
+)
+
+// ErrSecretNotFound is returned by SecretStore.GetSecret when nothing is
+// stored under the name
+var ErrSecretNotFound = errors.New("secret not found")
+
 // KeyringManager defines the interface for keyring operations
 type KeyringManager interface {
 	SetKeys(providerID string, keys []string) error
@@ -22,8 +37,24 @@ type KeyringManager interface {
 	DeleteKeys(providerID string) error
 }
 
+// KeyLister is implemented by backends that can enumerate the providers
+// they hold keys for. Reconciliation uses it to find orphaned entries.
+type KeyLister interface {
+	ListProviderIDs() ([]string, error)
+}
+
+// SecretStore is implemented by backends that can hold app secrets outside
+// the per-provider key namespace, such as the data encryption key
+type SecretStore interface {
+	SetSecret(name, value string) error
+	GetSecret(name string) (string, error)
+	DeleteSecret(name string) error
+}
+
 // KeyringStore handles secure storage of API keys using OS-native keyring
-type KeyringStore struct{}
+type KeyringStore struct {
+	mu sync.Mutex // Serializes updates of the index entry
+}
 
 // NewKeyringStore creates a new KeyringStore instance
 func NewKeyringStore() KeyringManager {
@@ -48,6 +79,10 @@ func (k *KeyringStore) SetKeys(providerID string, keys []string) error {
 		return fmt.Errorf("failed to set keys in keyring for %s: %w", providerID, err)
 	}
 
+	if err := k.updateIndex(providerID, true); err != nil {
+		logger.Warn("Failed to update keyring index", "providerID", providerID, "error", err)
+	}
+
 	logger.Debug("Stored keys in keyring", "providerID", providerID)
 	return nil
 }
@@ -61,7 +96,7 @@ func (k *KeyringStore) GetKeys(providerID string) ([]string, error) {
 	user := keyringUserPrefix + providerID
 	data, err := keyring.Get(keyringService, user)
 	if err != nil {
-		if strings.Contains(err.Error(), "secret not found") || strings.Contains(err.Error(), "item not found") {
+		if isNotFound(err) {
 			return []string{}, nil
 		}
 		return nil, fmt.Errorf("failed to get keys from keyring for %s: %w", providerID, err)
@@ -83,13 +118,130 @@ func (k *KeyringStore) DeleteKeys(providerID string) error {
 
 	user := keyringUserPrefix + providerID
 	err := keyring.Delete(keyringService, user)
-	if err != nil {
-		if strings.Contains(err.Error(), "secret not found") || strings.Contains(err.Error(), "item not found") {
-			return nil
-		}
+	if err != nil && !isNotFound(err) {
 		return fmt.Errorf("failed to delete keys from keyring for %s: %w", providerID, err)
 	}
 
+	if err := k.updateIndex(providerID, false); err != nil {
+		logger.Warn("Failed to update keyring index", "providerID", providerID, "error", err)
+	}
+
 	logger.Debug("Deleted keys from keyring", "providerID", providerID)
 	return nil
 }
+
+// SetSecret stores an app secret under name
+func (k *KeyringStore) SetSecret(name, value string) error {
+	if err := keyring.Set(keyringService, keyringSecretPrefix+name, value); err != nil {
+		return fmt.Errorf("failed to store %s in keyring: %w", name, err)
+	}
+	return nil
+}
+
+// GetSecret returns the app secret stored under name
+func (k *KeyringStore) GetSecret(name string) (string, error) {
+	value, err := keyring.Get(keyringService, keyringSecretPrefix+name)
+	if err != nil {
+		if isNotFound(err) {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		}
+		return "", fmt.Errorf("failed to read %s from keyring: %w", name, err)
+	}
+	return value, nil
+}
+
+// DeleteSecret removes the app secret stored under name
+func (k *KeyringStore) DeleteSecret(name string) error {
+	err := keyring.Delete(keyringService, keyringSecretPrefix+name)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete %s from keyring: %w", name, err)
+	}
+	return nil
+}
+
+// ListProviderIDs returns the providers recorded in the keyring index.
+// The OS keyring APIs cannot enumerate entries, so every SetKeys and
+// DeleteKeys maintains an index entry; keys written by versions before the
+// index existed are not listed.
+func (k *KeyringStore) ListProviderIDs() ([]string, error) {
+	return k.readIndex()
+}
+
+// readIndex reads the provider ID index entry
+func (k *KeyringStore) readIndex() ([]string, error) {
+	data, err := keyring.Get(keyringService, keyringIndexUser)
+	if err != nil {
+		if isNotFound(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read keyring index: %w", err)
+	}
+
+	var ids []string
+	if err := json.Unmarshal([]byte(data), &ids); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal keyring index: %w", err)
+	}
+	return ids, nil
+}
+
+// updateIndex adds or removes providerID from the index entry. An index
+// that cannot be read is left alone rather than replaced, so a transient
+// keyring error does not drop the other providers from it.
+func (k *KeyringStore) updateIndex(providerID string, present bool) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	ids, err := k.readIndex()
+	if err != nil {
+		return err
+	}
+
+	updated := make([]string, 0, len(ids)+1)
+	found := false
+	for _, id := range ids {
+		if id == providerID {
+			found = true
+			if !present {
+				continue
+			}
+		}
+		updated = append(updated, id)
+	}
+	if present && !found {
+		updated = append(updated, providerID)
+	}
+	if found == present {
+		return nil
+	}
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(keyringService, keyringIndexUser, string(data))
+}
+
+// isNotFound reports whether a keyring error means the entry does not exist
+func isNotFound(err error) bool {
+	return errors.Is(err, keyring.ErrNotFound) || errors.Is(err, ErrSecretNotFound)
+}
+
+// ProbeKeyring checks that the OS keyring is reachable by writing, reading
+// back and deleting a throwaway entry
+func ProbeKeyring() error {
+	const value = "ok"
+	if err := keyring.Set(keyringService, keyringProbeUser, value); err != nil {
+		return fmt.Errorf("keyring unavailable: %w", err)
+	}
+	got, err := keyring.Get(keyringService, keyringProbeUser)
+	if err != nil {
+		return fmt.Errorf("keyring unavailable: %w", err)
+	}
+	if got != value {
+		return fmt.Errorf("keyring returned unexpected probe value")
+	}
+	if err := keyring.Delete(keyringService, keyringProbeUser); err != nil {
+		return fmt.Errorf("keyring unavailable: %w", err)
+	}
+	return nil
+}
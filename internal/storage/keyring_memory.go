@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryKeyring is an in-process KeyringManager. It keeps keys only for the
+// lifetime of the process and is meant for tests and throwaway environments.
+type MemoryKeyring struct {
+	mu      sync.Mutex
+	keys    map[string][]string
+	secrets map[string]string
+}
+
+// NewMemoryKeyring creates an empty MemoryKeyring
+func NewMemoryKeyring() *MemoryKeyring {
+	return &MemoryKeyring{keys: make(map[string][]string), secrets: make(map[string]string)}
+}
+
+// SetKeys stores a copy of keys for a provider
+func (m *MemoryKeyring) SetKeys(providerID string, keys []string) error {
+	if providerID == "" {
+		return fmt.Errorf("provider ID cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[providerID] = append([]string{}, keys...)
+	return nil
+}
+
+// GetKeys returns the keys stored for a provider, or an empty slice
+func (m *MemoryKeyring) GetKeys(providerID string) ([]string, error) {
+	if providerID == "" {
+		return nil, fmt.Errorf("provider ID cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.keys[providerID]...), nil
+}
+
+// DeleteKeys removes the keys stored for a provider
+func (m *MemoryKeyring) DeleteKeys(providerID string) error {
+	if providerID == "" {
+		return fmt.Errorf("provider ID cannot be empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, providerID)
+	return nil
+}
+
+// ListProviderIDs returns the providers that have keys stored
+func (m *MemoryKeyring) ListProviderIDs() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.keys))
+	for id := range m.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// SetSecret stores an app secret under name
+func (m *MemoryKeyring) SetSecret(name, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[name] = value
+	return nil
+}
+
+// GetSecret returns the app secret stored under name
+func (m *MemoryKeyring) GetSecret(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// DeleteSecret removes the app secret stored under name
+func (m *MemoryKeyring) DeleteSecret(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.secrets, name)
+	return nil
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringStore_KeepsUnreadableIndex(t *testing.T) {
+	keyring.MockInit()
+	k := &KeyringStore{}
+
+	if err := k.SetKeys("a", []string{"sk-a"}); err != nil {
+		t.Fatalf("SetKeys failed: %v", err)
+	}
+	if err := keyring.Set(keyringService, keyringIndexUser, "{not json"); err != nil {
+		t.Fatalf("Failed to damage the index: %v", err)
+	}
+	if err := k.SetKeys("b", []string{"sk-b"}); err != nil {
+		t.Fatalf("SetKeys failed: %v", err)
+	}
+	if data, _ := keyring.Get(keyringService, keyringIndexUser); data != "{not json" {
+		t.Errorf("Expected the unreadable index to be left alone, got %q", data)
+	}
+}
+
+func TestKeyringStore_SecretNotFound(t *testing.T) {
+	keyring.MockInit()
+	k := &KeyringStore{}
+
+	if _, err := k.GetSecret("missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound, got %v", err)
+	}
+	if err := k.DeleteSecret("missing"); err != nil {
+		t.Errorf("Expected deleting a missing secret to succeed, got %v", err)
+	}
+	if _, err := NewMemoryKeyring().GetSecret("missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound from the memory keyring, got %v", err)
+	}
+}
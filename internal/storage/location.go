@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DataDirEnv overrides the data directory when neither --data-dir nor
+	// --portable is given
+	DataDirEnv = "LLMDESK_HOME"
+
+	// PortableMarker is the file next to the executable that enables portable mode
+	PortableMarker = "llmdesk.portable"
+
+	// portableDirName is the data directory created next to the executable in portable mode
+	portableDirName = "LLMDeskData"
+
+	// appDirName is the directory created under the user config dir
+	appDirName = "LLMDesk"
+)
+
+// Location sources
+const (
+	LocationFlag     = "flag"
+	LocationEnv      = "env"
+	LocationPortable = "portable"
+	LocationDefault  = "default"
+)
+
+// LocationOptions holds the launch options that influence the data directory
+type LocationOptions struct {
+	DataDir  string // Explicit directory from --data-dir
+	Portable bool   // --portable flag
+}
+
+// Location describes where application data is stored and why
+type Location struct {
+	DataDir  string `json:"dataDir"`
+	Source   string `json:"source"`
+	Portable bool   `json:"portable"`
+}
+
+// ResolveLocation picks the data directory: --data-dir, then --portable,
+// then LLMDESK_HOME, then the portable marker file next to the executable,
+// and finally the OS user config directory. Flags always beat the
+// environment.
+func ResolveLocation(opts LocationOptions) (Location, error) {
+	if opts.DataDir != "" {
+		dir, err := filepath.Abs(opts.DataDir)
+		if err != nil {
+			return Location{}, fmt.Errorf("invalid data directory %q: %w", opts.DataDir, err)
+		}
+		return Location{DataDir: dir, Source: LocationFlag}, nil
+	}
+
+	if opts.Portable {
+		return portableLocation()
+	}
+
+	if env := os.Getenv(DataDirEnv); env != "" {
+		dir, err := filepath.Abs(env)
+		if err != nil {
+			return Location{}, fmt.Errorf("invalid %s %q: %w", DataDirEnv, env, err)
+		}
+		return Location{DataDir: dir, Source: LocationEnv}, nil
+	}
+
+	if hasPortableMarker() {
+		return portableLocation()
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return Location{}, err
+	}
+	return Location{DataDir: filepath.Join(configDir, appDirName), Source: LocationDefault}, nil
+}
+
+// portableLocation returns the data directory next to the executable
+func portableLocation() (Location, error) {
+	exeDir, err := executableDir()
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to locate executable for portable mode: %w", err)
+	}
+	return Location{
+		DataDir:  filepath.Join(exeDir, portableDirName),
+		Source:   LocationPortable,
+		Portable: true,
+	}, nil
+}
+
+// executableDir returns the directory containing the running binary
+func executableDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Dir(exe), nil
+}
+
+// hasPortableMarker reports whether the portable marker file sits next to the executable
+func hasPortableMarker() bool {
+	exeDir, err := executableDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(exeDir, PortableMarker))
+	return err == nil
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LockFilename is the advisory instance lock inside the data directory
+const LockFilename = "llmdesk.lock"
+
+// LockStaleAfter is how long a lock survives without a heartbeat before
+// another instance may take it over (e.g. after a crash)
+const LockStaleAfter = 30 * time.Second
+
+// LockInfo identifies the instance holding the data directory
+type LockInfo struct {
+	PID       int    `json:"pid"`
+	Hostname  string `json:"hostname"`
+	StartedAt string `json:"startedAt"`
+	Heartbeat string `json:"heartbeat"`
+}
+
+// InstanceLock is an advisory lock that lets instances sharing a data
+// directory notice each other. It never blocks: a second instance is told
+// who holds the lock and keeps running.
+type InstanceLock struct {
+	mu    sync.Mutex
+	path  string
+	self  LockInfo
+	owned bool
+	now   func() time.Time
+}
+
+// NewInstanceLock creates an unacquired lock for dataDir
+func NewInstanceLock(dataDir string) *InstanceLock {
+	hostname, _ := os.Hostname()
+	return &InstanceLock{
+		path: filepath.Join(dataDir, LockFilename),
+		self: LockInfo{
+			PID:       os.Getpid(),
+			Hostname:  hostname,
+			StartedAt: time.Now().Format(time.RFC3339Nano),
+		},
+		now: time.Now,
+	}
+}
+
+// Acquire takes the lock, or refreshes its heartbeat if already held. If
+// another live instance holds it, the lock is left alone and that
+// instance's info is returned. Call it periodically to keep the heartbeat
+// fresh and to take over once the other instance exits.
+func (l *InstanceLock) Acquire() (*LockInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	holder, err := l.read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		// An unreadable lock is treated as stale and overwritten
+		holder = nil
+	}
+	if holder != nil && !l.isSelf(*holder) && !l.isStale(*holder) {
+		l.owned = false
+		return holder, nil
+	}
+
+	l.self.Heartbeat = l.now().Format(time.RFC3339)
+	data, err := json.MarshalIndent(l.self, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(l.path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	l.owned = true
+	return nil, nil
+}
+
+// Owned reports whether this instance held the lock at the last Acquire
+func (l *InstanceLock) Owned() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.owned
+}
+
+// Release removes the lock file if this instance holds it
+func (l *InstanceLock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.owned {
+		return nil
+	}
+	l.owned = false
+
+	holder, err := l.read()
+	if err != nil || !l.isSelf(*holder) {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// read parses the lock file
+// NOTE: Caller MUST hold l.mu
+func (l *InstanceLock) read() (*LockInfo, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// isSelf reports whether info describes this instance
+func (l *InstanceLock) isSelf(info LockInfo) bool {
+	return info.PID == l.self.PID && info.Hostname == l.self.Hostname && info.StartedAt == l.self.StartedAt
+}
+
+// isStale reports whether the holder's heartbeat has expired
+func (l *InstanceLock) isStale(info LockInfo) bool {
+	beat, err := time.Parse(time.RFC3339, info.Heartbeat)
+	if err != nil {
+		return true
+	}
+	return l.now().Sub(beat) > LockStaleAfter
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInstanceLock_SecondInstance(t *testing.T) {
+	dir := t.TempDir()
+
+	first := NewInstanceLock(dir)
+	if holder, err := first.Acquire(); err != nil || holder != nil {
+		t.Fatalf("Expected first instance to take the lock, got holder=%v err=%v", holder, err)
+	}
+
+	second := NewInstanceLock(dir)
+	second.self.StartedAt = "other"
+	holder, err := second.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if holder == nil || holder.StartedAt != first.self.StartedAt {
+		t.Fatalf("Expected second instance to see the first, got %+v", holder)
+	}
+	if second.Owned() {
+		t.Error("Expected second instance not to own the lock")
+	}
+
+	// Releasing the second instance must not remove the first one's lock
+	if err := second.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockFilename)); err != nil {
+		t.Errorf("Expected lock file to remain, got %v", err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockFilename)); !os.IsNotExist(err) {
+		t.Errorf("Expected lock file to be removed, got %v", err)
+	}
+}
+
+func TestInstanceLock_StaleTakeover(t *testing.T) {
+	dir := t.TempDir()
+
+	crashed := NewInstanceLock(dir)
+	if _, err := crashed.Acquire(); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	next := NewInstanceLock(dir)
+	next.self.StartedAt = "later"
+	next.now = func() time.Time { return time.Now().Add(2 * LockStaleAfter) }
+
+	holder, err := next.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if holder != nil || !next.Owned() {
+		t.Errorf("Expected stale lock to be taken over, got holder %+v", holder)
+	}
+}
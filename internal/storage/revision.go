@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// ErrConflict is matched by every ConflictError
+var ErrConflict = errors.New("provider was changed by someone else")
+
+// ConflictError is returned when a caller's expected revision of a provider
+// is older than the stored one, or when a bulk save leaves out a provider
+// the caller did not know about
+type ConflictError struct {
+	ProviderID string
+	Expected   int64
+	Actual     int64
+	Missing    bool
+}
+
+func (e *ConflictError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("provider %s (revision %d) is stored but missing from the saved list; reload and try again",
+			e.ProviderID, e.Actual)
+	}
+	return fmt.Sprintf("provider %s was changed elsewhere (expected revision %d, found %d); reload and try again",
+		e.ProviderID, e.Expected, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrConflict) match
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// CheckRevision returns a ConflictError if expected does not match the
+// revision of current. An expected revision of 0 skips the check.
+func CheckRevision(current models.Provider, expected int64) error {
+	if expected == 0 || expected == current.Revision {
+		return nil
+	}
+	return &ConflictError{ProviderID: current.ID, Expected: expected, Actual: current.Revision}
+}
+
+// revisionState is a provider's revision and content before an update
+type revisionState struct {
+	revision  int64
+	updatedAt string
+	content   []byte
+}
+
+// revisionIndex records the revision and content of each provider by ID
+func revisionIndex(providers []models.Provider) map[string]revisionState {
+	index := make(map[string]revisionState, len(providers))
+	for _, p := range providers {
+		index[p.ID] = revisionState{revision: p.Revision, updatedAt: p.UpdatedAt, content: revisionContent(p)}
+	}
+	return index
+}
+
+// bumpRevisions assigns revisions after an update: unchanged providers keep
+// the stored revision, changed ones get the next, and new ones start at 1.
+// UpdatedAt follows the same rule. Values supplied by the caller are never
+// trusted.
+func bumpRevisions(before map[string]revisionState, providers []models.Provider) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for i := range providers {
+		p := &providers[i]
+		prev, existed := before[p.ID]
+		switch {
+		case !existed:
+			p.Revision = 1
+			p.UpdatedAt = now
+		case bytes.Equal(prev.content, revisionContent(*p)):
+			p.Revision = prev.revision
+			p.UpdatedAt = prev.updatedAt
+		default:
+			p.Revision = prev.revision + 1
+			p.UpdatedAt = now
+		}
+	}
+}
+
+// revisionContent encodes p without its revision and timestamp for change
+// detection
+func revisionContent(p models.Provider) []byte {
+	p.Revision = 0
+	p.UpdatedAt = ""
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+	return data
+}
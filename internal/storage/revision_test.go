@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+func TestStorage_UpdateBumpsRevisions(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	err := s.Update(func(providers *[]models.Provider) error {
+		(*providers)[0].Name = "A2"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	providers, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if providers[0].Revision != 2 {
+		t.Errorf("Expected changed provider at revision 2, got %d", providers[0].Revision)
+	}
+	if providers[1].Revision != 1 {
+		t.Errorf("Expected unchanged provider to stay at revision 1, got %d", providers[1].Revision)
+	}
+}
+
+func TestStorage_UpdateIgnoresCallerRevisions(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A", Revision: 42}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	providers, _ := s.Load()
+	if providers[0].Revision != 1 {
+		t.Errorf("Expected new provider to start at revision 1, got %d", providers[0].Revision)
+	}
+}
+
+func TestStorage_UpdateErrorWritesNothing(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	sentinel := errors.New("abort")
+	err := s.Update(func(providers *[]models.Provider) error {
+		(*providers)[0].Name = "changed"
+		return sentinel
+	})
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("Expected fn error to be returned, got %v", err)
+	}
+
+	providers, _ := s.Load()
+	if providers[0].Name != "A" || providers[0].Revision != 1 {
+		t.Errorf("Expected provider to be untouched, got %+v", providers[0])
+	}
+}
+
+func TestStorage_UpdateConcurrent(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A", Models: []models.Model{}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.Update(func(providers *[]models.Provider) error {
+				p := &(*providers)[0]
+				p.Models = append(p.Models, models.Model{ID: fmt.Sprintf("m%d", i)})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Update failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	providers, _ := s.Load()
+	if len(providers[0].Models) != writers {
+		t.Errorf("Expected %d models, got %d (lost updates)", writers, len(providers[0].Models))
+	}
+	if providers[0].Revision != writers+1 {
+		t.Errorf("Expected revision %d, got %d", writers+1, providers[0].Revision)
+	}
+}
+
+func TestCheckRevision(t *testing.T) {
+	p := models.Provider{ID: "a", Revision: 3}
+
+	if err := CheckRevision(p, 0); err != nil {
+		t.Errorf("Expected revision 0 to skip the check, got %v", err)
+	}
+	if err := CheckRevision(p, 3); err != nil {
+		t.Errorf("Expected matching revision to pass, got %v", err)
+	}
+
+	err := CheckRevision(p, 2)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected ConflictError, got %v", err)
+	}
+	if conflict.Expected != 2 || conflict.Actual != 3 {
+		t.Errorf("Expected 2/3 in conflict, got %d/%d", conflict.Expected, conflict.Actual)
+	}
+}
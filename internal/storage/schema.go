@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+// CurrentSchemaVersion is the providers.json schema written by this build.
+// Bump it together with a new entry in migrations whenever the on-disk
+// shape of models.Provider changes.
+const CurrentSchemaVersion = 3
+
+// Migration upgrades the providers payload from schema version From to From+1.
+// Apply receives the raw JSON array of providers and returns the upgraded array.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(providers json.RawMessage) (json.RawMessage, error)
+}
+
+// migrations is the ordered registry of schema upgrades.
+// Entry i must upgrade version i+1 to i+2.
+var migrations = []Migration{
+	{
+		From:        1,
+		Description: "wrap bare provider list in a versioned envelope",
+		Apply: func(providers json.RawMessage) (json.RawMessage, error) {
+			// The envelope itself is the change; the payload is unchanged
+			return providers, nil
+		},
+	},
+	{
+		From:        2,
+		Description: "turn API key strings into key records",
+		Apply:       migrateKeyRecords,
+	},
+}
+
+// migrateKeyRecords replaces every string in credentials.apiKeys with a key
+// record. Normally the array is empty on disk because secrets live in the
+// keyring; those keys get their records when first loaded.
+func migrateKeyRecords(payload json.RawMessage) (json.RawMessage, error) {
+	var providers []map[string]any
+	if err := json.Unmarshal(payload, &providers); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	for _, p := range providers {
+		creds, _ := p["credentials"].(map[string]any)
+		if creds == nil {
+			continue
+		}
+		keys, _ := creds["apiKeys"].([]any)
+		records := make([]any, 0, len(keys))
+		for i, k := range keys {
+			secret, ok := k.(string)
+			if !ok {
+				records = append(records, k)
+				continue
+			}
+			records = append(records, map[string]any{
+				"id":        models.NewAPIKeyID(),
+				"label":     fmt.Sprintf("Key %d", i+1),
+				"key":       secret,
+				"createdAt": now,
+				"enabled":   true,
+				"status":    models.KeyStatusUnknown,
+			})
+		}
+		creds["apiKeys"] = records
+	}
+
+	return json.Marshal(providers)
+}
+
+// providersFile is the on-disk envelope of providers.json
+type providersFile struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Providers     json.RawMessage `json:"providers"`
+}
+
+// SchemaTooNewError is returned when the data file was written by a newer
+// version of the app. The file is left untouched and writes are refused.
+type SchemaTooNewError struct {
+	Found     int
+	Supported int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf("data file uses schema version %d but this version of LLM Desk only supports up to %d; please upgrade LLM Desk", e.Found, e.Supported)
+}
+
+// decodeProvidersFile splits a providers.json document into its schema
+// version and raw provider array. Legacy files are a bare array (version 1).
+func decodeProvidersFile(data []byte) (int, json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return 1, json.RawMessage(trimmed), nil
+	}
+
+	var file providersFile
+	if err := json.Unmarshal(trimmed, &file); err != nil {
+		return 0, nil, err
+	}
+	if file.SchemaVersion < 1 {
+		return 0, nil, fmt.Errorf("missing or invalid schemaVersion")
+	}
+	if file.SchemaVersion > CurrentSchemaVersion {
+		return file.SchemaVersion, nil, &SchemaTooNewError{Found: file.SchemaVersion, Supported: CurrentSchemaVersion}
+	}
+	if len(file.Providers) == 0 || string(file.Providers) == "null" {
+		file.Providers = json.RawMessage("[]")
+	}
+	return file.SchemaVersion, file.Providers, nil
+}
+
+// migrateProviders runs every registered migration from version up to
+// CurrentSchemaVersion and returns the upgraded payload
+func migrateProviders(version int, payload json.RawMessage) (json.RawMessage, error) {
+	for v := version; v < CurrentSchemaVersion; v++ {
+		m := migrations[v-1]
+		if m.From != v {
+			return nil, fmt.Errorf("migration registry out of order: expected migration from %d, found %d", v, m.From)
+		}
+		upgraded, err := m.Apply(payload)
+		if err != nil {
+			return nil, fmt.Errorf("schema migration %d->%d (%s) failed: %w", v, v+1, m.Description, err)
+		}
+		payload = upgraded
+	}
+	return payload, nil
+}
+
+// parseProviders decodes, migrates and unmarshals a providers.json document.
+// It returns the providers and the schema version the document was written in.
+func parseProviders(data []byte) ([]models.Provider, int, error) {
+	version, payload, err := decodeProvidersFile(data)
+	if err != nil {
+		return nil, version, err
+	}
+
+	payload, err = migrateProviders(version, payload)
+	if err != nil {
+		return nil, version, err
+	}
+
+	var providers []models.Provider
+	if err := json.Unmarshal(payload, &providers); err != nil {
+		return nil, version, err
+	}
+	return providers, version, nil
+}
+
+// encodeProvidersFile wraps providers in the current schema envelope
+func encodeProvidersFile(providers []models.Provider) ([]byte, error) {
+	payload, err := json.Marshal(providers)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(providersFile{
+		SchemaVersion: CurrentSchemaVersion,
+		Providers:     payload,
+	}, "", "  ")
+}
+
+// schemaBackupPath returns where the pre-migration copy of a file is kept
+func schemaBackupPath(path string, version int) string {
+	return path + ".v" + strconv.Itoa(version) + ".bak"
+}
+
+// backupBeforeMigration keeps the original document before a schema upgrade.
+// An existing backup for the same version is never overwritten.
+func backupBeforeMigration(path string, version int, data []byte) error {
+	backup := schemaBackupPath(path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	return writeFileAtomic(backup, data, 0600)
+}
+
+// checkWritable refuses to overwrite a providers.json written by a newer schema
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) checkWritable() error {
+	data, err := s.readData(s.filename)
+	if err != nil {
+		return nil
+	}
+	var tooNew *SchemaTooNewError
+	if _, _, err := decodeProvidersFile(data); errors.As(err, &tooNew) {
+		return tooNew
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+func TestSchema_MigrationRegistryIsComplete(t *testing.T) {
+	if len(migrations) != CurrentSchemaVersion-1 {
+		t.Fatalf("Expected %d migrations for schema %d, got %d", CurrentSchemaVersion-1, CurrentSchemaVersion, len(migrations))
+	}
+	for i, m := range migrations {
+		if m.From != i+1 {
+			t.Errorf("Migration %d upgrades from %d, expected %d", i, m.From, i+1)
+		}
+	}
+}
+
+func TestSchema_LegacyFileIsMigrated(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	legacy := []byte(`[{"id":"legacy","name":"Legacy","enabled":true,"credentials":{"apiKeys":[]},"endpoints":{"openai":"https://api.example.com"},"limits":[],"features":{},"models":[]}]`)
+	if err := os.WriteFile(storage.filename, legacy, 0644); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	providers, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(providers) != 1 || providers[0].ID != "legacy" {
+		t.Fatalf("Unexpected providers after migration: %v", providers)
+	}
+
+	// The original is backed up before the upgrade
+	backup, err := os.ReadFile(schemaBackupPath(storage.filename, 1))
+	if err != nil {
+		t.Fatalf("Expected pre-migration backup: %v", err)
+	}
+	if string(backup) != string(legacy) {
+		t.Error("Pre-migration backup does not match the original file")
+	}
+
+	// The file is rewritten in the current envelope
+	content, _ := os.ReadFile(storage.filename)
+	var envelope struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		Providers     []models.Provider `json:"providers"`
+	}
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		t.Fatalf("Expected envelope format: %v", err)
+	}
+	if envelope.SchemaVersion != CurrentSchemaVersion || len(envelope.Providers) != 1 {
+		t.Errorf("Unexpected envelope: %+v", envelope)
+	}
+}
+
+func TestSchema_NewerFileIsRefused(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// A valid backup generation must not be used in place of a newer file
+	if err := storage.Save([]models.Provider{{ID: "old", Name: "Old"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := storage.Save([]models.Provider{{ID: "old", Name: "Old"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	future := []byte(`{"schemaVersion": 999, "providers": []}`)
+	if err := os.WriteFile(storage.filename, future, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var tooNew *SchemaTooNewError
+	if _, err := storage.Load(); !errors.As(err, &tooNew) {
+		t.Fatalf("Expected SchemaTooNewError from Load, got %v", err)
+	}
+	if err := storage.Save([]models.Provider{}); !errors.As(err, &tooNew) {
+		t.Fatalf("Expected SchemaTooNewError from Save, got %v", err)
+	}
+
+	content, _ := os.ReadFile(storage.filename)
+	if string(content) != string(future) {
+		t.Error("Newer data file must be left untouched")
+	}
+}
+
+func TestSchema_MigrationFailureIsReported(t *testing.T) {
+	original := migrations
+	defer func() { migrations = original }()
+
+	migrations = []Migration{{
+		From:        1,
+		Description: "always fails",
+		Apply: func(json.RawMessage) (json.RawMessage, error) {
+			return nil, errors.New("boom")
+		},
+	}}
+
+	if _, _, err := parseProviders([]byte(`[]`)); err == nil {
+		t.Error("Expected migration failure to be returned")
+	}
+}
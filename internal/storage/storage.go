@@ -1,40 +1,74 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
+	"unicode/utf8"
 
+	"llm-desk/internal/logger"
 	"llm-desk/internal/models"
 )
 
 // Storage handles file-based persistence for provider data
 type Storage struct {
-	dataDir  string
-	filename string
-	mu       sync.RWMutex
-	keyring  KeyringManager
+	dataDir     string
+	filename    string
+	mu          sync.RWMutex
+	keyring     KeyringManager
+	generations int               // Number of rolling backups kept per data file
+	recovery    *RecoveryReport   // Set when the last Load fell back to a backup
+	keyErrors   map[string]string // Key read failures from the last Load, by provider ID
+	written     map[string]string // Hash of the content this instance last wrote, by path
+	journal     *journal
+	secrets     SecretStore // Holds the data key for encryption at rest; nil if unsupported
+	crypt       atRest
+	onChange    func(op string) // Called after each saved catalog change
 }
 
-// New creates a new Storage instance
+// New creates a new Storage instance in the resolved default location
+// (LLMDESK_HOME, portable mode or the OS user config directory) backed by
+// the OS keyring
 func New() (*Storage, error) {
-	dataDir, err := os.UserConfigDir()
+	loc, err := ResolveLocation(LocationOptions{})
 	if err != nil {
 		return nil, err
 	}
+	return NewWithDir(loc.DataDir, NewKeyringStore())
+}
+
+// NewWithDir creates a Storage rooted at dataDir using the given keyring.
+// The directory is created if it does not exist.
+func NewWithDir(dataDir string, keyring KeyringManager) (*Storage, error) {
+	if dataDir == "" {
+		return nil, fmt.Errorf("data directory cannot be empty")
+	}
+	if keyring == nil {
+		return nil, fmt.Errorf("keyring cannot be nil")
+	}
 
-	appDir := filepath.Join(dataDir, "LLMDesk")
-	if err := os.MkdirAll(appDir, 0755); err != nil {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, err
 	}
 
-	return &Storage{
-		dataDir:  appDir,
-		filename: filepath.Join(appDir, "providers.json"),
-		keyring:  NewKeyringStore(),
-	}, nil
+	s := &Storage{
+		dataDir:     dataDir,
+		filename:    filepath.Join(dataDir, "providers.json"),
+		keyring:     keyring,
+		generations: DefaultBackupGenerations,
+		written:     make(map[string]string),
+	}
+	s.journal = newJournal(filepath.Join(dataDir, JournalFilename), &s.crypt)
+	if secrets, ok := keyring.(SecretStore); ok {
+		s.secrets = secrets
+	}
+	s.loadEncryption()
+	return s, nil
 }
 
 // GetDataDir returns the data directory path
@@ -42,12 +76,157 @@ func (s *Storage) GetDataDir() string {
 	return s.dataDir
 }
 
+// Keyring returns the KeyringManager currently used for API keys
+func (s *Storage) Keyring() KeyringManager {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keyring
+}
+
+// activeSecrets returns where named secrets such as the backup passphrase
+// and the data key are kept: the active key backend, so vault users keep
+// them in the vault, or the keyring Storage was created with otherwise.
+// NOTE: Caller MUST hold s.mu
+func (s *Storage) activeSecrets() SecretStore {
+	if secrets, ok := s.keyring.(SecretStore); ok {
+		return secrets
+	}
+	return s.secrets
+}
+
+// movedSecrets are the named secrets that follow the API keys to a new backend
+var movedSecrets = []string{backupPassphraseSecret, dataKeySecret}
+
+// movableSecret returns the secret called name stored in source and whether
+// it can be moved to target
+func movableSecret(source, target KeyringManager, name string) (string, bool) {
+	from, ok := source.(SecretStore)
+	if !ok {
+		return "", false
+	}
+	if _, ok := target.(SecretStore); !ok {
+		return "", false
+	}
+	value, err := from.GetSecret(name)
+	if err != nil {
+		if !isNotFound(err) {
+			logger.Warn("Failed to read a secret to move it", "name", name, "error", err)
+		}
+		return "", false
+	}
+	return value, true
+}
+
+// SetKeyring replaces the KeyringManager without moving any keys
+func (s *Storage) SetKeyring(k KeyringManager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keyring = k
+}
+
+// SwitchKeyring moves the keys of every stored provider from the current
+// KeyringManager to target and makes target the active one. Nothing is
+// removed from the old backend until every key has been copied.
+// Returns the number of providers whose keys were moved.
+func (s *Storage) SwitchKeyring(target KeyringManager) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target == s.keyring {
+		return 0, nil
+	}
+
+	var providers []models.Provider
+	data, err := s.readData(s.filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	if err == nil {
+		if providers, _, err = parseProviders(data); err != nil {
+			return 0, err
+		}
+	}
+
+	// 1. Read everything first so a failing source aborts before any change
+	source := s.keyring
+	collected := make(map[string][]string)
+	for _, p := range providers {
+		keys, err := source.GetKeys(p.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read keys for %s: %w", p.ID, err)
+		}
+		if len(keys) > 0 {
+			collected[p.ID] = keys
+		}
+	}
+
+	// 2. Copy to the target, undoing partial copies on failure
+	copied := []string{}
+	for id, keys := range collected {
+		if err := target.SetKeys(id, keys); err != nil {
+			for _, done := range copied {
+				target.DeleteKeys(done)
+			}
+			return 0, fmt.Errorf("failed to write keys for %s: %w", id, err)
+		}
+		copied = append(copied, id)
+	}
+
+	// The backup passphrase and data key live in the active backend too
+	moved := []string{}
+	for _, name := range movedSecrets {
+		value, ok := movableSecret(source, target, name)
+		if !ok {
+			continue
+		}
+		if err := target.(SecretStore).SetSecret(name, value); err != nil {
+			for _, done := range copied {
+				target.DeleteKeys(done)
+			}
+			for _, done := range moved {
+				target.(SecretStore).DeleteSecret(done)
+			}
+			return 0, fmt.Errorf("failed to move %s: %w", name, err)
+		}
+		moved = append(moved, name)
+	}
+
+	// 3. Switch and clean up the old backend
+	s.keyring = target
+	for id := range collected {
+		if err := source.DeleteKeys(id); err != nil {
+			logger.Warn("Failed to remove migrated keys from previous backend", "providerID", id, "error", err)
+		}
+	}
+	for _, name := range moved {
+		if err := source.(SecretStore).DeleteSecret(name); err != nil {
+			logger.Warn("Failed to remove a moved secret from previous backend", "name", name, "error", err)
+		}
+	}
+
+	return len(collected), nil
+}
+
 // Load reads providers from the JSON file and injects keys from keyring
 func (s *Storage) Load() ([]models.Provider, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.loadLocked()
+}
 
-	data, err := os.ReadFile(s.filename)
+// loadLocked reads providers, migrating the schema and plaintext keys.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) loadLocked() ([]models.Provider, error) {
+	var providers []models.Provider
+	var fileVersion int
+	data, recovery, err := readWithFallback(s.filename, func(b []byte) error {
+		plaintext, err := s.crypt.decode(b)
+		if err != nil {
+			return err
+		}
+		providers, fileVersion, err = parseProviders(plaintext)
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return []models.Provider{}, nil
@@ -55,20 +234,32 @@ func (s *Storage) Load() ([]models.Provider, error) {
 		return nil, err
 	}
 
-	var providers []models.Provider
-	if err := json.Unmarshal(data, &providers); err != nil {
-		return nil, err
+	if recovery != nil {
+		s.restoreRecovered(recovery, data)
+	}
+
+	// Keep the original document before upgrading an older schema
+	needsUpgrade := fileVersion < CurrentSchemaVersion
+	if needsUpgrade {
+		if err := backupBeforeMigration(s.filename, fileVersion, data); err != nil {
+			return nil, fmt.Errorf("failed to back up data before schema migration: %w", err)
+		}
+		logger.Info("Migrating data file schema", "from", fileVersion, "to", CurrentSchemaVersion)
 	}
 
 	// Handle secure key migration and injection
 	needsMigration := false
+	s.keyErrors = make(map[string]string)
 	for i := range providers {
 		p := &providers[i]
 
 		// 1. Check if keys exist in JSON (needs migration)
-		if len(p.Credentials.APIKeys) > 0 {
-			if err := s.keyring.SetKeys(p.ID, p.Credentials.APIKeys); err != nil {
-				// Log error but continue with what we have
+		if hasPlaintextKeys(*p) {
+			normalizeKeys(p)
+			if err := s.writeKeys(*p, nil); err != nil {
+				// Keep the plaintext keys in memory; reconciliation reports them
+				logger.Warn("Failed to move plaintext keys to key backend", "providerID", p.ID, "error", err)
+				s.keyErrors[p.ID] = err.Error()
 				continue
 			}
 			needsMigration = true
@@ -77,56 +268,294 @@ func (s *Storage) Load() ([]models.Provider, error) {
 		// 2. Fetch/Inject keys from keyring
 		keys, err := s.keyring.GetKeys(p.ID)
 		if err != nil {
-			// Log error
+			logger.Warn("Failed to load keys", "providerID", p.ID, "error", err)
+			s.keyErrors[p.ID] = err.Error()
 			continue
 		}
-		p.Credentials.APIKeys = keys
+		if injectSecrets(p, keys) {
+			// Keys stored before key records existed get records on disk
+			needsMigration = true
+		}
 	}
 
 	// 3. If migration happened, we need to save the scrubbed version to JSON
 	// Since we hold the Lock, this is safe and atomic.
-	if needsMigration {
+	if needsMigration || needsUpgrade {
 		if err := s.saveToFile(providers); err != nil {
-			// We continue even if save fails, but log it would be ideal
-			// For now just return the providers as they are valid in memory
+			// The providers are valid in memory; the next save retries
+			logger.Warn("Failed to save migrated data file", "error", err)
 		}
 	}
 
 	return providers, nil
 }
 
-// Save writes providers to the JSON file after securely storing keys in keyring
-// Save writes providers to the JSON file after securely storing keys in keyring
+// Save replaces all providers, storing their keys in the keyring. Revisions
+// of changed providers are bumped; use Update for read-modify-write. A
+// corrupt data file does not block a Save, since nothing is read from it.
 func (s *Storage) Save(providers []models.Provider) error {
+	return s.update("save", true, func(current *[]models.Provider) error {
+		*current = providers
+		return nil
+	})
+}
+
+// Update runs fn on the current providers and saves the result, holding the
+// lock throughout so concurrent updates cannot overwrite each other. Nothing
+// is written if fn returns an error. Changed providers get a new revision.
+func (s *Storage) Update(fn func(providers *[]models.Provider) error) error {
+	return s.UpdateOp("update", fn)
+}
+
+// UpdateOp is Update with the operation name recorded in the journal
+func (s *Storage) UpdateOp(op string, fn func(providers *[]models.Provider) error) error {
+	return s.update(op, false, fn)
+}
+
+// update runs an update. With replace set, fn discards the current
+// providers, so an unreadable data file is overwritten instead of blocking
+// the save; revisions then start over.
+func (s *Storage) update(op string, replace bool, fn func(providers *[]models.Provider) error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.saveToFile(providers)
+
+	providers, err := s.loadLocked()
+	if err != nil {
+		if !replace || !errors.Is(err, ErrDataCorrupt) {
+			return err
+		}
+		logger.Warn("Replacing corrupt data file", "error", err)
+		providers = []models.Provider{}
+	}
+	before := snapshotProviders(providers)
+	revisions := revisionIndex(providers)
+
+	if err := fn(&providers); err != nil {
+		return err
+	}
+
+	bumpRevisions(revisions, providers)
+	if err := s.saveToFile(providers); err != nil {
+		return err
+	}
+	s.deleteRemovedKeys(revisions, providers)
+	s.record(op, before, providers)
+	return nil
+}
+
+// deleteRemovedKeys deletes the keys of providers that were in revisions but
+// are no longer in providers. A failure is only logged; reconciliation then
+// reports the keys as orphaned.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) deleteRemovedKeys(revisions map[string]revisionState, providers []models.Provider) {
+	kept := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		kept[p.ID] = true
+	}
+	for id := range revisions {
+		if kept[id] {
+			continue
+		}
+		if err := s.deleteKeys(id); err != nil {
+			logger.Warn("Failed to delete keys of removed provider", "providerID", id, "error", err)
+		}
+	}
+}
+
+// OnChange registers fn to be called after every saved catalog change. fn
+// runs with the storage lock held, so it must not block or call back into
+// the Storage.
+func (s *Storage) OnChange(fn func(op string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// record appends a journal entry and notifies the change hook; the change
+// is already saved, so a journal failure is only logged.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) record(op string, before, after []models.Provider) {
+	if err := s.journal.append(op, before, after); err != nil {
+		logger.Warn("Failed to write journal entry", "operation", op, "error", err)
+	}
+	if s.onChange != nil && len(diffProviders(before, after)) > 0 {
+		s.onChange(op)
+	}
 }
 
 // saveToFile writes providers to JSON, scrubbing sensitive keys.
 // NOTE: Caller MUST hold s.mu.Lock()
 func (s *Storage) saveToFile(providers []models.Provider) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	// Fingerprints currently on disk tell which key sets actually changed
+	previous := make(map[string]map[string]bool)
+	if onDisk, err := s.readRawProviders(); err == nil {
+		for _, p := range onDisk {
+			previous[p.ID] = fingerprintSet(p.Credentials.APIKeys)
+		}
+	}
+
 	// Create a sanitized copy for JSON storage (scrubbed of keys)
 	scrubbed := make([]models.Provider, len(providers))
-	for i, p := range providers {
+	for i := range providers {
+		normalizeKeys(&providers[i])
+		p := providers[i]
+
 		// Save keys to keyring first
-		if len(p.Credentials.APIKeys) > 0 {
-			if err := s.keyring.SetKeys(p.ID, p.Credentials.APIKeys); err != nil {
-				return err
-			}
+		if err := s.writeKeys(p, previous[p.ID]); err != nil {
+			return err
 		}
 
 		// Copy and scrub
 		scrubbed[i] = p
-		scrubbed[i].Credentials.APIKeys = []string{}
+		scrubbed[i].Credentials.APIKeys = scrubKeys(p.Credentials.APIKeys)
 	}
 
-	data, err := json.MarshalIndent(scrubbed, "", "  ")
+	data, err := encodeProvidersFile(scrubbed)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.filename, data, 0644)
+	return s.writeDataFile(s.filename, data)
+}
+
+// writeDataFile atomically replaces path with data, then rotates the backup
+// generations so the previous content becomes path.1.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) writeDataFile(path string, data []byte) error {
+	encoded, err := s.crypt.encode(data)
+	if err != nil {
+		return err
+	}
+	previous := readPrevious(path)
+	if err := writeFileAtomic(path, encoded, 0600); err != nil {
+		return err
+	}
+	s.written[path] = contentHash(encoded)
+	if err := rotateGenerations(path, previous, s.generations); err != nil {
+		// The new file is in place; only the backup of the old one is missing
+		logger.Warn("Failed to rotate backups", "file", filepath.Base(path), "error", err)
+	}
+	return nil
+}
+
+// readData reads a data file and decrypts it if it is encrypted at rest
+func (s *Storage) readData(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.crypt.decode(data)
+}
+
+// WrittenHash returns the hash of the content this instance last wrote to
+// path, so a watcher can tell its own writes from external edits
+func (s *Storage) WrittenHash(path string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hash, ok := s.written[path]
+	return hash, ok
+}
+
+// restoreRecovered puts the recovered generation back in place of the corrupt
+// file, keeping the corrupt copy aside for inspection.
+// NOTE: Caller MUST hold s.mu.Lock()
+func (s *Storage) restoreRecovered(report *RecoveryReport, data []byte) {
+	logger.Warn("Recovered data file from backup",
+		"file", report.File, "generation", report.Generation, "reason", report.Reason)
+
+	path := filepath.Join(s.dataDir, report.File)
+	corruptPath := path + ".corrupt-" + time.Now().Format("20060102-150405")
+	if err := os.Rename(path, corruptPath); err != nil {
+		logger.Warn("Failed to set aside corrupt file", "file", report.File, "error", err)
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		logger.Warn("Failed to restore recovered file", "file", report.File, "error", err)
+	} else {
+		s.written[path] = contentHash(data)
+	}
+
+	s.recovery = report
+}
+
+// LastRecovery returns the report of the most recent fallback to a backup
+// generation, or nil if every load so far read a valid file
+func (s *Storage) LastRecovery() *RecoveryReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recovery
+}
+
+// ListSnapshots returns the rolling backup generations of providers.json
+func (s *Storage) ListSnapshots() ([]Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := []Snapshot{}
+	for _, n := range listGenerations(s.filename) {
+		path := generationPath(s.filename, n)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		snap := Snapshot{
+			Generation: n,
+			Path:       path,
+			ModifiedAt: info.ModTime().Format(time.RFC3339),
+			Size:       info.Size(),
+		}
+
+		var providers []models.Provider
+		data, err := s.readData(path)
+		if err == nil {
+			providers, _, err = parseProviders(data)
+		}
+		if err != nil {
+			snap.Error = err.Error()
+		} else {
+			snap.Valid = true
+			snap.Providers = len(providers)
+		}
+
+		snapshots = append(snapshots, snap)
+	}
+
+	return snapshots, nil
+}
+
+// RestoreSnapshot replaces providers.json with the given backup generation.
+// The current file is rotated into the generations first, so a restore can
+// itself be undone.
+func (s *Storage) RestoreSnapshot(generation int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.readData(generationPath(s.filename, generation))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("snapshot not found: %d", generation)
+		}
+		return err
+	}
+
+	restored, _, err := parseProviders(data)
+	if err != nil {
+		return fmt.Errorf("snapshot %d cannot be restored: %w", generation, err)
+	}
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	current, _ := s.readRawProviders()
+	if err := s.writeDataFile(s.filename, data); err != nil {
+		return err
+	}
+	s.record(fmt.Sprintf("restoreSnapshot:%d", generation), current, restored)
+	return nil
 }
 
 // ExportToFile exports data to a specified file path
@@ -139,7 +568,7 @@ func (s *Storage) ExportToFile(filepath string, data *models.LLMDeskData) error
 		return err
 	}
 
-	return os.WriteFile(filepath, jsonData, 0644)
+	return os.WriteFile(filepath, jsonData, 0600)
 }
 
 // ExportEncryptedToFile exports data to a specified file path with encryption
@@ -157,12 +586,49 @@ func (s *Storage) ExportEncryptedToFile(filepath string, data *models.LLMDeskDat
 		return err
 	}
 
-	return os.WriteFile(filepath, encryptedData, 0644)
+	return os.WriteFile(filepath, encryptedData, 0600)
+}
+
+// IsEncryptedFile reports whether a backup file is encrypted rather than
+// plain JSON or other text such as YAML
+func (s *Storage) IsEncryptedFile(filepath string) (bool, error) {
+	data, err := s.ReadAtRest(filepath)
+	if err != nil {
+		return false, err
+	}
+	return IsEncrypted(data), nil
+}
+
+// IsEncrypted reports whether backup content is encrypted rather than plain
+// JSON or other text such as YAML
+func IsEncrypted(data []byte) bool {
+	if IsContainer(data) {
+		return true
+	}
+	// Legacy encrypted files are random bytes and never valid UTF-8 text
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[' || utf8.Valid(trimmed)) {
+		return false
+	}
+	_, err := InspectEncrypted(data)
+	return err == nil
+}
+
+// ReadAtRest reads a file that may be encrypted at rest outside the data
+// files, such as an automatic backup, and returns its plaintext. Other
+// content is returned as is.
+func (s *Storage) ReadAtRest(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.crypt.decode(data)
 }
 
 // ImportFromFile reads and parses a backup file
 func (s *Storage) ImportFromFile(filepath string) (*models.LLMDeskData, error) {
-	data, err := os.ReadFile(filepath)
+	data, err := s.ReadAtRest(filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -177,7 +643,7 @@ func (s *Storage) ImportFromFile(filepath string) (*models.LLMDeskData, error) {
 
 // ImportEncryptedFromFile reads and decrypts a backup file
 func (s *Storage) ImportEncryptedFromFile(filepath string, passphrase string) (*models.LLMDeskData, error) {
-	encryptedData, err := os.ReadFile(filepath)
+	encryptedData, err := s.ReadAtRest(filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -195,34 +661,87 @@ func (s *Storage) ImportEncryptedFromFile(filepath string, passphrase string) (*
 	return &llmData, nil
 }
 
+// QuarantineImport writes imported items that failed validation to the
+// quarantine directory, encrypted at rest like the catalog, and returns the
+// file path
+func (s *Storage) QuarantineImport(data *models.LLMDeskData) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	encoded, err := s.crypt.encode(jsonData)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(s.dataDir, "quarantine")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "import-"+time.Now().UTC().Format("20060102-150405.000000000")+".json")
+	if err := writeFileAtomic(path, encoded, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
 // Clear removes all stored data from JSON and keyring
 func (s *Storage) Clear() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// 1. Try to load providers to find IDs to delete from keyring
-	data, err := os.ReadFile(s.filename)
-	if err == nil {
-		var providers []models.Provider
-		if err := json.Unmarshal(data, &providers); err == nil {
-			for _, p := range providers {
-				s.keyring.DeleteKeys(p.ID)
-			}
-		}
+	providers, _ := s.readRawProviders()
+	for _, p := range providers {
+		s.keyring.DeleteKeys(p.ID)
 	}
 
-	// 2. Delete the file
+	// 2. Delete the file, keeping the cleared catalog as a backup generation
+	previous := readPrevious(s.filename)
 	if err := os.Remove(s.filename); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
+	if err := rotateGenerations(s.filename, previous, s.generations); err != nil {
+		logger.Warn("Failed to back up data before clear", "error", err)
+	}
+	s.written[s.filename] = ""
+	s.record("clear", providers, nil)
 	return nil
 }
 
 // AppSettings represents user preferences (defined here to avoid import cycle)
 type AppSettings struct {
-	Theme                string `json:"theme"`
-	FollowSystemTheme    bool   `json:"followSystemTheme"`
-	EnableCrashReporting bool   `json:"enableCrashReporting"`
+	Theme                string             `json:"theme"`
+	FollowSystemTheme    bool               `json:"followSystemTheme"`
+	EnableCrashReporting bool               `json:"enableCrashReporting"`
+	KeyBackend           string             `json:"keyBackend,omitempty"` // "auto", "keyring" or "vault"
+	Backup               BackupSettings     `json:"backup"`
+	ModelFetch           ModelFetchSettings `json:"modelFetch"`
+}
+
+// ModelFetchSettings caps how much of a paginated model list is fetched
+type ModelFetchSettings struct {
+	MaxPages  int `json:"maxPages"`  // Pages requested per fetch
+	MaxModels int `json:"maxModels"` // Distinct models kept per fetch
+}
+
+// DefaultModelFetchSettings returns the caps used until the user changes
+// them
+func DefaultModelFetchSettings() ModelFetchSettings {
+	return ModelFetchSettings{MaxPages: 20, MaxModels: 5000}
+}
+
+// ProvidersPath returns the path of providers.json
+func (s *Storage) ProvidersPath() string {
+	return s.filename
+}
+
+// SettingsPath returns the path of settings.json
+func (s *Storage) SettingsPath() string {
+	return s.settingsFilename()
 }
 
 // settingsFilename returns the path to the settings file
@@ -232,10 +751,18 @@ func (s *Storage) settingsFilename() string {
 
 // LoadSettings reads settings from the JSON file
 func (s *Storage) LoadSettings() (*AppSettings, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	data, err := os.ReadFile(s.settingsFilename())
+	var settings AppSettings
+	data, recovery, err := readWithFallback(s.settingsFilename(), func(b []byte) error {
+		plaintext, err := s.crypt.decode(b)
+		if err != nil {
+			return err
+		}
+		settings = AppSettings{}
+		return json.Unmarshal(plaintext, &settings)
+	})
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil // No settings file yet
@@ -243,9 +770,8 @@ func (s *Storage) LoadSettings() (*AppSettings, error) {
 		return nil, err
 	}
 
-	var settings AppSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
-		return nil, err
+	if recovery != nil {
+		s.restoreRecovered(recovery, data)
 	}
 
 	return &settings, nil
@@ -261,5 +787,5 @@ func (s *Storage) SaveSettings(settings *AppSettings) error {
 		return err
 	}
 
-	return os.WriteFile(s.settingsFilename(), data, 0644)
+	return s.writeDataFile(s.settingsFilename(), data)
 }
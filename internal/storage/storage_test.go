@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -20,10 +22,9 @@ func setupTestStorage(t *testing.T) (*Storage, func()) {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 
-	storage := &Storage{
-		dataDir:  tempDir,
-		filename: filepath.Join(tempDir, "providers.json"),
-		keyring:  &MockKeyringStore{store: make(map[string]string)},
+	storage, err := NewWithDir(tempDir, &MockKeyringStore{store: make(map[string]string)})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
 	}
 
 	cleanup := func() {
@@ -95,7 +96,7 @@ func TestStorage_SaveAndLoad(t *testing.T) {
 			Name:    "Test Provider 1",
 			Enabled: true,
 			Credentials: models.Credentials{
-				APIKeys: []string{"key1", "key2"},
+				APIKeys: models.NewAPIKeys("key1", "key2"),
 			},
 			Endpoints: models.Endpoints{
 				OpenAI: "https://api.example.com/v1",
@@ -175,6 +176,36 @@ func TestStorage_Clear(t *testing.T) {
 	}
 }
 
+func TestStorage_SaveDeletesKeysOfRemovedProviders(t *testing.T) {
+	keyring := &MockKeyringStore{store: make(map[string]string)}
+	storage, err := NewWithDir(t.TempDir(), keyring)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	providers := []models.Provider{
+		{ID: "kept", Name: "Kept", Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-kept-0001")}},
+		{ID: "dropped", Name: "Dropped", Credentials: models.Credentials{APIKeys: models.NewAPIKeys("sk-dropped-0001")}},
+	}
+	if err := storage.Save(providers); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, ok := keyring.store["dropped"]; !ok {
+		t.Fatal("Expected keys of dropped to be stored")
+	}
+
+	// Replacing the catalog without the provider removes its keys
+	if err := storage.Save(providers[:1]); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, ok := keyring.store["dropped"]; ok {
+		t.Error("Expected keys of the removed provider to be deleted")
+	}
+	if _, ok := keyring.store["kept"]; !ok {
+		t.Error("Expected keys of the kept provider to remain")
+	}
+}
+
 func TestStorage_ClearNonExistent(t *testing.T) {
 	storage, cleanup := setupTestStorage(t)
 	defer cleanup()
@@ -342,7 +373,7 @@ func TestStorage_Scrubbing(t *testing.T) {
 			ID:   "secret-provider",
 			Name: "Secret Provider",
 			Credentials: models.Credentials{
-				APIKeys: []string{"super-secret-key-123"},
+				APIKeys: models.NewAPIKeys("super-secret-key-123"),
 			},
 			Endpoints: models.Endpoints{OpenAI: "https://api.example.com"},
 			Limits:    []models.Limit{},
@@ -360,7 +391,7 @@ func TestStorage_Scrubbing(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load failed: %v", err)
 	}
-	if len(loaded[0].Credentials.APIKeys) == 0 || loaded[0].Credentials.APIKeys[0] != "super-secret-key-123" {
+	if len(loaded[0].Credentials.APIKeys) == 0 || loaded[0].Credentials.APIKeys[0].Key != "super-secret-key-123" {
 		t.Errorf("Expected keys to be available after Load, got %v", loaded[0].Credentials.APIKeys)
 	}
 
@@ -380,16 +411,25 @@ func TestStorage_Scrubbing(t *testing.T) {
 		t.Error("SECURITY BREACH: Persistent JSON file contains plaintext API key!")
 	}
 
-	// 3. Verify apiKeys field is an empty array in the JSON
-	var raw []map[string]interface{}
+	// 3. Verify key records in the JSON carry no secret
+	var raw struct {
+		Providers []map[string]interface{} `json:"providers"`
+	}
 	if err := json.Unmarshal(content, &raw); err != nil {
 		t.Fatalf("Failed to unmarshal raw JSON: %v", err)
 	}
 
-	creds := raw[0]["credentials"].(map[string]interface{})
+	creds := raw.Providers[0]["credentials"].(map[string]interface{})
 	keys := creds["apiKeys"].([]interface{})
-	if len(keys) != 0 {
-		t.Errorf("Expected apiKeys to be empty in JSON, got %v", keys)
+	if len(keys) != 1 {
+		t.Fatalf("Expected one key record in JSON, got %v", keys)
+	}
+	record := keys[0].(map[string]interface{})
+	if _, ok := record["key"]; ok {
+		t.Errorf("Expected key record without secret, got %v", record)
+	}
+	if record["id"] == "" || record["fingerprint"] == "" {
+		t.Errorf("Expected key record to keep its ID and fingerprint, got %v", record)
 	}
 }
 
@@ -450,3 +490,211 @@ func TestStorage_ExportMinified(t *testing.T) {
 		t.Errorf("Expected 2 providers, got %d", len(imported.Providers))
 	}
 }
+
+func TestStorage_LoadRecoversFromCorruptFile(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	first := []models.Provider{{ID: "p1", Name: "First", Limits: []models.Limit{}, Models: []models.Model{}}}
+	second := []models.Provider{
+		{ID: "p1", Name: "First", Limits: []models.Limit{}, Models: []models.Model{}},
+		{ID: "p2", Name: "Second", Limits: []models.Limit{}, Models: []models.Model{}},
+	}
+	if err := storage.Save(first); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := storage.Save(second); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Simulate a crash mid-write leaving a truncated file
+	if err := os.WriteFile(storage.filename, []byte(`[{"id": "p1", "na`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+
+	loaded, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load should recover from backup, got: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].ID != "p1" {
+		t.Errorf("Expected recovery from generation 1 with 1 provider, got %v", loaded)
+	}
+
+	report := storage.LastRecovery()
+	if report == nil {
+		t.Fatal("Expected a recovery report")
+	}
+	if report.Generation != 1 || report.File != "providers.json" {
+		t.Errorf("Unexpected recovery report: %+v", report)
+	}
+
+	// The recovered content is put back in place
+	if _, err := storage.Load(); err != nil {
+		t.Errorf("Load after recovery failed: %v", err)
+	}
+}
+
+func TestStorage_LoadCorruptWithoutBackups(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := os.WriteFile(storage.filename, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	_, err := storage.Load()
+	if !errors.Is(err, ErrDataCorrupt) {
+		t.Errorf("Expected a corrupt data error, got %v", err)
+	}
+
+	// Only a full replace may overwrite it
+	if err := storage.Update(func(p *[]models.Provider) error { return nil }); !errors.Is(err, ErrDataCorrupt) {
+		t.Errorf("Expected Update to be blocked, got %v", err)
+	}
+	if err := storage.Save([]models.Provider{{ID: "fresh", Name: "Fresh", Limits: []models.Limit{}, Models: []models.Model{}}}); err != nil {
+		t.Fatalf("Save over a corrupt file failed: %v", err)
+	}
+	providers, err := storage.Load()
+	if err != nil || len(providers) != 1 || providers[0].Revision != 1 {
+		t.Errorf("Expected the saved provider at revision 1, got %+v (%v)", providers, err)
+	}
+}
+
+func TestStorage_SnapshotsAndRestore(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	for i := 1; i <= DefaultBackupGenerations+2; i++ {
+		providers := make([]models.Provider, i)
+		for j := range providers {
+			providers[j] = models.Provider{ID: string(rune('a' + j)), Name: "P", Limits: []models.Limit{}, Models: []models.Model{}}
+		}
+		if err := storage.Save(providers); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+
+	snapshots, err := storage.ListSnapshots()
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != DefaultBackupGenerations {
+		t.Fatalf("Expected %d snapshots, got %d", DefaultBackupGenerations, len(snapshots))
+	}
+	if snapshots[0].Generation != 1 || !snapshots[0].Valid || snapshots[0].Providers != DefaultBackupGenerations+1 {
+		t.Errorf("Unexpected newest snapshot: %+v", snapshots[0])
+	}
+
+	if err := storage.RestoreSnapshot(3); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	loaded, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) != DefaultBackupGenerations-1 {
+		t.Errorf("Expected %d providers after restore, got %d", DefaultBackupGenerations-1, len(loaded))
+	}
+
+	if err := storage.RestoreSnapshot(99); err == nil {
+		t.Error("Expected error restoring missing snapshot")
+	}
+}
+
+func TestStorage_FailedWriteKeepsSnapshots(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	for _, id := range []string{"a", "b"} {
+		if err := storage.Save([]models.Provider{{ID: id, Name: "P"}}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	before, _ := os.ReadFile(generationPath(storage.filename, 1))
+
+	// Encryption enabled without a key: encoding the new file fails
+	storage.crypt.config.Enabled = true
+	if err := storage.writeDataFile(storage.filename, []byte(`{}`)); !errors.Is(err, ErrDataLocked) {
+		t.Fatalf("Expected ErrDataLocked, got %v", err)
+	}
+	after, _ := os.ReadFile(generationPath(storage.filename, 1))
+	if !bytes.Equal(before, after) || len(listGenerations(storage.filename)) != 1 {
+		t.Errorf("Expected a failed write to leave the snapshots alone, got %v", listGenerations(storage.filename))
+	}
+}
+
+func TestStorage_SettingsRecoverFromCorruptFile(t *testing.T) {
+	storage, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := storage.SaveSettings(&AppSettings{Theme: "light"}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	if err := storage.SaveSettings(&AppSettings{Theme: "dark"}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	if err := os.WriteFile(storage.settingsFilename(), []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt settings: %v", err)
+	}
+
+	loaded, err := storage.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings should recover, got: %v", err)
+	}
+	if loaded.Theme != "light" {
+		t.Errorf("Expected recovered theme 'light', got '%s'", loaded.Theme)
+	}
+}
+
+func TestNewWithDir_Validation(t *testing.T) {
+	if _, err := NewWithDir("", NewMemoryKeyring()); err == nil {
+		t.Error("Expected error for empty data directory")
+	}
+	if _, err := NewWithDir(t.TempDir(), nil); err == nil {
+		t.Error("Expected error for nil keyring")
+	}
+
+	dir := filepath.Join(t.TempDir(), "nested", "data")
+	s, err := NewWithDir(dir, NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+	if s.GetDataDir() != dir {
+		t.Errorf("Expected data dir '%s', got '%s'", dir, s.GetDataDir())
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected data dir to be created: %v", err)
+	}
+}
+
+func TestResolveLocation(t *testing.T) {
+	flagDir := t.TempDir()
+	envDir := t.TempDir()
+
+	t.Setenv(DataDirEnv, envDir)
+
+	loc, err := ResolveLocation(LocationOptions{DataDir: flagDir})
+	if err != nil {
+		t.Fatalf("ResolveLocation failed: %v", err)
+	}
+	if loc.DataDir != flagDir || loc.Source != LocationFlag {
+		t.Errorf("Expected flag dir to win, got %+v", loc)
+	}
+
+	// An explicit --portable beats LLMDESK_HOME
+	loc, err = ResolveLocation(LocationOptions{Portable: true})
+	if err != nil {
+		t.Fatalf("ResolveLocation failed: %v", err)
+	}
+	if !loc.Portable || loc.Source != LocationPortable || filepath.Base(loc.DataDir) != portableDirName {
+		t.Errorf("Expected portable flag to win over env, got %+v", loc)
+	}
+
+	loc, err = ResolveLocation(LocationOptions{})
+	if err != nil {
+		t.Fatalf("ResolveLocation failed: %v", err)
+	}
+	if loc.DataDir != envDir || loc.Source != LocationEnv {
+		t.Errorf("Expected env dir without flags, got %+v", loc)
+	}
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"llm-desk/internal/logger"
+)
+
+// VaultFilename is the name of the encrypted key vault inside the data directory
+const VaultFilename = "keys.vault"
+
+// vaultFormatVersion is the version of the decrypted vault payload
+const vaultFormatVersion = 1
+
+// ErrVaultLocked is returned by vault operations before Unlock has succeeded
+var ErrVaultLocked = errors.New("key vault is locked")
+
+// vaultPayload is the decrypted content of the vault file
+type vaultPayload struct {
+	Version int                 `json:"version"`
+	Keys    map[string][]string `json:"keys"`
+	Secrets map[string]string   `json:"secrets,omitempty"`
+}
+
+// FileVault is a KeyringManager that keeps API keys in a local file
+// encrypted with a master passphrase. It is used where no OS keyring is
+// available (headless Linux, minimal desktops) and in portable mode.
+type FileVault struct {
+	mu      sync.Mutex
+	path    string
+	crypt   *keyCache           // nil while locked
+	keys    map[string][]string // nil while locked
+	secrets map[string]string   // App secrets such as the backup passphrase
+}
+
+// NewFileVault creates a locked vault backed by the file at path
+func NewFileVault(path string) *FileVault {
+	return &FileVault{path: path}
+}
+
+// Path returns the location of the vault file
+func (v *FileVault) Path() string {
+	return v.path
+}
+
+// Exists reports whether the vault file has been created
+func (v *FileVault) Exists() bool {
+	_, err := os.Stat(v.path)
+	return err == nil
+}
+
+// IsUnlocked reports whether the vault can currently be read and written
+func (v *FileVault) IsUnlocked() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.keys != nil
+}
+
+// Unlock decrypts the vault with passphrase. If the vault file does not
+// exist yet, it is created empty and protected by passphrase.
+func (v *FileVault) Unlock(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("vault passphrase cannot be empty")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	data, err := os.ReadFile(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		v.crypt = newKeyCache(passphrase)
+		v.keys = make(map[string][]string)
+		v.secrets = make(map[string]string)
+		if err := v.persist(); err != nil {
+			v.keys = nil
+			v.secrets = nil
+			v.crypt = nil
+			return err
+		}
+		logger.Info("Created key vault", "path", v.path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read key vault: %w", err)
+	}
+
+	crypt := newKeyCache(passphrase)
+	plaintext, err := crypt.open(data)
+	if err != nil {
+		return fmt.Errorf("failed to unlock key vault: %w", err)
+	}
+
+	var payload vaultPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return fmt.Errorf("key vault is corrupt: %w", err)
+	}
+	if payload.Keys == nil {
+		payload.Keys = make(map[string][]string)
+	}
+	if payload.Secrets == nil {
+		payload.Secrets = make(map[string]string)
+	}
+
+	v.crypt = crypt
+	v.keys = payload.Keys
+	v.secrets = payload.Secrets
+
+	// Move vaults written before versioned containers to the current format
+	if NeedsReencrypt(data) {
+		if err := v.persist(); err != nil {
+			logger.Warn("Failed to re-encrypt key vault", "error", err)
+		} else {
+			logger.Info("Re-encrypted key vault in the current format", "path", v.path)
+		}
+	}
+	return nil
+}
+
+// Lock forgets the passphrase and decrypted keys
+func (v *FileVault) Lock() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.crypt = nil
+	v.keys = nil
+	v.secrets = nil
+}
+
+// SetKeys stores the keys for a provider and rewrites the vault file
+func (v *FileVault) SetKeys(providerID string, keys []string) error {
+	if providerID == "" {
+		return fmt.Errorf("provider ID cannot be empty")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return ErrVaultLocked
+	}
+
+	previous, had := v.keys[providerID]
+	v.keys[providerID] = append([]string{}, keys...)
+	if err := v.persist(); err != nil {
+		if had {
+			v.keys[providerID] = previous
+		} else {
+			delete(v.keys, providerID)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetKeys returns the keys stored for a provider, or an empty slice
+func (v *FileVault) GetKeys(providerID string) ([]string, error) {
+	if providerID == "" {
+		return nil, fmt.Errorf("provider ID cannot be empty")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return nil, ErrVaultLocked
+	}
+	return append([]string{}, v.keys[providerID]...), nil
+}
+
+// DeleteKeys removes the keys for a provider and rewrites the vault file
+func (v *FileVault) DeleteKeys(providerID string) error {
+	if providerID == "" {
+		return fmt.Errorf("provider ID cannot be empty")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return ErrVaultLocked
+	}
+
+	previous, had := v.keys[providerID]
+	if !had {
+		return nil
+	}
+	delete(v.keys, providerID)
+	if err := v.persist(); err != nil {
+		v.keys[providerID] = previous
+		return err
+	}
+	return nil
+}
+
+// ListProviderIDs returns the providers that have keys in the vault
+func (v *FileVault) ListProviderIDs() ([]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return nil, ErrVaultLocked
+	}
+	ids := make([]string, 0, len(v.keys))
+	for id := range v.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// SetSecret stores an app secret under name and rewrites the vault file
+func (v *FileVault) SetSecret(name, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return ErrVaultLocked
+	}
+
+	previous, had := v.secrets[name]
+	v.secrets[name] = value
+	if err := v.persist(); err != nil {
+		if had {
+			v.secrets[name] = previous
+		} else {
+			delete(v.secrets, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetSecret returns the app secret stored under name
+func (v *FileVault) GetSecret(name string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return "", ErrVaultLocked
+	}
+	value, ok := v.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// DeleteSecret removes the app secret stored under name and rewrites the
+// vault file
+func (v *FileVault) DeleteSecret(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.keys == nil {
+		return ErrVaultLocked
+	}
+
+	previous, had := v.secrets[name]
+	if !had {
+		return nil
+	}
+	delete(v.secrets, name)
+	if err := v.persist(); err != nil {
+		v.secrets[name] = previous
+		return err
+	}
+	return nil
+}
+
+// persist encrypts the in-memory keys and atomically replaces the vault file.
+// NOTE: Caller MUST hold v.mu
+func (v *FileVault) persist() error {
+	plaintext, err := json.Marshal(vaultPayload{Version: vaultFormatVersion, Keys: v.keys, Secrets: v.secrets})
+	if err != nil {
+		return fmt.Errorf("failed to marshal key vault: %w", err)
+	}
+
+	encrypted, err := v.crypt.seal(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key vault: %w", err)
+	}
+
+	return writeFileAtomic(v.path, encrypted, 0600)
+}
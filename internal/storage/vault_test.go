@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"llm-desk/internal/models"
+)
+
+func TestFileVault_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), VaultFilename)
+	vault := NewFileVault(path)
+
+	if vault.Exists() {
+		t.Fatal("Vault should not exist before first unlock")
+	}
+	if _, err := vault.GetKeys("p1"); !errors.Is(err, ErrVaultLocked) {
+		t.Errorf("Expected ErrVaultLocked, got %v", err)
+	}
+
+	// First unlock creates the vault
+	if err := vault.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := vault.SetKeys("p1", []string{"sk-secret-1", "sk-secret-2"}); err != nil {
+		t.Fatalf("SetKeys failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read vault: %v", err)
+	}
+	if strings.Contains(string(content), "sk-secret-1") {
+		t.Error("Vault file contains a plaintext key")
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Expected vault to be private, got %v", info.Mode().Perm())
+	}
+
+	// A fresh instance reads it back with the right passphrase only
+	reopened := NewFileVault(path)
+	if err := reopened.Unlock("wrong"); err == nil {
+		t.Error("Unlock with wrong passphrase should fail")
+	}
+	if err := reopened.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	keys, err := reopened.GetKeys("p1")
+	if err != nil {
+		t.Fatalf("GetKeys failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "sk-secret-1" {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+
+	if err := reopened.DeleteKeys("p1"); err != nil {
+		t.Fatalf("DeleteKeys failed: %v", err)
+	}
+	keys, _ = reopened.GetKeys("p1")
+	if len(keys) != 0 {
+		t.Errorf("Expected no keys after delete, got %v", keys)
+	}
+
+	reopened.Lock()
+	if reopened.IsUnlocked() {
+		t.Error("Vault should be locked")
+	}
+}
+
+func TestStorage_SwitchKeyring(t *testing.T) {
+	storage, err := NewWithDir(t.TempDir(), NewMemoryKeyring())
+	if err != nil {
+		t.Fatalf("NewWithDir failed: %v", err)
+	}
+
+	providers := []models.Provider{
+		{ID: "p1", Name: "P1", Credentials: models.Credentials{APIKeys: models.NewAPIKeys("k1")}},
+		{ID: "p2", Name: "P2", Credentials: models.Credentials{APIKeys: []models.APIKey{}}},
+	}
+	if err := storage.Save(providers); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	source := storage.Keyring()
+	if err := storage.SetBackupPassphrase("backup-pw"); err != nil {
+		t.Fatalf("SetBackupPassphrase failed: %v", err)
+	}
+
+	vault := NewFileVault(filepath.Join(storage.GetDataDir(), VaultFilename))
+	if _, err := storage.SwitchKeyring(vault); !errors.Is(err, ErrVaultLocked) {
+		t.Fatalf("Expected switch to a locked vault to fail, got %v", err)
+	}
+	if storage.Keyring() != source {
+		t.Fatal("Failed switch must keep the previous backend")
+	}
+
+	if err := vault.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	moved, err := storage.SwitchKeyring(vault)
+	if err != nil {
+		t.Fatalf("SwitchKeyring failed: %v", err)
+	}
+	if moved != 1 {
+		t.Errorf("Expected 1 provider moved, got %d", moved)
+	}
+
+	if keys, _ := source.GetKeys("p1"); len(keys) != 0 {
+		t.Errorf("Expected keys removed from previous backend, got %v", keys)
+	}
+	if passphrase, err := storage.BackupPassphrase(); err != nil || passphrase != "backup-pw" {
+		t.Errorf("Expected the backup passphrase to move to the vault, got %q (%v)", passphrase, err)
+	}
+	if _, err := source.(SecretStore).GetSecret(backupPassphraseSecret); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected the backup passphrase removed from previous backend, got %v", err)
+	}
+	vault.Lock()
+	if err := vault.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if passphrase, _ := storage.BackupPassphrase(); passphrase != "backup-pw" {
+		t.Errorf("Expected the backup passphrase to be kept in the vault file, got %q", passphrase)
+	}
+	loaded, err := storage.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded[0].Credentials.APIKeys) != 1 || loaded[0].Credentials.APIKeys[0].Key != "k1" {
+		t.Errorf("Expected key served from vault, got %v", loaded[0].Credentials.APIKeys)
+	}
+
+	// And back again
+	if _, err := storage.SwitchKeyring(source); err != nil {
+		t.Fatalf("SwitchKeyring back failed: %v", err)
+	}
+	if keys, _ := source.GetKeys("p1"); len(keys) != 1 {
+		t.Errorf("Expected keys back in original backend, got %v", keys)
+	}
+	if passphrase, _ := source.(SecretStore).GetSecret(backupPassphraseSecret); passphrase != "backup-pw" {
+		t.Errorf("Expected the backup passphrase back in original backend, got %q", passphrase)
+	}
+}
+
+func TestFileVault_UpgradesLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), VaultFilename)
+	legacy := legacyEncrypt(t, []byte(`{"version":1,"keys":{"p1":["sk-old"]}}`), "master")
+	if err := os.WriteFile(path, legacy, 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	vault := NewFileVault(path)
+	if err := vault.Unlock("master"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if keys, _ := vault.GetKeys("p1"); len(keys) != 1 || keys[0] != "sk-old" {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+
+	data, _ := os.ReadFile(path)
+	if NeedsReencrypt(data) {
+		t.Error("Expected vault to be rewritten in the current format")
+	}
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"llm-desk/internal/logger"
+)
+
+// DefaultWatchInterval is how often the watcher polls the data files
+const DefaultWatchInterval = 2 * time.Second
+
+// ChangeEvent reports a data file that was modified outside this instance
+type ChangeEvent struct {
+	File       string `json:"file"` // Base name, e.g. "providers.json"
+	Path       string `json:"path"`
+	Removed    bool   `json:"removed"`
+	DetectedAt string `json:"detectedAt"`
+}
+
+// fileState is what the watcher last saw of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+	hash    string // "" when the file does not exist
+}
+
+// Watcher polls data files for changes made by other processes (hand edits,
+// the CLI, a second app instance). Writes made through the Storage it was
+// created with are recognised by content hash and not reported.
+type Watcher struct {
+	storage  *Storage
+	interval time.Duration
+	onChange func(ChangeEvent)
+	files    []string
+
+	mu    sync.Mutex
+	state map[string]fileState
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewWatcher creates a watcher for providers.json and settings.json. The
+// current content of each file is taken as the starting point.
+func NewWatcher(s *Storage, interval time.Duration, onChange func(ChangeEvent)) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	w := &Watcher{
+		storage:  s,
+		interval: interval,
+		onChange: onChange,
+		files:    []string{s.ProvidersPath(), s.SettingsPath()},
+		state:    make(map[string]fileState),
+	}
+	for _, path := range w.files {
+		w.state[path] = statFile(path)
+	}
+	return w
+}
+
+// Start begins polling in the background until Stop is called
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		return
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.run(w.stop, w.done)
+}
+
+// Stop ends background polling and waits for it to finish
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// run is the polling loop
+func (w *Watcher) run(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, event := range w.Poll() {
+				if w.onChange != nil {
+					w.onChange(event)
+				}
+			}
+		}
+	}
+}
+
+// Poll checks each file once and returns the external changes found since
+// the previous poll
+func (w *Watcher) Poll() []ChangeEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []ChangeEvent
+	for _, path := range w.files {
+		prev := w.state[path]
+		info, err := os.Stat(path)
+		if err == nil && info.ModTime().Equal(prev.modTime) && info.Size() == prev.size && prev.hash != "" {
+			continue // Unchanged; skip hashing
+		}
+
+		current := statFile(path)
+		w.state[path] = current
+		if current.hash == prev.hash {
+			continue
+		}
+		if own, ok := w.storage.WrittenHash(path); ok && own == current.hash {
+			continue
+		}
+
+		logger.Info("Data file changed outside the app", "file", filepath.Base(path))
+		events = append(events, ChangeEvent{
+			File:       filepath.Base(path),
+			Path:       path,
+			Removed:    current.hash == "",
+			DetectedAt: time.Now().Format(time.RFC3339),
+		})
+	}
+	return events
+}
+
+// statFile reads the modification time, size and content hash of path
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Failed to stat watched file", "file", filepath.Base(path), "error", err)
+		}
+		return fileState{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size(), hash: contentHash(data)}
+}
+
+// contentHash returns the hex SHA-256 of data
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
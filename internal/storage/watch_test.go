@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"llm-desk/internal/models"
+)
+
+func TestWatcher_IgnoresOwnWrites(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	w := NewWatcher(s, time.Hour, nil)
+
+	if err := s.Save([]models.Provider{{ID: "a", Name: "A"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.SaveSettings(&AppSettings{Theme: "light"}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+
+	if events := w.Poll(); len(events) != 0 {
+		t.Errorf("Expected own writes to be ignored, got %+v", events)
+	}
+}
+
+func TestWatcher_ReportsExternalEdits(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if err := s.SaveSettings(&AppSettings{Theme: "light"}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	w := NewWatcher(s, time.Hour, nil)
+
+	if err := os.WriteFile(s.SettingsPath(), []byte(`{"theme":"dark"}`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	events := w.Poll()
+	if len(events) != 1 || events[0].File != "settings.json" || events[0].Removed {
+		t.Fatalf("Expected one settings.json change, got %+v", events)
+	}
+
+	// The same content is not reported twice
+	if events := w.Poll(); len(events) != 0 {
+		t.Errorf("Expected no further events, got %+v", events)
+	}
+
+	if err := os.Remove(s.SettingsPath()); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	events = w.Poll()
+	if len(events) != 1 || !events[0].Removed {
+		t.Errorf("Expected removal to be reported, got %+v", events)
+	}
+}
+
+func TestWatcher_StartStop(t *testing.T) {
+	s, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	changed := make(chan ChangeEvent, 1)
+	w := NewWatcher(s, 10*time.Millisecond, func(e ChangeEvent) { changed <- e })
+	w.Start()
+	defer w.Stop()
+
+	if err := os.WriteFile(s.ProvidersPath(), []byte(`[]`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	select {
+	case e := <-changed:
+		if e.File != "providers.json" {
+			t.Errorf("Expected providers.json, got %s", e.File)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change event from the polling loop")
+	}
+}
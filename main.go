@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"os"
 
 	"llm-desk/internal/logger"
 	"llm-desk/internal/version"
@@ -20,8 +21,13 @@ var assets embed.FS
 
 func main() {
 	defer logger.Recovery()
+	// Headless commands such as export-config run without a window
+	if code, ok := runCommand(os.Args[1:], os.Stdout, os.Stderr); ok {
+		os.Exit(code)
+	}
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(parseLaunchOptions(os.Args[1:]))
 
 	// Check for initialization errors
 	if app.HasInitError() {